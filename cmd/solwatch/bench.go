@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+)
+
+// Upstreams of the benchmarked analyzer, all answered by fixtureTransport.
+const (
+	benchHeliusURL = "http://helius.bench/v0/transactions"
+	benchDASURL    = "http://das.bench"
+	benchRPCURL    = "http://rpc.bench"
+)
+
+// runBench implements `solwatch bench`: it runs the analysis pipeline over
+// recorded transactions and reports throughput, allocations and latency
+// percentiles. No upstream is contacted: transactions come from the
+// fixtures, other lookups get empty answers after --latency, so results are
+// comparable between runs and machines.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	dir := fs.String("fixtures", "", "directory of *.json Helius enhanced transactions (an array or a single object per file)")
+	passes := fs.Int("passes", 10, "times to analyze every fixture")
+	concurrency := fs.Int("concurrency", 1, "analyses run in parallel")
+	latency := fs.Duration("latency", 0, "simulated delay of every upstream call")
+	solPrice := fs.Float64("sol-price", 150, "SOL price in USD served to the price oracle")
+	wallet := fs.String("wallet", "", "analyze from this wallet's point of view; default: each transaction's fee payer")
+	cpuProfile := fs.String("cpuprofile", "", "write a CPU profile to this file")
+	memProfile := fs.String("memprofile", "", "write a heap profile to this file when done")
+	verbose := fs.Bool("v", false, "keep the analyzer's log output")
+	_ = fs.Parse(args)
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "--fixtures is required")
+		return 2
+	}
+	txs, fixtures, err := loadFixtures(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+		return 1
+	}
+	an := fixtureAnalyzer(txs, *latency, *solPrice)
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--cpuprofile: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "--cpuprofile: %v\n", err)
+			return 1
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	jobs := make(chan string)
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		failed    int
+		filtered  int
+	)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for range max(*concurrency, 1) {
+		wg.Go(func() {
+			for sig := range jobs {
+				t0 := time.Now()
+				res, err := an.Reanalyze(context.Background(), sig, *wallet)
+				took := time.Since(t0)
+				mu.Lock()
+				latencies = append(latencies, took)
+				if err != nil {
+					failed++
+				} else if res == nil {
+					filtered++
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	for range max(*passes, 1) {
+		for _, f := range fixtures {
+			jobs <- f.Signature
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	n := len(latencies)
+	slices.Sort(latencies)
+	pct := func(p float64) time.Duration { return latencies[min(n-1, int(float64(n)*p))] }
+	fmt.Printf("%d analyses of %d fixture(s), concurrency %d, upstream latency %s\n", n, len(fixtures), max(*concurrency, 1), *latency)
+	fmt.Printf("  failed:      %d\n", failed)
+	fmt.Printf("  filtered:    %d\n", filtered)
+	fmt.Printf("  wall time:   %s\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("  throughput:  %.1f analyses/s\n", float64(n)/elapsed.Seconds())
+	fmt.Printf("  allocations: %d allocs/op, %d B/op\n", (after.Mallocs-before.Mallocs)/uint64(n), (after.TotalAlloc-before.TotalAlloc)/uint64(n))
+	fmt.Printf("  latency:     p50 %s  p90 %s  p99 %s  max %s\n", pct(0.50), pct(0.90), pct(0.99), latencies[n-1])
+
+	if *memProfile != "" {
+		f, err := os.Create(*memProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--memprofile: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "--memprofile: %v\n", err)
+			return 1
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// fixture identifies one recorded transaction.
+type fixture struct {
+	Signature string `json:"signature"`
+	FeePayer  string `json:"feePayer"`
+}
+
+// loadFixtures reads every *.json file in dir. Files hold what the Helius
+// transactions API returns: an array of enhanced transactions, or a single
+// one. It returns them keyed by signature, and listed in signature order.
+func loadFixtures(dir string) (map[string]json.RawMessage, []fixture, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	txs := make(map[string]json.RawMessage)
+	var list []fixture
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		var items []json.RawMessage
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+			items = []json.RawMessage{trimmed}
+		} else if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+		for _, item := range items {
+			var f fixture
+			if err := json.Unmarshal(item, &f); err != nil || f.Signature == "" {
+				return nil, nil, fmt.Errorf("%s: entry without a signature", filepath.Base(file))
+			}
+			if _, dup := txs[f.Signature]; !dup {
+				list = append(list, f)
+			}
+			txs[f.Signature] = item
+		}
+	}
+	if len(list) == 0 {
+		return nil, nil, fmt.Errorf("no transactions in %s", dir)
+	}
+	slices.SortFunc(list, func(a, b fixture) int { return strings.Compare(a.Signature, b.Signature) })
+	return txs, list, nil
+}
+
+// fixtureAnalyzer returns an analyzer with the features the bot enables by
+// default, served entirely by a fixtureTransport.
+func fixtureAnalyzer(txs map[string]json.RawMessage, latency time.Duration, solPrice float64) *analyzer.Analyzer {
+	an := analyzer.New(benchHeliusURL, benchRPCURL)
+	an.DASURL = benchDASURL
+	an.DetectEarlyBuy = true
+	an.CheckSellRoute = true
+	an.HolderConcentration = 50
+	an.MarketData = true
+	an.SetTransport(&fixtureTransport{txs: txs, latency: latency, solPrice: solPrice})
+	return an
+}
+
+// fixtureTransport stands in for every upstream the analyzer calls. The
+// Helius transactions endpoint is served from txs, ignoring a "~n" suffix
+// on the signature (see fixtureSignature); DAS names every mint
+// after its first characters; the price API knows SOL and USDC; anything
+// else (RPC, Jupiter) gets an empty result, which the analyzer treats like
+// an unknown token.
+type fixtureTransport struct {
+	txs      map[string]json.RawMessage
+	latency  time.Duration
+	solPrice float64
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.latency > 0 {
+		select {
+		case <-time.After(t.latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	body := `{"jsonrpc":"2.0","id":1,"result":null}`
+	var payload struct {
+		Transactions []string `json:"transactions"`
+		Params       struct {
+			IDs []string `json:"ids"`
+		} `json:"params"`
+	}
+	if req.Body != nil {
+		_ = json.NewDecoder(req.Body).Decode(&payload)
+	}
+	switch {
+	case req.URL.String() == benchHeliusURL:
+		list := []json.RawMessage{}
+		for _, sig := range payload.Transactions {
+			sig, _, _ = strings.Cut(sig, "~")
+			if tx, ok := t.txs[sig]; ok {
+				list = append(list, tx)
+			}
+		}
+		raw, _ := json.Marshal(list)
+		body = string(raw)
+	case req.URL.String() == benchDASURL:
+		assets := make([]map[string]any, 0, len(payload.Params.IDs))
+		for _, id := range payload.Params.IDs {
+			symbol := strings.ToUpper(id[:min(4, len(id))])
+			assets = append(assets, map[string]any{"id": id, "token_info": map[string]any{"symbol": symbol, "decimals": 6}})
+		}
+		raw, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "result": assets})
+		body = string(raw)
+	case strings.Contains(req.URL.Host, "coingecko"):
+		body = fmt.Sprintf(`{"solana":{"usd":%g},"usd-coin":{"usd":1}}`, t.solPrice)
+	case req.Method == http.MethodGet:
+		body = `{}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// fixtureSignature returns a distinct signature for the n-th replay of sig,
+// so the handler's replay dedupe doesn't skip it. "~" is not base58, so it
+// can't clash with a real signature.
+func fixtureSignature(sig string, n int) string {
+	return fmt.Sprintf("%s~%d", sig, n)
+}
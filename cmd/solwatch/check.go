@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/config"
+	"github.com/0xsamyy/solwatch-v2/internal/health"
+	tg "github.com/go-telegram/bot"
+	"github.com/gorilla/websocket"
+)
+
+// checkResult is one row of the `solwatch check` table.
+type checkResult struct {
+	name   string
+	err    error
+	detail string
+	took   time.Duration
+}
+
+// runCheck implements `solwatch check`: it validates the configuration and
+// tries every upstream once (WebSocket dial, Helius API, Solana RPC,
+// Telegram getMe, store open/close), printing a pass/fail table. It exits
+// non-zero when any check fails. Stop the bot first when using Bolt.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 15*time.Second, "timeout per check")
+	applyFlags := config.RegisterFlags(fs)
+	_ = fs.Parse(args)
+	applyFlags()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL  config\n%v\n", err)
+		return 1
+	}
+
+	type check struct {
+		name string
+		run  func(ctx context.Context) (string, error)
+	}
+	checks := []check{
+		{"config", func(context.Context) (string, error) { return "valid", nil }},
+		{"helius websocket", func(ctx context.Context) (string, error) {
+			conn, _, err := websocket.DefaultDialer.DialContext(ctx, cfg.HeliusWSS, http.Header{})
+			if err != nil {
+				return "", redactErr(err, cfg.HeliusWSS)
+			}
+			_ = conn.Close()
+			return "connected", nil
+		}},
+		{"helius api", probeCheck(health.Endpoint{Name: "helius api", URL: cfg.HeliusAPIURL})},
+	}
+	for i, u := range cfg.SolanaRPCURLs {
+		name := "solana rpc"
+		if len(cfg.SolanaRPCURLs) > 1 {
+			name = fmt.Sprintf("solana rpc %d", i+1)
+		}
+		checks = append(checks, check{name, probeCheck(health.Endpoint{Name: name, URL: u, JSONRPC: true})})
+	}
+	checks = append(checks, []check{
+		{"telegram", func(ctx context.Context) (string, error) {
+			bot, err := tg.New(cfg.TelegramBotToken, tg.WithSkipGetMe())
+			if err != nil {
+				return "", err
+			}
+			me, err := bot.GetMe(ctx)
+			if err != nil {
+				return "", err
+			}
+			return "@" + me.Username, nil
+		}},
+		{"store", func(ctx context.Context) (string, error) {
+			st, err := openStore(ctx, cfg)
+			if err != nil {
+				return "", err
+			}
+			wallets, err := st.ListWallets(ctx)
+			if cerr := st.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d wallet(s)", len(wallets)), nil
+		}},
+	}...)
+
+	var results []checkResult
+	failed := 0
+	for _, c := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		start := time.Now()
+		detail, err := c.run(ctx)
+		cancel()
+		results = append(results, checkResult{name: c.name, err: err, detail: detail, took: time.Since(start)})
+		if err != nil {
+			failed++
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESULT\tCHECK\tTIME\tDETAIL")
+	for _, r := range results {
+		status, detail := "PASS", r.detail
+		if r.err != nil {
+			status, detail = "FAIL", r.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status, r.name, r.took.Round(time.Millisecond), detail)
+	}
+	_ = w.Flush()
+
+	if failed > 0 {
+		fmt.Printf("\n%d of %d checks failed\n", failed, len(results))
+		return 1
+	}
+	fmt.Println("\nall checks passed")
+	return 0
+}
+
+// probeCheck adapts a one-off health probe to a check.
+func probeCheck(e health.Endpoint) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		st := health.NewProber(e).Probe(ctx, e)
+		if !st.OK {
+			return "", fmt.Errorf("%s", st.Error)
+		}
+		return "reachable", nil
+	}
+}
+
+// redactErr keeps API keys embedded in url out of error output.
+func redactErr(err error, url string) error {
+	msg := err.Error()
+	if url != "" {
+		msg = strings.ReplaceAll(msg, url, "<url>")
+	}
+	return fmt.Errorf("%s", msg)
+}
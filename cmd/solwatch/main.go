@@ -2,17 +2,33 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"syscall"
+	"time"
 
+	"github.com/0xsamyy/solwatch-v2/internal/admin"
 	"github.com/0xsamyy/solwatch-v2/internal/analyzer" // V2 Import
 	"github.com/0xsamyy/solwatch-v2/internal/config"
+	"github.com/0xsamyy/solwatch-v2/internal/email"
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/geyser"
+	"github.com/0xsamyy/solwatch-v2/internal/grpcapi"
 	"github.com/0xsamyy/solwatch-v2/internal/health"
+	"github.com/0xsamyy/solwatch-v2/internal/hooks"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/plugins"
+	"github.com/0xsamyy/solwatch-v2/internal/portfolio"
+	"github.com/0xsamyy/solwatch-v2/internal/pubsub"
+	"github.com/0xsamyy/solwatch-v2/internal/retention"
 	"github.com/0xsamyy/solwatch-v2/internal/store"
 	"github.com/0xsamyy/solwatch-v2/internal/telegram"
 	"github.com/0xsamyy/solwatch-v2/internal/tracker"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
 	tg "github.com/go-telegram/bot"
 )
 
@@ -20,15 +36,47 @@ func main() {
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lmsgprefix)
 	log.SetPrefix("solwatch ")
 
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		os.Exit(runSimulate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		os.Exit(runBench(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "soak" {
+		os.Exit(runSoak(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "unseal" {
+		os.Exit(runUnseal(os.Args[2:]))
+	}
+
+	noPersist := flag.Bool("no-persist", false, "keep all state in memory; nothing is written to DB_PATH")
+	applyFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	applyFlags()
+
 	cfg := config.MustLoad()
 	log.Println(cfg.RedactedSummary())
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	util.Errors.Window = cfg.ErrorLogWindow
+	go util.Supervise(ctx, "errorlog", util.Errors.Run)
 
-	st, err := store.NewBolt(cfg.DBPath)
-	if err != nil {
-		log.Fatalf("store: %v", err)
+	var st store.Store
+	if *noPersist {
+		log.Println("running with --no-persist; state is in-memory only")
+		st = store.NewMemory()
+	} else {
+		var err error
+		if st, err = openStore(ctx, cfg); err != nil {
+			log.Fatalf("store: %v", err)
+		}
+		if b, ok := st.(*store.Bolt); ok && cfg.DBMaintenanceInterval > 0 {
+			go util.Supervise(ctx, "maintenance", func(ctx context.Context) { b.RunMaintenance(ctx, cfg.DBMaintenanceInterval, 0.5) })
+		}
 	}
 	defer func() {
 		if e := st.Close(); e != nil {
@@ -38,9 +86,145 @@ func main() {
 
 	// V2 Change: Initialize the new Analyzer
 	an := analyzer.New(cfg.HeliusAPIURL, cfg.SolanaRPCURL)
+	an.SetClients(analyzer.ClientConfig(cfg.HeliusHTTP), analyzer.ClientConfig(cfg.RPCHTTP), analyzer.ClientConfig(cfg.PriceHTTP))
+	an.DASURL = cfg.HeliusRPCURL
+	an.SetHeliusPools(cfg.HeliusAPIPool, cfg.HeliusRPCPool)
+	an.SetRPCPool(cfg.SolanaRPCURLs, cfg.SolanaRPCWeights)
+	an.NegativeTTL = cfg.MetadataNegativeTTL
+	an.DetectSandwich = cfg.MEVDetection
+	an.History = st
+	an.Symbols = st
+	an.MintAccounts = st
+	an.Marketplaces = cfg.MarketplaceLabels
+	an.AnomalyFactor = cfg.AnomalyFactor
+	an.DetectEarlyBuy = cfg.EarlyBuy
+	an.CheckSellRoute = cfg.SellRouteCheck
+	an.HolderConcentration = cfg.HolderConcentration
+	an.MarketData = cfg.MarketData
+	an.SeverityUSD = cfg.SeverityUSD
+	an.Budget = analyzer.Budget{Fetch: cfg.AnalysisFetch, Metadata: cfg.AnalysisMetadata, Prices: cfg.AnalysisPrices}
+
+	pruner := retention.New(retention.Policy{
+		History:  cfg.HistoryRetention,
+		Metadata: cfg.MetadataTTL,
+		Prices:   cfg.PriceCacheTTL,
+		Notified: telegram.NotifiedWindow,
+		Settings: map[string]time.Duration{
+			analyzer.SymbolKeyPrefix:      cfg.HistoryRetention,
+			analyzer.MintAccountKeyPrefix: cfg.HistoryRetention,
+			portfolio.SamplesKeyPrefix:    cfg.HistoryRetention,
+		},
+	}, st, an)
+	go util.Supervise(ctx, "retention", func(ctx context.Context) { pruner.Run(ctx, cfg.PruneInterval) })
+	if cfg.MetadataNegativeTTL > 0 {
+		go util.Supervise(ctx, "metadata", func(ctx context.Context) { an.RunMetadataRefresh(ctx, cfg.MetadataNegativeTTL) })
+	}
+	if cfg.TokenListInterval > 0 {
+		go util.Supervise(ctx, "tokenlist", func(ctx context.Context) { an.RunTokenListSync(ctx, cfg.TokenListInterval) })
+	}
 
-	tm := tracker.NewManager(cfg.HeliusWSS, cfg.Commitment)
+	sigs := make(chan tracker.Signature, 256)
+	tm := tracker.NewManager(cfg.HeliusWSS, cfg.Commitment, sigs)
+	tm.Context = ctx
+	tm.DialRate = cfg.SubscribeRate
+	tm.BlockMode = cfg.SubscribeMode == "blocks"
+	tm.WSSPool = cfg.HeliusWSSPool
+	tm.KeepAlive = tracker.KeepAlive{
+		PingInterval:   cfg.WSPingInterval,
+		ReadTimeout:    cfg.WSReadTimeout,
+		MaxMissedPongs: cfg.WSMaxMissedPongs,
+		IdleRecycle:    cfg.WSIdleRecycle,
+	}
+	tm.Reconnect = tracker.Reconnect{
+		Initial:        cfg.ReconnectInitial,
+		Max:            cfg.ReconnectMax,
+		Factor:         cfg.ReconnectFactor,
+		Jitter:         cfg.ReconnectJitter,
+		StormThreshold: cfg.ReconnectStorm,
+		StormWindow:    cfg.ReconnectStormWindow,
+	}
+	if cfg.ReconnectStorm == 0 {
+		tm.Reconnect.StormThreshold = -1 // off
+	}
+	if cfg.BackfillLimit > 0 {
+		tm.Backfill = tracker.Backfill{RPC: an.RPCClient(), Limit: cfg.BackfillLimit}
+	}
+	if cfg.SubscribeMode == "geyser" {
+		tm.Source = &geyser.Source{
+			URL:          cfg.GeyserURL,
+			Token:        cfg.GeyserToken,
+			PingInterval: cfg.WSPingInterval,
+			ReadTimeout:  cfg.WSReadTimeout,
+		}
+	}
 	hlth := health.New(tm, st)
+	if cfg.ProbeInterval > 0 {
+		var endpoints []health.Endpoint
+		for i, u := range cfg.SolanaRPCURLs {
+			name := "Solana RPC"
+			if len(cfg.SolanaRPCURLs) > 1 {
+				name = fmt.Sprintf("Solana RPC %d", i+1)
+			}
+			endpoints = append(endpoints, health.Endpoint{Name: name, URL: u, JSONRPC: true})
+		}
+		endpoints = append(endpoints, health.Endpoint{Name: "Helius API", URL: cfg.HeliusAPIURL})
+		hlth.Prober = health.NewProber(endpoints...)
+		go util.Supervise(ctx, "prober", func(ctx context.Context) { hlth.Prober.Run(ctx, cfg.ProbeInterval) })
+	}
+
+	bus := events.NewBus()
+	if cfg.EventBusURL != "" {
+		p, err := pubsub.New(cfg.EventBusURL)
+		if err != nil {
+			log.Fatalf("event bus: %v", err)
+		}
+		go util.Supervise(ctx, "pubsub", func(ctx context.Context) { pubsub.Run(ctx, bus, p) })
+	}
+	if cfg.HookCommand != "" {
+		runner := hooks.Runner{
+			Command:     cfg.HookCommand,
+			Timeout:     cfg.HookTimeout,
+			Concurrency: cfg.HookConcurrency,
+		}
+		go util.Supervise(ctx, "hooks", func(ctx context.Context) { runner.Run(ctx, bus) })
+	}
+	if cfg.SMTPHost != "" {
+		rules, err := email.ParseRules(cfg.EmailRules)
+		if err != nil {
+			log.Fatalf("EMAIL_RULES: %v", err)
+		}
+		notifier := email.New(email.Config{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			User:     cfg.SMTPUser,
+			Password: cfg.SMTPPassword,
+			From:     cfg.EmailFrom,
+			To:       cfg.EmailTo,
+			Rules:    rules,
+			DigestAt: cfg.EmailDigestAt,
+		})
+		go util.Supervise(ctx, "email", func(ctx context.Context) { notifier.Run(ctx, bus) })
+	}
+	if cfg.AdminAddr != "" {
+		srv := admin.New(cfg.AdminAddr, cfg.AdminToken, st, hlth, bus)
+		if cfg.StoreEncryptionKey != "" {
+			srv.SnapshotKey = []byte(cfg.StoreEncryptionKey)
+		}
+		go util.Supervise(ctx, "admin", srv.Run)
+	}
+	if cfg.MetricsAddr != "" {
+		go util.Supervise(ctx, "metrics", func(ctx context.Context) { metrics.Serve(ctx, cfg.MetricsAddr) })
+	}
+	if cfg.SnapshotPath != "" {
+		var key []byte
+		if cfg.StoreEncryptionKey != "" {
+			key = []byte(cfg.StoreEncryptionKey)
+		}
+		go util.Supervise(ctx, "snapshots", func(ctx context.Context) { runSnapshots(ctx, st, cfg.SnapshotPath, cfg.SnapshotInterval, key) })
+	}
+	if cfg.NetWorthInterval > 0 {
+		go util.Supervise(ctx, "networth", func(ctx context.Context) { portfolio.RunSampler(ctx, st, an.SOLBalance, cfg.NetWorthInterval) })
+	}
 
 	bot, err := tg.New(cfg.TelegramBotToken)
 	if err != nil {
@@ -49,18 +233,127 @@ func main() {
 
 	// V2 Change: Pass the analyzer instance to the Telegram handler
 	th := telegram.New(bot, tm, st, hlth, an, cfg.TelegramAdminChatID, cancel)
-
-	if addrs, err := st.ListWallets(ctx); err != nil {
-		log.Printf("store list: %v", err)
-	} else {
-		for _, a := range addrs {
-			if err := tm.Track(ctx, a); err != nil {
-				log.Printf("track %s: %v", a, err)
-			}
+	util.PanicHandler = th.NotifyPanic
+	util.ErrorHandler = th.NotifyError
+	go util.Supervise(ctx, "analysis", func(ctx context.Context) { th.Consume(ctx, sigs) })
+	th.BotRateLimit = cfg.BotRateLimit
+	th.SuppressAirdrops = cfg.SuppressAirdrops
+	th.MaxWalletsPerUser = cfg.MaxWalletsPerUser
+	th.FirehoseChat = cfg.FirehoseChatID
+	th.FirehoseRateLimit = cfg.FirehoseRateLimit
+	th.DebugChat = cfg.DebugChatID
+	th.DebugErrorGap = cfg.DebugErrorGap
+	th.CompactAbove = cfg.CompactAbove
+	th.Workers = cfg.AnalysisWorkers
+	th.ConfluenceWallets = cfg.ConfluenceWallets
+	th.ConfluenceWindow = cfg.ConfluenceWindow
+	th.AnalysisTimeout = cfg.AnalysisTimeout
+	th.AutoTrackMinUSD = cfg.AutoTrackMinUSD
+	th.AutoTrack = cfg.AutoTrackMode == "auto"
+	th.AutoTrackTrial = cfg.AutoTrackTrial
+	th.AutoTrackIgnore = make(map[string]bool)
+	for _, addr := range cfg.AutoTrackIgnore {
+		th.AutoTrackIgnore[addr] = true
+	}
+	th.RecheckFinalized = cfg.FinalityRecheck && cfg.Commitment != "finalized"
+	th.AllowUsers(cfg.AllowedUsers...)
+	th.Events = bus
+	if th.Templates, err = telegram.LoadTemplates(cfg.TemplatesDir); err != nil {
+		log.Fatalf("templates: %v", err)
+	}
+	if cfg.PluginDir != "" {
+		ph, err := plugins.Load(cfg.PluginDir)
+		if err != nil {
+			log.Fatalf("plugins: %v", err)
 		}
+		th.Plugins = ph
+		ph.RunSinks(ctx, bus)
+	}
+	if cfg.DroppedAlertAfter > 0 {
+		go util.Supervise(ctx, "watchdog", (&health.Watchdog{
+			H:      hlth,
+			After:  cfg.DroppedAlertAfter,
+			Repeat: cfg.DroppedAlertRepeat,
+			Notify: th.NotifyDropped,
+		}).Run)
+	}
+	go util.Supervise(ctx, "trials", func(ctx context.Context) { th.RunTrialSweeper(ctx, time.Minute) })
+	if err := th.ClaimUnownedWallets(ctx); err != nil {
+		log.Printf("claim wallets: %v", err)
+	}
+
+	if cfg.StartPaused {
+		tm.Pause()
+		log.Println("starting paused; /resumeall to subscribe")
+	}
+	rep, err := th.Resubscribe(ctx)
+	if err != nil {
+		log.Printf("resubscribe: %v", err)
+	}
+	if cfg.LifecycleNotices {
+		th.AnnounceStart(ctx, buildVersion(), rep, err)
+	}
+	util.Go("rampup", func() { th.ReportRampUp(ctx, 10*time.Second, cfg.LifecycleNotices) })
+	if cfg.GRPCAddr != "" {
+		go util.Supervise(ctx, "grpc", grpcapi.New(cfg.GRPCAddr, cfg.AdminToken, cfg.TelegramAdminChatID, th, bus).Run)
 	}
 
 	log.Println("started; awaiting Telegram commands")
 	th.Run(ctx)
+	if cfg.LifecycleNotices {
+		th.AnnounceStop(buildVersion())
+	}
 	log.Println("shutdown complete")
 }
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version string
+
+// buildVersion returns version, or the module version and VCS revision Go
+// stamped into the binary when it wasn't set.
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	v := info.Main.Version
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && len(s.Value) >= 12 {
+			v += " " + s.Value[:12]
+		}
+	}
+	return v
+}
+
+// openStore opens the configured persistent store: Postgres when
+// DATABASE_URL is set, otherwise the (optionally encrypted) Bolt file.
+func openStore(ctx context.Context, cfg config.Config) (store.Store, error) {
+	if cfg.DatabaseURL != "" {
+		return store.NewPostgres(ctx, cfg.DatabaseURL, cfg.DBMaxConns)
+	}
+	if cfg.StoreEncryptionKey != "" {
+		return store.NewEncryptedBolt(cfg.DBPath, []byte(cfg.StoreEncryptionKey))
+	}
+	return store.NewBolt(cfg.DBPath)
+}
+
+// runSnapshots writes a JSON export of the store every interval so other
+// processes can read state without touching the locked Bolt file. With a
+// key the file is sealed like the store (see `solwatch unseal`).
+func runSnapshots(ctx context.Context, st store.Store, path string, interval time.Duration, key []byte) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := store.WriteSnapshotFile(ctx, st, path, 0, key); err != nil {
+			log.Printf("snapshot %s: %v", path, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/config"
+	"github.com/0xsamyy/solwatch-v2/internal/email"
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// runSimulate implements `solwatch simulate`: it replays stored history
+// through the anomaly and email-rule settings and reports what would have
+// been flagged, so thresholds can be tuned offline. Stop the bot first when
+// using Bolt, which allows only one process at a time.
+func runSimulate(args []string) int {
+	cfg := config.MustLoad()
+
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	from := fs.String("from", "", "start date (YYYY-MM-DD or RFC3339); default: all history")
+	to := fs.String("to", "", "end date (YYYY-MM-DD or RFC3339); default: now")
+	wallet := fs.String("wallet", "", "only replay this wallet; default: every tracked wallet")
+	factor := fs.Float64("anomaly-factor", cfg.AnomalyFactor, "ANOMALY_FACTOR to simulate (0 disables)")
+	rulesStr := fs.String("email-rules", cfg.EmailRules, "EMAIL_RULES to simulate")
+	verbose := fs.Bool("v", false, "list every replayed transaction, not only flagged ones")
+	_ = fs.Parse(args)
+
+	start, err := parseDate(*from, time.Time{}, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--from: %v\n", err)
+		return 2
+	}
+	end, err := parseDate(*to, time.Now().UTC(), true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--to: %v\n", err)
+		return 2
+	}
+	rules, err := email.ParseRules(*rulesStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--email-rules: %v\n", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	st, err := openStore(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "store: %v\n", err)
+		return 1
+	}
+	defer st.Close()
+
+	wallets := []string{*wallet}
+	if *wallet == "" {
+		if wallets, err = st.ListWallets(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "list wallets: %v\n", err)
+			return 1
+		}
+	}
+
+	var total, unusual, emailed int
+	for _, w := range wallets {
+		entries, err := st.RecentHistory(ctx, w, 0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "history %s: %v\n", w, err)
+			return 1
+		}
+		t, u, e := simulateWallet(w, entries, start, end, *factor, rules, *verbose)
+		total, unusual, emailed = total+t, unusual+u, emailed+e
+	}
+
+	// Per-chat alert filters, mutes and minimums aren't replayed, so there
+	// is no count of Telegram alerts here.
+	fmt.Printf("\n%d transaction(s) replayed across %d wallet(s)\n", total, len(wallets))
+	fmt.Printf("  flagged unusual:  %d (anomaly factor %g)\n", unusual, *factor)
+	if len(rules) > 0 {
+		fmt.Printf("  emails:           %d (rules %q)\n", emailed, *rulesStr)
+	}
+	return 0
+}
+
+// simulateWallet replays one wallet's history (newest first, as stored).
+// Entries before start still seed the anomaly baseline.
+func simulateWallet(wallet string, entries []store.HistoryEntry, start, end time.Time, factor float64, rules []email.Rule, verbose bool) (total, unusual, emailed int) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+
+	var sizes []float64 // newest first, like RecentSizes
+	header := false
+	for _, e := range entries {
+		ratio, _ := analyzer.AnomalyRatio(e.SizeUSD, sizes, factor)
+		if e.SizeUSD > 0 {
+			sizes = append([]float64{e.SizeUSD}, sizes...)
+		}
+		if e.Time.Before(start) || e.Time.After(end) {
+			continue
+		}
+		total++
+
+		ev := historyEvent(e, ratio)
+		mailed := len(rules) > 0 && matchAny(rules, ev)
+		if ratio > 0 {
+			unusual++
+		}
+		if mailed {
+			emailed++
+		}
+		if !verbose && ratio == 0 && !mailed {
+			continue
+		}
+		if !header {
+			fmt.Printf("\n%s\n", wallet)
+			header = true
+		}
+		line := fmt.Sprintf("  %s  %-12s", e.Time.Format("2006-01-02 15:04"), e.Type)
+		if e.SizeUSD > 0 {
+			line += fmt.Sprintf("  $%12.2f", e.SizeUSD)
+		} else {
+			line += fmt.Sprintf("  %13s", "-")
+		}
+		if ratio > 0 {
+			line += fmt.Sprintf("  unusual %.0fx", ratio)
+		}
+		if mailed {
+			line += "  email"
+		}
+		fmt.Println(line + "  " + e.Signature)
+	}
+	return total, unusual, emailed
+}
+
+func historyEvent(e store.HistoryEntry, ratio float64) events.Event {
+	conv := func(list []store.HistoryAmount) []events.Amount {
+		out := make([]events.Amount, 0, len(list))
+		for _, a := range list {
+			out = append(out, events.Amount{Mint: a.Mint, Symbol: a.Symbol, Amount: a.Amount, USD: a.USD})
+		}
+		return out
+	}
+	return events.Event{
+		Signature:     e.Signature,
+		Wallet:        e.Wallet,
+		Type:          e.Type,
+		Source:        e.Source,
+		Time:          e.Time,
+		Sent:          conv(e.Sent),
+		Received:      conv(e.Received),
+		SizeUSD:       e.SizeUSD,
+		AnomalyFactor: ratio,
+	}
+}
+
+func matchAny(rules []email.Rule, ev events.Event) bool {
+	for _, r := range rules {
+		if r.Match(ev) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDate accepts YYYY-MM-DD or RFC3339. A bare date used as an upper
+// bound (endOfDay) covers that whole day.
+func parseDate(s string, def time.Time, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/health"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+	"github.com/0xsamyy/solwatch-v2/internal/telegram"
+	"github.com/0xsamyy/solwatch-v2/internal/tracker"
+	tg "github.com/go-telegram/bot"
+)
+
+// soakAdminChat is the chat every soak alert goes to: no wallet has an
+// owner, so the handler falls back to the admin.
+const soakAdminChat = 1
+
+// runSoak implements `solwatch soak`: it feeds recorded transactions into
+// the real analysis pipeline (worker pool, per-wallet queues, handler) at a
+// fixed rate and prints queue depth, throughput and memory as it goes.
+// Upstreams are answered from the fixtures as in `solwatch bench`, Telegram
+// by a local stand-in that prints every alert to stdout, and state lives in
+// memory, so nothing outside the process is touched.
+func runSoak(args []string) int {
+	fs := flag.NewFlagSet("soak", flag.ExitOnError)
+	dir := fs.String("fixtures", "", "directory of *.json Helius enhanced transactions (see `solwatch bench`)")
+	rate := fs.Float64("rate", 50, "signatures fed into the pipeline per second")
+	duration := fs.Duration("duration", time.Minute, "how long to generate load")
+	queue := fs.Int("queue", 256, "capacity of the signature queue, as between subscribers and workers in the bot")
+	workers := fs.Int("workers", telegram.DefaultWorkers, "analysis workers")
+	latency := fs.Duration("latency", 50*time.Millisecond, "simulated delay of every upstream call")
+	solPrice := fs.Float64("sol-price", 150, "SOL price in USD served to the price oracle")
+	botRateLimit := fs.Duration("bot-rate-limit", 0, "BOT_RATE_LIMIT to apply")
+	every := fs.Duration("report", 10*time.Second, "how often to print a progress line")
+	quiet := fs.Bool("q", false, "don't print alerts, only progress")
+	verbose := fs.Bool("v", false, "keep the bot's log output")
+	_ = fs.Parse(args)
+
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "--fixtures is required")
+		return 2
+	}
+	if *rate <= 0 {
+		fmt.Fprintln(os.Stderr, "--rate must be positive")
+		return 2
+	}
+	txs, fixtures, err := loadFixtures(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+		return 1
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	var alerts atomic.Int64
+	out := io.Writer(os.Stdout)
+	if *quiet {
+		out = io.Discard
+	}
+	sink := httptest.NewServer(stdoutSink(out, &alerts))
+	defer sink.Close()
+	bot, err := tg.New("soak", tg.WithServerURL(sink.URL), tg.WithSkipGetMe())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "telegram: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	st := store.NewMemory()
+	sigs := make(chan tracker.Signature, *queue)
+	tm := tracker.NewManager("", "confirmed", sigs) // never dials: no wallet is tracked
+	th := telegram.New(bot, tm, st, health.New(tm, st), fixtureAnalyzer(txs, *latency, *solPrice), soakAdminChat, cancel)
+	th.Workers = *workers
+	th.BotRateLimit = *botRateLimit
+
+	consumed := make(chan struct{})
+	go func() {
+		defer close(consumed)
+		th.Consume(ctx, sigs)
+	}()
+
+	var (
+		sent     int
+		blocked  int // sends that found the queue full
+		peakHeap uint64
+		mem      runtime.MemStats
+	)
+	start := time.Now()
+	// report prints one progress line; "waited" counts signatures that
+	// queued behind an earlier one of the same wallet.
+	report := func(label string, generating time.Duration) {
+		runtime.ReadMemStats(&mem)
+		peakHeap = max(peakHeap, mem.HeapAlloc)
+		fmt.Fprintf(os.Stderr, "[soak] %-6s sent %d (%.1f/s)  alerts %d  queue %d/%d  full %d  waited %d  heap %.1fMB  goroutines %d\n",
+			label, sent, float64(sent)/generating.Seconds(), alerts.Load(), len(sigs), cap(sigs), blocked,
+			metrics.Get("pipeline.queued"), float64(mem.HeapAlloc)/(1<<20), runtime.NumGoroutine())
+	}
+
+	tick := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+	defer tick.Stop()
+	progress := time.NewTicker(*every)
+	defer progress.Stop()
+	deadline := time.After(*duration)
+generate:
+	for {
+		select {
+		case <-deadline:
+			break generate
+		case <-progress.C:
+			report(time.Since(start).Round(time.Second).String(), time.Since(start))
+		case <-tick.C:
+			f := fixtures[sent%len(fixtures)]
+			ev := tracker.Signature{Signature: fixtureSignature(f.Signature, sent), Wallet: f.FeePayer, Received: time.Now()}
+			select {
+			case sigs <- ev:
+			default:
+				blocked++
+				sigs <- ev // wait like a subscriber would
+			}
+			sent++
+		}
+	}
+
+	// Let the workers drain what was queued before reporting.
+	generated := time.Since(start)
+	close(sigs)
+	<-consumed
+	report("done", generated)
+	fmt.Fprintf(os.Stderr, "[soak] generated for %s, drained in %s, peak heap %.1fMB\n",
+		generated.Round(time.Millisecond), (time.Since(start) - generated).Round(time.Millisecond), float64(peakHeap)/(1<<20))
+	return 0
+}
+
+// stdoutSink stands in for the Telegram Bot API: every message sent or
+// edited is printed to out as one line and counted in alerts, and every
+// call succeeds.
+func stdoutSink(out io.Writer, alerts *atomic.Int64) http.Handler {
+	var (
+		mu    sync.Mutex
+		msgID int
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseMultipartForm(1 << 20)
+		method := path.Base(r.URL.Path)
+		chatID, _ := strconv.ParseInt(r.FormValue("chat_id"), 10, 64)
+		text := r.FormValue("text")
+		if text == "" {
+			text = r.FormValue("caption")
+		}
+
+		var result any = true
+		if strings.HasPrefix(method, "send") || strings.HasPrefix(method, "edit") {
+			mu.Lock()
+			msgID++
+			id := msgID
+			mu.Unlock()
+			if strings.HasPrefix(method, "send") {
+				alerts.Add(1)
+			}
+			line, _, _ := strings.Cut(text, "\n")
+			fmt.Fprintf(out, "%s %d #%d: %s\n", method, chatID, id, line)
+			result = map[string]any{
+				"message_id": id,
+				"date":       time.Now().Unix(),
+				"chat":       map[string]any{"id": chatID, "type": "private"},
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+	})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// runUnseal implements `solwatch unseal`: it decrypts a snapshot file or
+// /api/snapshot response sealed with STORE_ENCRYPTION_KEY and prints the
+// JSON. The key comes from --key-file or STORE_ENCRYPTION_KEY.
+func runUnseal(args []string) int {
+	fs := flag.NewFlagSet("unseal", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "file holding the STORE_ENCRYPTION_KEY; default: the environment variable")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: solwatch unseal [--key-file <path>] [<file>|-]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	key := os.Getenv("STORE_ENCRYPTION_KEY")
+	if *keyFile != "" {
+		raw, err := os.ReadFile(*keyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--key-file: %v\n", err)
+			return 2
+		}
+		key = string(raw)
+	}
+	if key = strings.TrimSpace(key); key == "" {
+		fmt.Fprintln(os.Stderr, "no key: set STORE_ENCRYPTION_KEY or pass --key-file")
+		return 2
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch path := fs.Arg(0); path {
+	case "", "-":
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	plain, err := store.OpenExport(data, []byte(key))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	_, _ = os.Stdout.Write(plain)
+	fmt.Println()
+	return 0
+}
@@ -0,0 +1,43 @@
+// Command programs is an example solwatch plugin that names well-known
+// programs a transaction touched. Build it with:
+//
+//	go build -buildmode=plugin -o plugins/programs.so ./examples/plugins/programs
+//
+// and start solwatch with PLUGIN_DIR=plugins.
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/pkg/sdk"
+)
+
+var known = map[string]string{
+	"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4":  "Jupiter v6",
+	"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8": "Raydium AMM",
+	"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc":  "Orca Whirlpools",
+	"6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P":  "Pump.fun",
+}
+
+type programs struct{}
+
+func (programs) Name() string { return "programs" }
+
+func (programs) Interpret(_ context.Context, ev sdk.Event) []string {
+	var names []string
+	for _, id := range ev.Programs {
+		if n, ok := known[id]; ok {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return []string{"Programs: " + strings.Join(names, ", ")}
+}
+
+// Plugin is the symbol solwatch looks up.
+var Plugin sdk.Plugin = programs{}
+
+func main() {}
@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// web holds the dashboard. The page itself is public; it asks for the admin
+// token and sends it with every API call, so no data leaks without it.
+//
+//go:embed web
+var web embed.FS
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	page, err := web.ReadFile("web/index.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(page)
+}
+
+func (s *Server) handleWallets(w http.ResponseWriter, r *http.Request) {
+	list, err := s.st.ListWallets(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []string{}
+	}
+	writeJSON(w, list)
+}
+
+// walletDetail is the per-wallet payload behind the dashboard charts.
+type walletDetail struct {
+	Address   string               `json:"address"`
+	History   []store.HistoryEntry `json:"history"`
+	Positions []store.Position     `json:"positions"`
+	Closed    []store.Position     `json:"closed_positions"`
+}
+
+func (s *Server) handleWallet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	d := walletDetail{Address: r.PathValue("addr")}
+	var err error
+	if d.History, err = s.st.RecentHistory(ctx, d.Address, 500); err == nil {
+		if d.Positions, err = s.st.ListPositions(ctx, d.Address); err == nil {
+			d.Closed, err = s.st.ListClosedPositions(ctx, d.Address)
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, d)
+}
@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/health"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// defaultHistoryLimit caps history per wallet in /api/snapshot unless the
+// caller asks for more with ?history=N (0 = all).
+const defaultHistoryLimit = 100
+
+// Server is the optional admin HTTP API and dashboard. Every route except
+// /healthz and the dashboard page needs the token as
+// "Authorization: Bearer <token>"; /ws/events also takes it as the first
+// message, for browsers (see handleEvents).
+type Server struct {
+	// SnapshotKey, when set (the STORE_ENCRYPTION_KEY), seals
+	// /api/snapshot responses with store.SealExport.
+	SnapshotKey []byte
+
+	addr  string
+	token string
+	st    store.Store
+	hlth  *health.Health
+	bus   *events.Bus
+	mux   *http.ServeMux
+}
+
+// New builds the admin server. Call Run to start listening.
+func New(addr, token string, st store.Store, hlth *health.Health, bus *events.Bus) *Server {
+	s := &Server{addr: addr, token: token, st: st, hlth: hlth, bus: bus, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	s.Handle("/api/snapshot", http.HandlerFunc(s.handleSnapshot))
+	s.Handle("/api/health", http.HandlerFunc(s.handleHealth))
+	s.mux.HandleFunc("/ws/events", s.handleEvents) // authenticates itself
+	s.Handle("GET /api/wallets", http.HandlerFunc(s.handleWallets))
+	s.Handle("GET /api/wallets/{addr}", http.HandlerFunc(s.handleWallet))
+	s.mux.HandleFunc("/", s.handleDashboard)
+	return s
+}
+
+// Handle registers an authenticated route. Other packages use it to mount
+// their endpoints on the same listener.
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, s.auth(h))
+}
+
+// Run serves until ctx is done, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) {
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	log.Printf("[admin] listening on %s", s.addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("[admin] server error: %v", err)
+	}
+}
+
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorized reports whether r carries the token in its Authorization
+// header. Query parameters aren't accepted: they end up in proxy and
+// access logs.
+func (s *Server) authorized(r *http.Request) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.validToken(got)
+}
+
+func (s *Server) validToken(got string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1
+}
+
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("history"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "history must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	exp, err := store.ExportAll(r.Context(), s.st, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(s.SnapshotKey) == 0 {
+		writeJSON(w, exp)
+		return
+	}
+	data, err := json.MarshalIndent(exp, "", "  ")
+	if err == nil {
+		data, err = store.SealExport(data, s.SnapshotKey)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.hlth.Snapshot(r.Context()))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("[admin] encode response: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	// Auth is by token, not cookies, so cross-origin dashboards are fine.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// wsAuthTimeout is how long a /ws/events client without an Authorization
+// header has to send the token.
+const wsAuthTimeout = 10 * time.Second
+
+// handleEvents streams analysis events as JSON text frames. Optional
+// ?wallet=<addr> parameters (repeatable) filter the stream. Browsers can't
+// set headers on WebSocket requests, so a client without an Authorization
+// header must send the token as its first text message.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	filter := make(map[string]bool)
+	for _, a := range r.URL.Query()["wallet"] {
+		filter[a] = true
+	}
+
+	preauth := s.authorized(r)
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade already wrote the HTTP error
+	}
+	defer conn.Close()
+	if !preauth {
+		_ = conn.SetReadDeadline(time.Now().Add(wsAuthTimeout))
+		typ, msg, err := conn.ReadMessage()
+		if err != nil || typ != websocket.TextMessage || !s.validToken(string(msg)) {
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "unauthorized"), time.Now().Add(time.Second))
+			return
+		}
+		_ = conn.SetReadDeadline(time.Time{})
+	}
+
+	ch, cancel := s.bus.Subscribe(64)
+	defer cancel()
+
+	// Reader: answers pings and notices when the client goes away.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-gone:
+			return
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if len(filter) > 0 && !filter[ev.Wallet] {
+				continue
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.WriteJSON(ev); err != nil {
+				log.Printf("[admin] ws write: %v", err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+// airdropMassRecipients is the number of distinct recipients of the same mint
+// in one transaction that marks it as a mass distribution.
+const airdropMassRecipients = 3
+
+// detectAirdrop reports whether the tracked wallet passively received tokens
+// from a mass distribution: it did not sign or pay for the transaction,
+// spent nothing, only got non-SOL tokens, and at least
+// airdropMassRecipients wallets received the same mint. A plain transfer
+// from one sender to the wallet is a payment, not an airdrop. recipients is
+// the largest number of distinct wallets that received one of its mints.
+func detectAirdrop(tx *HeliusTransaction, trackedAddr string, sent, received []Amount) (ok bool, recipients int) {
+	if tx.FeePayer == trackedAddr || len(sent) > 0 || len(received) == 0 {
+		return false, 0
+	}
+	for _, a := range received {
+		if _, priced := isPriceTracked(a.Mint); priced {
+			return false, 0 // SOL/USDC receipts are payments, not airdrops
+		}
+	}
+
+	perMint := make(map[string]map[string]bool)
+	for _, tt := range tx.TokenTransfers {
+		if tt.ToUserAccount == "" {
+			continue
+		}
+		if perMint[tt.Mint] == nil {
+			perMint[tt.Mint] = make(map[string]bool)
+		}
+		perMint[tt.Mint][tt.ToUserAccount] = true
+	}
+	for _, a := range received {
+		if n := len(perMint[a.Mint]); n > recipients {
+			recipients = n
+		}
+	}
+	return recipients >= airdropMassRecipients, recipients
+}
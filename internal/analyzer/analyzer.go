@@ -5,86 +5,333 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/util"
 )
 
 var solanaAddressRegex = regexp.MustCompile(`[1-9A-HJ-NP-Za-km-z]{32,44}`)
 
 type Analyzer struct {
-	HeliusTxURL   string
-	SolanaRPCURL  string // The mainnet-beta RPC for on-chain lookups
-	httpClient    *http.Client
+	HeliusTxURL  string
+	SolanaRPCURL string // The mainnet-beta RPC for on-chain lookups
+	// DASURL, when set, is a Helius RPC URL used to resolve several new
+	// mints in one getAssetBatch call before falling back to SolanaRPCURL.
+	DASURL string
+	// Symbols, when set, persists which mint claimed each symbol, and which
+	// symbols several mints use, so look-alikes are shown as "WIF·a1b2"
+	// (see disambiguateSymbols).
+	Symbols SymbolStore
+	// MintAccounts, when set, persists each mint's token program, decimals
+	// and Metaplex metadata address so known tokens skip those RPC lookups
+	// (see mintAccount). Stored entries are pruned by retention under
+	// MintAccountKeyPrefix.
+	MintAccounts SymbolStore
+	// Marketplaces names extra NFT marketplace programs (program ID ->
+	// name) on top of the built-in ones; see sourceLabel.
+	Marketplaces map[string]string
+	// NegativeTTL is how long a failed metadata lookup is cached before the
+	// mint is looked up again (0 = DefaultNegativeTTL).
+	NegativeTTL time.Duration
+	// DetectSandwich enables the optional getBlock lookup that flags swaps
+	// bracketed by a front-run/back-run pair from the same signer. Analyze
+	// doesn't run it; see SandwichNote.
+	DetectSandwich bool
+	// History and AnomalyFactor enable unusual-size flagging: a result is
+	// marked when its USD size is at least AnomalyFactor × the wallet median.
+	History       SizeHistory
+	AnomalyFactor float64
+	// DetectEarlyBuy annotates swap buys with the token's age at buy time.
+	DetectEarlyBuy bool
+	// CheckSellRoute quotes a reverse swap for bought tokens and warns when
+	// no DEX route exists (likely honeypot).
+	CheckSellRoute bool
+	// HolderConcentration flags bought tokens whose top 10 holders, pools and
+	// bonding curves aside, own at least this percentage of supply. Zero disables.
+	HolderConcentration float64
+	// MarketData prices traded tokens via Jupiter and notes their FDV.
+	MarketData bool
+	// Budget caps the fetch, metadata and price stages of each analysis
+	// (zero fields = DefaultBudget).
+	Budget Budget
+	// SeverityUSD are the sizes at which results become notice, important
+	// and critical (zero value = DefaultSeverityUSD).
+	SeverityUSD   [3]float64
+	httpClient    *http.Client // Solana RPC
+	heliusClient  *http.Client
+	offChain      *http.Client // off-chain token metadata (see offChainClient)
+	heliusTxPool  *keyPool     // see SetHeliusPools
+	dasPool       *keyPool
+	rpcPool       *rpcPool // see SetRPCPool
 	metadataCache *sync.Map
 	priceOracle   *PriceOracle
+	classifier    *Classifier
+	launchCache   *sync.Map // mint -> launchInfo
+	symbolOwners  *sync.Map // upper-cased symbol -> first mint seen with it, or contestedSymbol
+	riskCache     *sync.Map // mint -> sellVerdict
+	holderCache   *sync.Map // mint -> holderShare
+	marketCache   *sync.Map // mint -> tokenMarket
+	mintAccounts  *sync.Map // mint -> mintAccount
+	// verified holds the mints of the last synced token list; nil until
+	// SyncTokenList first succeeds.
+	verified atomic.Pointer[map[string]bool]
 }
 
 func New(heliusTxURL, solanaRPCURL string) *Analyzer {
 	cache := &sync.Map{}
 	cache.Store(wsolMint, TokenMetadata{Symbol: "SOL", Decimals: 9})
 	cache.Store(usdcMint, TokenMetadata{Symbol: "USDC", Decimals: 6})
+	owners := &sync.Map{} // built-ins own their symbols, so clones get a suffix
+	owners.Store("SOL", wsolMint)
+	owners.Store("USDC", usdcMint)
 
 	return &Analyzer{
 		HeliusTxURL:   heliusTxURL,
-		SolanaRPCURL:  solanaRPCURL,                            // Store the public RPC URL
-		httpClient:    &http.Client{Timeout: 20 * time.Second}, // Increased timeout for RPC calls
+		SolanaRPCURL:  solanaRPCURL, // Store the public RPC URL
+		httpClient:    DefaultRPCClient.Client(),
+		heliusClient:  DefaultHeliusClient.Client(),
+		offChain:      offChainClient(),
 		metadataCache: cache,
 		priceOracle:   NewPriceOracle(),
+		classifier:    NewClassifier(),
+		launchCache:   &sync.Map{},
+		symbolOwners:  owners,
+		riskCache:     &sync.Map{},
+		holderCache:   &sync.Map{},
+		marketCache:   &sync.Map{},
+		mintAccounts:  &sync.Map{},
+	}
+}
+
+// SetClients replaces the HTTP clients used for the Helius API, the Solana
+// RPC and the price API. Call it before the analyzer is used.
+func (a *Analyzer) SetClients(helius, rpc, prices ClientConfig) {
+	a.heliusClient = helius.Client()
+	a.httpClient = rpc.Client()
+	a.priceOracle.httpClient = prices.Client()
+}
+
+// SetTransport routes every upstream request through rt, e.g. to serve
+// recorded responses in `solwatch bench`. Call it before the analyzer is
+// used.
+func (a *Analyzer) SetTransport(rt http.RoundTripper) {
+	for _, c := range []*http.Client{a.heliusClient, a.httpClient, a.offChain, a.priceOracle.httpClient} {
+		c.Transport = rt
 	}
 }
 
+// Classify returns the bot/human verdict for a wallet based on the
+// transactions analyzed so far.
+func (a *Analyzer) Classify(addr string) Classification {
+	return a.classifier.Classify(addr)
+}
+
+// PruneCaches drops token metadata, launch lookups and in-memory mint
+// accounts older than metadataTTL and prices older than priceTTL. A zero
+// TTL keeps that cache untouched. Returns the number of metadata (incl.
+// launch), mint account and price entries removed.
+func (a *Analyzer) PruneCaches(metadataTTL, priceTTL time.Duration) (metadata, mintAccounts, prices int) {
+	if metadataTTL > 0 {
+		cutoff := time.Now().Add(-metadataTTL)
+		a.metadataCache.Range(func(k, v any) bool {
+			m := v.(TokenMetadata)
+			if !m.FetchedAt.IsZero() && m.FetchedAt.Before(cutoff) {
+				a.metadataCache.Delete(k)
+				metadata++
+			}
+			return true
+		})
+		a.launchCache.Range(func(k, v any) bool {
+			if v.(launchInfo).CheckedAt.Before(cutoff) {
+				a.launchCache.Delete(k)
+				metadata++
+			}
+			return true
+		})
+		// Mint accounts never go stale: this only bounds memory. The store
+		// keeps them until retention prunes MintAccountKeyPrefix.
+		a.mintAccounts.Range(func(k, v any) bool {
+			if v.(mintAccount).SeenAt.Before(cutoff) {
+				a.mintAccounts.Delete(k)
+				mintAccounts++
+			}
+			return true
+		})
+	}
+	if priceTTL > 0 {
+		prices = a.priceOracle.Prune(priceTTL)
+	}
+	return metadata, mintAccounts, prices
+}
+
+// ForgetWallet discards per-wallet state (e.g. after the wallet is untracked).
+func (a *Analyzer) ForgetWallet(addr string) {
+	a.classifier.Forget(addr)
+}
+
+// AnalyzeSignature fetches and interprets a transaction and returns the
+// rendered HTML summary, or "" when the transaction was filtered out.
 func (a *Analyzer) AnalyzeSignature(ctx context.Context, signature, trackedAddr string) (string, error) {
-	tx, err := fetchHeliusTransaction(ctx, signature, a.HeliusTxURL, a.httpClient)
+	res, err := a.Analyze(ctx, signature, trackedAddr)
+	if err != nil || res == nil {
+		return "", err
+	}
+	return res.Summary(), nil
+}
+
+// Analyze fetches and interprets a transaction for trackedAddr. It returns
+// (nil, nil) when the transaction was filtered as dust/spam. An empty
+// trackedAddr analyzes from the fee payer's point of view.
+func (a *Analyzer) Analyze(ctx context.Context, signature, trackedAddr string) (*Result, error) {
+	return a.analyze(ctx, signature, trackedAddr, true)
+}
+
+// Reanalyze is Analyze for a transaction seen before: the wallet profile is
+// not fed the transaction a second time.
+func (a *Analyzer) Reanalyze(ctx context.Context, signature, trackedAddr string) (*Result, error) {
+	return a.analyze(ctx, signature, trackedAddr, false)
+}
+
+func (a *Analyzer) analyze(ctx context.Context, signature, trackedAddr string, observe bool) (*Result, error) {
+	var warm sync.WaitGroup
+	a.prefetchSOL(ctx, &warm)
+
+	fetchCtx, done := a.stage(ctx, "fetch")
+	txURL, upstream := a.heliusTxPool.pick("tx", a.HeliusTxURL)
+	tx, err := fetchHeliusTransaction(fetchCtx, signature, txURL, upstream, a.heliusClient)
+	done()
+	if err != nil && ctx.Err() == nil {
+		// The fallback gets a budget of its own: Helius timing out would
+		// otherwise leave it none.
+		util.Errors.Printf("[analyzer] helius fetch for %s failed: %v; falling back to getTransaction", signature, err)
+		rpcCtx, done := a.stage(ctx, "fallback")
+		var rpcErr error
+		tx, rpcErr = fetchRPCTransaction(rpcCtx, signature, a.rpc())
+		done()
+		if rpcErr != nil {
+			err = fmt.Errorf("failed to fetch tx %s: %w (rpc fallback: %w)", signature, err, rpcErr)
+		} else {
+			err = nil
+		}
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch tx %s: %w", signature, err)
+		return nil, err
+	}
+	if trackedAddr == "" {
+		trackedAddr = tx.FeePayer // ad-hoc lookup; not a tracked wallet, so don't profile it
+	} else if tx.FeePayer == trackedAddr && observe {
+		a.classifier.Observe(trackedAddr, tx)
 	}
 
 	if shouldFilter(tx, trackedAddr) {
-		return "", nil
+		return nil, nil
 	}
 
-	a.ensureMetadataIsCached(ctx, tx)
+	// The prices stage starts here: its lookups run in the background (see
+	// prefetch) while metadata is resolved.
+	priceCtx, done := a.stage(ctx, "prices")
+	defer done()
+	a.prefetch(priceCtx, &warm, tx, trackedAddr)
 
-	var sent, received []string
-	var interpretation string
-	metadataMap := a.getMetadataMap()
+	metaCtx, done := a.stage(ctx, "metadata")
+	mints := a.ensureMetadataIsCached(metaCtx, tx)
+
+	res := &Result{
+		Signature:   tx.Signature,
+		Wallet:      trackedAddr,
+		Type:        tx.Type,
+		Source:      tx.Source,
+		Slot:        tx.Slot,
+		Description: tx.Description,
+		Programs:    programIDs(tx.Instructions),
+	}
+	if tx.Timestamp > 0 {
+		res.Timestamp = time.Unix(tx.Timestamp, 0).UTC()
+	} else {
+		res.Timestamp = time.Now().UTC()
+	}
+	source := a.sourceLabel(tx)
+	metadataMap := a.metadataFor(mints)
+	a.disambiguateSymbols(metaCtx, metadataMap, mints)
+	done()
+	warm.Wait()
 
 	switch tx.Type {
 	case "CREATE":
-		sent, received = calculateNetBalanceChanges(tx, trackedAddr, metadataMap, a.priceOracle)
+		res.Sent, res.Received = calculateNetBalanceChanges(priceCtx, tx, trackedAddr, metadataMap, a.priceOracle)
 		tokenName := "new token"
-		if len(received) > 0 {
-			tokenName = received[0]
+		if len(res.Received) > 0 {
+			tokenName = formatAmount(res.Received[0], DefaultNumberFormat)
 		}
-		interpretation = fmt.Sprintf("🧱 CREATE & BUY via %s: Bought %s", tx.Source, tokenName)
+		res.Interpretation = fmt.Sprintf("🧱 CREATE & BUY via %s: Bought %s", source, tokenName)
 	case "SWAP":
-		sent, received = a.parseSwapEvent(tx, trackedAddr, metadataMap)
-		interpretation = fmt.Sprintf("🔁 SWAP via %s", tx.Source)
+		res.Sent, res.Received = a.parseSwapEvent(priceCtx, tx, trackedAddr, metadataMap)
+		a.badgeAmounts(res.Sent)
+		a.badgeAmounts(res.Received)
+		res.Interpretation = fmt.Sprintf("🔁 SWAP via %s", source)
+		if a.DetectEarlyBuy {
+			a.annotateEarlyBuy(ctx, res)
+		}
+		a.annotateRisk(ctx, res, metadataMap)
 	default:
-		sent, received = calculateNetBalanceChanges(tx, trackedAddr, metadataMap, a.priceOracle)
-		if len(sent) > 0 && len(received) > 0 {
-			interpretation = fmt.Sprintf("↔️ INTERACTION via %s", tx.Source)
-		} else if len(sent) > 0 {
-			interpretation = fmt.Sprintf("⬆️ SEND via %s", tx.Source)
-		} else if len(received) > 0 {
-			interpretation = fmt.Sprintf("⬇️ RECEIVE via %s", tx.Source)
+		res.Sent, res.Received = calculateNetBalanceChanges(priceCtx, tx, trackedAddr, metadataMap, a.priceOracle)
+		if len(res.Sent) > 0 && len(res.Received) > 0 {
+			res.Interpretation = fmt.Sprintf("↔️ INTERACTION via %s", source)
+		} else if len(res.Sent) > 0 {
+			res.Interpretation = fmt.Sprintf("⬆️ SEND via %s", source)
+			res.Counterparty = counterparty(tx, trackedAddr)
+		} else if len(res.Received) > 0 {
+			res.Interpretation = fmt.Sprintf("⬇️ RECEIVE via %s", source)
+			res.Counterparty = counterparty(tx, trackedAddr)
+			if ok, n := detectAirdrop(tx, trackedAddr, res.Sent, res.Received); ok {
+				res.Counterparty = ""
+				res.Airdrop = true
+				res.Interpretation = fmt.Sprintf("🎁 AIRDROP via %s (sent to %d wallets)", source, n)
+			}
 		} else {
-			interpretation = fmt.Sprintf("⚙️ %s via %s", strings.ToTitle(strings.ToLower(tx.Type)), tx.Source)
+			res.Interpretation = fmt.Sprintf("⚙️ %s via %s", strings.ToTitle(strings.ToLower(tx.Type)), source)
 		}
 	}
-	return a.buildSummary(tx, interpretation, sent, received), nil
+
+	if a.MarketData && (tx.Type == "SWAP" || tx.Type == "CREATE") {
+		a.annotateMarket(priceCtx, res)
+	}
+
+	res.RentSOL = collectRent(tx, trackedAddr).Net()
+	res.SizeUSD = sizeUSD(res.Sent, res.Received)
+	a.checkAnomaly(ctx, res)
+	a.classifySeverity(res)
+	return res, nil
 }
 
-func (a *Analyzer) ensureMetadataIsCached(ctx context.Context, tx *HeliusTransaction) {
+// DefaultNegativeTTL is how long a failed metadata lookup is remembered.
+const DefaultNegativeTTL = 10 * time.Minute
+
+// rateLimitedRetry is how soon a lookup that failed on a rate limit, or
+// ran out of metadata budget, is tried again.
+const rateLimitedRetry = time.Minute
+
+// ensureMetadataIsCached resolves every mint tx touches and returns them.
+func (a *Analyzer) ensureMetadataIsCached(ctx context.Context, tx *HeliusTransaction) map[string]bool {
 	mints := make(map[string]bool)
 	for _, transfer := range tx.TokenTransfers {
 		if transfer.Mint != "" {
 			mints[transfer.Mint] = true
 		}
 	}
+	for _, ad := range tx.AccountData {
+		for _, tb := range ad.TokenBalanceChanges {
+			if tb.Mint != "" {
+				mints[tb.Mint] = true
+			}
+		}
+	}
 	if tx.Events.Swap != nil {
 		for _, item := range tx.Events.Swap.TokenInputs {
 			mints[item.Mint] = true
@@ -94,86 +341,167 @@ func (a *Analyzer) ensureMetadataIsCached(ctx context.Context, tx *HeliusTransac
 		}
 	}
 
+	var missing []string
+	negativeTTL := a.NegativeTTL
+	if negativeTTL <= 0 {
+		negativeTTL = DefaultNegativeTTL
+	}
 	for mint := range mints {
-		if _, found := a.metadataCache.Load(mint); !found {
-			meta, err := fetchOnChainMetadata(ctx, mint, a.SolanaRPCURL, a.httpClient)
-			if err != nil {
-				log.Printf("[analyzer] failed to fetch on-chain metadata for %s: %v. Using fallback.", mint, err)
-				a.metadataCache.Store(mint, TokenMetadata{Symbol: fmt.Sprintf("Mint(%s)", shortenAddress(mint)), Decimals: 6})
-				continue
+		v, found := a.metadataCache.Load(mint)
+		if !found {
+			missing = append(missing, mint)
+			continue
+		}
+		if m := v.(TokenMetadata); m.Failed && time.Since(m.FetchedAt) > negativeTTL {
+			missing = append(missing, mint)
+		}
+	}
+	if len(missing) == 0 {
+		return mints
+	}
+
+	fetched, limited := a.fetchMetadataBatch(ctx, missing)
+	expired := ctx.Err() != nil
+	for _, mint := range missing {
+		meta, ok := fetched[mint]
+		if !ok {
+			// A rate-limited or timed-out lookup says nothing about the
+			// mint, so its placeholder is backdated to be retried after
+			// rateLimitedRetry.
+			at, wait := time.Now(), negativeTTL
+			if (limited[mint] || expired) && rateLimitedRetry < negativeTTL {
+				at, wait = at.Add(rateLimitedRetry-negativeTTL), rateLimitedRetry
 			}
-			log.Printf("[analyzer] fetched and cached on-chain metadata for %s (%s)", mint, meta.Symbol)
-			a.metadataCache.Store(mint, *meta)
+			log.Printf("[analyzer] no metadata for %s. Using fallback for %s.", mint, wait)
+			a.metadataCache.Store(mint, TokenMetadata{Symbol: fmt.Sprintf("Mint(%s)", shortenAddress(mint)), Decimals: 6, FetchedAt: at, Failed: true})
+			continue
 		}
+		log.Printf("[analyzer] fetched and cached metadata for %s (%s)", mint, meta.Symbol)
+		meta.FetchedAt = time.Now()
+		a.metadataCache.Store(mint, meta)
 	}
+	return mints
 }
 
-func (a *Analyzer) buildSummary(tx *HeliusTransaction, interpretation string, sent, received []string) string {
+// Bought returns the tokens the wallet bought: non-SOL/USDC amounts received
+// in exchange for something.
+func (r *Result) Bought() []Amount {
+	if len(r.Sent) == 0 {
+		return nil
+	}
+	var out []Amount
+	for _, amt := range r.Received {
+		if _, priced := isPriceTracked(amt.Mint); !priced && amt.Amount > 0 {
+			out = append(out, amt)
+		}
+	}
+	return out
+}
+
+// Summary renders the result as the Telegram HTML block. Interpretation,
+// Description and amounts are plain text and escaped here; Notes are HTML
+// fragments whose producers escape their own dynamic parts.
+func (r *Result) Summary() string { return r.SummaryWith(DefaultNumberFormat) }
+
+// SummaryWith is Summary with amounts formatted by nf.
+func (r *Result) SummaryWith(nf NumberFormat) string {
 	var b strings.Builder
-	b.WriteString(fmt.Sprintf("<b>%s</b>\n", interpretation))
-	if tx.Description != "" {
-		cleanedDesc := solanaAddressRegex.ReplaceAllStringFunc(tx.Description, func(addr string) string {
+	b.WriteString(fmt.Sprintf("<b>%s</b>\n", EscapeHTML(r.Interpretation)))
+	if r.Description != "" {
+		cleanedDesc := solanaAddressRegex.ReplaceAllStringFunc(r.Description, func(addr string) string {
 			if len(addr) > 8 {
 				return fmt.Sprintf("%s...%s", addr[:4], addr[len(addr)-4:])
 			}
 			return addr
 		})
-		b.WriteString(fmt.Sprintf("ℹ️ <i>%s</i>\n", cleanedDesc))
+		b.WriteString(fmt.Sprintf("ℹ️ <i>%s</i>\n", EscapeHTML(cleanedDesc)))
 	}
 	b.WriteString("\n")
-	if len(sent) > 0 {
-		b.WriteString(fmt.Sprintf("💰 <b>Sent:</b> %s\n", strings.Join(sent, ", ")))
+	if len(r.Sent) > 0 {
+		b.WriteString(fmt.Sprintf("💰 <b>Sent:</b> %s\n", EscapeHTML(joinAmounts(r.Sent, nf))))
+	}
+	if len(r.Received) > 0 {
+		b.WriteString(fmt.Sprintf("💸 <b>Received:</b> %s\n", EscapeHTML(joinAmounts(r.Received, nf))))
 	}
-	if len(received) > 0 {
-		b.WriteString(fmt.Sprintf("💸 <b>Received:</b> %s\n", strings.Join(received, ", ")))
+	if exact := exactAmounts(append(append([]Amount(nil), r.Sent...), r.Received...), nf); exact != "" {
+		b.WriteString("<blockquote expandable>🔢 Exact amounts\n" + EscapeHTML(exact) + "</blockquote>\n")
 	}
-	b.WriteString(fmt.Sprintf("\n<a href=\"https://solscan.io/tx/%s\">%s...%s</a>", tx.Signature, tx.Signature[:6], tx.Signature[len(tx.Signature)-6:]))
+	if math.Abs(r.RentSOL) > 1e-9 {
+		sign := "+"
+		if r.RentSOL < 0 {
+			sign = "-"
+		}
+		b.WriteString(fmt.Sprintf("🏠 <b>Rent:</b> %s%s SOL\n", sign, formatHumanReadable(math.Abs(r.RentSOL), nf)))
+	}
+	for _, n := range r.Notes {
+		b.WriteString(n + "\n")
+	}
+	sig := EscapeHTML(r.Signature)
+	b.WriteString(fmt.Sprintf("\n<a href=\"https://solscan.io/tx/%s\">%s...%s</a>", sig, sig[:6], sig[len(sig)-6:]))
 	return b.String()
 }
-func (a *Analyzer) parseSwapEvent(tx *HeliusTransaction, trackedAddr string, metadataMap map[string]TokenMetadata) (sent, received []string) {
+func (a *Analyzer) parseSwapEvent(ctx context.Context, tx *HeliusTransaction, trackedAddr string, metadataMap map[string]TokenMetadata) (sent, received []Amount) {
 	if tx.Events.Swap == nil {
-		return calculateNetBalanceChanges(tx, trackedAddr, metadataMap, a.priceOracle)
+		return calculateNetBalanceChanges(ctx, tx, trackedAddr, metadataMap, a.priceOracle)
 	}
-	addFormattedItem := func(list *[]string, item TokenSwapAmount) {
+	addItem := func(list *[]Amount, item TokenSwapAmount) {
 		amount := parseAmount(item.RawTokenAmount.TokenAmount, item.RawTokenAmount.Decimals)
 		meta, ok := metadataMap[item.Mint]
 		if !ok { // Should be rare now
 			meta = TokenMetadata{Symbol: fmt.Sprintf("Mint(%s)", shortenAddress(item.Mint)), Decimals: item.RawTokenAmount.Decimals}
 		}
-		formattedStr := fmt.Sprintf("%s %s", formatHumanReadable(amount), meta.Symbol)
+		amt := Amount{Mint: item.Mint, Symbol: meta.Symbol, Amount: amount}
 		if coinID, isTracked := isPriceTracked(item.Mint); isTracked {
-			if price, ok := a.priceOracle.GetPriceUSD(context.Background(), coinID); ok {
-				usdValue := amount * price
-				formattedStr += fmt.Sprintf(" ($%.2f)", usdValue)
+			if price, ok := a.priceOracle.GetPriceUSD(ctx, coinID); ok {
+				amt.USD = amount * price
 			}
 		}
-		*list = append(*list, formattedStr)
+		*list = append(*list, amt)
 	}
 	for _, item := range tx.Events.Swap.TokenInputs {
 		if item.UserAccount == trackedAddr {
-			addFormattedItem(&sent, item)
+			addItem(&sent, item)
 		}
 	}
 	for _, item := range tx.Events.Swap.TokenOutputs {
 		if item.UserAccount == trackedAddr {
-			addFormattedItem(&received, item)
+			addItem(&received, item)
 		}
 	}
 	return sent, received
 }
+
+// exactAmounts lists the full values of amounts nf renders compactly, one
+// per line, or "" when none are.
+func exactAmounts(list []Amount, nf NumberFormat) string {
+	var lines []string
+	for _, a := range list {
+		if nf.compacts(a.Amount) {
+			lines = append(lines, nf.Exact(a.Amount)+" "+a.Symbol)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shortenAddress returns plain "abcd...wxyz"; callers add markup.
 func shortenAddress(addr string) string {
 	if len(addr) <= 8 {
-		return fmt.Sprintf("<code>%s</code>", addr)
+		return addr
 	}
-	shortened := addr[:4] + "..." + addr[len(addr)-4:]
-	return fmt.Sprintf("<code>%s</code>", shortened)
+	return addr[:4] + "..." + addr[len(addr)-4:]
 }
-func (a *Analyzer) getMetadataMap() map[string]TokenMetadata {
-	m := make(map[string]TokenMetadata)
-	a.metadataCache.Range(func(key, value any) bool {
-		m[key.(string)] = value.(TokenMetadata)
-		return true
-	})
+
+// metadataFor returns the cached metadata of mints (those ensureMetadataIsCached
+// found in a transaction) as a map the caller may modify. It loads only
+// those keys; copying the whole cache got expensive once it held thousands
+// of mints.
+func (a *Analyzer) metadataFor(mints map[string]bool) map[string]TokenMetadata {
+	m := make(map[string]TokenMetadata, len(mints))
+	for mint := range mints {
+		if v, ok := a.metadataCache.Load(mint); ok {
+			m[mint] = v.(TokenMetadata)
+		}
+	}
 	return m
 }
 
@@ -187,8 +515,22 @@ type cachedPrice struct {
 }
 
 func NewPriceOracle() *PriceOracle {
-	return &PriceOracle{httpClient: &http.Client{Timeout: 5 * time.Second}, cache: &sync.Map{}}
+	return &PriceOracle{httpClient: DefaultPriceClient.Client(), cache: &sync.Map{}}
+}
+
+// Prune removes cached prices older than ttl and returns how many.
+func (o *PriceOracle) Prune(ttl time.Duration) int {
+	n := 0
+	o.cache.Range(func(k, v any) bool {
+		if time.Since(v.(cachedPrice).LastFetched) > ttl {
+			o.cache.Delete(k)
+			n++
+		}
+		return true
+	})
+	return n
 }
+
 func (o *PriceOracle) GetPriceUSD(ctx context.Context, coinID string) (float64, bool) {
 	if val, found := o.cache.Load(coinID); found {
 		if time.Since(val.(cachedPrice).LastFetched) < 60*time.Second {
@@ -212,3 +554,21 @@ func (o *PriceOracle) GetPriceUSD(ctx context.Context, coinID string) (float64,
 	}
 	return 0, false
 }
+
+// programIDs flattens the instruction tree into distinct program IDs.
+func programIDs(ixs []Instruction) []string {
+	seen := make(map[string]bool)
+	var out []string
+	var walk func([]Instruction)
+	walk = func(list []Instruction) {
+		for _, ix := range list {
+			if ix.ProgramID != "" && !seen[ix.ProgramID] {
+				seen[ix.ProgramID] = true
+				out = append(out, ix.ProgramID)
+			}
+			walk(ix.InnerInstructions)
+		}
+	}
+	walk(ixs)
+	return out
+}
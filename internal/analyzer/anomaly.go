@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+)
+
+const (
+	// anomalyMinSamples is how many priced past transactions a wallet needs
+	// before its median is trusted.
+	anomalyMinSamples = 10
+	// anomalyLookback is how many recent sizes feed the median.
+	anomalyLookback = 200
+)
+
+// SizeHistory supplies a wallet's recent transaction sizes (USD, newest first).
+type SizeHistory interface {
+	RecentSizes(ctx context.Context, wallet string, limit int) ([]float64, error)
+}
+
+// checkAnomaly compares res.SizeUSD with the wallet's historical median and
+// flags the result when it exceeds AnomalyFactor times that median.
+func (a *Analyzer) checkAnomaly(ctx context.Context, res *Result) {
+	if a.History == nil || a.AnomalyFactor <= 0 || res.SizeUSD <= 0 {
+		return
+	}
+	sizes, err := a.History.RecentSizes(ctx, res.Wallet, anomalyLookback)
+	if err != nil {
+		log.Printf("[analyzer] size history for %s: %v", res.Wallet, err)
+		return
+	}
+	ratio, med := AnomalyRatio(res.SizeUSD, sizes, a.AnomalyFactor)
+	if ratio == 0 {
+		return
+	}
+	res.AnomalyFactor = ratio
+	res.Notes = append(res.Notes, fmt.Sprintf(
+		"📈 <b>Unusual size:</b> %.0fx this wallet's median ($%.2f vs $%.2f)", ratio, res.SizeUSD, med,
+	))
+}
+
+// AnomalyRatio returns size divided by the median of recent (newest first,
+// at most the first anomalyLookback are used) when that ratio reaches factor,
+// else 0. The median is also returned. Too little history yields 0.
+func AnomalyRatio(size float64, recent []float64, factor float64) (ratio, med float64) {
+	if factor <= 0 || size <= 0 || len(recent) < anomalyMinSamples {
+		return 0, 0
+	}
+	if len(recent) > anomalyLookback {
+		recent = recent[:anomalyLookback]
+	}
+	med = median(recent)
+	if med <= 0 || size/med < factor {
+		return 0, med
+	}
+	return size / med, med
+}
+
+func median(vals []float64) float64 {
+	s := append([]float64(nil), vals...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"log"
+	"math"
+)
+
+// deltaMismatchTolerance is the relative difference between the two
+// computation paths that we still consider "the same" (float rounding).
+const deltaMismatchTolerance = 1e-6
+
+// tokenDeltasFromTransfers nets non-WSOL token movement for trackedAddr using
+// Helius's tokenTransfers list (UI amounts).
+func tokenDeltasFromTransfers(tx *HeliusTransaction, trackedAddr string) map[string]float64 {
+	deltas := make(map[string]float64)
+	for _, tt := range tx.TokenTransfers {
+		if tt.Mint == wsolMint {
+			continue
+		}
+		if tt.FromUserAccount == trackedAddr {
+			deltas[tt.Mint] -= tt.TokenAmount
+		}
+		if tt.ToUserAccount == trackedAddr {
+			deltas[tt.Mint] += tt.TokenAmount
+		}
+	}
+	return deltas
+}
+
+// tokenDeltasFromBalances nets non-WSOL token movement for trackedAddr using
+// the raw pre/post differences in accountData.tokenBalanceChanges. The second
+// return value reports whether any balance rows for the wallet were present.
+func tokenDeltasFromBalances(tx *HeliusTransaction, trackedAddr string) (map[string]float64, bool) {
+	deltas := make(map[string]float64)
+	found := false
+	for _, ad := range tx.AccountData {
+		for _, tb := range ad.TokenBalanceChanges {
+			if tb.UserAccount != trackedAddr || tb.Mint == wsolMint {
+				continue
+			}
+			found = true
+			deltas[tb.Mint] += parseAmount(tb.RawTokenAmount.TokenAmount, tb.RawTokenAmount.Decimals)
+		}
+	}
+	return deltas, found
+}
+
+// reconcileTokenDeltas merges both computation paths per mint.
+//
+// Preference order:
+//  1. balance changes (raw on-chain pre/post, exact and complete), when the
+//     wallet has balance rows for that mint;
+//  2. token transfers, for mints the balance data does not cover.
+//
+// Disagreements are logged so we can spot Helius parsing gaps.
+func reconcileTokenDeltas(tx *HeliusTransaction, trackedAddr string) map[string]float64 {
+	fromTransfers := tokenDeltasFromTransfers(tx, trackedAddr)
+	fromBalances, ok := tokenDeltasFromBalances(tx, trackedAddr)
+	if !ok {
+		return fromTransfers
+	}
+
+	out := make(map[string]float64, len(fromBalances)+len(fromTransfers))
+	for mint, bal := range fromBalances {
+		out[mint] = bal
+		tr, seen := fromTransfers[mint]
+		switch {
+		case !seen && math.Abs(bal) > 0:
+			log.Printf("[analyzer] %s: mint %s moved %g per balances but has no transfer rows", tx.Signature, mint, bal)
+		case seen && !closeEnough(bal, tr):
+			log.Printf("[analyzer] %s: mint %s balance delta %g != transfer delta %g; using balances", tx.Signature, mint, bal, tr)
+		}
+	}
+	for mint, tr := range fromTransfers {
+		if _, seen := fromBalances[mint]; !seen {
+			out[mint] = tr
+		}
+	}
+	return out
+}
+
+func closeEnough(a, b float64) bool {
+	diff := math.Abs(a - b)
+	scale := math.Max(math.Abs(a), math.Abs(b))
+	if scale == 0 {
+		return true
+	}
+	return diff/scale <= deltaMismatchTolerance
+}
@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// Budget caps how long each stage of an analysis may take, within whatever
+// deadline the caller's context already has. Zero fields take the
+// DefaultBudget value.
+type Budget struct {
+	Fetch    time.Duration // the transaction itself from Helius; the RPC fallback gets as much again
+	Metadata time.Duration // token metadata and symbol disambiguation
+	Prices   time.Duration // USD prices and market data
+}
+
+// DefaultBudget leaves room for all three stages inside the handler's
+// default 20s analysis timeout.
+var DefaultBudget = Budget{Fetch: 10 * time.Second, Metadata: 5 * time.Second, Prices: 5 * time.Second}
+
+// stage returns a context for one analysis stage: ctx with the stage's
+// budget as an extra deadline. done counts analyzer.timeout.<name> when the
+// budget, not the caller, ran out, and must be called when the stage ends.
+func (a *Analyzer) stage(ctx context.Context, name string) (stageCtx context.Context, done func()) {
+	var d time.Duration
+	switch name {
+	case "fetch", "fallback":
+		d = orDefault(a.Budget.Fetch, DefaultBudget.Fetch)
+	case "metadata":
+		d = orDefault(a.Budget.Metadata, DefaultBudget.Metadata)
+	case "prices":
+		d = orDefault(a.Budget.Prices, DefaultBudget.Prices)
+	}
+	stageCtx, cancel := context.WithTimeout(ctx, d)
+	return stageCtx, func() {
+		if errors.Is(stageCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			metrics.Inc("analyzer.timeout." + name)
+		}
+		cancel()
+	}
+}
+
+// orDefault returns d, or def when d is zero.
+func orDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// profileWindow caps how many recent transactions are kept per wallet.
+	profileWindow = 200
+	// minClassifySamples is the number of observations needed before we
+	// commit to a label; below it the wallet stays "unknown".
+	minClassifySamples = 10
+	// botScoreThreshold is the heuristic score at which a wallet is tagged a bot.
+	botScoreThreshold = 3
+)
+
+// Wallet classification labels.
+const (
+	LabelUnknown = "unknown"
+	LabelHuman   = "human"
+	LabelBot     = "likely bot"
+)
+
+// observation is the per-transaction data the classifier learns from.
+type observation struct {
+	At     time.Time
+	Source string
+	Fee    int64 // lamports
+}
+
+// Classification is a point-in-time verdict for one wallet.
+type Classification struct {
+	Label     string
+	Score     int
+	Samples   int
+	TxPerHour float64
+	Sources   int     // distinct programs/sources seen
+	TopShare  float64 // share of txs going to the most used source
+	MedianFee int64   // lamports
+	Bursts    int     // consecutive txs less than 2s apart
+}
+
+// IsBot reports whether the wallet was classified as a likely bot.
+func (c Classification) IsBot() bool { return c.Label == LabelBot }
+
+// Classifier keeps a rolling activity window per wallet and scores it with
+// simple heuristics (frequency, program mix, fee patterns).
+type Classifier struct {
+	mu      sync.Mutex
+	history map[string][]observation
+}
+
+// NewClassifier returns an empty Classifier.
+func NewClassifier() *Classifier {
+	return &Classifier{history: make(map[string][]observation)}
+}
+
+// Observe records a transaction for wallet. Signature dedupe happens upstream.
+func (c *Classifier) Observe(wallet string, tx *HeliusTransaction) {
+	at := time.Now()
+	if tx.Timestamp > 0 {
+		at = time.Unix(tx.Timestamp, 0)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	obs := append(c.history[wallet], observation{At: at, Source: tx.Source, Fee: tx.Fee})
+	if len(obs) > profileWindow {
+		obs = obs[len(obs)-profileWindow:]
+	}
+	c.history[wallet] = obs
+}
+
+// Forget drops everything learned about wallet (e.g. after /untrack).
+func (c *Classifier) Forget(wallet string) {
+	c.mu.Lock()
+	delete(c.history, wallet)
+	c.mu.Unlock()
+}
+
+// Classify scores the wallet's recent activity.
+func (c *Classifier) Classify(wallet string) Classification {
+	c.mu.Lock()
+	obs := append([]observation(nil), c.history[wallet]...)
+	c.mu.Unlock()
+
+	out := Classification{Label: LabelUnknown, Samples: len(obs)}
+	if len(obs) == 0 {
+		return out
+	}
+	sort.Slice(obs, func(i, j int) bool { return obs[i].At.Before(obs[j].At) })
+
+	// Frequency and burstiness.
+	span := obs[len(obs)-1].At.Sub(obs[0].At)
+	if span < time.Minute {
+		span = time.Minute
+	}
+	out.TxPerHour = float64(len(obs)) / span.Hours()
+	for i := 1; i < len(obs); i++ {
+		if obs[i].At.Sub(obs[i-1].At) < 2*time.Second {
+			out.Bursts++
+		}
+	}
+
+	// Program mix.
+	perSource := make(map[string]int)
+	top := 0
+	for _, o := range obs {
+		perSource[o.Source]++
+		if perSource[o.Source] > top {
+			top = perSource[o.Source]
+		}
+	}
+	out.Sources = len(perSource)
+	out.TopShare = float64(top) / float64(len(obs))
+
+	// Fee patterns.
+	fees := make([]int64, len(obs))
+	sameFee := 0
+	for i, o := range obs {
+		fees[i] = o.Fee
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+	out.MedianFee = fees[len(fees)/2]
+	for _, f := range fees {
+		if f == out.MedianFee {
+			sameFee++
+		}
+	}
+
+	if len(obs) < minClassifySamples {
+		return out
+	}
+
+	if out.TxPerHour >= 30 {
+		out.Score += 2
+	} else if out.TxPerHour >= 10 {
+		out.Score++
+	}
+	if float64(out.Bursts) >= 0.2*float64(len(obs)) {
+		out.Score++
+	}
+	if len(obs) >= 20 && out.TopShare >= 0.9 {
+		out.Score++
+	}
+	if out.MedianFee >= 100_000 { // ≥0.0001 SOL: consistently paying priority fees
+		out.Score++
+	}
+	if float64(sameFee) >= 0.8*float64(len(obs)) && out.MedianFee > 5000 {
+		out.Score++ // identical non-default fees are a scripted-sender tell
+	}
+
+	if out.Score >= botScoreThreshold {
+		out.Label = LabelBot
+	} else {
+		out.Label = LabelHuman
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+const (
+	systemProgram = solanarpc.SystemProgramID
+	// busyWindow: a counterparty with a full page of signatures inside this
+	// window is an exchange hot wallet, bot or service rather than a person.
+	busyWindow = 24 * time.Hour
+)
+
+// counterparty returns the one other wallet trackedAddr sent SOL/tokens to
+// or received them from, or "" when there are several or none. Native
+// transfers into token accounts (rent for a new account) are not
+// counterparties.
+func counterparty(tx *HeliusTransaction, trackedAddr string) string {
+	tokenAccounts := make(map[string]bool)
+	for _, tt := range tx.TokenTransfers {
+		tokenAccounts[tt.FromTokenAccount] = true
+		tokenAccounts[tt.ToTokenAccount] = true
+	}
+	for _, ad := range tx.AccountData {
+		for _, tb := range ad.TokenBalanceChanges {
+			tokenAccounts[tb.TokenAccount] = true
+		}
+	}
+
+	others := make(map[string]bool)
+	add := func(from, to string) {
+		switch {
+		case from == trackedAddr && to != "" && to != trackedAddr && !tokenAccounts[to]:
+			others[to] = true
+		case to == trackedAddr && from != "" && from != trackedAddr && !tokenAccounts[from]:
+			others[from] = true
+		}
+	}
+	for _, nt := range tx.NativeTransfers {
+		add(nt.FromUserAccount, nt.ToUserAccount)
+	}
+	for _, tt := range tx.TokenTransfers {
+		add(tt.FromUserAccount, tt.ToUserAccount)
+	}
+	if len(others) != 1 {
+		return ""
+	}
+	for addr := range others {
+		return addr
+	}
+	return ""
+}
+
+// Personal reports whether addr looks like a person's wallet: owned by the
+// system program (or not yet funded) and not so busy that it must be an
+// exchange or service. reason explains a false verdict.
+func (a *Analyzer) Personal(ctx context.Context, addr string) (ok bool, reason string, err error) {
+	rpc := a.rpc()
+	rpc.Commitment = "confirmed"
+	acc, err := rpc.GetAccountInfo(ctx, addr)
+	switch {
+	case errors.Is(err, ErrAccountNotFound):
+		// not funded yet: still a plain wallet
+	case err != nil:
+		return false, "", fmt.Errorf("getAccountInfo(%s): %w", addr, err)
+	case acc.Executable || acc.Owner != systemProgram:
+		return false, "not a wallet (program-owned account)", nil
+	}
+
+	sigs, err := rpc.GetSignaturesForAddress(ctx, addr, solanarpc.SignaturesOptions{Limit: launchLookupPageSize})
+	if err != nil {
+		return false, "", fmt.Errorf("getSignaturesForAddress(%s): %w", addr, err)
+	}
+	if n := len(sigs); n == launchLookupPageSize {
+		if bt := sigs[n-1].BlockTime; bt != nil && time.Since(time.Unix(*bt, 0)) < busyWindow {
+			return false, fmt.Sprintf("%d+ transactions in %s (exchange or service)", n, busyWindow), nil
+		}
+	}
+	return true, "", nil
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+var (
+	// ErrAccountNotFound is returned when the RPC answers null for an
+	// account: it does not exist (yet) or the node has not seen it.
+	ErrAccountNotFound = solanarpc.ErrAccountNotFound
+	// ErrUpstreamRateLimited is returned when an upstream kept answering
+	// 429 (or a JSON-RPC rate-limit error) after the retries. It is
+	// solanarpc.ErrRateLimited, so RPC errors match it too.
+	ErrUpstreamRateLimited = solanarpc.ErrRateLimited
+	// ErrTxNotIndexedYet is returned when neither Helius nor the RPC knows
+	// a signature yet, usually because it was seen at processed commitment
+	// moments ago.
+	ErrTxNotIndexedYet = errors.New("transaction not indexed yet")
+)
+
+// UpstreamError is a call that failed after postJSON's retries.
+type UpstreamError struct {
+	Upstream string // "helius" (or "helius.key<N>" with several keys) or "rpc"
+	Class    string // see errorClass
+	Attempts int
+	Err      error
+}
+
+func (e *UpstreamError) Error() string {
+	// A *url.Error quotes the request URL, API key included; keep only
+	// the cause.
+	err := e.Err
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		err = ue.Err
+	}
+	return fmt.Sprintf("%s %s error after %d attempt(s): %v", e.Upstream, strings.ReplaceAll(e.Class, "_", " "), e.Attempts, err)
+}
+
+func (e *UpstreamError) Unwrap() error { return e.Err }
+
+// Is makes a rate-limited UpstreamError match ErrUpstreamRateLimited.
+func (e *UpstreamError) Is(target error) bool {
+	return target == ErrUpstreamRateLimited && e.Class == classRateLimited
+}
+
+// RPCError is the JSON-RPC error object of a failed call.
+type RPCError = solanarpc.RPCError
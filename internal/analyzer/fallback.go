@@ -0,0 +1,223 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+	"github.com/mr-tron/base58"
+)
+
+// resolveInstruction looks a compiled instruction's indexes up in the
+// resolved keys.
+func resolveInstruction(ix solanarpc.CompiledInstruction, keys []string) Instruction {
+	at := func(i int) string {
+		if i >= 0 && i < len(keys) {
+			return keys[i]
+		}
+		return ""
+	}
+	out := Instruction{ProgramID: at(ix.ProgramIDIndex), Data: ix.Data}
+	for _, a := range ix.Accounts {
+		out.Accounts = append(out.Accounts, at(a))
+	}
+	return out
+}
+
+// System Program instruction discriminators (little-endian u32) whose
+// lamports move from accounts[0] to accounts[1].
+const (
+	systemCreateAccount         = 0
+	systemTransfer              = 2
+	systemCreateAccountWithSeed = 3
+)
+
+// nativeTransfers rebuilds Helius-style native transfers from the System
+// Program transfer and createAccount instructions in ixs, inner calls
+// included. collectRent relies on them to tell which rent deposits the
+// wallet funded.
+func nativeTransfers(ixs []Instruction) []NativeTransfer {
+	var out []NativeTransfer
+	var walk func([]Instruction)
+	walk = func(list []Instruction) {
+		for _, ix := range list {
+			if ix.ProgramID == solanarpc.SystemProgramID && len(ix.Accounts) >= 2 {
+				if lamports, ok := systemLamports(ix.Data); ok && lamports > 0 {
+					out = append(out, NativeTransfer{FromUserAccount: ix.Accounts[0], ToUserAccount: ix.Accounts[1], Amount: lamports})
+				}
+			}
+			walk(ix.InnerInstructions)
+		}
+	}
+	walk(ixs)
+	return out
+}
+
+// systemLamports decodes the lamports of a System Program transfer,
+// createAccount or createAccountWithSeed instruction.
+func systemLamports(data string) (int64, bool) {
+	raw, err := base58.Decode(data)
+	if err != nil || len(raw) < 12 {
+		return 0, false
+	}
+	off := 4
+	switch binary.LittleEndian.Uint32(raw) {
+	case systemCreateAccount, systemTransfer:
+	case systemCreateAccountWithSeed:
+		// base pubkey, then the seed as a u64-length-prefixed string
+		if len(raw) < 4+32+8 {
+			return 0, false
+		}
+		seedLen := binary.LittleEndian.Uint64(raw[4+32:])
+		if seedLen > uint64(len(raw)) {
+			return 0, false
+		}
+		off = 4 + 32 + 8 + int(seedLen)
+	default:
+		return 0, false
+	}
+	if len(raw) < off+8 {
+		return 0, false
+	}
+	return int64(binary.LittleEndian.Uint64(raw[off:])), true
+}
+
+// fetchRPCTransaction is the fallback when the Helius enhanced API is down.
+// It reads the raw transaction via getTransaction and converts it with
+// convertRPCTransaction.
+func fetchRPCTransaction(ctx context.Context, signature string, rpc *solanarpc.Client) (*HeliusTransaction, error) {
+	rpc.Commitment = "confirmed"
+	r, err := rpc.GetTransaction(ctx, signature)
+	if err != nil {
+		return nil, fmt.Errorf("getTransaction failed: %w", err)
+	}
+	if r == nil || r.Meta == nil {
+		return nil, fmt.Errorf("rpc: %w", ErrTxNotIndexedYet)
+	}
+	return convertRPCTransaction(signature, r)
+}
+
+// convertRPCTransaction turns a getTransaction result into the subset of
+// HeliusTransaction the analyzer relies on (account native changes, token
+// balance changes, instructions and the native transfers they make). Type
+// and Source are unknown on this path.
+//
+// v0 transactions reference accounts through address lookup tables; those
+// keys are not in message.accountKeys but in meta.loadedAddresses, appended
+// in writable-then-readonly order. Balance arrays are indexed over the full
+// resolved list.
+func convertRPCTransaction(signature string, r *solanarpc.Transaction) (*HeliusTransaction, error) {
+	keys := append([]string(nil), r.Transaction.Message.AccountKeys...)
+	if la := r.Meta.LoadedAddresses; la != nil {
+		keys = append(keys, la.Writable...)
+		keys = append(keys, la.Readonly...)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("transaction has no account keys")
+	}
+
+	tx := &HeliusTransaction{
+		Signature: signature,
+		Slot:      r.Slot,
+		Fee:       r.Meta.Fee,
+		FeePayer:  keys[0],
+		Type:      "UNKNOWN",
+		Source:    "RPC",
+	}
+	if r.BlockTime != nil {
+		tx.Timestamp = *r.BlockTime
+	}
+	if len(r.Meta.Err) > 0 && string(r.Meta.Err) != "null" {
+		e := json.RawMessage(append([]byte(nil), r.Meta.Err...))
+		tx.TransactionError = &e
+	}
+
+	for _, ix := range r.Transaction.Message.Instructions {
+		tx.Instructions = append(tx.Instructions, resolveInstruction(ix, keys))
+	}
+	for _, inner := range r.Meta.InnerInstructions {
+		if inner.Index < 0 || inner.Index >= len(tx.Instructions) {
+			continue
+		}
+		parent := &tx.Instructions[inner.Index]
+		for _, ix := range inner.Instructions {
+			parent.InnerInstructions = append(parent.InnerInstructions, resolveInstruction(ix, keys))
+		}
+	}
+	tx.NativeTransfers = nativeTransfers(tx.Instructions)
+
+	// Native balance changes per resolved key.
+	byAccount := make(map[string]*AccountData, len(keys))
+	for i, k := range keys {
+		ad := AccountData{Account: k}
+		if i < len(r.Meta.PreBalances) && i < len(r.Meta.PostBalances) {
+			ad.NativeBalanceChange = r.Meta.PostBalances[i] - r.Meta.PreBalances[i]
+		}
+		tx.AccountData = append(tx.AccountData, ad)
+	}
+	for i := range tx.AccountData {
+		byAccount[tx.AccountData[i].Account] = &tx.AccountData[i]
+	}
+
+	// Token balance changes: post - pre per token account, raw integer math.
+	type tokenKey struct {
+		idx  int
+		mint string
+	}
+	type tokenRow struct {
+		owner    string
+		decimals int
+		pre      *big.Int
+		post     *big.Int
+	}
+	rows := make(map[tokenKey]*tokenRow)
+	var order []tokenKey
+	collect := func(list []solanarpc.TokenBalance, post bool) {
+		for _, tb := range list {
+			k := tokenKey{tb.AccountIndex, tb.Mint}
+			row, ok := rows[k]
+			if !ok {
+				row = &tokenRow{pre: new(big.Int), post: new(big.Int)}
+				rows[k] = row
+				order = append(order, k)
+			}
+			row.owner = tb.Owner
+			row.decimals = tb.UITokenAmount.Decimals
+			v, ok := new(big.Int).SetString(tb.UITokenAmount.Amount, 10)
+			if !ok {
+				continue
+			}
+			if post {
+				row.post = v
+			} else {
+				row.pre = v
+			}
+		}
+	}
+	collect(r.Meta.PreTokenBalances, false)
+	collect(r.Meta.PostTokenBalances, true)
+
+	for _, k := range order {
+		row := rows[k]
+		delta := new(big.Int).Sub(row.post, row.pre)
+		if delta.Sign() == 0 || k.idx >= len(keys) {
+			continue
+		}
+		tokenAcc := keys[k.idx]
+		ad := byAccount[tokenAcc]
+		if ad == nil {
+			continue
+		}
+		ad.TokenBalanceChanges = append(ad.TokenBalanceChanges, TokenBalanceChange{
+			UserAccount:    row.owner,
+			TokenAccount:   tokenAcc,
+			Mint:           k.mint,
+			RawTokenAmount: RawTokenAmount{TokenAmount: delta.String(), Decimals: row.decimals},
+		})
+	}
+	return tx, nil
+}
@@ -1,88 +1,143 @@
 package analyzer
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
 )
 
 const (
-	splTokenProgramID         = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	splTokenProgramID         = solanarpc.TokenProgramID
 	metaplexMetadataProgramID = "metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s"
+	// mintDecimalsOffset is where an SPL mint account stores its decimals.
+	mintDecimalsOffset = 44
 )
 
-func fetchHeliusTransaction(ctx context.Context, signature, heliusURL string, client *http.Client) (*HeliusTransaction, error) {
+func fetchHeliusTransaction(ctx context.Context, signature, heliusURL, upstream string, client *http.Client) (*HeliusTransaction, error) {
 	payload := map[string][]string{"transactions": {signature}}
 	body, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, "POST", heliusURL, bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Do(req)
+	resp, err := postJSON(ctx, client, upstream, heliusURL, body)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("helius api returned non-200 status: %d %s", resp.StatusCode, string(bodyBytes))
-	}
 	var transactions []HeliusTransaction
-	if err := json.NewDecoder(resp.Body).Decode(&transactions); err != nil || len(transactions) == 0 {
-		return nil, fmt.Errorf("failed to decode or empty helius response for signature %s", signature)
+	if err := json.NewDecoder(resp.Body).Decode(&transactions); err != nil {
+		return nil, fmt.Errorf("failed to decode helius response for signature %s: %w", signature, err)
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("helius: %w", ErrTxNotIndexedYet)
 	}
 	return &transactions[0], nil
 }
 
-func rpcCall(ctx context.Context, rpcURL string, client *http.Client, method string, params []interface{}, result interface{}) error {
-	payload := RPCRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params}
-	body, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, "POST", rpcURL, bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Do(req)
+// RPCClient returns the client the analyzer uses for SolanaRPCURL, for
+// other components that should share its retries and endpoint pool.
+func (a *Analyzer) RPCClient() *solanarpc.Client { return a.rpc() }
+
+// rpc returns a client for SolanaRPCURL whose calls go through postJSON,
+// so they are retried and counted as upstream.rpc.*. With several RPC
+// URLs (SetRPCPool) calls go through the pool instead.
+func (a *Analyzer) rpc() *solanarpc.Client {
+	if a.rpcPool != nil {
+		return &solanarpc.Client{
+			URL:  a.SolanaRPCURL,
+			HTTP: a.httpClient,
+			Post: func(ctx context.Context, _ string, body []byte) (*http.Response, error) {
+				return a.rpcPool.post(ctx, a.httpClient, body)
+			},
+		}
+	}
+	return &solanarpc.Client{
+		URL:  a.SolanaRPCURL,
+		HTTP: a.httpClient,
+		Post: func(ctx context.Context, url string, body []byte) (*http.Response, error) {
+			return postJSON(ctx, a.httpClient, "rpc", url, body)
+		},
+	}
+}
+
+// fetchOnChainMetadata resolves token metadata via on-chain accounts with
+// retries. The mint's owner, decimals and metadata address come from
+// mintAccount when the mint was seen before.
+func (a *Analyzer) fetchOnChainMetadata(ctx context.Context, mint string) (*TokenMetadata, error) {
+	rpc := a.rpc()
+	acc, err := a.mintAccount(ctx, mint, rpc)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("rpc call to %s failed with status %d", rpcURL, resp.StatusCode)
+	if acc.Owner != solanarpc.TokenProgramID {
+		return nil, fmt.Errorf("unsupported token program: %s", acc.Owner)
+	}
+	if acc.PDA == "" {
+		if acc.PDA, err = metadataPDA(mint); err != nil {
+			return nil, fmt.Errorf("metaplex pda: %w", err)
+		}
+		a.saveMintAccount(ctx, mint, acc)
+	}
+	meta, err := fetchMetaplexMetadata(ctx, acc.PDA, rpc)
+	if err != nil {
+		return nil, err
 	}
-	return json.NewDecoder(resp.Body).Decode(result)
+	meta.Decimals = acc.Decimals
+	return &meta, nil
 }
 
-// fetchOnChainMetadata resolves token metadata via on-chain accounts with retries.
-func fetchOnChainMetadata(ctx context.Context, mint, rpcURL string, client *http.Client) (*TokenMetadata, error) {
+// fetchMintAccount reads mint's owner and decimals, retrying while the
+// account is missing or not yet owned by a program.
+func fetchMintAccount(ctx context.Context, mint string, rpc *solanarpc.Client) (mintAccount, error) {
 	const maxRetries = 3
 	const retryDelay = 2 * time.Second
 
-	var accInfo GetAccountInfoResponse
+	var acc *solanarpc.Account
 	var err error
 	var owner string
-	var decimals int
+	// wait pauses between attempts, giving up with the caller's deadline.
+	wait := func() error {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("getAccountInfo for mint %s: %w", mint, ctx.Err())
+		case <-time.After(retryDelay):
+			return nil
+		}
+	}
 
-	// 1. Get account info with retries to handle RPC flakiness and propagation lag.
+	// Get account info with retries to handle RPC flakiness and propagation lag.
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		params := []interface{}{mint, map[string]string{"encoding": "jsonParsed"}}
-		err = rpcCall(ctx, rpcURL, client, "getAccountInfo", params, &accInfo)
-		if err != nil {
-			log.Printf("[analyzer] getAccountInfo(%s) attempt %d failed: %v", mint, attempt, err)
-			time.Sleep(retryDelay)
+		acc, err = rpc.GetAccountInfo(ctx, mint)
+		switch {
+		case errors.Is(err, ErrUpstreamRateLimited):
+			// postJSON already backed off; more attempts only add load.
+			return mintAccount{}, fmt.Errorf("getAccountInfo for mint %s: %w", mint, err)
+		case errors.Is(err, ErrAccountNotFound):
+			log.Printf("[analyzer] mint %s not found yet (attempt %d/%d); retrying...", mint, attempt, maxRetries)
+			if err := wait(); err != nil {
+				return mintAccount{}, err
+			}
+			continue
+		case err != nil:
+			util.Errors.Printf("[analyzer] getAccountInfo(%s) attempt %d failed: %v", mint, attempt, err)
+			if err := wait(); err != nil {
+				return mintAccount{}, err
+			}
 			continue
 		}
 
-		owner = accInfo.Result.Value.Owner
-		decimals = accInfo.Result.Value.Data.Parsed.Info.Decimals
+		owner = acc.Owner
 
 		// Some RPCs briefly return an empty owner for new mints.
 		if owner == "" || owner == "11111111111111111111111111111111" {
 			log.Printf("[analyzer] mint %s has empty or system owner (attempt %d/%d); retrying...", mint, attempt, maxRetries)
-			time.Sleep(retryDelay)
+			if err := wait(); err != nil {
+				return mintAccount{}, err
+			}
 			continue
 		}
 
@@ -91,69 +146,31 @@ func fetchOnChainMetadata(ctx context.Context, mint, rpcURL string, client *http
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("getAccountInfo for mint %s failed after %d retries: %w", mint, maxRetries, err)
+		return mintAccount{}, fmt.Errorf("getAccountInfo for mint %s failed after %d retries: %w", mint, maxRetries, err)
 	}
 	if owner == "" || owner == "11111111111111111111111111111111" {
-		return nil, fmt.Errorf("mint %s still has empty owner after %d retries", mint, maxRetries)
+		return mintAccount{}, fmt.Errorf("mint %s still has empty owner after %d retries", mint, maxRetries)
 	}
-
-	if owner != splTokenProgramID {
-		return nil, fmt.Errorf("unsupported token program: %s", owner)
+	if owner != solanarpc.TokenProgramID {
+		return mintAccount{Owner: owner}, nil
 	}
 
-	// 2. Find the Metaplex PDA.
-	var progAccounts GetProgramAccountsResponse
-	params := []interface{}{
-		metaplexMetadataProgramID,
-		map[string]interface{}{
-			"encoding": "base64",
-			"filters": []map[string]interface{}{
-				{"memcmp": map[string]interface{}{"offset": 33, "bytes": mint}},
-			},
-		},
-	}
-	if err := rpcCall(ctx, rpcURL, client, "getProgramAccounts", params, &progAccounts); err != nil {
-		return nil, fmt.Errorf("getProgramAccounts for pda failed: %w", err)
+	// An SPL mint is mint authority (36 bytes), supply (8), then decimals.
+	if len(acc.Data) <= mintDecimalsOffset {
+		return mintAccount{}, fmt.Errorf("mint %s: account data too short (%d bytes)", mint, len(acc.Data))
 	}
-	if len(progAccounts.Result) == 0 {
-		return nil, errors.New("metaplex pda not found")
-	}
-	pdaAddress := progAccounts.Result[0].Pubkey
+	return mintAccount{Owner: owner, Decimals: int(acc.Data[mintDecimalsOffset])}, nil
+}
 
-	// 3. Get the raw data of the PDA.
-	var pdaInfo GetAccountInfoResponse_Base64
-	params = []interface{}{pdaAddress, map[string]string{"encoding": "base64"}}
-	if err := rpcCall(ctx, rpcURL, client, "getAccountInfo", params, &pdaInfo); err != nil {
-		return nil, fmt.Errorf("getAccountInfo for pda (base64) failed: %w", err)
-	}
-	if len(pdaInfo.Result.Value.Data) < 1 {
-		return nil, errors.New("pda has no data")
-	}
-	rawData, err := base64.StdEncoding.DecodeString(pdaInfo.Result.Value.Data[0])
+// fetchMetaplexMetadata reads and parses the Metaplex metadata account at
+// pdaAddress.
+func fetchMetaplexMetadata(ctx context.Context, pdaAddress string, rpc *solanarpc.Client) (TokenMetadata, error) {
+	pda, err := rpc.GetAccountInfo(ctx, pdaAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode pda data: %w", err)
+		return TokenMetadata{}, fmt.Errorf("getAccountInfo for pda failed: %w", err)
 	}
-
-	// 4. Parse the Borsh data to get the symbol.
-	const headerOffset = 65
-	if len(rawData) < headerOffset {
-		return nil, errors.New("metadata account data is too short")
-	}
-
-	nameLen := binary.LittleEndian.Uint32(rawData[headerOffset : headerOffset+4])
-	symbolOffset := headerOffset + 4 + int(nameLen)
-	if symbolOffset+4 > len(rawData) {
-		return nil, errors.New("failed to parse name: length exceeds buffer")
+	if len(pda.Data) < 1 {
+		return TokenMetadata{}, errors.New("pda has no data")
 	}
-
-	symbolLen := binary.LittleEndian.Uint32(rawData[symbolOffset : symbolOffset+4])
-	symbolEnd := symbolOffset + 4 + int(symbolLen)
-	if symbolEnd > len(rawData) {
-		return nil, errors.New("failed to parse symbol: length exceeds buffer")
-	}
-
-	symbolBytes := rawData[symbolOffset+4 : symbolEnd]
-	symbol := string(bytes.TrimRight(symbolBytes, "\x00"))
-
-	return &TokenMetadata{Symbol: symbol, Decimals: decimals}, nil
+	return parseMetaplexMetadata(pda.Data)
 }
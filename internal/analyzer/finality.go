@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Status is a transaction's cluster confirmation state.
+type Status struct {
+	Found  bool   // false while unknown to the cluster (or dropped)
+	Level  string // processed, confirmed or finalized
+	Failed bool   // landed with an error
+}
+
+// SignatureStatus looks signature up with getSignatureStatuses, searching
+// the ledger history so older transactions are found too.
+func (a *Analyzer) SignatureStatus(ctx context.Context, signature string) (Status, error) {
+	var resp struct {
+		Value []*struct {
+			ConfirmationStatus string `json:"confirmationStatus"`
+			Err                any    `json:"err"`
+		} `json:"value"`
+	}
+	params := []any{[]string{signature}, map[string]bool{"searchTransactionHistory": true}}
+	if err := a.rpc().Call(ctx, "getSignatureStatuses", params, &resp); err != nil {
+		return Status{}, fmt.Errorf("getSignatureStatuses: %w", err)
+	}
+	if len(resp.Value) == 0 || resp.Value[0] == nil {
+		return Status{}, nil
+	}
+	v := resp.Value[0]
+	return Status{Found: true, Level: v.ConfirmationStatus, Failed: v.Err != nil}, nil
+}
+
+// materialTolerance is the relative amount change that counts as different.
+const materialTolerance = 0.001
+
+// MateriallyDifferent reports whether two analyses of the same transaction
+// disagree on what moved: type, legs, mints or amounts beyond rounding.
+func MateriallyDifferent(a, b *Result) bool {
+	if a.Type != b.Type || a.Airdrop != b.Airdrop {
+		return true
+	}
+	return amountsDiffer(a.Sent, b.Sent) || amountsDiffer(a.Received, b.Received)
+}
+
+func amountsDiffer(x, y []Amount) bool {
+	if len(x) != len(y) {
+		return true
+	}
+	byMint := make(map[string]float64, len(x))
+	for _, a := range x {
+		byMint[a.Mint] += a.Amount
+	}
+	for _, b := range y {
+		v, ok := byMint[b.Mint]
+		if !ok {
+			return true
+		}
+		if diff := math.Abs(v - b.Amount); diff > materialTolerance*math.Max(math.Abs(v), math.Abs(b.Amount)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// ClientConfig tunes the HTTP client used for one upstream.
+type ClientConfig struct {
+	Timeout   time.Duration // whole request including retries; 0 = no timeout
+	Retries   int           // extra attempts on network errors, 429 and 5xx
+	KeepAlive time.Duration // idle connection lifetime; 0 disables keep-alive
+}
+
+// Defaults for each upstream, matching the previous hardcoded clients.
+var (
+	DefaultHeliusClient = ClientConfig{Timeout: 20 * time.Second, KeepAlive: 90 * time.Second}
+	DefaultRPCClient    = ClientConfig{Timeout: 20 * time.Second, KeepAlive: 90 * time.Second}
+	DefaultPriceClient  = ClientConfig{Timeout: 5 * time.Second, KeepAlive: 90 * time.Second}
+)
+
+// Client builds an *http.Client for c.
+func (c ClientConfig) Client() *http.Client {
+	tr := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     c.KeepAlive,
+		DisableKeepAlives:   c.KeepAlive == 0,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	var rt http.RoundTripper = tr
+	if c.Retries > 0 {
+		rt = &retryTransport{next: tr, retries: c.Retries}
+	}
+	return &http.Client{Timeout: c.Timeout, Transport: rt}
+}
+
+// offChainTimeout bounds one off-chain metadata download, redirects
+// included.
+const offChainTimeout = 10 * time.Second
+
+// offChainClient builds the client for off-chain metadata. Token URIs are
+// chosen by whoever minted the token, so it only connects to public
+// addresses (checked after DNS resolution, on every redirect) and ignores
+// proxy settings, which would hide the real destination.
+func offChainClient() *http.Client {
+	tr := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: refusePrivate,
+		}).DialContext,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     30 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   offChainTimeout,
+		Transport: tr,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+}
+
+// cgnat is the carrier-grade NAT range, private in practice.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// refusePrivate is a net.Dialer Control hook that refuses loopback,
+// private, link-local and other non-public addresses.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	ip := ap.Addr().Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || cgnat.Contains(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", ip)
+	}
+	return nil
+}
+
+// retryTransport retries requests that failed in transit or were answered
+// with 429/5xx. Every upstream call here is a read, so replaying is safe.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bo := util.NewBackoff(250*time.Millisecond, 4*time.Second, 2.0, 0.2)
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		retryable := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable || attempt >= t.retries || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(bo.Next()):
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
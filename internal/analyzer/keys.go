@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// keyPool spreads calls round robin over one URL per Helius API key and
+// counts each key's calls as helius.key<N>.<kind>, so usage per plan shows
+// in /health.
+type keyPool struct {
+	urls []string
+	next atomic.Uint64
+}
+
+// pick returns the URL for the next call of kind ("tx", "das") and the
+// upstream name it is reported under. With fewer than two URLs it returns
+// fallback and "helius".
+func (p *keyPool) pick(kind, fallback string) (url, upstream string) {
+	if p == nil || len(p.urls) < 2 {
+		return fallback, "helius"
+	}
+	i := int((p.next.Add(1) - 1) % uint64(len(p.urls)))
+	upstream = fmt.Sprintf("helius.key%d", i+1)
+	metrics.Inc(upstream + "." + kind)
+	return p.urls[i], upstream
+}
+
+// SetHeliusPools spreads enhanced-API (transaction) and DAS calls over
+// several Helius API keys, one URL per key. Call it before the analyzer is
+// used; a pool with fewer than two URLs is ignored.
+func (a *Analyzer) SetHeliusPools(txURLs, dasURLs []string) {
+	a.heliusTxPool = &keyPool{urls: txURLs}
+	a.dasPool = &keyPool{urls: dasURLs}
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+const (
+	// launchLookupPageSize is the getSignaturesForAddress page size (RPC max).
+	launchLookupPageSize = 1000
+	// launchLookupMaxPages bounds how far back we walk. Mints with more history
+	// than this are old enough that "minutes after launch" is meaningless.
+	launchLookupMaxPages = 3
+)
+
+// launchInfo is the cached outcome of a creation-time lookup for a mint.
+type launchInfo struct {
+	Created   time.Time
+	Known     bool // false when the mint has too much history to walk
+	CheckedAt time.Time
+}
+
+// mintCreationTime walks a mint's signature history back to its first
+// transaction and returns that block time. Only found creation times are
+// cached per mint: a mint with too much history to walk now may be looked up
+// again, and an empty history isn't a verdict either.
+func (a *Analyzer) mintCreationTime(ctx context.Context, mint string) (launchInfo, error) {
+	if v, ok := a.launchCache.Load(mint); ok {
+		return v.(launchInfo), nil
+	}
+
+	var oldest *solanarpc.SignatureInfo
+	before := ""
+	for page := 0; page < launchLookupMaxPages; page++ {
+		sigs, err := a.rpc().GetSignaturesForAddress(ctx, mint, solanarpc.SignaturesOptions{Limit: launchLookupPageSize, Before: before})
+		if err != nil {
+			return launchInfo{}, fmt.Errorf("getSignaturesForAddress(%s): %w", mint, err)
+		}
+		if len(sigs) > 0 {
+			oldest = &sigs[len(sigs)-1]
+		}
+		// A short page is the last one; so is an empty page after full
+		// ones, when the history is an exact multiple of the page size.
+		if len(sigs) < launchLookupPageSize {
+			if oldest == nil {
+				return launchInfo{Known: false, CheckedAt: time.Now()}, nil
+			}
+			if oldest.BlockTime == nil {
+				return launchInfo{}, fmt.Errorf("first signature of %s has no block time", mint)
+			}
+			info := launchInfo{Created: time.Unix(*oldest.BlockTime, 0).UTC(), Known: true, CheckedAt: time.Now()}
+			a.launchCache.Store(mint, info)
+			return info, nil
+		}
+		before = oldest.Signature
+	}
+	return launchInfo{Known: false, CheckedAt: time.Now()}, nil
+}
+
+// annotateEarlyBuy adds a "bought N minutes after token creation" note for
+// each unpriced token the wallet received in a swap.
+func (a *Analyzer) annotateEarlyBuy(ctx context.Context, res *Result) {
+	if len(res.Sent) == 0 {
+		return // a buy always spends something
+	}
+	for _, amt := range res.Received {
+		if _, priced := isPriceTracked(amt.Mint); priced {
+			continue
+		}
+		info, err := a.mintCreationTime(ctx, amt.Mint)
+		if err != nil || !info.Known {
+			continue
+		}
+		age := res.Timestamp.Sub(info.Created)
+		if age < 0 {
+			age = 0
+		}
+		if res.LaunchAge == 0 || age < res.LaunchAge {
+			res.LaunchAge = age
+		}
+		res.Notes = append(res.Notes, fmt.Sprintf("⏱ Bought %s <b>%s</b> after token creation", EscapeHTML(amt.Symbol), formatAge(age)))
+	}
+}
+
+// formatAge renders a duration as a coarse human string ("7 minutes", "3 hours").
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%d seconds", int(d.Seconds()))
+	case d < 2*time.Hour:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	}
+}
@@ -28,6 +28,14 @@ func isPriceTracked(mint string) (string, bool) {
 	}
 }
 
+// IsQuoteMint reports whether mint is SOL or USDC, the currencies trades are
+// priced in. Other tokens can carry a USD value too (see annotateMarket), so
+// callers telling buys from sells should use this rather than Amount.USD.
+func IsQuoteMint(mint string) bool {
+	_, ok := isPriceTracked(mint)
+	return ok
+}
+
 // shouldFilter ignores tiny dust-only SOL moves when no other tokens move.
 func shouldFilter(tx *HeliusTransaction, trackedAddr string) bool {
 	if tx.TransactionError != nil && string(*tx.TransactionError) != "null" {
@@ -62,38 +70,23 @@ func shouldFilter(tx *HeliusTransaction, trackedAddr string) bool {
 // calculateNetBalanceChanges nets balances for the tracked address.
 //
 // Core SOL rule:
-//   - Trust ONLY accountData.nativeBalanceChange for SOL exposure.
-//   - DO NOT add negative WSOL deltas (those are usually spends of newly wrapped SOL).
-//   - Add positive WSOL delta to SOL ONLY if there was a WSOL inflow from a different user.
-//
-// Everything else (non-WSOL SPL) is summed normally across the tx.
+//   - Start from accountData.nativeBalanceChange (includes fees, wraps,
+//     unwraps and rent for temporary WSOL accounts).
+//   - Add the WSOL exposure that is not already native; see wsolFlows for the
+//     full lifecycle model and the fallback when balance data is missing.
 //
-// We ignore nativeTransfers entirely (wrap/unwrap/rent noise).
+// Everything else (non-WSOL SPL) is netted per mint; see reconcileTokenDeltas.
 func calculateNetBalanceChanges(
+	ctx context.Context,
 	tx *HeliusTransaction,
 	trackedAddr string,
 	metadataCache map[string]TokenMetadata,
 	oracle *PriceOracle,
-) (sent []string, received []string) {
+) (sent []Amount, received []Amount) {
 
-	// 1) Per-mint SPL deltas for the tracked user
-	tokenDeltas := make(map[string]float64)
-
-	// Track whether we saw any WSOL inflow from someone else (not a self wrap)
-	wsolInflowFromOther := false
-
-	for _, tt := range tx.TokenTransfers {
-		if tt.FromUserAccount == trackedAddr {
-			tokenDeltas[tt.Mint] -= tt.TokenAmount
-		}
-		if tt.ToUserAccount == trackedAddr {
-			tokenDeltas[tt.Mint] += tt.TokenAmount
-			// Detect true WSOL inflow (from another user)
-			if tt.Mint == wsolMint && tt.FromUserAccount != trackedAddr {
-				wsolInflowFromOther = true
-			}
-		}
-	}
+	// 1) Per-mint SPL deltas for the tracked user (WSOL handled separately),
+	// cross-checked between balance changes and the transfer list.
+	tokenDeltas := reconcileTokenDeltas(tx, trackedAddr)
 
 	// 2) Native SOL net (includes fees) for the tracked user
 	var nativeChangeLamports int64
@@ -105,31 +98,20 @@ func calculateNetBalanceChanges(
 	}
 	nativeSol := float64(nativeChangeLamports) / lamportsPerSol
 
-	// 3) WSOL handling (see rule above)
-	wsolDelta := tokenDeltas[wsolMint]
-	delete(tokenDeltas, wsolMint)
-
-	// Start with native SOL only
-	totalSolChange := nativeSol
-
-	// If user truly received WSOL from someone else, add only the positive net to SOL.
-	// Never subtract negative WSOL deltas (avoids double count when wrapping + spending).
-	if wsolInflowFromOther && wsolDelta > 1e-12 {
-		totalSolChange += wsolDelta
-	}
+	// 3) WSOL lifecycle (wrap, spend/receive, unwrap), minus token-account
+	// rent which is reported on its own line rather than as a trade.
+	totalSolChange := nativeSol + collectWSOLFlows(tx, trackedAddr).solAdjustment() - collectRent(tx, trackedAddr).Net()
 
 	// 4) Emit SOL (with USD)
 	if math.Abs(totalSolChange) > 1e-12 {
-		amount := math.Abs(totalSolChange)
-		formatted := fmt.Sprintf("%s SOL", formatHumanReadable(amount))
-		if price, ok := oracle.GetPriceUSD(context.Background(), "solana"); ok {
-			usd := amount * price
-			formatted += fmt.Sprintf(" ($%.2f)", usd)
+		amt := Amount{Mint: wsolMint, Symbol: "SOL", Amount: math.Abs(totalSolChange)}
+		if price, ok := oracle.GetPriceUSD(ctx, "solana"); ok {
+			amt.USD = amt.Amount * price
 		}
 		if totalSolChange > 0 {
-			received = append(received, formatted)
+			received = append(received, amt)
 		} else {
-			sent = append(sent, formatted)
+			sent = append(sent, amt)
 		}
 	}
 
@@ -145,25 +127,91 @@ func calculateNetBalanceChanges(
 			meta = TokenMetadata{Symbol: fmt.Sprintf("Mint(%s...)", mint[:4]), Decimals: 6}
 		}
 
-		formatted := fmt.Sprintf("%s %s", formatHumanReadable(amount), meta.Symbol)
+		amt := Amount{Mint: mint, Symbol: meta.Symbol, Amount: amount}
 
 		if coinID, tracked := isPriceTracked(mint); tracked {
-			if price, ok := oracle.GetPriceUSD(context.Background(), coinID); ok {
-				usd := amount * price
-				formatted += fmt.Sprintf(" ($%.2f)", usd)
+			if price, ok := oracle.GetPriceUSD(ctx, coinID); ok {
+				amt.USD = amount * price
 			}
 		}
 
 		if delta > 0 {
-			received = append(received, formatted)
+			received = append(received, amt)
 		} else {
-			sent = append(sent, formatted)
+			sent = append(sent, amt)
 		}
 	}
 
 	return sent, received
 }
 
+// formatAmount renders an Amount as "1,234 SYMBOL ($12.34)", or
+// "1,234 SYMBOL ($12.34, −12% today)" when the 24h change is known.
+func formatAmount(a Amount, nf NumberFormat) string {
+	s := fmt.Sprintf("%s %s", formatHumanReadable(a.Amount, nf), a.Symbol)
+	if a.Verified != nil {
+		if *a.Verified {
+			s += " ✅ verified"
+		} else {
+			s += " ⚠️ unverified"
+		}
+	}
+	if a.USD > 0 {
+		if a.Change24h != nil {
+			s += fmt.Sprintf(" ($%s, %s today)", nf.Fixed(a.USD, 2), formatChange(*a.Change24h, nf))
+		} else {
+			s += fmt.Sprintf(" ($%s)", nf.Fixed(a.USD, 2))
+		}
+	}
+	return s
+}
+
+// formatChange renders a percentage change with an explicit sign: "+5%",
+// "−12%", "+0.4%".
+func formatChange(pct float64, nf NumberFormat) string {
+	sign := "+"
+	if pct < 0 {
+		sign = "−"
+	}
+	v := math.Round(math.Abs(pct)*10) / 10
+	if v == 0 {
+		sign = ""
+	}
+	prec := 1
+	if v >= 10 || v == math.Trunc(v) {
+		prec = 0 // "+12%", "+5%", but "+0.4%"
+	}
+	return sign + nf.Fixed(v, prec) + "%"
+}
+
+// String renders the amount like the built-in summary does.
+func (a Amount) String() string { return formatAmount(a, DefaultNumberFormat) }
+
+// Format renders the amount with the given number format.
+func (a Amount) Format(nf NumberFormat) string { return formatAmount(a, nf) }
+
+// joinAmounts formats a list of amounts as a comma-separated string.
+func joinAmounts(list []Amount, nf NumberFormat) string {
+	parts := make([]string, len(list))
+	for i, a := range list {
+		parts[i] = formatAmount(a, nf)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sizeUSD measures a transaction by the larger of its priced sides, so a swap
+// of $100 SOL for a memecoin counts as $100 rather than $0 or $200.
+func sizeUSD(sent, received []Amount) float64 {
+	var out, in float64
+	for _, a := range sent {
+		out += a.USD
+	}
+	for _, a := range received {
+		in += a.USD
+	}
+	return math.Max(out, in)
+}
+
 // parseAmount is a new helper from analyzer.go, consolidated here for reuse.
 func parseAmount(amountStr string, decimals int) float64 {
 	val, _ := strconv.ParseFloat(amountStr, 64)
@@ -171,51 +219,34 @@ func parseAmount(amountStr string, decimals int) float64 {
 }
 
 // formatHumanReadable formats numbers according to the specific rules:
-// - Adds thousand separators to the integer part.
-// - For numbers >= 1000, shows 0 decimal places.
-// - For numbers >= 1, shows 2 decimal places.
-// - For numbers < 1, shows 3 significant figures (e.g., 0.123 or 0.000123).
-func formatHumanReadable(f float64) string {
+//   - Adds thousand separators to the integer part.
+//   - For numbers >= 1000, shows 0 decimal places.
+//   - For numbers >= 1, shows 2 decimal places.
+//   - For numbers < 1, shows nf.SigDigits significant figures (e.g., 0.123 or 0.000123).
+//   - With nf.Compact, numbers of a million or more use K/M/B/T suffixes, as do
+//     numbers at or above nf.CompactAbove.
+//
+// Separators come from nf.
+func formatHumanReadable(f float64, nf NumberFormat) string {
+	if f < 0 {
+		return "-" + formatHumanReadable(-f, nf)
+	}
+	if nf.compacts(f) {
+		return nf.compact(f)
+	}
 	// Rule for numbers >= 1
 	if f >= 1 {
 		prec := 2
 		if f >= 1000 {
 			prec = 0
 		}
-
-		// Format with precision and split into integer and fractional parts
-		s := strconv.FormatFloat(f, 'f', prec, 64)
-		parts := strings.Split(s, ".")
-		integerPart := parts[0]
-		fractionalPart := ""
-		if len(parts) > 1 {
-			fractionalPart = "." + parts[1]
-		}
-
-		// Add thousand separators to the integer part
-		n := len(integerPart)
-		if n <= 3 {
-			return s
-		}
-		// Calculate the number of commas
-		commas := (n - 1) / 3
-		// Create a new string with space for commas
-		b := make([]byte, n+commas)
-		// Fill from right to left
-		for i, j, k := n-1, len(b)-1, 0; ; i, j = i-1, j-1 {
-			b[j] = integerPart[i]
-			if i == 0 {
-				return string(b) + fractionalPart
-			}
-			k++
-			if k%3 == 0 {
-				j--
-				b[j] = ','
-			}
-		}
+		return nf.Fixed(f, prec)
 	}
 
-	// Rule for numbers < 1 (3 significant figures)
-	s := strconv.FormatFloat(f, 'g', 3, 64)
-	return s
+	// Rule for numbers < 1 (significant figures)
+	digits := nf.SigDigits
+	if digits <= 0 {
+		digits = 3
+	}
+	return strings.Replace(strconv.FormatFloat(f, 'g', digits, 64), ".", nf.Decimal, 1)
 }
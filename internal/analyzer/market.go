@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// jupiterPriceURL serves USD prices for arbitrary SPL mints.
+	jupiterPriceURL = "https://lite-api.jup.ag/price/v3"
+	// marketCacheTTL is how long a token's price and supply are reused.
+	marketCacheTTL = time.Minute
+)
+
+// tokenMarket is the cached market snapshot of one mint.
+type tokenMarket struct {
+	PriceUSD  float64
+	Change24h *float64 // percent; nil when the source doesn't say
+	Supply    float64  // UI units
+	CheckedAt time.Time
+}
+
+// annotateMarket prices the traded non-SOL/USDC tokens of a swap, attaches
+// their 24h change and notes their fully diluted valuation (total supply ×
+// price; circulating supply isn't known on chain). Tokens Jupiter can't
+// price fall back to the price implied by the swap itself.
+func (a *Analyzer) annotateMarket(ctx context.Context, res *Result) {
+	pricedUSD, unpriced := 0.0, map[string]bool{}
+	for _, list := range [][]Amount{res.Sent, res.Received} {
+		for _, amt := range list {
+			if _, ok := isPriceTracked(amt.Mint); ok {
+				pricedUSD = max(pricedUSD, amt.USD)
+			} else if amt.Amount > 0 {
+				unpriced[amt.Mint] = true
+			}
+		}
+	}
+
+	for _, list := range [][]Amount{res.Sent, res.Received} {
+		for i := range list {
+			amt := &list[i]
+			if !unpriced[amt.Mint] {
+				continue
+			}
+			delete(unpriced, amt.Mint) // one note per mint
+			m, err := a.tokenMarket(ctx, amt.Mint)
+			if err != nil {
+				log.Printf("[analyzer] market data for %s: %v", amt.Mint, err)
+			}
+			if m.PriceUSD <= 0 && pricedUSD > 0 && len(res.Sent)+len(res.Received) == 2 {
+				m.PriceUSD = pricedUSD / amt.Amount // implied by this swap
+			}
+			if m.PriceUSD <= 0 {
+				continue
+			}
+			if amt.USD == 0 {
+				amt.USD = amt.Amount * m.PriceUSD
+			}
+			amt.Change24h = m.Change24h
+			if m.Supply > 0 {
+				res.Notes = append(res.Notes, fmt.Sprintf("🏷 %s FDV ≈ <b>$%s</b>", EscapeHTML(amt.Symbol), formatUSDCompact(m.Supply*m.PriceUSD)))
+			}
+		}
+	}
+}
+
+// tokenMarket fetches (or returns the cached) price and supply of mint.
+// A partial result is returned alongside an error.
+func (a *Analyzer) tokenMarket(ctx context.Context, mint string) (tokenMarket, error) {
+	if v, ok := a.marketCache.Load(mint); ok && time.Since(v.(tokenMarket).CheckedAt) < marketCacheTTL {
+		return v.(tokenMarket), nil
+	}
+	m := tokenMarket{CheckedAt: time.Now()}
+
+	var supply struct {
+		Value struct {
+			UIAmountString string `json:"uiAmountString"`
+		} `json:"value"`
+	}
+	if err := a.rpc().Call(ctx, "getTokenSupply", []any{mint}, &supply); err != nil {
+		return m, fmt.Errorf("getTokenSupply: %w", err)
+	}
+	m.Supply, _ = strconv.ParseFloat(supply.Value.UIAmountString, 64)
+
+	quote, err := a.jupiterPrice(ctx, mint)
+	if err != nil {
+		return m, err
+	}
+	m.PriceUSD = quote.USDPrice
+	m.Change24h = quote.PriceChange24h
+	a.marketCache.Store(mint, m)
+	return m, nil
+}
+
+// jupiterQuote is one entry of the Jupiter price API response.
+type jupiterQuote struct {
+	USDPrice       float64  `json:"usdPrice"`
+	PriceChange24h *float64 `json:"priceChange24h"`
+}
+
+// jupiterPrice returns Jupiter's current quote for mint; a zero USDPrice
+// means Jupiter doesn't price it.
+func (a *Analyzer) jupiterPrice(ctx context.Context, mint string) (jupiterQuote, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", jupiterPriceURL+"?ids="+mint, nil)
+	if err != nil {
+		return jupiterQuote{}, err
+	}
+	resp, err := a.priceOracle.httpClient.Do(req)
+	if err != nil {
+		return jupiterQuote{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return jupiterQuote{}, fmt.Errorf("jupiter price: status %d", resp.StatusCode)
+	}
+	var body map[string]jupiterQuote
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return jupiterQuote{}, fmt.Errorf("jupiter price: %w", err)
+	}
+	return body[mint], nil
+}
+
+// formatUSDCompact renders dollar amounts for notes: 950, 12.4K, 1.23M.
+func formatUSDCompact(v float64) string {
+	if v < 1000 {
+		return DefaultNumberFormat.Fixed(v, 0)
+	}
+	return DefaultNumberFormat.compact(v)
+}
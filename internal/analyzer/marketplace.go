@@ -0,0 +1,39 @@
+package analyzer
+
+import "strings"
+
+// marketplacePrograms names the NFT marketplace programs Helius often
+// reports as source UNKNOWN. Analyzer.Marketplaces adds to it.
+var marketplacePrograms = map[string]string{
+	"M2mx93ekt1fmXSVkTrUL9xVFHkmME8HTUi5Cyc5aF7K":  "Magic Eden",
+	"MEisE1HzehtrDpAAT8PnLHjpSSkRYakotTuJRPjTpo8":  "Magic Eden",
+	"mmm3XBJg5gk8XJxEKBvdgptZz6SgK4tXvn36sodowMc":  "Magic Eden",
+	"TSWAPaqyCSx2KABk68Shruf4rp7CxcNi8hAsbdwmHbN":  "Tensor",
+	"TCMPhJdwDryooaGtiocG1u3xcYbRpiJzb283XfCZsDp":  "Tensor",
+	"CJsLwbP1iu5DuUikHEJnLfANgKy6stB2uFgvBBHoyxwz": "Solanart",
+	"SNPRohhBurQwrpwAptw1QYtpFdfEKitr4WSJ125cN1g":  "Sniper",
+}
+
+// isNFTEvent reports whether a Helius transaction type is an NFT event
+// (NFT_SALE, NFT_LISTING, COMPRESSED_NFT_MINT, ...).
+func isNFTEvent(txType string) bool {
+	return strings.Contains(txType, "NFT")
+}
+
+// sourceLabel is the "via ..." name for tx: Helius' source, except that an
+// NFT event from an unknown source is named after the first marketplace
+// program it invokes.
+func (a *Analyzer) sourceLabel(tx *HeliusTransaction) string {
+	if !isNFTEvent(tx.Type) || (tx.Source != "" && tx.Source != "UNKNOWN") {
+		return tx.Source
+	}
+	for _, id := range programIDs(tx.Instructions) {
+		if name, ok := a.Marketplaces[id]; ok {
+			return name
+		}
+		if name, ok := marketplacePrograms[id]; ok {
+			return name
+		}
+	}
+	return tx.Source
+}
@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+const (
+	// metadataParallelism bounds concurrent per-mint RPC lookups.
+	metadataParallelism = 4
+	// dasBatchLimit is the most ids getAssetBatch accepts per call.
+	dasBatchLimit = 1000
+)
+
+// dasAsset is the subset of a DAS getAssetBatch entry we need.
+type dasAsset struct {
+	ID      string `json:"id"`
+	Content struct {
+		JSONURI  string `json:"json_uri"`
+		Metadata struct {
+			Name        string `json:"name"`
+			Symbol      string `json:"symbol"`
+			Description string `json:"description"`
+		} `json:"metadata"`
+		Links struct {
+			Image       string `json:"image"`
+			ExternalURL string `json:"external_url"`
+		} `json:"links"`
+	} `json:"content"`
+	Royalty struct {
+		BasisPoints int `json:"basis_points"`
+	} `json:"royalty"`
+	Creators []struct {
+		Address  string `json:"address"`
+		Share    int    `json:"share"`
+		Verified bool   `json:"verified"`
+	} `json:"creators"`
+	TokenInfo *struct {
+		Symbol   string `json:"symbol"`
+		Decimals *int   `json:"decimals"`
+	} `json:"token_info"`
+}
+
+// fetchMetadataBatch resolves metadata for mints: first in one DAS
+// getAssetBatch call when DASURL is set, then the remainder concurrently via
+// on-chain lookups. Mints that can't be resolved are missing from out;
+// those that failed only because the RPC rate-limited us are in limited.
+func (a *Analyzer) fetchMetadataBatch(ctx context.Context, mints []string) (out map[string]TokenMetadata, limited map[string]bool) {
+	out = make(map[string]TokenMetadata, len(mints))
+	limited = make(map[string]bool)
+	rest := mints
+	if a.DASURL != "" {
+		dasURL, _ := a.dasPool.pick("das", a.DASURL)
+		found, err := fetchAssetBatch(ctx, dasURL, a.httpClient, mints)
+		if err != nil {
+			util.Errors.Printf("[analyzer] getAssetBatch for %d mint(s) failed: %v; using RPC lookups", len(mints), err)
+		}
+		rest = rest[:0:0]
+		for _, mint := range mints {
+			if meta, ok := found[mint]; ok {
+				out[mint] = meta
+			} else {
+				rest = append(rest, mint)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, metadataParallelism)
+	for _, mint := range rest {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(mint string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			meta, err := a.fetchOnChainMetadata(ctx, mint)
+			if err != nil {
+				util.Errors.Printf("[analyzer] failed to fetch on-chain metadata for %s: %v", mint, err)
+				if errors.Is(err, ErrUpstreamRateLimited) {
+					mu.Lock()
+					limited[mint] = true
+					mu.Unlock()
+				}
+				return
+			}
+			mu.Lock()
+			out[mint] = *meta
+			mu.Unlock()
+		}(mint)
+	}
+	wg.Wait()
+	return out, limited
+}
+
+// fetchAssetBatch calls the DAS getAssetBatch method. Assets without token
+// decimals (NFTs, unknown ids) are left out.
+func fetchAssetBatch(ctx context.Context, dasURL string, client *http.Client, mints []string) (map[string]TokenMetadata, error) {
+	out := make(map[string]TokenMetadata, len(mints))
+	for start := 0; start < len(mints); start += dasBatchLimit {
+		end := min(start+dasBatchLimit, len(mints))
+		payload := map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "getAssetBatch",
+			"params":  map[string]any{"ids": mints[start:end]},
+		}
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequestWithContext(ctx, "POST", dasURL, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return out, err
+		}
+		var decoded struct {
+			Result []*dasAsset `json:"result"`
+			Error  *RPCError   `json:"error"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&decoded)
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode != http.StatusOK:
+			return out, fmt.Errorf("status %d", resp.StatusCode)
+		case err != nil:
+			return out, err
+		case decoded.Error != nil:
+			return out, decoded.Error
+		}
+
+		for _, asset := range decoded.Result {
+			if asset == nil || asset.TokenInfo == nil || asset.TokenInfo.Decimals == nil {
+				continue
+			}
+			symbol := strings.TrimSpace(asset.TokenInfo.Symbol)
+			if symbol == "" {
+				symbol = strings.TrimSpace(asset.Content.Metadata.Symbol)
+			}
+			if symbol == "" {
+				continue // let the on-chain lookup try
+			}
+			out[asset.ID] = asset.metadata(symbol, *asset.TokenInfo.Decimals)
+		}
+	}
+	return out, nil
+}
+
+// metadata converts the asset. DAS has already read the off-chain JSON, so
+// OffChain is filled from it when it has anything to show.
+func (asset *dasAsset) metadata(symbol string, decimals int) TokenMetadata {
+	c := asset.Content
+	meta := TokenMetadata{
+		Symbol:       symbol,
+		Decimals:     decimals,
+		Name:         strings.TrimSpace(c.Metadata.Name),
+		URI:          strings.TrimSpace(c.JSONURI),
+		SellerFeeBps: asset.Royalty.BasisPoints,
+		FetchedAt:    time.Now(),
+	}
+	for _, cr := range asset.Creators {
+		meta.Creators = append(meta.Creators, Creator{Address: cr.Address, Verified: cr.Verified, Share: cr.Share})
+	}
+	if c.Metadata.Description != "" || c.Links.Image != "" {
+		meta.OffChain = &OffChainMetadata{
+			Description: strings.TrimSpace(c.Metadata.Description),
+			Image:       gatewayURL(strings.TrimSpace(c.Links.Image)),
+			ExternalURL: c.Links.ExternalURL,
+		}
+	}
+	return meta
+}
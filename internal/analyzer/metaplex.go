@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// Creator is one entry of a token's Metaplex creator list.
+type Creator struct {
+	Address  string
+	Verified bool // signed by the creator, not just listed
+	Share    int  // percent of royalties
+}
+
+// OffChainMetadata is the subset of the JSON document at a token's URI
+// shown in token displays.
+type OffChainMetadata struct {
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	ExternalURL string `json:"external_url"`
+}
+
+const (
+	// metaplexHeaderLen skips the key byte, update authority and mint.
+	metaplexHeaderLen = 1 + 32 + 32
+	// maxOffChainBytes caps how much of an off-chain JSON document is read.
+	maxOffChainBytes = 256 << 10
+	// ipfsGateway serves ipfs:// URIs.
+	ipfsGateway = "https://ipfs.io/ipfs/"
+)
+
+// borshReader reads the Borsh encoding of the metadata account in order;
+// the first error sticks and later reads return zero values.
+type borshReader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *borshReader) take(n int, what string) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data)-r.off {
+		r.err = fmt.Errorf("failed to parse %s: length exceeds buffer", what)
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *borshReader) u8(what string) byte {
+	if b := r.take(1, what); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *borshReader) u16(what string) uint16 {
+	if b := r.take(2, what); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *borshReader) u32(what string) uint32 {
+	if b := r.take(4, what); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// string reads a length-prefixed string. Metaplex pads names, symbols and
+// URIs with NULs to a fixed size; they are trimmed.
+func (r *borshReader) string(what string) string {
+	n := r.u32(what)
+	b := r.take(int(n), what)
+	return strings.TrimSpace(string(bytes.TrimRight(b, "\x00")))
+}
+
+// parseMetaplexMetadata decodes a Metaplex metadata account: name, symbol,
+// URI, seller fee and creators. Decimals are not part of it.
+func parseMetaplexMetadata(data []byte) (TokenMetadata, error) {
+	if len(data) < metaplexHeaderLen {
+		return TokenMetadata{}, errors.New("metadata account data is too short")
+	}
+	r := &borshReader{data: data, off: metaplexHeaderLen}
+	meta := TokenMetadata{
+		Name:         r.string("name"),
+		Symbol:       r.string("symbol"),
+		URI:          r.string("uri"),
+		SellerFeeBps: int(r.u16("seller fee")),
+	}
+	if r.err != nil {
+		return TokenMetadata{}, r.err
+	}
+	// Creators are an Option<Vec<Creator>>. Older or truncated accounts
+	// may end before it; the fields above are still good then.
+	if r.u8("creators") == 1 {
+		n := r.u32("creators")
+		for i := uint32(0); i < n && r.err == nil; i++ {
+			addr := r.take(32, "creator")
+			verified := r.u8("creator")
+			share := r.u8("creator")
+			if r.err == nil {
+				meta.Creators = append(meta.Creators, Creator{Address: base58.Encode(addr), Verified: verified == 1, Share: int(share)})
+			}
+		}
+	}
+	return meta, nil
+}
+
+// fetchOffChainMetadata downloads the JSON document at uri, at most
+// maxOffChainBytes of it. ipfs:// URIs go through a public gateway; other
+// schemes are not fetched. client should be an offChainClient.
+func fetchOffChainMetadata(ctx context.Context, client *http.Client, uri string) (*OffChainMetadata, error) {
+	u := gatewayURL(uri)
+	if !strings.HasPrefix(u, "https://") && !strings.HasPrefix(u, "http://") {
+		return nil, fmt.Errorf("unsupported metadata uri %q", uri)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxOffChainBytes {
+		return nil, fmt.Errorf("document of %d bytes exceeds %d", resp.ContentLength, maxOffChainBytes)
+	}
+	var off OffChainMetadata
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOffChainBytes)).Decode(&off); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", u, err)
+	}
+	off.Description = strings.TrimSpace(off.Description)
+	off.Image = gatewayURL(strings.TrimSpace(off.Image))
+	return &off, nil
+}
+
+// gatewayURL rewrites ipfs:// URIs to ipfsGateway and leaves others alone.
+func gatewayURL(uri string) string {
+	if rest, ok := strings.CutPrefix(uri, "ipfs://"); ok {
+		return ipfsGateway + strings.TrimPrefix(rest, "ipfs/")
+	}
+	return uri
+}
+
+// TokenDetails returns everything known about mint for a token display:
+// the cached metadata (looked up if missing or a fallback) plus, when it
+// has a URI, the off-chain JSON, fetched once and then cached with it. A
+// failed off-chain fetch is logged and leaves OffChain nil.
+func (a *Analyzer) TokenDetails(ctx context.Context, mint string) (TokenMetadata, error) {
+	meta, ok := a.CachedMetadata(mint)
+	if !ok || meta.Failed {
+		var err error
+		if meta, err = a.RefreshMetadata(ctx, mint); err != nil {
+			return TokenMetadata{}, err
+		}
+	}
+	if meta.URI == "" || meta.OffChain != nil {
+		return meta, nil
+	}
+	off, err := fetchOffChainMetadata(ctx, a.offChain, meta.URI)
+	if err != nil {
+		log.Printf("[analyzer] off-chain metadata for %s: %v", mint, err)
+		return meta, nil
+	}
+	meta.OffChain = off
+	a.metadataCache.Store(mint, meta)
+	return meta, nil
+}
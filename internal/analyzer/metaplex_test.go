@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/mr-tron/base58"
+)
+
+// metadataAccount builds a Metaplex metadata account up to the creators
+// option; creators is appended raw so tests can cut it short.
+func metadataAccount(name, symbol, uri string, feeBps uint16, creators []byte) []byte {
+	var b bytes.Buffer
+	b.Write(make([]byte, metaplexHeaderLen))
+	for _, s := range []string{name, symbol, uri} {
+		binary.Write(&b, binary.LittleEndian, uint32(len(s)))
+		b.WriteString(s)
+	}
+	binary.Write(&b, binary.LittleEndian, feeBps)
+	b.Write(creators)
+	return b.Bytes()
+}
+
+func creatorList(cs ...Creator) []byte {
+	var b bytes.Buffer
+	b.WriteByte(1)
+	binary.Write(&b, binary.LittleEndian, uint32(len(cs)))
+	for _, c := range cs {
+		addr, _ := base58.Decode(c.Address)
+		b.Write(addr)
+		if c.Verified {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+		b.WriteByte(byte(c.Share))
+	}
+	return b.Bytes()
+}
+
+func TestParseMetaplexMetadata(t *testing.T) {
+	creators := []Creator{
+		{Address: "7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU", Verified: true, Share: 70},
+		{Address: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Share: 30},
+	}
+	full := metadataAccount("Bonk\x00\x00\x00\x00", "BONK\x00\x00", "https://example.com/bonk.json\x00\x00", 250, creatorList(creators...))
+	oversized := metadataAccount("Bonk", "BONK", "", 0, nil)
+	binary.LittleEndian.PutUint32(oversized[metaplexHeaderLen:], 0xFFFFFFFF)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    TokenMetadata
+		wantErr bool
+	}{
+		{
+			name: "full account with creators",
+			data: full,
+			want: TokenMetadata{Name: "Bonk", Symbol: "BONK", URI: "https://example.com/bonk.json", SellerFeeBps: 250, Creators: creators},
+		},
+		{
+			name: "ends before the creators option",
+			data: metadataAccount("Bonk", "BONK", "", 0, nil),
+			want: TokenMetadata{Name: "Bonk", Symbol: "BONK"},
+		},
+		{
+			name: "creator list cut short keeps complete entries",
+			data: full[:len(full)-10],
+			want: TokenMetadata{Name: "Bonk", Symbol: "BONK", URI: "https://example.com/bonk.json", SellerFeeBps: 250, Creators: creators[:1]},
+		},
+		{
+			name: "no creators",
+			data: metadataAccount("Bonk", "BONK", "", 0, []byte{0}),
+			want: TokenMetadata{Name: "Bonk", Symbol: "BONK"},
+		},
+		{name: "oversized length prefix", data: oversized, wantErr: true},
+		{name: "string runs past the end", data: metadataAccount("Bonk", "BONK", "", 0, nil)[:metaplexHeaderLen+6], wantErr: true},
+		{name: "shorter than the header", data: make([]byte, metaplexHeaderLen-1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMetaplexMetadata(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
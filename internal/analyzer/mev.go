@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+// sandwichWindow is how many block positions on either side of the victim tx
+// we scan for the front-run/back-run pair. Bundles are usually contiguous, but
+// a single sandwich sometimes wraps several victims.
+const sandwichWindow = 3
+
+// The block of a swap alerted at processed commitment isn't served until it
+// is confirmed, so a sandwich check tries sandwichAttempts times,
+// sandwichRetry apart.
+const (
+	sandwichAttempts = 4
+	sandwichRetry    = 5 * time.Second
+)
+
+// Programs and sysvars that appear writable in nearly every swap; they must not
+// count as a "shared pool" between the three transactions.
+var sandwichIgnoredAccounts = map[string]bool{
+	splTokenProgramID:                  true,
+	wsolMint:                           true,
+	"11111111111111111111111111111111": true,
+	"ComputeBudget111111111111111111111111111111":  true,
+	"ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL": true,
+}
+
+// blockTx is the reduced view of a block transaction used for sandwich checks.
+type blockTx struct {
+	signature string
+	signer    string
+	writable  map[string]bool
+}
+
+// sandwichInfo describes the attacker found around a tracked wallet's swap.
+type sandwichInfo struct {
+	Attacker   string
+	FrontRun   string
+	BackRun    string
+	SharedPool string
+}
+
+// SandwichNote checks whether res, a swap, was sandwiched and returns the
+// alert note saying so, or "" when it wasn't. It is not part of Analyze:
+// getBlock is slow and often not ready yet, so callers run it after the
+// alert is out.
+func (a *Analyzer) SandwichNote(ctx context.Context, res *Result) (string, error) {
+	var err error
+	for attempt := 0; attempt < sandwichAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(sandwichRetry):
+			}
+		}
+		var s *sandwichInfo
+		if s, err = detectSandwich(ctx, res.Slot, res.Signature, res.Wallet, a.rpc()); err != nil {
+			continue
+		}
+		if s == nil {
+			return "", nil
+		}
+		return fmt.Sprintf("🥪 <b>Possible sandwich</b> by <code>%s</code>", EscapeHTML(shortenAddress(s.Attacker))), nil
+	}
+	return "", err
+}
+
+// detectSandwich looks at block slot and reports whether the swap signature
+// was bracketed by two transactions from the same signer that write to the
+// same pool account. Returns (nil, nil) when no sandwich pattern is found.
+func detectSandwich(ctx context.Context, slot uint64, signature, trackedAddr string, rpc *solanarpc.Client) (*sandwichInfo, error) {
+	if slot == 0 {
+		return nil, errors.New("transaction has no slot")
+	}
+
+	var block *getBlockResult
+	params := []any{
+		slot,
+		map[string]any{
+			"encoding":                       "json",
+			"transactionDetails":             "accounts",
+			"maxSupportedTransactionVersion": 0,
+			"rewards":                        false,
+			"commitment":                     "confirmed",
+		},
+	}
+	if err := rpc.Call(ctx, "getBlock", params, &block); err != nil {
+		return nil, fmt.Errorf("getBlock %d failed: %w", slot, err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not available", slot)
+	}
+
+	txs := make([]blockTx, 0, len(block.Transactions))
+	victim := -1
+	for _, raw := range block.Transactions {
+		if raw.Meta != nil && raw.Meta.Err != nil {
+			continue // failed txs can't move the price
+		}
+		if len(raw.Transaction.Signatures) == 0 {
+			continue
+		}
+		bt := blockTx{signature: raw.Transaction.Signatures[0], writable: make(map[string]bool)}
+		for _, k := range raw.Transaction.AccountKeys {
+			if k.Signer && bt.signer == "" {
+				bt.signer = k.Pubkey
+			}
+			if k.Writable && !k.Signer && !sandwichIgnoredAccounts[k.Pubkey] {
+				bt.writable[k.Pubkey] = true
+			}
+		}
+		if bt.signature == signature {
+			victim = len(txs)
+		}
+		txs = append(txs, bt)
+	}
+	if victim == -1 {
+		return nil, fmt.Errorf("signature not found in block %d", slot)
+	}
+
+	v := txs[victim]
+	for i := victim - 1; i >= 0 && i >= victim-sandwichWindow; i-- {
+		front := txs[i]
+		if front.signer == "" || front.signer == trackedAddr || front.signer == v.signer {
+			continue
+		}
+		for j := victim + 1; j < len(txs) && j <= victim+sandwichWindow; j++ {
+			back := txs[j]
+			if back.signer != front.signer {
+				continue
+			}
+			for acc := range v.writable {
+				if front.writable[acc] && back.writable[acc] {
+					return &sandwichInfo{
+						Attacker:   front.signer,
+						FrontRun:   front.signature,
+						BackRun:    back.signature,
+						SharedPool: acc,
+					}, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+// mintAccount is what a metadata lookup needs from a mint's accounts. None
+// of it changes once the mint exists, so it is cached in memory and, with
+// MintAccounts set, in the store: a token seen before costs no mint
+// getAccountInfo call after a restart or after its metadata expired.
+type mintAccount struct {
+	Owner    string    `json:"owner"` // token program
+	Decimals int       `json:"decimals"`
+	PDA      string    `json:"pda,omitempty"` // Metaplex metadata account (metadataPDA); "" = not derived yet
+	SeenAt   time.Time `json:"-"`             // for PruneCaches
+}
+
+// MintAccountKeyPrefix starts the settings keys of stored mint accounts,
+// for retention.
+const MintAccountKeyPrefix = "mintacct:"
+
+// mintAccountKey is the settings key holding mint's cached mintAccount.
+func mintAccountKey(mint string) string { return MintAccountKeyPrefix + mint }
+
+// mintAccount returns mint's owner, decimals and known metadata address
+// from the cache, the store or, failing both, the RPC.
+func (a *Analyzer) mintAccount(ctx context.Context, mint string, rpc *solanarpc.Client) (mintAccount, error) {
+	if v, ok := a.mintAccounts.Load(mint); ok {
+		metrics.Inc("analyzer.mint_account.cached")
+		return v.(mintAccount), nil
+	}
+	if a.MintAccounts != nil {
+		raw, found, err := a.MintAccounts.GetSetting(ctx, mintAccountKey(mint))
+		if err != nil {
+			log.Printf("[analyzer] mint account lookup %s: %v", mint, err)
+		} else if found {
+			var acc mintAccount
+			if err := json.Unmarshal([]byte(raw), &acc); err == nil && acc.Owner != "" {
+				metrics.Inc("analyzer.mint_account.stored")
+				acc.SeenAt = time.Now()
+				a.mintAccounts.Store(mint, acc)
+				return acc, nil
+			}
+		}
+	}
+	acc, err := fetchMintAccount(ctx, mint, rpc)
+	if err != nil {
+		return mintAccount{}, err
+	}
+	metrics.Inc("analyzer.mint_account.fetched")
+	a.saveMintAccount(ctx, mint, acc)
+	return acc, nil
+}
+
+// saveMintAccount caches acc for mint in memory and, if configured, in the
+// store.
+func (a *Analyzer) saveMintAccount(ctx context.Context, mint string, acc mintAccount) {
+	acc.SeenAt = time.Now()
+	a.mintAccounts.Store(mint, acc)
+	if a.MintAccounts == nil {
+		return
+	}
+	raw, err := json.Marshal(acc)
+	if err != nil {
+		return
+	}
+	if err := a.MintAccounts.SetSetting(ctx, mintAccountKey(mint), string(raw)); err != nil {
+		log.Printf("[analyzer] mint account save %s: %v", mint, err)
+	}
+}
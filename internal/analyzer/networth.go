@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+// SOLBalance returns the wallet's native SOL balance and its USD value. The
+// USD value is 0 when the price oracle is unavailable.
+func (a *Analyzer) SOLBalance(ctx context.Context, addr string) (sol, usd float64, err error) {
+	rpc := a.rpc()
+	rpc.Commitment = "confirmed"
+	lamports, err := rpc.GetBalance(ctx, addr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("getBalance: %w", err)
+	}
+	sol = float64(lamports) / lamportsPerSol
+	if price, ok := a.priceOracle.GetPriceUSD(ctx, "solana"); ok {
+		usd = sol * price
+	}
+	return sol, usd, nil
+}
+
+// TokenHolding is one non-empty token account of a wallet.
+type TokenHolding struct {
+	Mint   string
+	Symbol string // from the metadata cache, empty when the mint is unknown
+	Amount float64
+}
+
+// Holdings lists the wallet's non-empty SPL Token and Token-2022 accounts,
+// largest amount first.
+func (a *Analyzer) Holdings(ctx context.Context, addr string) ([]TokenHolding, error) {
+	rpc := a.rpc()
+	rpc.Commitment = "confirmed"
+	var out []TokenHolding
+	for _, program := range []string{solanarpc.TokenProgramID, solanarpc.Token2022ProgramID} {
+		accounts, err := rpc.GetTokenAccountsByOwner(ctx, addr, program)
+		if err != nil {
+			return nil, fmt.Errorf("getTokenAccountsByOwner: %w", err)
+		}
+		for _, acc := range accounts {
+			if acc.UIAmount <= 0 {
+				continue
+			}
+			h := TokenHolding{Mint: acc.Mint, Amount: acc.UIAmount}
+			if meta, ok := a.CachedMetadata(acc.Mint); ok {
+				h.Symbol = meta.Symbol
+			}
+			out = append(out, h)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Amount > out[j].Amount })
+	return out, nil
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// compactFrom is where compact notation ("1.25B") starts when enabled.
+const compactFrom = 1e6
+
+// NumberFormat controls how amounts are rendered in summaries.
+type NumberFormat struct {
+	Decimal   string // decimal mark, "." or ","
+	Thousands string // group separator; empty disables grouping
+	SigDigits int    // significant digits below 1 (default 3)
+	Compact   bool   // 1,250,000,000 -> 1.25B
+	// CompactAbove compacts amounts at or above it even without Compact;
+	// 0 disables. Summaries list the exact values of compacted amounts.
+	CompactAbove float64
+}
+
+// DefaultNumberFormat is the historical "1,234.56" style.
+var DefaultNumberFormat = NumberFormat{Decimal: ".", Thousands: ",", SigDigits: 3}
+
+// NumberLocales are the named separator styles users can pick.
+var NumberLocales = map[string]NumberFormat{
+	"en":    {Decimal: ".", Thousands: ","},
+	"de":    {Decimal: ",", Thousands: "."},
+	"fr":    {Decimal: ",", Thousands: "\u202f"}, // narrow no-break space
+	"ch":    {Decimal: ".", Thousands: "'"},
+	"plain": {Decimal: "."},
+}
+
+// compacts reports whether v is rendered in compact notation.
+func (nf NumberFormat) compacts(v float64) bool {
+	v = math.Abs(v)
+	return (nf.Compact && v >= compactFrom) || (nf.CompactAbove > 0 && v >= nf.CompactAbove)
+}
+
+// Exact formats v with every significant decimal and the format's separators.
+func (nf NumberFormat) Exact(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	_, frac, _ := strings.Cut(s, ".")
+	return nf.Fixed(v, len(frac))
+}
+
+// Fixed formats v with prec decimals and the format's separators.
+func (nf NumberFormat) Fixed(v float64, prec int) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+	out := sign + groupThousands(intPart, nf.Thousands)
+	if frac != "" {
+		dec := nf.Decimal
+		if dec == "" {
+			dec = "."
+		}
+		out += dec + frac
+	}
+	return out
+}
+
+// compact renders v (>= 1000) with a K/M/B/T suffix and three significant
+// digits: 12.4M, 125M, 1.25B.
+func (nf NumberFormat) compact(v float64) string {
+	units := []string{"", "K", "M", "B", "T"}
+	i := 0
+	for v >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
+	}
+	prec := 2
+	switch {
+	case v >= 100:
+		prec = 0
+	case v >= 10:
+		prec = 1
+	}
+	// Rounding can carry into the next unit (999.96K -> 1000K).
+	if r := math.Pow10(-prec); v+r/2 >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
+		prec = 2
+	}
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	dec := nf.Decimal
+	if dec == "" {
+		dec = "."
+	}
+	return strings.Replace(s, ".", dec, 1) + units[i]
+}
+
+func groupThousands(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/mr-tron/base58"
+)
+
+// Program derived addresses are computed the way the runtime does it: the
+// SHA-256 of the seeds, a bump byte, the program id and a fixed marker,
+// trying bumps from 255 down until the hash is not an ed25519 point.
+
+// errNoPDA means no bump yields an off-curve address (practically never).
+var errNoPDA = errors.New("no viable bump seed")
+
+// metadataPDA returns mint's Metaplex metadata account, the PDA of
+// ["metadata", program id, mint] under the metadata program.
+func metadataPDA(mint string) (string, error) {
+	program, err := decodePubkey(metaplexMetadataProgramID)
+	if err != nil {
+		return "", err
+	}
+	m, err := decodePubkey(mint)
+	if err != nil {
+		return "", fmt.Errorf("mint %s: %w", mint, err)
+	}
+	return findProgramAddress([][]byte{[]byte("metadata"), program, m}, program)
+}
+
+// findProgramAddress returns the first off-curve address for seeds under
+// program, base58-encoded.
+func findProgramAddress(seeds [][]byte, program []byte) (string, error) {
+	for bump := 255; bump >= 0; bump-- {
+		h := sha256.New()
+		for _, s := range seeds {
+			h.Write(s)
+		}
+		h.Write([]byte{byte(bump)})
+		h.Write(program)
+		h.Write([]byte("ProgramDerivedAddress"))
+		addr := h.Sum(nil)
+		if !onCurve(addr) {
+			return base58.Encode(addr), nil
+		}
+	}
+	return "", errNoPDA
+}
+
+// decodePubkey decodes a base58 public key.
+func decodePubkey(s string) ([]byte, error) {
+	b, err := base58.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 32 {
+		return nil, fmt.Errorf("public key is %d bytes, want 32", len(b))
+	}
+	return b, nil
+}
+
+// Curve constants for onCurve: the field prime 2^255-19 and the twisted
+// Edwards d = -121665/121666.
+var (
+	curveP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	curveD = func() *big.Int {
+		d := new(big.Int).ModInverse(big.NewInt(121666), curveP)
+		d.Mul(d, big.NewInt(-121665))
+		return d.Mod(d, curveP)
+	}()
+	curveHalf = new(big.Int).Rsh(new(big.Int).Sub(curveP, big.NewInt(1)), 1) // (p-1)/2
+)
+
+// onCurve reports whether the 32 bytes decompress to an ed25519 point:
+// with y the little-endian value (sign bit dropped), x² = (y²-1)/(dy²+1)
+// must be a square mod p.
+func onCurve(b []byte) bool {
+	le := make([]byte, 32)
+	for i := range 32 {
+		le[31-i] = b[i]
+	}
+	le[0] &= 0x7f
+	y := new(big.Int).SetBytes(le)
+	y.Mod(y, curveP)
+
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, curveP)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	v := new(big.Int).Mul(curveD, y2)
+	v.Add(v, big.NewInt(1))
+	v.Mod(v, curveP)
+	x2 := u.Mul(u, v.ModInverse(v, curveP))
+	x2.Mod(x2, curveP)
+	if x2.Sign() == 0 {
+		return true
+	}
+	// Euler's criterion.
+	return new(big.Int).Exp(x2, curveHalf, curveP).Cmp(big.NewInt(1)) == 0
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestMetadataPDA(t *testing.T) {
+	tests := []struct {
+		mint string
+		want string
+	}{
+		{"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "5x38Kp4hvdomTCnCrAny4UtMUt5rQBdB6px2K1Ui45Wq"}, // USDC
+		{"DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263", "FDZZbyY9XGpL3CNKUZxLk3wFTTQYL3TkDiDzqxrizcPN"}, // BONK
+		{"So11111111111111111111111111111111111111112", "6dM4TqWyWJsbx7obrdLcviBkTafD5E8av61zfU6jq57X"},  // wrapped SOL
+	}
+	for _, tt := range tests {
+		got, err := metadataPDA(tt.mint)
+		if err != nil {
+			t.Fatalf("metadataPDA(%s): %v", tt.mint, err)
+		}
+		if got != tt.want {
+			t.Errorf("metadataPDA(%s) = %s, want %s", tt.mint, got, tt.want)
+		}
+		b, _ := decodePubkey(got)
+		if onCurve(b) {
+			t.Errorf("PDA %s is on the curve", got)
+		}
+	}
+	if _, err := metadataPDA("not-a-mint"); err == nil {
+		t.Error("invalid mint accepted")
+	}
+}
+
+func TestOnCurve(t *testing.T) {
+	// Every ed25519 public key is a curve point.
+	for range 200 {
+		pub, _, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !onCurve(pub) {
+			t.Fatalf("ed25519 public key %x reported off curve", []byte(pub))
+		}
+	}
+	// The identity (0, 1) takes the x² = 0 branch.
+	identity := make([]byte, 32)
+	identity[0] = 1
+	if !onCurve(identity) {
+		t.Error("identity point reported off curve")
+	}
+	// y = 2 gives a non-square x², so no point.
+	two := make([]byte, 32)
+	two[0] = 2
+	if onCurve(two) {
+		t.Error("y = 2 reported on curve")
+	}
+	// The sign bit doesn't change whether y is on the curve.
+	signed := append([]byte(nil), identity...)
+	signed[31] |= 0x80
+	if !onCurve(signed) {
+		t.Error("identity with the sign bit set reported off curve")
+	}
+}
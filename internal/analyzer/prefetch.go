@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+)
+
+// The analysis stages depend on each other (metadata and prices need the
+// transaction's mints), but the lookups behind them don't: prefetch warms
+// the price oracle and market cache in the background, so by the time the
+// amounts are computed those lookups are cache hits and an alert takes about
+// as long as its slowest call rather than the sum of them.
+
+// prefetchSOL looks up the SOL price, which nearly every alert needs, while
+// the transaction is still being fetched. It has a prices budget of its own.
+func (a *Analyzer) prefetchSOL(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Go(func() {
+		ctx, done := a.stage(ctx, "prices")
+		defer done()
+		a.priceOracle.GetPriceUSD(ctx, "solana")
+	})
+}
+
+// prefetch starts the price and market lookups for the tokens trackedAddr
+// moved in tx; they run alongside the metadata stage.
+func (a *Analyzer) prefetch(ctx context.Context, wg *sync.WaitGroup, tx *HeliusTransaction, trackedAddr string) {
+	market := a.MarketData && (tx.Type == "SWAP" || tx.Type == "CREATE")
+	seen := make(map[string]bool)
+	for _, mint := range walletMints(tx, trackedAddr) {
+		if seen[mint] {
+			continue
+		}
+		seen[mint] = true
+		if coinID, ok := isPriceTracked(mint); ok {
+			if coinID != "solana" { // already under way
+				wg.Go(func() { a.priceOracle.GetPriceUSD(ctx, coinID) })
+			}
+		} else if market {
+			wg.Go(func() { _, _ = a.tokenMarket(ctx, mint) })
+		}
+	}
+}
+
+// walletMints lists the mints trackedAddr sent or received in tx, with
+// repeats; route hops between pools are left out.
+func walletMints(tx *HeliusTransaction, trackedAddr string) []string {
+	var out []string
+	for _, t := range tx.TokenTransfers {
+		if t.Mint != "" && (t.FromUserAccount == trackedAddr || t.ToUserAccount == trackedAddr) {
+			out = append(out, t.Mint)
+		}
+	}
+	if sw := tx.Events.Swap; sw != nil {
+		for _, item := range sw.TokenInputs {
+			out = append(out, item.Mint)
+		}
+		for _, item := range sw.TokenOutputs {
+			out = append(out, item.Mint)
+		}
+	}
+	return out
+}
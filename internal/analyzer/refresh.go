@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CachedMetadata returns the cached metadata for mint, if any.
+func (a *Analyzer) CachedMetadata(mint string) (TokenMetadata, bool) {
+	v, ok := a.metadataCache.Load(mint)
+	if !ok {
+		return TokenMetadata{}, false
+	}
+	return v.(TokenMetadata), true
+}
+
+// RefreshMetadata looks mint up again, bypassing the cache, and stores the
+// result. Built-in entries (SOL, USDC) are returned unchanged. On failure
+// the cached entry is left as it was.
+func (a *Analyzer) RefreshMetadata(ctx context.Context, mint string) (TokenMetadata, error) {
+	if old, ok := a.CachedMetadata(mint); ok && old.FetchedAt.IsZero() {
+		return old, nil
+	}
+	found, limited := a.fetchMetadataBatch(ctx, []string{mint})
+	meta, ok := found[mint]
+	switch {
+	case limited[mint]:
+		return TokenMetadata{}, fmt.Errorf("metadata lookup for %s: %w", mint, ErrUpstreamRateLimited)
+	case !ok:
+		return TokenMetadata{}, fmt.Errorf("no metadata found for %s", mint)
+	}
+	meta.FetchedAt = time.Now()
+	a.metadataCache.Store(mint, meta)
+	return meta, nil
+}
+
+// RefreshFailed retries every cached placeholder from a failed lookup
+// (shown as "Mint(...)") and returns how many now resolve.
+func (a *Analyzer) RefreshFailed(ctx context.Context) (fixed int) {
+	var failed []string
+	a.metadataCache.Range(func(k, v any) bool {
+		if v.(TokenMetadata).Failed {
+			failed = append(failed, k.(string))
+		}
+		return true
+	})
+	if len(failed) == 0 {
+		return 0
+	}
+	found, _ := a.fetchMetadataBatch(ctx, failed)
+	now := time.Now()
+	for _, mint := range failed {
+		if meta, ok := found[mint]; ok {
+			meta.FetchedAt = now
+			a.metadataCache.Store(mint, meta)
+			fixed++
+			continue
+		}
+		// Still unknown: restart its negative-cache period.
+		if v, ok := a.metadataCache.Load(mint); ok {
+			m := v.(TokenMetadata)
+			m.FetchedAt = now
+			a.metadataCache.Store(mint, m)
+		}
+	}
+	log.Printf("[analyzer] metadata refresh: %d of %d fallback entries resolved", fixed, len(failed))
+	return fixed
+}
+
+// RunMetadataRefresh calls RefreshFailed every interval until ctx is done,
+// so tokens whose metadata appeared after launch display correctly.
+func (a *Analyzer) RunMetadataRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.RefreshFailed(ctx)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"strconv"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+// closeAccountData is the base58 data of an SPL Token / Token-2022
+// CloseAccount instruction: the single discriminator byte 9.
+const closeAccountData = "A"
+
+// rentFlows is the rent the tracked wallet paid for opening token accounts
+// and got back from closing them in one transaction.
+//
+// Token accounts only change lamports when they are created (rent deposit)
+// or closed (rent refund), so their nativeBalanceChange in accountData is
+// exactly the rent moved. WSOL accounts also carry the wrapped balance in
+// their lamports; its change is taken off first, leaving the rent (see
+// wsolFlows for the balance itself).
+type rentFlows struct {
+	Paid     float64 // SOL deposited into newly created accounts (positive)
+	Refunded float64 // SOL returned to the wallet from closed accounts (positive)
+	Opened   int
+	Closed   int
+}
+
+// Net is the rent impact on the wallet's SOL balance (negative when paying).
+func (r rentFlows) Net() float64 { return r.Refunded - r.Paid }
+
+// collectRent finds rent deposits and refunds for trackedAddr's token accounts.
+func collectRent(tx *HeliusTransaction, trackedAddr string) rentFlows {
+	accounts := make(map[string]bool)
+	wrapped := make(map[string]int64) // WSOL account -> balance change, lamports
+	for _, ad := range tx.AccountData {
+		for _, tb := range ad.TokenBalanceChanges {
+			if tb.UserAccount != trackedAddr || tb.TokenAccount == "" {
+				continue
+			}
+			accounts[tb.TokenAccount] = true
+			if tb.Mint == wsolMint {
+				v, _ := strconv.ParseInt(tb.RawTokenAmount.TokenAmount, 10, 64)
+				wrapped[tb.TokenAccount] += v
+			}
+		}
+	}
+	for _, tt := range tx.TokenTransfers {
+		if tt.FromUserAccount == trackedAddr && tt.FromTokenAccount != "" {
+			accounts[tt.FromTokenAccount] = true
+		}
+		if tt.ToUserAccount == trackedAddr && tt.ToTokenAccount != "" {
+			accounts[tt.ToTokenAccount] = true
+		}
+	}
+
+	// Only count refunds paid out to the wallet; a close can send the rent
+	// to any destination.
+	closedTo := closeDestinations(tx.Instructions)
+
+	// Only count deposits the wallet itself funded; senders often pay to
+	// create the recipient's ATA.
+	funded := make(map[string]bool)
+	for _, nt := range tx.NativeTransfers {
+		if nt.FromUserAccount == trackedAddr && accounts[nt.ToUserAccount] {
+			funded[nt.ToUserAccount] = true
+		}
+	}
+
+	var r rentFlows
+	for _, ad := range tx.AccountData {
+		lamports := ad.NativeBalanceChange - wrapped[ad.Account]
+		if !accounts[ad.Account] || lamports == 0 {
+			continue
+		}
+		sol := float64(lamports) / lamportsPerSol
+		if sol > 0 {
+			if !funded[ad.Account] {
+				continue
+			}
+			r.Paid += sol
+			r.Opened++
+		} else {
+			if closedTo[ad.Account] != trackedAddr {
+				continue
+			}
+			r.Refunded += -sol
+			r.Closed++
+		}
+	}
+	return r
+}
+
+// closeDestinations maps each token account closed by ixs (inner calls
+// included) to the account its rent was sent to.
+func closeDestinations(ixs []Instruction) map[string]string {
+	out := make(map[string]string)
+	var walk func([]Instruction)
+	walk = func(list []Instruction) {
+		for _, ix := range list {
+			isToken := ix.ProgramID == solanarpc.TokenProgramID || ix.ProgramID == solanarpc.Token2022ProgramID
+			if isToken && ix.Data == closeAccountData && len(ix.Accounts) >= 2 {
+				out[ix.Accounts[0]] = ix.Accounts[1]
+			}
+			walk(ix.InnerInstructions)
+		}
+	}
+	walk(ixs)
+	return out
+}
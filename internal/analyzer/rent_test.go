@@ -0,0 +1,176 @@
+package analyzer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	rentWallet  = "Wa11et1111111111111111111111111111111111111"
+	rentAccount = "ATA1111111111111111111111111111111111111111"
+	rentOther   = "0ther111111111111111111111111111111111111111"
+	rentMint    = "Mint111111111111111111111111111111111111111"
+	rentLamps   = 2_039_280
+)
+
+func rentBalance(account string, lamports int64) AccountData {
+	return AccountData{
+		Account:             account,
+		NativeBalanceChange: lamports,
+		TokenBalanceChanges: []TokenBalanceChange{{
+			UserAccount:    rentWallet,
+			TokenAccount:   rentAccount,
+			Mint:           rentMint,
+			RawTokenAmount: RawTokenAmount{TokenAmount: "100", Decimals: 6},
+		}},
+	}
+}
+
+func closeIx(dest string) Instruction {
+	return Instruction{ProgramID: solanarpc.TokenProgramID, Data: closeAccountData, Accounts: []string{rentAccount, dest, rentWallet}}
+}
+
+// systemIxData encodes a System Program instruction with a u32
+// discriminator, lamports and optional trailing bytes.
+func systemIxData(disc uint32, lamports uint64, tail int) string {
+	raw := make([]byte, 12+tail)
+	binary.LittleEndian.PutUint32(raw, disc)
+	binary.LittleEndian.PutUint64(raw[4:], lamports)
+	return base58.Encode(raw)
+}
+
+func TestCollectRent(t *testing.T) {
+	tests := []struct {
+		name     string
+		tx       HeliusTransaction
+		paid     float64
+		refunded float64
+	}{
+		{
+			name: "wallet creates its account",
+			tx: HeliusTransaction{
+				AccountData:     []AccountData{rentBalance(rentAccount, rentLamps)},
+				NativeTransfers: []NativeTransfer{{FromUserAccount: rentWallet, ToUserAccount: rentAccount, Amount: rentLamps}},
+			},
+			paid: 0.00203928,
+		},
+		{
+			name: "sender creates the wallet's account",
+			tx: HeliusTransaction{
+				AccountData:     []AccountData{rentBalance(rentAccount, rentLamps)},
+				NativeTransfers: []NativeTransfer{{FromUserAccount: rentOther, ToUserAccount: rentAccount, Amount: rentLamps}},
+			},
+		},
+		{
+			name: "close to the wallet",
+			tx: HeliusTransaction{
+				AccountData:  []AccountData{rentBalance(rentAccount, -rentLamps)},
+				Instructions: []Instruction{closeIx(rentWallet)},
+			},
+			refunded: 0.00203928,
+		},
+		{
+			name: "close to another account",
+			tx: HeliusTransaction{
+				AccountData:  []AccountData{rentBalance(rentAccount, -rentLamps)},
+				Instructions: []Instruction{{ProgramID: "Router11111111111111111111111111111111111111", InnerInstructions: []Instruction{closeIx(rentOther)}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := collectRent(&tt.tx, rentWallet)
+			if math.Abs(r.Paid-tt.paid) > 1e-12 || math.Abs(r.Refunded-tt.refunded) > 1e-12 {
+				t.Errorf("paid %.9f refunded %.9f, want %.9f and %.9f", r.Paid, r.Refunded, tt.paid, tt.refunded)
+			}
+		})
+	}
+}
+
+// TestCollectRentFallback runs an ATA creation through the getTransaction
+// fallback: the deposit is only known to be the wallet's from the inner
+// createAccount instruction.
+func TestCollectRentFallback(t *testing.T) {
+	const ataProgram = "ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"
+	tests := []struct {
+		name  string
+		payer int // key index funding the account
+		paid  float64
+	}{
+		{name: "wallet pays", payer: 0, paid: 0.00203928},
+		{name: "other payer", payer: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			walletPost, otherPost := int64(10_000_000_000-5_000), int64(10_000_000_000)
+			if tt.payer == 0 {
+				walletPost -= rentLamps
+			} else {
+				otherPost -= rentLamps
+			}
+			raw := fmt.Sprintf(`{
+				"slot": 1,
+				"meta": {
+					"fee": 5000,
+					"preBalances":  [10000000000, 0, 1, 1, 1, 1, 10000000000],
+					"postBalances": [%d, %d, 1, 1, 1, 1, %d],
+					"preTokenBalances": [],
+					"postTokenBalances": [{"accountIndex": 1, "mint": %q, "owner": %q, "uiTokenAmount": {"amount": "100", "decimals": 6}}],
+					"innerInstructions": [{"index": 0, "instructions": [
+						{"programIdIndex": 3, "accounts": [%d, 1], "data": %q}
+					]}]
+				},
+				"transaction": {"message": {
+					"accountKeys": [%q, %q, %q, %q, %q, %q, %q],
+					"instructions": [{"programIdIndex": 5, "accounts": [%d, 1, 0, 2, 3, 4], "data": ""}]
+				}}
+			}`,
+				walletPost, rentLamps, otherPost,
+				rentMint, rentWallet,
+				tt.payer, systemIxData(systemCreateAccount, rentLamps, 40),
+				rentWallet, rentAccount, rentMint, solanarpc.SystemProgramID, solanarpc.TokenProgramID, ataProgram, rentOther,
+				tt.payer,
+			)
+			var r solanarpc.Transaction
+			if err := json.Unmarshal([]byte(raw), &r); err != nil {
+				t.Fatal(err)
+			}
+			tx, err := convertRPCTransaction("sig", &r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := collectRent(tx, rentWallet).Paid; math.Abs(got-tt.paid) > 1e-12 {
+				t.Errorf("paid %.9f, want %.9f", got, tt.paid)
+			}
+		})
+	}
+}
+
+func TestSystemLamports(t *testing.T) {
+	seed := make([]byte, 4+32+8+5+8+8+32)
+	binary.LittleEndian.PutUint32(seed, systemCreateAccountWithSeed)
+	binary.LittleEndian.PutUint64(seed[36:], 5)
+	binary.LittleEndian.PutUint64(seed[49:], 777)
+	tests := []struct {
+		data string
+		want int64
+		ok   bool
+	}{
+		{systemIxData(systemTransfer, 42, 0), 42, true},
+		{systemIxData(systemCreateAccount, rentLamps, 40), rentLamps, true},
+		{base58.Encode(seed), 777, true},
+		{systemIxData(8, 1, 0), 0, false}, // allocate
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := systemLamports(tt.data); got != tt.want || ok != tt.ok {
+			t.Errorf("systemLamports(%q) = %d, %t; want %d, %t", tt.data, got, ok, tt.want, tt.ok)
+		}
+	}
+}
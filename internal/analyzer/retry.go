@@ -0,0 +1,113 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// Failed upstream calls fall into classes that decide whether they are
+// retried: rate limits (429), server errors (5xx) and network errors are
+// transient and retried with jittered backoff; client errors (other
+// non-200 answers) are permanent and returned at once. Every attempt is
+// counted as upstream.<name>.<class>.
+const (
+	classOK          = "ok"
+	classRateLimited = "rate_limited"
+	classServer      = "server"
+	classNetwork     = "network"
+	classClient      = "client"
+)
+
+const (
+	// transientAttempts is how often postJSON tries a call that keeps
+	// failing transiently, unless the client's transport already retries
+	// (ClientConfig.Retries).
+	transientAttempts = 3
+	// maxRetryAfter caps how long a 429's Retry-After can hold a call.
+	maxRetryAfter = 10 * time.Second
+)
+
+// errorClass classifies the outcome of one HTTP attempt.
+func errorClass(resp *http.Response, err error) string {
+	switch {
+	case err != nil:
+		return classNetwork
+	case resp.StatusCode == http.StatusOK:
+		return classOK
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return classRateLimited
+	case resp.StatusCode >= 500:
+		return classServer
+	default:
+		return classClient
+	}
+}
+
+// postJSON POSTs body to url and returns the 200 response, retrying
+// transient failures. upstream names the service in metrics and errors.
+func postJSON(ctx context.Context, client *http.Client, upstream, url string, body []byte) (*http.Response, error) {
+	return postJSONAttempts(ctx, client, upstream, url, body, transientAttempts)
+}
+
+// postJSONAttempts is postJSON with at most attempts tries.
+func postJSONAttempts(ctx context.Context, client *http.Client, upstream, url string, body []byte, attempts int) (*http.Response, error) {
+	if _, ok := client.Transport.(*retryTransport); ok {
+		attempts = 1
+	}
+	bo := util.NewBackoff(250*time.Millisecond, 4*time.Second, 2.0, 0.3)
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		class := errorClass(resp, err)
+		metrics.Inc("upstream." + upstream + "." + class)
+		if class == classOK {
+			return resp, nil
+		}
+
+		wait := bo.Next()
+		if resp != nil {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			resp.Body.Close()
+			err = fmt.Errorf("status %d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+			if d := retryAfter(resp); d > 0 {
+				wait = min(d, maxRetryAfter)
+			}
+		}
+		if class == classClient || attempt >= attempts || ctx.Err() != nil {
+			return nil, &UpstreamError{Upstream: upstream, Class: class, Attempts: attempt, Err: err}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryAfter reads a Retry-After header in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
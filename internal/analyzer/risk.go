@@ -0,0 +1,236 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// jupiterQuoteURL answers "what would selling this get?"; no route means
+	// no DEX will take the token.
+	jupiterQuoteURL = "https://lite-api.jup.ag/swap/v1/quote"
+	// riskCacheTTL is how long a per-mint verdict is reused.
+	riskCacheTTL = 10 * time.Minute
+)
+
+// sellVerdict is the cached outcome of a sell-route check.
+type sellVerdict struct {
+	NoRoute   bool
+	Reason    string
+	CheckedAt time.Time
+}
+
+// annotateRisk adds risk notes for tokens a wallet just bought. It only
+// looks at non-SOL/USDC tokens received in exchange for something.
+func (a *Analyzer) annotateRisk(ctx context.Context, res *Result, meta map[string]TokenMetadata) {
+	for _, amt := range res.Bought() {
+		if a.HolderConcentration > 0 {
+			share, err := a.topHolderShare(ctx, amt.Mint)
+			if err != nil {
+				log.Printf("[analyzer] holder concentration for %s: %v", amt.Mint, err)
+			} else if share*100 >= a.HolderConcentration {
+				res.Notes = append(res.Notes, fmt.Sprintf("⚠️ <b>Top 10 holders own %.0f%%</b> of %s", share*100, EscapeHTML(amt.Symbol)))
+			}
+		}
+		if a.CheckSellRoute {
+			v, err := a.sellRoute(ctx, amt, meta[amt.Mint].Decimals)
+			if err != nil {
+				log.Printf("[analyzer] sell route check for %s: %v", amt.Mint, err)
+			} else if v.NoRoute {
+				res.Notes = append(res.Notes, fmt.Sprintf("⚠️ <b>No sell route found</b> for %s (%s)", EscapeHTML(amt.Symbol), EscapeHTML(v.Reason)))
+			}
+		}
+	}
+}
+
+// sellRoute asks Jupiter for a quote selling amt back to SOL. Verdicts are
+// cached per mint; transport errors and rate limits return an error rather
+// than a "no route" verdict.
+func (a *Analyzer) sellRoute(ctx context.Context, amt Amount, decimals int) (sellVerdict, error) {
+	if v, ok := a.riskCache.Load(amt.Mint); ok && time.Since(v.(sellVerdict).CheckedAt) < riskCacheTTL {
+		return v.(sellVerdict), nil
+	}
+
+	raw := amt.Amount * math.Pow10(decimals)
+	if raw < 1 {
+		raw = 1
+	}
+	q := url.Values{}
+	q.Set("inputMint", amt.Mint)
+	q.Set("outputMint", wsolMint)
+	q.Set("amount", strconv.FormatFloat(math.Floor(raw), 'f', 0, 64))
+	q.Set("slippageBps", "5000")
+	req, err := http.NewRequestWithContext(ctx, "GET", jupiterQuoteURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return sellVerdict{}, err
+	}
+	resp, err := a.priceOracle.httpClient.Do(req)
+	if err != nil {
+		return sellVerdict{}, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OutAmount string `json:"outAmount"`
+		Error     string `json:"error"`
+		ErrorCode string `json:"errorCode"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+
+	v := sellVerdict{CheckedAt: time.Now()}
+	switch {
+	case resp.StatusCode == http.StatusOK && body.OutAmount != "" && body.OutAmount != "0":
+	case resp.StatusCode == http.StatusOK:
+		v.NoRoute, v.Reason = true, "quote returns nothing"
+	case body.ErrorCode == "COULD_NOT_FIND_ANY_ROUTE" || body.ErrorCode == "NO_ROUTES_FOUND":
+		v.NoRoute, v.Reason = true, "no DEX route"
+	case body.ErrorCode == "TOKEN_NOT_TRADABLE":
+		v.NoRoute, v.Reason = true, "not tradable"
+	default:
+		return sellVerdict{}, fmt.Errorf("jupiter quote: status %d %s", resp.StatusCode, body.ErrorCode)
+	}
+	a.riskCache.Store(amt.Mint, v)
+	return v, nil
+}
+
+// holderShare is the cached top-10 share of a mint's supply.
+type holderShare struct {
+	Share     float64
+	CheckedAt time.Time
+}
+
+// poolPrograms are AMM and bonding-curve programs whose pool accounts own
+// the token vaults; poolAuthorities are the PDAs that own vaults directly
+// for programs that use one authority for every pool.
+var (
+	poolPrograms = map[string]bool{
+		"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8": true, // Raydium AMM v4
+		"CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK": true, // Raydium CLMM
+		"CPMMoo8L3F4NbTegBCKVNunggL7H1ZpdTHKxQB5qKP1C": true, // Raydium CPMM
+		"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc":  true, // Orca Whirlpools
+		"LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo":  true, // Meteora DLMM
+		"Eo7WjKq67rjJQSZxS6z3YkapzY3eMj6Xy8X5EQVn5UaB": true, // Meteora pools
+		"6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P":  true, // pump.fun bonding curve
+		"pAMMBay6oceH9fJKBRHGP5D4bD4sWpmSwMn52FMfXEA":  true, // PumpSwap
+	}
+	poolAuthorities = map[string]bool{
+		"5Q544fKrFoe6tsEbD7S8EmxGTJYAKtTVhAW5Q5pge4j1": true, // Raydium AMM v4
+		"GpMZbSM2GgvTKHJirzeGfMFoaZ8UR2X7F4v8vHTvxFbL": true, // Raydium CPMM
+	}
+)
+
+// topHolderShare returns the fraction of supply held by the 10 largest token
+// accounts that aren't pool vaults or bonding curves: a fresh token keeps
+// most of its supply in its curve or pool, which says nothing about who
+// could dump it.
+func (a *Analyzer) topHolderShare(ctx context.Context, mint string) (float64, error) {
+	if v, ok := a.holderCache.Load(mint); ok && time.Since(v.(holderShare).CheckedAt) < riskCacheTTL {
+		return v.(holderShare).Share, nil
+	}
+
+	var largest struct {
+		Value []struct {
+			Address string `json:"address"`
+			Amount  string `json:"amount"`
+		} `json:"value"`
+	}
+	if err := a.rpc().Call(ctx, "getTokenLargestAccounts", []any{mint}, &largest); err != nil {
+		return 0, err
+	}
+	accounts := make([]string, len(largest.Value))
+	for i, acc := range largest.Value {
+		accounts[i] = acc.Address
+	}
+	pools, err := a.poolVaults(ctx, accounts)
+	if err != nil {
+		return 0, err
+	}
+	var supply struct {
+		Value struct {
+			Amount string `json:"amount"`
+		} `json:"value"`
+	}
+	if err := a.rpc().Call(ctx, "getTokenSupply", []any{mint}, &supply); err != nil {
+		return 0, err
+	}
+
+	total, err := strconv.ParseFloat(supply.Value.Amount, 64)
+	if err != nil || total <= 0 {
+		return 0, fmt.Errorf("unusable supply %q", supply.Value.Amount)
+	}
+	var top float64
+	var counted int
+	for _, acc := range largest.Value {
+		if counted == 10 {
+			break
+		}
+		if pools[acc.Address] {
+			continue
+		}
+		n, _ := strconv.ParseFloat(acc.Amount, 64)
+		top += n
+		counted++
+	}
+	share := math.Min(top/total, 1)
+	a.holderCache.Store(mint, holderShare{Share: share, CheckedAt: time.Now()})
+	return share, nil
+}
+
+// poolVaults returns which of the token accounts belong to a pool or bonding
+// curve: their owner is a known pool authority or an account of a pool
+// program.
+func (a *Analyzer) poolVaults(ctx context.Context, accounts []string) (map[string]bool, error) {
+	var tokens struct {
+		Value []*struct {
+			Data struct {
+				Parsed struct {
+					Info struct {
+						Owner string `json:"owner"`
+					} `json:"info"`
+				} `json:"parsed"`
+			} `json:"data"`
+		} `json:"value"`
+	}
+	params := []any{accounts, map[string]any{"encoding": "jsonParsed"}}
+	if err := a.rpc().Call(ctx, "getMultipleAccounts", params, &tokens); err != nil {
+		return nil, err
+	}
+	pools := make(map[string]bool)
+	var owners, owned []string // owners to look up and their token accounts
+	for i, v := range tokens.Value {
+		if i >= len(accounts) || v == nil || v.Data.Parsed.Info.Owner == "" {
+			continue
+		}
+		if owner := v.Data.Parsed.Info.Owner; poolAuthorities[owner] {
+			pools[accounts[i]] = true
+		} else {
+			owners, owned = append(owners, owner), append(owned, accounts[i])
+		}
+	}
+	if len(owners) == 0 {
+		return pools, nil
+	}
+
+	var programs struct {
+		Value []*struct {
+			Owner string `json:"owner"`
+		} `json:"value"`
+	}
+	params = []any{owners, map[string]any{"encoding": "base64", "dataSlice": map[string]int{"offset": 0, "length": 0}}}
+	if err := a.rpc().Call(ctx, "getMultipleAccounts", params, &programs); err != nil {
+		return nil, err
+	}
+	for i, v := range programs.Value {
+		if i < len(owned) && v != nil && poolPrograms[v.Owner] {
+			pools[owned[i]] = true
+		}
+	}
+	return pools, nil
+}
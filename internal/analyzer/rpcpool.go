@@ -0,0 +1,197 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+// An RPC endpoint that fails transiently (rate limit, 5xx, network) is
+// benched for rpcBenchBase, doubling with every further failure up to
+// rpcBenchMax; one success clears it.
+const (
+	rpcBenchBase = 5 * time.Second
+	rpcBenchMax  = 2 * time.Minute
+)
+
+// rpcEndpoint is one Solana RPC URL of the pool and its recent health.
+type rpcEndpoint struct {
+	url      string
+	weight   int
+	upstream string // rpc.ep<N>, for upstream.* metrics
+
+	fails   int       // consecutive transient failures
+	benched time.Time // skipped until then unless every endpoint is
+}
+
+// rpcPool spreads Solana RPC calls over several endpoints, weighted, and
+// fails a call over to the next endpoint when one is rate-limited or down.
+type rpcPool struct {
+	mu  sync.Mutex
+	eps []*rpcEndpoint
+}
+
+// order returns the endpoints to try for one call: the healthy ones in a
+// weighted random order, then the benched ones, soonest back first.
+func (p *rpcPool) order(now time.Time) []*rpcEndpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	type ranked struct {
+		ep  *rpcEndpoint
+		key float64
+	}
+	var healthy []ranked
+	var benched []*rpcEndpoint
+	for _, ep := range p.eps {
+		if now.Before(ep.benched) {
+			benched = append(benched, ep)
+			continue
+		}
+		// Weighted sampling without replacement: the largest u^(1/w)
+		// goes first.
+		healthy = append(healthy, ranked{ep, math.Pow(rand.Float64(), 1/float64(ep.weight))})
+	}
+	sort.Slice(healthy, func(i, j int) bool { return healthy[i].key > healthy[j].key })
+	sort.Slice(benched, func(i, j int) bool { return benched[i].benched.Before(benched[j].benched) })
+
+	out := make([]*rpcEndpoint, 0, len(p.eps))
+	for _, r := range healthy {
+		out = append(out, r.ep)
+	}
+	return append(out, benched...)
+}
+
+// report records the outcome of a call to ep. Only transient failures
+// bench it: a client error says nothing about the endpoint.
+func (p *rpcPool) report(ep *rpcEndpoint, err error, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ue *UpstreamError
+	switch {
+	case err == nil:
+		ep.fails = 0
+		ep.benched = time.Time{}
+	case errors.As(err, &ue) && ue.Class != classClient:
+		ep.fails++
+		bench := min(rpcBenchBase<<min(ep.fails-1, 10), rpcBenchMax)
+		ep.benched = now.Add(bench)
+		metrics.Inc(ep.upstream + ".benched")
+	}
+}
+
+// post sends one JSON-RPC request body, trying endpoints in order() until
+// one answers 200 without a transient JSON-RPC error (node behind, rate
+// limited). Every endpoint but the last gets a single attempt so a
+// struggling one is left quickly; the last retries as postJSON does, and
+// its JSON-RPC error is passed on for Call to return.
+func (p *rpcPool) post(ctx context.Context, client *http.Client, body []byte) (*http.Response, error) {
+	eps := p.order(time.Now())
+	var last error
+	for i, ep := range eps {
+		attempts := 1
+		if i == len(eps)-1 {
+			attempts = transientAttempts
+		}
+		resp, err := postJSONAttempts(ctx, client, ep.upstream, ep.url, body, attempts)
+		if err != nil && ctx.Err() != nil {
+			return nil, err // our deadline, not the endpoint's fault
+		}
+		if err == nil {
+			resp, err = transientRPCError(ep.upstream, resp, i == len(eps)-1)
+		}
+		p.report(ep, err, time.Now())
+		if err == nil {
+			if i > 0 {
+				metrics.Inc("rpc.failover")
+			}
+			return resp, nil
+		}
+		last = err
+		var ue *UpstreamError
+		if errors.As(err, &ue) && ue.Class == classClient {
+			break
+		}
+	}
+	return nil, last
+}
+
+// transientRPCError reads resp and returns an UpstreamError when it carries
+// a transient JSON-RPC error, so the pool moves on to the next endpoint;
+// otherwise, and always when last, resp is returned with its body intact.
+func transientRPCError(upstream string, resp *http.Response, last bool) (*http.Response, error) {
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, &UpstreamError{Upstream: upstream, Class: classNetwork, Attempts: 1, Err: err}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	var envelope struct {
+		Error *solanarpc.RPCError `json:"error"`
+	}
+	if last || json.Unmarshal(raw, &envelope) != nil || envelope.Error == nil || !envelope.Error.Transient() {
+		return resp, nil
+	}
+	class := classServer
+	if errors.Is(envelope.Error, solanarpc.ErrRateLimited) {
+		class = classRateLimited
+	}
+	metrics.Inc("upstream." + upstream + "." + class)
+	return nil, &UpstreamError{Upstream: upstream, Class: class, Attempts: 1, Err: envelope.Error}
+}
+
+// RPCEndpoint is the state of one pooled Solana RPC endpoint, for /health.
+type RPCEndpoint struct {
+	Name    string // rpc.ep<N>
+	Weight  int
+	Fails   int           // consecutive transient failures
+	Benched time.Duration // how much longer it is skipped; 0 = in rotation
+}
+
+// RPCEndpoints reports the pool's endpoints in configuration order, or nil
+// without a pool.
+func (a *Analyzer) RPCEndpoints() []RPCEndpoint {
+	if a.rpcPool == nil {
+		return nil
+	}
+	p := a.rpcPool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]RPCEndpoint, len(p.eps))
+	for i, ep := range p.eps {
+		out[i] = RPCEndpoint{Name: ep.upstream, Weight: ep.weight, Fails: ep.fails, Benched: max(time.Until(ep.benched), 0)}
+	}
+	return out
+}
+
+// SetRPCPool spreads Solana RPC calls over urls, each getting a share of
+// them proportional to its weight (nil weights = equal shares), and fails
+// calls over between them. Call it before the analyzer is used; fewer than
+// two URLs leave SolanaRPCURL on its own.
+func (a *Analyzer) SetRPCPool(urls []string, weights []int) {
+	if len(urls) < 2 {
+		a.rpcPool = nil
+		return
+	}
+	p := &rpcPool{}
+	for i, u := range urls {
+		w := 1
+		if i < len(weights) && weights[i] > 0 {
+			w = weights[i]
+		}
+		p.eps = append(p.eps, &rpcEndpoint{url: u, weight: w, upstream: fmt.Sprintf("rpc.ep%d", i+1)})
+	}
+	a.SolanaRPCURL = urls[0]
+	a.rpcPool = p
+}
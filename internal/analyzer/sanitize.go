@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// htmlEscaper covers the characters Telegram's HTML parser treats as markup.
+var htmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&quot;",
+)
+
+// EscapeHTML is the single sanitizer for dynamic text placed in Telegram
+// HTML. Token symbols and descriptions come from on-chain data anyone can
+// write, so besides escaping markup it drops control and bidi-override
+// characters, which can reorder a message to spoof amounts or addresses.
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(SanitizeText(s))
+}
+
+// SanitizeText removes control characters (except newline and tab) and
+// Unicode format characters such as U+202E RIGHT-TO-LEFT OVERRIDE. Zero
+// width joiners are kept so emoji sequences survive. Escapers for other
+// markups (MarkdownV2) call it before escaping.
+func SanitizeText(s string) string {
+	clean := true
+	for _, r := range s {
+		if unsafeRune(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if unsafeRune(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func unsafeRune(r rune) bool {
+	if r == '\n' || r == '\t' || r == '\u200d' || r == '\ufe0f' {
+		return false
+	}
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks how much attention a result deserves. Routing uses it to
+// send, say, only critical events to a phone and everything to a log chat.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityNotice
+	SeverityImportant
+	SeverityCritical
+)
+
+var severityNames = []string{"info", "notice", "important", "critical"}
+
+func (s Severity) String() string {
+	if s < SeverityInfo || s > SeverityCritical {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity reads a severity name, case-insensitively.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q (want info, notice, important or critical)", name)
+}
+
+// SeverityNames lists the severities from lowest to highest.
+func SeverityNames() []string { return append([]string(nil), severityNames...) }
+
+// DefaultSeverityUSD are the USD sizes at which a result becomes notice,
+// important and critical.
+var DefaultSeverityUSD = [3]float64{1_000, 10_000, 100_000}
+
+// classifySeverity sets res.Severity from its USD size (against
+// a.SeverityUSD), then adjusts for what happened: airdrops are info,
+// early buys at least notice, unusual sizes at least important.
+func (a *Analyzer) classifySeverity(res *Result) {
+	thresholds := a.SeverityUSD
+	if thresholds == ([3]float64{}) {
+		thresholds = DefaultSeverityUSD
+	}
+	sev := SeverityInfo
+	for i, t := range thresholds {
+		if t > 0 && res.SizeUSD >= t {
+			sev = Severity(i + 1)
+		}
+	}
+	switch {
+	case res.Airdrop:
+		sev = SeverityInfo
+	case res.AnomalyFactor > 0:
+		sev = max(sev, SeverityImportant)
+	case res.LaunchAge > 0:
+		sev = max(sev, SeverityNotice)
+	}
+	res.Severity = sev
+}
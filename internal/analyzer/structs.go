@@ -1,10 +1,14 @@
 package analyzer
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type HeliusTransaction struct {
 	Signature        string            `json:"signature"`
 	Timestamp        int64             `json:"timestamp"`
+	Slot             uint64            `json:"slot"`
 	Fee              int64             `json:"fee"`
 	FeePayer         string            `json:"feePayer"`
 	Type             string            `json:"type"`
@@ -15,6 +19,15 @@ type HeliusTransaction struct {
 	AccountData      []AccountData     `json:"accountData"`
 	TransactionError *json.RawMessage  `json:"transactionError"`
 	Events           TransactionEvents `json:"events"`
+	Instructions     []Instruction     `json:"instructions"`
+}
+
+// Instruction is one program invocation; inner (CPI) calls are nested.
+type Instruction struct {
+	ProgramID         string        `json:"programId"`
+	Accounts          []string      `json:"accounts"`
+	Data              string        `json:"data"` // base58
+	InnerInstructions []Instruction `json:"innerInstructions"`
 }
 type TokenTransfer struct {
 	FromTokenAccount string  `json:"fromTokenAccount"`
@@ -57,44 +70,95 @@ type RawTokenAmount struct {
 	TokenAmount string `json:"tokenAmount"`
 	Decimals    int    `json:"decimals"`
 }
-type TokenMetadata struct {
-	Symbol   string
-	Decimals int
-}
-type RPCRequest struct {
-	JSONRPC string        `json:"jsonrpc"`
-	ID      int           `json:"id"`
-	Method  string        `json:"method"`
-	Params  []interface{} `json:"params"`
+
+// Amount is one token leg of an analyzed transaction.
+type Amount struct {
+	Mint   string
+	Symbol string
+	Amount float64
+	USD    float64 // 0 when the mint has no price source
+	// Change24h is the token's 24h price change in percent, when known.
+	Change24h *float64
+	// Verified is the token list verdict shown as a badge in swap alerts;
+	// nil when no list is synced or for SOL/USDC.
+	Verified *bool
 }
 
-// GetAccountInfoResponse is for jsonParsed requests.
-type GetAccountInfoResponse struct {
-	Result struct {
-		Value struct {
-			Owner string `json:"owner"`
-			Data  struct {
-				Parsed struct {
-					Info struct {
-						Decimals int `json:"decimals"`
-					} `json:"info"`
-				} `json:"parsed"`
-			} `json:"data"`
-		} `json:"value"`
-	} `json:"result"`
+// Result is the structured outcome of analyzing one transaction for a
+// tracked wallet. Summary() renders it for Telegram.
+type Result struct {
+	Signature      string
+	Wallet         string
+	Type           string
+	Source         string
+	Slot           uint64
+	Timestamp      time.Time
+	Interpretation string
+	Description    string
+	Sent           []Amount
+	Received       []Amount
+	Notes          []string // extra HTML lines (warnings, enrichments)
+	// Programs lists the distinct program IDs invoked, outer and inner, in
+	// first-seen order.
+	Programs []string
+
+	// SizeUSD is the larger of the priced sent/received totals; 0 if unpriced.
+	SizeUSD float64
+	// AnomalyFactor is SizeUSD divided by the wallet's median size when the
+	// move is unusually large, otherwise 0.
+	AnomalyFactor float64
+	// LaunchAge is how long after token creation the wallet bought in
+	// (smallest across received tokens); 0 when unknown or not a buy.
+	LaunchAge time.Duration
+	// RentSOL is the token-account rent refunded (positive) or paid
+	// (negative) by the wallet; it is excluded from the SOL amounts.
+	RentSOL float64
+	// Airdrop is set for unsolicited, zero-cost token receipts from a mass
+	// distribution (see detectAirdrop).
+	Airdrop bool
+	// Counterparty is the single other wallet of a plain send or receive;
+	// empty for trades and multi-party transfers.
+	Counterparty string
+	// Severity ranks the result for routing; see classifySeverity.
+	Severity Severity
+	// Tags are the wallet owners' tags (/tag), set by the Telegram handler
+	// before the result is published.
+	Tags []string
 }
 
-// GetAccountInfoResponse_Base64 is for base64 requests.
-type GetAccountInfoResponse_Base64 struct {
-	Result struct {
-		Value struct {
-			Data []string `json:"data"` // e.g., ["base64_string", "base64"]
-		} `json:"value"`
-	} `json:"result"`
+type TokenMetadata struct {
+	Symbol   string
+	Decimals int
+	// Name, URI, SellerFeeBps and Creators come from the Metaplex metadata
+	// account (or DAS); they are empty for tokens without one.
+	Name         string
+	URI          string // off-chain JSON, see OffChainMetadata
+	SellerFeeBps int    // royalty in basis points
+	Creators     []Creator
+	// Logo and Tags come from the Jupiter token list (see SyncTokenList).
+	Logo string
+	Tags []string
+	// OffChain is the JSON at URI once fetched; nil until then.
+	OffChain  *OffChainMetadata
+	FetchedAt time.Time // zero for built-in entries, which never expire
+	// Failed marks a placeholder stored after a failed lookup; it is retried
+	// once older than Analyzer.NegativeTTL.
+	Failed bool
 }
 
-type GetProgramAccountsResponse struct {
-	Result []struct {
-		Pubkey string `json:"pubkey"`
-	} `json:"result"`
+// getBlockResult is the getBlock result with transactionDetails=accounts.
+type getBlockResult struct {
+	Transactions []struct {
+		Transaction struct {
+			AccountKeys []struct {
+				Pubkey   string `json:"pubkey"`
+				Signer   bool   `json:"signer"`
+				Writable bool   `json:"writable"`
+			} `json:"accountKeys"`
+			Signatures []string `json:"signatures"`
+		} `json:"transaction"`
+		Meta *struct {
+			Err any `json:"err"`
+		} `json:"meta"`
+	} `json:"transactions"`
 }
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// SymbolStore is the settings subset used to persist symbol ownership.
+type SymbolStore interface {
+	GetSetting(ctx context.Context, key string) (string, bool, error)
+	SetSetting(ctx context.Context, key, value string) error
+}
+
+// symbolSeparator joins a clashing symbol and its mint prefix: "WIF·a1b2".
+const symbolSeparator = "·"
+
+// SymbolKeyPrefix starts the settings keys of symbol claims, for retention.
+const SymbolKeyPrefix = "symbol:"
+
+// contestedSymbol is the owner recorded for a symbol once a second mint
+// used it: no unverified mint keeps it bare any more.
+const contestedSymbol = "*"
+
+// symbolKey is the settings key holding the mint that owns symbol.
+func symbolKey(symbol string) string { return SymbolKeyPrefix + strings.ToUpper(symbol) }
+
+// disambiguateSymbols rewrites, in meta, the symbol of each mint whose
+// symbol is also used by another mint: every such mint gets a short mint
+// suffix, the first one seen included, so a fake buy can't pass for the
+// real token whichever was seen first. Built-in, verified and fallback
+// entries are left alone.
+func (a *Analyzer) disambiguateSymbols(ctx context.Context, meta map[string]TokenMetadata, mints map[string]bool) {
+	for mint := range mints {
+		m, ok := meta[mint]
+		if !ok || m.Failed || m.FetchedAt.IsZero() || strings.TrimSpace(m.Symbol) == "" {
+			continue
+		}
+		if verified, _ := a.Verified(mint); verified {
+			continue // the listed token is the real one whoever came first
+		}
+		if a.symbolClash(ctx, m.Symbol, mint) && len(mint) >= 4 {
+			m.Symbol += symbolSeparator + mint[:4]
+			meta[mint] = m
+		}
+	}
+}
+
+// symbolClash reports whether a mint other than mint uses symbol. The
+// first clash marks the symbol contestedSymbol, so the mint that claimed
+// it is suffixed from then on too.
+func (a *Analyzer) symbolClash(ctx context.Context, symbol, mint string) bool {
+	switch owner := a.symbolOwner(ctx, symbol, mint); owner {
+	case "", mint:
+		return false
+	case contestedSymbol:
+		return true
+	}
+	a.symbolOwners.Store(strings.ToUpper(symbol), contestedSymbol)
+	if a.Symbols != nil {
+		if err := a.Symbols.SetSetting(ctx, symbolKey(symbol), contestedSymbol); err != nil {
+			log.Printf("[analyzer] symbol contest %s: %v", symbol, err)
+		}
+	}
+	return true
+}
+
+// symbolOwner returns the mint that first claimed symbol, or
+// contestedSymbol, claiming it for mint when nobody has.
+func (a *Analyzer) symbolOwner(ctx context.Context, symbol, mint string) string {
+	key := strings.ToUpper(symbol)
+	if v, ok := a.symbolOwners.Load(key); ok {
+		return v.(string)
+	}
+	if a.Symbols != nil {
+		owner, found, err := a.Symbols.GetSetting(ctx, symbolKey(symbol))
+		if err != nil {
+			log.Printf("[analyzer] symbol lookup %s: %v", symbol, err)
+			return ""
+		}
+		if found {
+			a.symbolOwners.Store(key, owner)
+			return owner
+		}
+	}
+	if v, loaded := a.symbolOwners.LoadOrStore(key, mint); loaded {
+		return v.(string)
+	}
+	if a.Symbols != nil {
+		if err := a.Symbols.SetSetting(ctx, symbolKey(symbol), mint); err != nil {
+			log.Printf("[analyzer] symbol claim %s: %v", symbol, err)
+		}
+	}
+	return mint
+}
@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+const (
+	// jupiterTokensURL lists the tokens Jupiter has verified.
+	jupiterTokensURL = "https://lite-api.jup.ag/tokens/v2/tag?query=verified"
+	// tokenListTimeout bounds one download; the list is a few MB, more
+	// than the price client's timeout allows for.
+	tokenListTimeout = time.Minute
+)
+
+// jupiterToken is the subset of a Jupiter token list entry we keep.
+type jupiterToken struct {
+	ID       string   `json:"id"` // mint
+	Name     string   `json:"name"`
+	Symbol   string   `json:"symbol"`
+	Icon     string   `json:"icon"`
+	Decimals int      `json:"decimals"`
+	Tags     []string `json:"tags"`
+}
+
+// SyncTokenList loads Jupiter's verified token list into the metadata
+// cache, so listed mints need no metadata lookup, and remembers which
+// mints are verified. Entries already cached keep what only on-chain
+// metadata has (URI, creators); built-in entries are left alone.
+func (a *Analyzer) SyncTokenList(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenListTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jupiterTokensURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	client := &http.Client{Transport: a.priceOracle.httpClient.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("token list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("token list: status %d", resp.StatusCode)
+	}
+	var tokens []jupiterToken
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return 0, fmt.Errorf("token list: decoding: %w", err)
+	}
+
+	now := time.Now()
+	verified := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		symbol := strings.TrimSpace(t.Symbol)
+		if t.ID == "" || symbol == "" {
+			continue
+		}
+		verified[t.ID] = true
+		var meta TokenMetadata
+		if v, ok := a.metadataCache.Load(t.ID); ok {
+			if meta = v.(TokenMetadata); meta.FetchedAt.IsZero() {
+				continue // built-in
+			}
+			if meta.Failed {
+				meta = TokenMetadata{}
+			}
+		}
+		meta.Symbol, meta.Decimals = symbol, t.Decimals
+		if name := strings.TrimSpace(t.Name); name != "" {
+			meta.Name = name
+		}
+		meta.Logo, meta.Tags = t.Icon, t.Tags
+		meta.FetchedAt = now
+		a.metadataCache.Store(t.ID, meta)
+	}
+	if len(verified) == 0 {
+		return 0, fmt.Errorf("token list: no usable entries")
+	}
+	a.verified.Store(&verified)
+	metrics.Inc("analyzer.tokenlist.synced")
+	return len(verified), nil
+}
+
+// RunTokenListSync calls SyncTokenList now and then every interval until
+// ctx is done. A failed sync keeps the previous list.
+func (a *Analyzer) RunTokenListSync(ctx context.Context, interval time.Duration) {
+	sync := func() {
+		n, err := a.SyncTokenList(ctx)
+		if err != nil {
+			log.Printf("[analyzer] %v", err)
+			return
+		}
+		log.Printf("[analyzer] token list synced: %d verified tokens", n)
+	}
+	sync()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sync()
+		}
+	}
+}
+
+// Verified reports whether mint is on the synced verified token list.
+// known is false until a list has been synced, so callers don't flag
+// everything while it is unavailable.
+func (a *Analyzer) Verified(mint string) (verified, known bool) {
+	set := a.verified.Load()
+	if set == nil {
+		return false, false
+	}
+	return (*set)[mint], true
+}
+
+// badgeAmounts sets Verified on each amount of a swap, except SOL and USDC,
+// once a token list has been synced.
+func (a *Analyzer) badgeAmounts(amts []Amount) {
+	for i := range amts {
+		if _, tracked := isPriceTracked(amts[i].Mint); tracked {
+			continue
+		}
+		if verified, known := a.Verified(amts[i].Mint); known {
+			amts[i].Verified = &verified
+		}
+	}
+}
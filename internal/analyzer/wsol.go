@@ -0,0 +1,82 @@
+package analyzer
+
+// wsolFlows models what happened to the tracked wallet's wrapped SOL in one
+// transaction. Routers typically:
+//
+//  1. create a temporary WSOL token account (rent deposit, user -> account),
+//  2. transfer native SOL into it and call SyncNative (the "wrap"),
+//  3. spend or receive WSOL through the swap,
+//  4. close the account, returning balance + rent to the user (the "unwrap").
+//
+// Steps 1, 2 and 4 are already reflected in the wallet's nativeBalanceChange,
+// so the only WSOL movement that is NOT yet in the native number is the change
+// in WSOL token balances that survives the transaction (persistent WSOL
+// accounts). A temporary account that is created and closed within the tx has
+// no pre/post token balance at all, so it contributes exactly zero.
+type wsolFlows struct {
+	// BalanceDelta is the net change of WSOL token balances across the
+	// wallet's accounts, from accountData.tokenBalanceChanges (in SOL).
+	BalanceDelta float64
+	// HasBalanceData is false when Helius returned no WSOL balance rows for
+	// the wallet; callers then fall back to the transfer-based heuristic.
+	HasBalanceData bool
+	// TransferDelta is WSOL in minus WSOL out via token transfers.
+	TransferDelta float64
+	// InflowFromOther is true when another user sent WSOL to the wallet.
+	InflowFromOther bool
+}
+
+// collectWSOLFlows gathers every WSOL-related movement for trackedAddr.
+func collectWSOLFlows(tx *HeliusTransaction, trackedAddr string) wsolFlows {
+	var f wsolFlows
+
+	for _, ad := range tx.AccountData {
+		for _, tb := range ad.TokenBalanceChanges {
+			if tb.Mint != wsolMint || tb.UserAccount != trackedAddr {
+				continue
+			}
+			f.BalanceDelta += parseAmount(tb.RawTokenAmount.TokenAmount, tb.RawTokenAmount.Decimals)
+			f.HasBalanceData = true
+		}
+	}
+
+	// WSOL received into an account closed in the same transaction was
+	// unwrapped to native SOL and is already in the native change.
+	closedTo := closeDestinations(tx.Instructions)
+	for _, tt := range tx.TokenTransfers {
+		if tt.Mint != wsolMint {
+			continue
+		}
+		if tt.FromUserAccount == trackedAddr {
+			f.TransferDelta -= tt.TokenAmount
+		}
+		if tt.ToUserAccount == trackedAddr {
+			if closedTo[tt.ToTokenAccount] != "" {
+				continue
+			}
+			f.TransferDelta += tt.TokenAmount
+			if tt.FromUserAccount != trackedAddr {
+				f.InflowFromOther = true
+			}
+		}
+	}
+	return f
+}
+
+// solAdjustment returns how much SOL to add on top of the wallet's native
+// balance change to get its true SOL exposure.
+//
+// With balance data this is exact: persistent WSOL balance changes count as
+// SOL, temporary accounts net to zero. Without it we keep the conservative
+// legacy rule: only a positive WSOL delta that came from another user counts,
+// never a negative one (that is usually a spend of SOL wrapped in the same tx
+// and already visible in the native change).
+func (f wsolFlows) solAdjustment() float64 {
+	if f.HasBalanceData {
+		return f.BalanceDelta
+	}
+	if f.InflowFromOther && f.TransferDelta > 1e-12 {
+		return f.TransferDelta
+	}
+	return 0
+}
@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	wsolWallet  = "Wa11et1111111111111111111111111111111111111"
+	wsolAccount = "WsoLAcct11111111111111111111111111111111111"
+	wsolPool    = "Poo11111111111111111111111111111111111111111"
+	wsolToken   = "Token11111111111111111111111111111111111111"
+	wsolRent    = 2_039_280 // lamports, rent-exempt minimum of a token account
+	wsolFee     = 5_000
+)
+
+// wsolAccountData is the WSOL account's accountData row: its lamport change
+// and, unless raw is empty, its WSOL balance change.
+func wsolAccountData(lamports int64, raw string) AccountData {
+	ad := AccountData{Account: wsolAccount, NativeBalanceChange: lamports}
+	if raw != "" {
+		ad.TokenBalanceChanges = []TokenBalanceChange{{
+			UserAccount:    wsolWallet,
+			TokenAccount:   wsolAccount,
+			Mint:           wsolMint,
+			RawTokenAmount: RawTokenAmount{TokenAmount: raw, Decimals: 9},
+		}}
+	}
+	return ad
+}
+
+func walletData(lamports int64) AccountData {
+	return AccountData{Account: wsolWallet, NativeBalanceChange: lamports}
+}
+
+// closeWSOL closes the WSOL account, sending its lamports to the wallet.
+var closeWSOL = Instruction{ProgramID: splTokenProgramID, Data: closeAccountData, Accounts: []string{wsolAccount, wsolWallet, wsolWallet}}
+
+// stubOracle returns a PriceOracle that answers SOL at 150 USD from its
+// cache without any HTTP.
+func stubOracle() *PriceOracle {
+	o := &PriceOracle{cache: &sync.Map{}}
+	o.cache.Store("solana", cachedPrice{Price: 150, LastFetched: time.Now().Add(time.Hour)})
+	return o
+}
+
+// solChange is the signed SOL amount among sent and received.
+func solChange(sent, received []Amount) float64 {
+	var v float64
+	for _, a := range received {
+		if a.Mint == wsolMint {
+			v += a.Amount
+		}
+	}
+	for _, a := range sent {
+		if a.Mint == wsolMint {
+			v -= a.Amount
+		}
+	}
+	return v
+}
+
+// TestWSOLNetting checks the SOL exposure calculateNetBalanceChanges reports
+// across the WSOL lifecycle: temporary and persistent accounts, their rent,
+// sync-native wraps and transactions without balance rows.
+func TestWSOLNetting(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   HeliusTransaction
+		want float64 // SOL
+	}{
+		{
+			name: "wrap into new persistent account",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{
+					walletData(-1_000_000_000 - wsolRent - wsolFee),
+					wsolAccountData(1_000_000_000+wsolRent, "1000000000"),
+				},
+				NativeTransfers: []NativeTransfer{
+					{FromUserAccount: wsolWallet, ToUserAccount: wsolAccount, Amount: wsolRent},
+					{FromUserAccount: wsolWallet, ToUserAccount: wsolAccount, Amount: 1_000_000_000},
+				},
+			},
+			want: -0.000005,
+		},
+		{
+			name: "sync-native into existing account",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{
+					walletData(-1_000_000_000 - wsolFee),
+					wsolAccountData(1_000_000_000, "1000000000"),
+				},
+				NativeTransfers: []NativeTransfer{{FromUserAccount: wsolWallet, ToUserAccount: wsolAccount, Amount: 1_000_000_000}},
+			},
+			want: -0.000005,
+		},
+		{
+			name: "unwrap and close persistent account",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{
+					walletData(1_000_000_000 + wsolRent - wsolFee),
+					wsolAccountData(-1_000_000_000-wsolRent, "-1000000000"),
+				},
+				Instructions: []Instruction{closeWSOL},
+			},
+			want: -0.000005,
+		},
+		{
+			name: "buy through temporary account created and closed",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{
+					walletData(-1_500_000_000 - wsolFee),
+					wsolAccountData(0, ""),
+				},
+				NativeTransfers: []NativeTransfer{
+					{FromUserAccount: wsolWallet, ToUserAccount: wsolAccount, Amount: wsolRent},
+					{FromUserAccount: wsolWallet, ToUserAccount: wsolAccount, Amount: 1_500_000_000},
+				},
+				TokenTransfers: []TokenTransfer{{
+					FromUserAccount: wsolWallet, FromTokenAccount: wsolAccount,
+					ToUserAccount: wsolPool, Mint: wsolMint, TokenAmount: 1.5,
+				}},
+				Instructions: []Instruction{closeWSOL},
+			},
+			want: -1.500005,
+		},
+		{
+			name: "sell into temporary account created and closed",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{
+					walletData(2_000_000_000 - wsolFee),
+					wsolAccountData(0, ""),
+				},
+				NativeTransfers: []NativeTransfer{{FromUserAccount: wsolWallet, ToUserAccount: wsolAccount, Amount: wsolRent}},
+				TokenTransfers: []TokenTransfer{{
+					FromUserAccount: wsolPool, ToUserAccount: wsolWallet,
+					ToTokenAccount: wsolAccount, Mint: wsolMint, TokenAmount: 2,
+				}},
+				Instructions: []Instruction{closeWSOL},
+			},
+			want: 1.999995,
+		},
+		{
+			name: "sell into persistent account",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{
+					walletData(-wsolFee),
+					wsolAccountData(2_000_000_000, "2000000000"),
+				},
+				TokenTransfers: []TokenTransfer{{
+					FromUserAccount: wsolPool, ToUserAccount: wsolWallet,
+					ToTokenAccount: wsolAccount, Mint: wsolMint, TokenAmount: 2,
+				}},
+			},
+			want: 1.999995,
+		},
+		{
+			name: "sell without balance rows",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{walletData(-wsolFee)},
+				TokenTransfers: []TokenTransfer{{
+					FromUserAccount: wsolPool, ToUserAccount: wsolWallet,
+					ToTokenAccount: wsolAccount, Mint: wsolMint, TokenAmount: 2,
+				}},
+			},
+			want: 1.999995,
+		},
+		{
+			name: "spend without balance rows is already native",
+			tx: HeliusTransaction{
+				AccountData: []AccountData{walletData(-2_000_000_000 - wsolFee)},
+				TokenTransfers: []TokenTransfer{
+					{ToUserAccount: wsolWallet, ToTokenAccount: wsolAccount, FromUserAccount: wsolWallet, Mint: wsolMint, TokenAmount: 2},
+					{FromUserAccount: wsolWallet, FromTokenAccount: wsolAccount, ToUserAccount: wsolPool, Mint: wsolMint, TokenAmount: 2},
+				},
+			},
+			want: -2.000005,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent, received := calculateNetBalanceChanges(context.Background(), &tt.tx, wsolWallet, nil, stubOracle())
+			if got := solChange(sent, received); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("SOL change = %.9f, want %.9f (sent %v, received %v)", got, tt.want, sent, received)
+			}
+			for _, a := range append(sent, received...) {
+				if a.Mint == wsolMint && math.Abs(a.USD-a.Amount*150) > 1e-6 {
+					t.Errorf("SOL USD = %.6f, want %.6f", a.USD, a.Amount*150)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package chart renders the small PNG charts sent as Telegram photos.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	gochart "github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+const (
+	width  = 900
+	height = 450
+)
+
+var (
+	green = drawing.ColorFromHex("2e9e5b")
+	red   = drawing.ColorFromHex("d64545")
+)
+
+// TimeLine renders a USD line over time. It needs at least two points.
+func TimeLine(title string, ts []time.Time, usd []float64) ([]byte, error) {
+	if len(ts) < 2 || len(ts) != len(usd) {
+		return nil, errors.New("need at least two points")
+	}
+	c := gochart.Chart{
+		Title:  title,
+		Width:  width,
+		Height: height,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20},
+		},
+		XAxis: gochart.XAxis{ValueFormatter: gochart.TimeValueFormatterWithFormat("01-02 15:04")},
+		YAxis: gochart.YAxis{ValueFormatter: usdFormatter},
+		Series: []gochart.Series{gochart.TimeSeries{
+			XValues: ts,
+			YValues: usd,
+			Style:   gochart.Style{StrokeColor: green, StrokeWidth: 2},
+		}},
+	}
+	return render(c)
+}
+
+// Bars renders one bar per label, green for gains and red for losses.
+func Bars(title string, labels []string, usd []float64) ([]byte, error) {
+	if len(labels) == 0 || len(labels) != len(usd) {
+		return nil, errors.New("nothing to chart")
+	}
+	bars := make([]gochart.Value, len(labels))
+	for i, l := range labels {
+		color := green
+		if usd[i] < 0 {
+			color = red
+		}
+		bars[i] = gochart.Value{
+			Label: l,
+			Value: usd[i],
+			Style: gochart.Style{FillColor: color, StrokeColor: color},
+		}
+	}
+	c := gochart.BarChart{
+		Title:  title,
+		Width:  width,
+		Height: height,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20},
+		},
+		BarWidth:     max(10, 600/len(bars)),
+		UseBaseValue: true,
+		BaseValue:    0,
+		YAxis:        gochart.YAxis{ValueFormatter: usdFormatter},
+		Bars:         bars,
+	}
+	return render(c)
+}
+
+func render(c interface {
+	Render(gochart.RendererProvider, io.Writer) error
+}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Render(gochart.PNG, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func usdFormatter(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		if f < 0 {
+			return fmt.Sprintf("-$%.0f", -f)
+		}
+		return fmt.Sprintf("$%.0f", f)
+	}
+	return ""
+}
@@ -3,9 +3,11 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,13 +18,110 @@ type Config struct {
 	TelegramBotToken    string
 	TelegramAdminChatID int64
 	HeliusWSS           string
-	HeliusAPIURL        string // V2: For fetching tx details
+	HeliusAPIURL        string   // V2: For fetching tx details
+	HeliusAPIKey        string   // optional; derives HeliusWSS/HeliusAPIURL when they are unset
+	HeliusAPIKeys       []string // HELIUS_API_KEY split on commas; HeliusAPIKey is the first
+	HeliusWSSPool       []string // one derived WSS URL per key when there are several
+	HeliusAPIPool       []string // one derived API URL per key when there are several
+	HeliusRPCPool       []string // one derived RPC URL per key when there are several
+	HeliusNetwork       string   // default: "mainnet"; selects the derived endpoints
+	HeliusRPCURL        string   // optional; DAS endpoint for batched metadata lookups
 
 	// Optional (with defaults)
-	DBPath       string // default: "solwatch.db"
-	Commitment   string // default: "processed"
-	SolanaRPCURL string // V2: For token metadata
-	LogLevel     string
+	DBPath                string        // default: "solwatch.db"
+	Commitment            string        // default: "processed"
+	FinalityRecheck       bool          // default: false; edit/retract alerts after finalization
+	SubscribeMode         string        // default: "logs"; "blocks" uses one blockSubscribe filtered locally; "geyser" uses GeyserURL
+	GeyserURL             string        // required with SUBSCRIBE_MODE=geyser; Yellowstone gRPC endpoint
+	GeyserToken           string        // optional; x-token for GeyserURL
+	SubscribeRate         float64       // default: 20; new WebSocket connections per second (0 = no cap)
+	WSPingInterval        time.Duration // default: 20s; gap between WebSocket pings
+	WSReadTimeout         time.Duration // default: 60s; drop a connection silent this long
+	WSMaxMissedPongs      int           // default: 2; drop a connection after this many unanswered pings
+	WSIdleRecycle         time.Duration // default: 0 (off); reconnect when no notification arrived this long
+	ReconnectInitial      time.Duration // default: 1s; first reconnect backoff
+	ReconnectMax          time.Duration // default: 30s; backoff cap
+	ReconnectFactor       float64       // default: 2; backoff growth per failed attempt
+	ReconnectJitter       float64       // default: 0.2; ±share of randomness in each backoff
+	ReconnectStorm        int           // default: 20; failures within ReconnectStormWindow that hold all reconnects (0 = off)
+	ReconnectStormWindow  time.Duration // default: 10s
+	BackfillLimit         int           // default: 100; signatures recovered per reconnect gap (0 = off)
+	SolanaRPCURL          string        // V2: For token metadata; the first of SolanaRPCURLs
+	SolanaRPCURLs         []string      // SOLANA_RPC_URL split on commas
+	SolanaRPCWeights      []int         // default: 1 each; share of calls per SolanaRPCURLs entry
+	LogLevel              string
+	MEVDetection          bool          // default: false; flags sandwiched swaps via getBlock lookups
+	BotRateLimit          time.Duration // default: 0 (off); min gap between alerts for bot-classified wallets
+	AnomalyFactor         float64       // default: 10; flag moves this many times the wallet median (0 = off)
+	CompactAbove          float64       // default: 1e9; token amounts at or above use 1.25B notation, 0 disables
+	EarlyBuy              bool          // default: true; annotate buys with time since token creation
+	SellRouteCheck        bool          // default: true; warn when a bought token has no sell route
+	HolderConcentration   float64       // default: 50; flag bought tokens whose top 10 holders (pools and curves aside) own this % (0 = off)
+	MarketData            bool          // default: true; price swapped tokens and show their FDV
+	SuppressAirdrops      bool          // default: true; drop alerts for unsolicited token receipts
+	SeverityUSD           [3]float64    // default: 1000,10000,100000; USD sizes for notice, important, critical
+	AnalysisTimeout       time.Duration // default: 20s; bound on handling one signature
+	AnalysisFetch         time.Duration // default: 10s; budget for fetching the transaction
+	AnalysisMetadata      time.Duration // default: 5s; budget for token metadata lookups
+	AnalysisPrices        time.Duration // default: 5s; budget for price and market lookups
+	AutoTrackMinUSD       float64       // default: 0 (off); offer to track counterparties of plain transfers this large
+	AutoTrackMode         string        // "offer" (default) or "auto"
+	AutoTrackTrial        time.Duration // default: 48h; how long a counterparty is tracked unless it is active
+	AutoTrackIgnore       []string      // optional; exchange/known addresses never offered
+	DBMaintenanceInterval time.Duration // default: 24h; periodic stats + auto-compaction (0 = off)
+	HistoryRetention      time.Duration // default: 90d; delete older history (0 = keep)
+	MetadataTTL           time.Duration // default: 7d; re-resolve cached token metadata after this
+	MetadataNegativeTTL   time.Duration // default: 10m; retry failed metadata lookups after this
+	TokenListInterval     time.Duration // default: 6h; how often to sync Jupiter's verified token list (0 = off)
+	PriceCacheTTL         time.Duration // default: 10m; drop unused cached prices after this
+	PruneInterval         time.Duration // default: 1h; how often the retention pruner runs
+	StoreEncryptionKey    string        // optional; enables encryption at rest for the Bolt DB
+	AdminAddr             string        // optional; admin HTTP listen address (e.g. 127.0.0.1:8080)
+	AdminToken            string        // required when AdminAddr or GRPCAddr is set
+	GRPCAddr              string        // optional; gRPC API listen address
+	MetricsAddr           string        // optional; Prometheus /metrics listen address
+	ConfigFile            string        // optional; KEY=VALUE file read instead of .env
+	EventBusURL           string        // optional; nats:// or redis:// publisher for analysis events
+	PluginDir             string        // optional; directory of Go plugin (.so) extensions
+	TemplatesDir          string        // optional; directory of <TYPE>.tmpl alert templates
+	HookCommand           string        // optional; shell command run per event with JSON on stdin
+	HookTimeout           time.Duration // default: 10s
+	HookConcurrency       int           // default: 4
+	SnapshotPath          string        // optional; periodic JSON export for sidecar readers
+	SnapshotInterval      time.Duration // default: 5m
+	DatabaseURL           string        // optional; use Postgres instead of Bolt
+	DBMaxConns            int           // default: 10
+	AllowedUsers          []int64       // optional; extra chats allowed to use the bot (multi-user mode)
+	MaxWalletsPerUser     int           // default: 50; the admin is exempt
+	FirehoseChatID        int64         // optional; chat that gets every event, unfiltered
+	FirehoseRateLimit     time.Duration // default: 3s; min gap between firehose messages
+	DebugChatID           int64         // optional; chat that gets analyzer and subscriber errors
+	DebugErrorGap         time.Duration // default: 5m; how often one kind of error is reported there
+	ErrorLogWindow        time.Duration // default: 5m; repeats of one error are logged as one count per window (0 = off)
+	LifecycleNotices      bool          // default: true; tell the admin chat when the service starts and stops
+	StartPaused           bool          // default: false; start with subscriptions paused until /resumeall
+	SMTPHost              string        // optional; enables the email sink
+	SMTPPort              int           // default: 587
+	SMTPUser              string
+	SMTPPassword          string
+	EmailFrom             string
+	EmailTo               []string
+	EmailRules            string        // see email.ParseRules; empty emails everything
+	EmailDigestAt         time.Duration // UTC time of day for the daily digest; -1 = send immediately
+	NetWorthInterval      time.Duration // default: 1h; 0 disables net worth sampling
+	DroppedAlertAfter     time.Duration // default: 5m; alert the admin when a subscription stays dropped this long (0 = off)
+	DroppedAlertRepeat    time.Duration // default: 30m; repeat the alert while unresolved (0 = once)
+	ProbeInterval         time.Duration // default: 1m; how often upstream endpoints are probed for /health (0 = off)
+	AnalysisWorkers       int           // default: 8; concurrent analyses (each wallet stays in order)
+	ConfluenceWallets     int           // default: 3; alert when this many tracked wallets buy one token (0 = off)
+	ConfluenceWindow      time.Duration // default: 1h; how close together those buys must be
+	HeliusHTTP            HTTPClient    // HELIUS_HTTP_*; default: 20s timeout, 0 retries, 90s keep-alive
+	RPCHTTP               HTTPClient    // RPC_HTTP_*; default: 20s timeout, 0 retries, 90s keep-alive
+	PriceHTTP             HTTPClient    // PRICE_HTTP_*; default: 5s timeout, 0 retries, 90s keep-alive
+
+	// MarketplaceLabels names extra NFT marketplace programs (program ID
+	// -> name) for NFT events Helius reports as source UNKNOWN.
+	MarketplaceLabels map[string]string
 }
 
 // allowedCommitments is kept small and explicit to avoid surprises.
@@ -32,19 +131,34 @@ var allowedCommitments = map[string]struct{}{
 	"finalized": {},
 }
 
+// heliusEndpoints are the standard Helius URLs per network; the API key is
+// appended.
+var heliusEndpoints = map[string]struct{ wss, api, rpc string }{
+	"mainnet": {"wss://mainnet.helius-rpc.com/?api-key=", "https://api.helius.xyz/v0/transactions/?api-key=", "https://mainnet.helius-rpc.com/?api-key="},
+	"devnet":  {"wss://devnet.helius-rpc.com/?api-key=", "https://api-devnet.helius.xyz/v0/transactions/?api-key=", "https://devnet.helius-rpc.com/?api-key="},
+}
+
 // Load reads environment variables, applies defaults, validates,
 // and returns a Config instance. It attempts to load .env if present.
 func Load() (Config, error) {
-	// Load .env if it exists; ignore if missing.
-	_ = godotenv.Load()
-
 	var cfg Config
 	var errs []string
 
+	// CONFIG_FILE names a KEY=VALUE file to read instead of .env; a missing
+	// .env is ignored, a missing CONFIG_FILE is an error. Either way the
+	// environment (and so flags) wins over the file.
+	if cfg.ConfigFile = strings.TrimSpace(os.Getenv("CONFIG_FILE")); cfg.ConfigFile != "" {
+		if err := godotenv.Load(cfg.ConfigFile); err != nil {
+			errs = append(errs, fmt.Sprintf("CONFIG_FILE: %v", err))
+		}
+	} else {
+		_ = godotenv.Load()
+	}
+
 	// --- Required Fields ---
 
 	// Required: TELEGRAM_BOT_TOKEN
-	cfg.TelegramBotToken = strings.TrimSpace(os.Getenv("TELEGRAM_BOT_TOKEN"))
+	cfg.TelegramBotToken = strings.TrimSpace(envSecret("TELEGRAM_BOT_TOKEN", &errs))
 	if cfg.TelegramBotToken == "" {
 		errs = append(errs, "TELEGRAM_BOT_TOKEN is required (get it from @BotFather)")
 	}
@@ -62,20 +176,79 @@ func Load() (Config, error) {
 		}
 	}
 
-	// Required: HELIUS_WSS (must start with wss://)
-	cfg.HeliusWSS = strings.TrimSpace(os.Getenv("HELIUS_WSS"))
+	// HELIUS_API_KEY + HELIUS_NETWORK (mainnet|devnet) derive the standard
+	// endpoints; HELIUS_WSS / HELIUS_API_URL still override them. Several
+	// comma-separated keys share the load; the first one is the default.
+	for _, k := range strings.Split(envSecret("HELIUS_API_KEY", &errs), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			cfg.HeliusAPIKeys = append(cfg.HeliusAPIKeys, k)
+		}
+	}
+	if len(cfg.HeliusAPIKeys) > 0 {
+		cfg.HeliusAPIKey = cfg.HeliusAPIKeys[0]
+	}
+	cfg.HeliusNetwork = strings.ToLower(strings.TrimSpace(os.Getenv("HELIUS_NETWORK")))
+	if cfg.HeliusNetwork == "" {
+		cfg.HeliusNetwork = "mainnet"
+	}
+	endpoints, ok := heliusEndpoints[cfg.HeliusNetwork]
+	if !ok {
+		errs = append(errs, fmt.Sprintf("HELIUS_NETWORK must be mainnet or devnet, got %q", cfg.HeliusNetwork))
+	}
+
+	// Required: HELIUS_WSS (must start with wss://) unless HELIUS_API_KEY is set
+	cfg.HeliusWSS = strings.TrimSpace(envSecret("HELIUS_WSS", &errs))
+	if cfg.HeliusWSS == "" && cfg.HeliusAPIKey != "" && ok {
+		cfg.HeliusWSS = endpoints.wss + url.QueryEscape(cfg.HeliusAPIKey)
+	}
 	if cfg.HeliusWSS == "" {
-		errs = append(errs, "HELIUS_WSS is required (your Helius WebSocket RPC URL, incl. api key)")
+		errs = append(errs, "HELIUS_WSS or HELIUS_API_KEY is required (your Helius WebSocket RPC URL, incl. api key)")
 	} else if !strings.HasPrefix(strings.ToLower(cfg.HeliusWSS), "wss://") {
-		errs = append(errs, fmt.Sprintf("HELIUS_WSS must start with wss://, got %q", cfg.HeliusWSS))
+		errs = append(errs, fmt.Sprintf("HELIUS_WSS must start with wss://, got %q", redactURL(cfg.HeliusWSS)))
 	}
 
-	// Required: HELIUS_API_URL (must start with https://)
-	cfg.HeliusAPIURL = strings.TrimSpace(os.Getenv("HELIUS_API_URL"))
+	// Required: HELIUS_API_URL (must start with https://) unless HELIUS_API_KEY is set
+	cfg.HeliusAPIURL = strings.TrimSpace(envSecret("HELIUS_API_URL", &errs))
+	if cfg.HeliusAPIURL == "" && cfg.HeliusAPIKey != "" && ok {
+		cfg.HeliusAPIURL = endpoints.api + url.QueryEscape(cfg.HeliusAPIKey)
+	}
 	if cfg.HeliusAPIURL == "" {
-		errs = append(errs, "HELIUS_API_URL is required (your Helius HTTP API URL for fetching transactions)")
+		errs = append(errs, "HELIUS_API_URL or HELIUS_API_KEY is required (your Helius HTTP API URL for fetching transactions)")
 	} else if !strings.HasPrefix(strings.ToLower(cfg.HeliusAPIURL), "https://") {
-		errs = append(errs, fmt.Sprintf("HELIUS_API_URL must start with https://, got %q", cfg.HeliusAPIURL))
+		errs = append(errs, fmt.Sprintf("HELIUS_API_URL must start with https://, got %q", redactURL(cfg.HeliusAPIURL)))
+	}
+
+	// Optional: HELIUS_RPC_URL (DAS getAssetBatch for token metadata). Derived
+	// from HELIUS_API_KEY or a helius-rpc.com HELIUS_WSS; "off" disables.
+	cfg.HeliusRPCURL = strings.TrimSpace(envSecret("HELIUS_RPC_URL", &errs))
+	switch {
+	case strings.EqualFold(cfg.HeliusRPCURL, "off"):
+		cfg.HeliusRPCURL = ""
+	case cfg.HeliusRPCURL != "":
+		if !strings.HasPrefix(strings.ToLower(cfg.HeliusRPCURL), "https://") {
+			errs = append(errs, fmt.Sprintf("HELIUS_RPC_URL must start with https://, got %q", redactURL(cfg.HeliusRPCURL)))
+		}
+	case cfg.HeliusAPIKey != "" && ok:
+		cfg.HeliusRPCURL = endpoints.rpc + url.QueryEscape(cfg.HeliusAPIKey)
+	case strings.Contains(cfg.HeliusWSS, ".helius-rpc.com"):
+		cfg.HeliusRPCURL = "https://" + strings.TrimPrefix(cfg.HeliusWSS, "wss://")
+	}
+
+	// With several keys, each endpoint that was derived (not overridden)
+	// gets one URL per key to spread subscriptions and API calls over.
+	if len(cfg.HeliusAPIKeys) > 1 && ok {
+		for _, k := range cfg.HeliusAPIKeys {
+			k = url.QueryEscape(k)
+			if cfg.HeliusWSS == endpoints.wss+url.QueryEscape(cfg.HeliusAPIKey) {
+				cfg.HeliusWSSPool = append(cfg.HeliusWSSPool, endpoints.wss+k)
+			}
+			if cfg.HeliusAPIURL == endpoints.api+url.QueryEscape(cfg.HeliusAPIKey) {
+				cfg.HeliusAPIPool = append(cfg.HeliusAPIPool, endpoints.api+k)
+			}
+			if cfg.HeliusRPCURL == endpoints.rpc+url.QueryEscape(cfg.HeliusAPIKey) {
+				cfg.HeliusRPCPool = append(cfg.HeliusRPCPool, endpoints.rpc+k)
+			}
+		}
 	}
 
 	// --- Optional Fields with Defaults ---
@@ -98,10 +271,136 @@ func Load() (Config, error) {
 		cfg.Commitment = commitment
 	}
 
-	// Optional: SOLANA_RPC_URL (default: public mainnet)
-	cfg.SolanaRPCURL = strings.TrimSpace(os.Getenv("SOLANA_RPC_URL"))
-	if cfg.SolanaRPCURL == "" {
-		cfg.SolanaRPCURL = "https://api.mainnet-beta.solana.com"
+	// Optional: FINALITY_RECHECK (default: false; no-op at finalized)
+	cfg.FinalityRecheck = envBool("FINALITY_RECHECK", false, &errs)
+
+	// Optional: SUBSCRIBE_MODE (default: logs)
+	cfg.SubscribeMode = strings.ToLower(strings.TrimSpace(os.Getenv("SUBSCRIBE_MODE")))
+	switch cfg.SubscribeMode {
+	case "":
+		cfg.SubscribeMode = "logs"
+	case "logs", "blocks", "geyser":
+	default:
+		errs = append(errs, fmt.Sprintf("SUBSCRIBE_MODE must be logs, blocks or geyser, got %q", cfg.SubscribeMode))
+	}
+
+	// GEYSER_URL (required with SUBSCRIBE_MODE=geyser), GEYSER_TOKEN
+	cfg.GeyserURL = strings.TrimSpace(os.Getenv("GEYSER_URL"))
+	cfg.GeyserToken = strings.TrimSpace(envSecret("GEYSER_TOKEN", &errs))
+	if cfg.SubscribeMode == "geyser" && cfg.GeyserURL == "" {
+		errs = append(errs, "GEYSER_URL is required with SUBSCRIBE_MODE=geyser (your Yellowstone gRPC endpoint, e.g. https://example.rpcpool.com)")
+	}
+
+	// Optional: SUBSCRIBE_RATE (default: 20/s; 0 disables)
+	cfg.SubscribeRate = 20
+	if v := strings.TrimSpace(os.Getenv("SUBSCRIBE_RATE")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			errs = append(errs, fmt.Sprintf("SUBSCRIBE_RATE must be a non-negative number of connections per second, got %q", v))
+		} else {
+			cfg.SubscribeRate = f
+		}
+	}
+
+	// Optional: WS_PING_INTERVAL (default: 20s), WS_READ_TIMEOUT (default:
+	// 60s), WS_MAX_MISSED_PONGS (default: 2), WS_IDLE_RECYCLE (default: 0, off)
+	cfg.WSPingInterval = envDuration("WS_PING_INTERVAL", 20*time.Second, &errs)
+	cfg.WSReadTimeout = envDuration("WS_READ_TIMEOUT", 60*time.Second, &errs)
+	if cfg.WSPingInterval < time.Second {
+		errs = append(errs, fmt.Sprintf("WS_PING_INTERVAL must be at least 1s, got %s", cfg.WSPingInterval))
+	} else if cfg.WSReadTimeout <= cfg.WSPingInterval {
+		errs = append(errs, fmt.Sprintf("WS_READ_TIMEOUT (%s) must be longer than WS_PING_INTERVAL (%s)", cfg.WSReadTimeout, cfg.WSPingInterval))
+	}
+	cfg.WSMaxMissedPongs = 2
+	if v := strings.TrimSpace(os.Getenv("WS_MAX_MISSED_PONGS")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			errs = append(errs, fmt.Sprintf("WS_MAX_MISSED_PONGS must be a positive integer, got %q", v))
+		} else {
+			cfg.WSMaxMissedPongs = n
+		}
+	}
+	cfg.WSIdleRecycle = envDuration("WS_IDLE_RECYCLE", 0, &errs)
+
+	// Optional: RECONNECT_INITIAL (default: 1s), RECONNECT_MAX (default:
+	// 30s), RECONNECT_FACTOR (default: 2), RECONNECT_JITTER (default: 0.2),
+	// RECONNECT_STORM_THRESHOLD (default: 20; 0 disables),
+	// RECONNECT_STORM_WINDOW (default: 10s)
+	cfg.ReconnectInitial = envDuration("RECONNECT_INITIAL", time.Second, &errs)
+	cfg.ReconnectMax = envDuration("RECONNECT_MAX", 30*time.Second, &errs)
+	if cfg.ReconnectInitial <= 0 || cfg.ReconnectMax < cfg.ReconnectInitial {
+		errs = append(errs, fmt.Sprintf("RECONNECT_INITIAL (%s) must be positive and at most RECONNECT_MAX (%s)", cfg.ReconnectInitial, cfg.ReconnectMax))
+	}
+	cfg.ReconnectFactor = 2
+	if v := strings.TrimSpace(os.Getenv("RECONNECT_FACTOR")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 1.1 {
+			errs = append(errs, fmt.Sprintf("RECONNECT_FACTOR must be a number of at least 1.1, got %q", v))
+		} else {
+			cfg.ReconnectFactor = f
+		}
+	}
+	cfg.ReconnectJitter = 0.2
+	if v := strings.TrimSpace(os.Getenv("RECONNECT_JITTER")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			errs = append(errs, fmt.Sprintf("RECONNECT_JITTER must be above 0 and at most 1, got %q", v))
+		} else {
+			cfg.ReconnectJitter = f
+		}
+	}
+	cfg.ReconnectStorm = 20
+	if v := strings.TrimSpace(os.Getenv("RECONNECT_STORM_THRESHOLD")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Sprintf("RECONNECT_STORM_THRESHOLD must be a non-negative integer, got %q", v))
+		} else {
+			cfg.ReconnectStorm = n
+		}
+	}
+	cfg.ReconnectStormWindow = envDuration("RECONNECT_STORM_WINDOW", 10*time.Second, &errs)
+	if cfg.ReconnectStormWindow == 0 {
+		errs = append(errs, "RECONNECT_STORM_WINDOW must be positive")
+	}
+
+	// Optional: BACKFILL_LIMIT (default: 100; 0 disables gap recovery)
+	cfg.BackfillLimit = 100
+	if v := strings.TrimSpace(os.Getenv("BACKFILL_LIMIT")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Sprintf("BACKFILL_LIMIT must be a non-negative integer, got %q", v))
+		} else {
+			cfg.BackfillLimit = n
+		}
+	}
+
+	// Optional: SOLANA_RPC_URL (default: public mainnet). Several
+	// comma-separated URLs form a pool that calls fail over across, weighted
+	// by SOLANA_RPC_WEIGHTS in the same order.
+	for _, u := range strings.Split(os.Getenv("SOLANA_RPC_URL"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			cfg.SolanaRPCURLs = append(cfg.SolanaRPCURLs, u)
+		}
+	}
+	if len(cfg.SolanaRPCURLs) == 0 {
+		cfg.SolanaRPCURLs = []string{"https://api.mainnet-beta.solana.com"}
+		if cfg.HeliusNetwork == "devnet" {
+			cfg.SolanaRPCURLs = []string{"https://api.devnet.solana.com"}
+		}
+	}
+	cfg.SolanaRPCURL = cfg.SolanaRPCURLs[0]
+	if v := strings.TrimSpace(os.Getenv("SOLANA_RPC_WEIGHTS")); v != "" {
+		for _, w := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(w))
+			if err != nil || n <= 0 {
+				errs = append(errs, fmt.Sprintf("SOLANA_RPC_WEIGHTS must be positive integers, got %q", w))
+				break
+			}
+			cfg.SolanaRPCWeights = append(cfg.SolanaRPCWeights, n)
+		}
+		if len(cfg.SolanaRPCWeights) != len(cfg.SolanaRPCURLs) {
+			errs = append(errs, fmt.Sprintf("SOLANA_RPC_WEIGHTS has %d weights for %d SOLANA_RPC_URL entries", len(cfg.SolanaRPCWeights), len(cfg.SolanaRPCURLs)))
+		}
 	}
 
 	// Optional: LOG_LEVEL (default: info)
@@ -117,6 +416,362 @@ func Load() (Config, error) {
 	}
 	cfg.LogLevel = logLevel
 
+	// Optional: MEV_DETECTION (default: false)
+	cfg.MEVDetection = envBool("MEV_DETECTION", false, &errs)
+
+	// Optional: BOT_RATE_LIMIT (default: 0 = disabled)
+	cfg.BotRateLimit = envDuration("BOT_RATE_LIMIT", 0, &errs)
+
+	// Optional: ANOMALY_FACTOR (default: 10; 0 disables)
+	cfg.AnomalyFactor = 10
+	if v := strings.TrimSpace(os.Getenv("ANOMALY_FACTOR")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || (f > 0 && f <= 1) {
+			errs = append(errs, fmt.Sprintf("ANOMALY_FACTOR must be 0 (off) or greater than 1, got %q", v))
+		} else {
+			cfg.AnomalyFactor = f
+		}
+	}
+
+	// Optional: COMPACT_AMOUNTS_ABOVE (default: 1e9; 0 disables)
+	cfg.CompactAbove = 1e9
+	if v := strings.TrimSpace(os.Getenv("COMPACT_AMOUNTS_ABOVE")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || (f > 0 && f < 1000) {
+			errs = append(errs, fmt.Sprintf("COMPACT_AMOUNTS_ABOVE must be 0 (off) or at least 1000, got %q", v))
+		} else {
+			cfg.CompactAbove = f
+		}
+	}
+
+	// Optional: EARLY_BUY_DETECTION (default: true)
+	cfg.EarlyBuy = envBool("EARLY_BUY_DETECTION", true, &errs)
+
+	// Optional: SELL_ROUTE_CHECK (default: true)
+	cfg.SellRouteCheck = envBool("SELL_ROUTE_CHECK", true, &errs)
+
+	// Optional: MARKET_DATA (default: true)
+	cfg.MarketData = envBool("MARKET_DATA", true, &errs)
+
+	// Optional: HOLDER_CONCENTRATION_PCT (default: 50, 0 = off)
+	cfg.HolderConcentration = 50
+	if v := strings.TrimSpace(os.Getenv("HOLDER_CONCENTRATION_PCT")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 100 {
+			errs = append(errs, fmt.Sprintf("HOLDER_CONCENTRATION_PCT must be a percentage between 0 and 100, got %q", v))
+		} else {
+			cfg.HolderConcentration = f
+		}
+	}
+
+	// Optional: SUPPRESS_AIRDROPS (default: true)
+	cfg.SuppressAirdrops = envBool("SUPPRESS_AIRDROPS", true, &errs)
+
+	// Optional: SEVERITY_USD (default: 1000,10000,100000)
+	cfg.SeverityUSD = [3]float64{1000, 10000, 100000}
+	if v := strings.TrimSpace(os.Getenv("SEVERITY_USD")); v != "" {
+		parts := strings.Split(v, ",")
+		var t [3]float64
+		ok := len(parts) == len(t)
+		for i := 0; ok && i < len(t); i++ {
+			f, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+			ok = err == nil && f > 0 && (i == 0 || f >= t[i-1])
+			t[i] = f
+		}
+		if ok {
+			cfg.SeverityUSD = t
+		} else {
+			errs = append(errs, fmt.Sprintf("SEVERITY_USD must be three ascending USD sizes for notice,important,critical, got %q", v))
+		}
+	}
+
+	// Optional: ANALYSIS_TIMEOUT (default: 20s) and the per-stage budgets
+	// ANALYSIS_FETCH_TIMEOUT (10s), ANALYSIS_METADATA_TIMEOUT (5s) and
+	// ANALYSIS_PRICE_TIMEOUT (5s). A stage never outlives the overall timeout.
+	cfg.AnalysisTimeout = envDuration("ANALYSIS_TIMEOUT", 20*time.Second, &errs)
+	cfg.AnalysisFetch = envDuration("ANALYSIS_FETCH_TIMEOUT", 10*time.Second, &errs)
+	cfg.AnalysisMetadata = envDuration("ANALYSIS_METADATA_TIMEOUT", 5*time.Second, &errs)
+	cfg.AnalysisPrices = envDuration("ANALYSIS_PRICE_TIMEOUT", 5*time.Second, &errs)
+	for _, v := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"ANALYSIS_TIMEOUT", cfg.AnalysisTimeout},
+		{"ANALYSIS_FETCH_TIMEOUT", cfg.AnalysisFetch},
+		{"ANALYSIS_METADATA_TIMEOUT", cfg.AnalysisMetadata},
+		{"ANALYSIS_PRICE_TIMEOUT", cfg.AnalysisPrices},
+	} {
+		if v.d == 0 {
+			errs = append(errs, fmt.Sprintf("%s must be positive", v.name))
+		}
+	}
+
+	// Optional: AUTOTRACK_MIN_USD (default: 0 = off), AUTOTRACK_MODE (offer|auto),
+	// AUTOTRACK_TRIAL (default: 48h), AUTOTRACK_IGNORE (comma-separated addresses)
+	if v := strings.TrimSpace(os.Getenv("AUTOTRACK_MIN_USD")); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			errs = append(errs, fmt.Sprintf("AUTOTRACK_MIN_USD must be a non-negative number, got %q", v))
+		} else {
+			cfg.AutoTrackMinUSD = f
+		}
+	}
+	cfg.AutoTrackMode = strings.ToLower(strings.TrimSpace(os.Getenv("AUTOTRACK_MODE")))
+	switch cfg.AutoTrackMode {
+	case "":
+		cfg.AutoTrackMode = "offer"
+	case "offer", "auto":
+	default:
+		errs = append(errs, fmt.Sprintf("AUTOTRACK_MODE must be offer or auto, got %q", cfg.AutoTrackMode))
+	}
+	cfg.AutoTrackTrial = envDuration("AUTOTRACK_TRIAL", 48*time.Hour, &errs)
+	if cfg.AutoTrackTrial == 0 {
+		cfg.AutoTrackTrial = 48 * time.Hour
+	}
+	for _, addr := range strings.Split(os.Getenv("AUTOTRACK_IGNORE"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.AutoTrackIgnore = append(cfg.AutoTrackIgnore, addr)
+		}
+	}
+
+	// Optional: TOKEN_LIST_INTERVAL (default: 6h; 0 disables)
+	cfg.TokenListInterval = envDuration("TOKEN_LIST_INTERVAL", 6*time.Hour, &errs)
+
+	// Optional: MARKETPLACE_LABELS (comma-separated programID=Name pairs)
+	for _, pair := range strings.Split(os.Getenv("MARKETPLACE_LABELS"), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		id, name, ok := strings.Cut(pair, "=")
+		id, name = strings.TrimSpace(id), strings.TrimSpace(name)
+		if !ok || id == "" || name == "" {
+			errs = append(errs, fmt.Sprintf("MARKETPLACE_LABELS entries must be programID=Name, got %q", pair))
+			continue
+		}
+		if cfg.MarketplaceLabels == nil {
+			cfg.MarketplaceLabels = make(map[string]string)
+		}
+		cfg.MarketplaceLabels[id] = name
+	}
+
+	// Optional: DB_MAINTENANCE_INTERVAL (default: 24h; 0 disables)
+	cfg.DBMaintenanceInterval = envDuration("DB_MAINTENANCE_INTERVAL", 24*time.Hour, &errs)
+
+	// Optional: retention (0 keeps forever)
+	cfg.HistoryRetention = envDuration("HISTORY_RETENTION", 90*24*time.Hour, &errs)
+	cfg.MetadataTTL = envDuration("METADATA_CACHE_TTL", 7*24*time.Hour, &errs)
+	cfg.MetadataNegativeTTL = envDuration("METADATA_NEGATIVE_TTL", 10*time.Minute, &errs)
+	cfg.PriceCacheTTL = envDuration("PRICE_CACHE_TTL", 10*time.Minute, &errs)
+	cfg.PruneInterval = envDuration("PRUNE_INTERVAL", time.Hour, &errs)
+	if cfg.PruneInterval == 0 {
+		errs = append(errs, "PRUNE_INTERVAL must be greater than 0")
+	}
+
+	// Optional: STORE_ENCRYPTION_KEY (or _FILE / _CMD)
+	cfg.StoreEncryptionKey = strings.TrimSpace(envSecret("STORE_ENCRYPTION_KEY", &errs))
+	if cfg.StoreEncryptionKey != "" && len(cfg.StoreEncryptionKey) < 16 {
+		errs = append(errs, "STORE_ENCRYPTION_KEY must be at least 16 characters (try: openssl rand -base64 32)")
+	}
+
+	// Optional: ADMIN_ADDR / GRPC_ADDR / ADMIN_TOKEN (admin HTTP and gRPC APIs)
+	cfg.AdminAddr = strings.TrimSpace(os.Getenv("ADMIN_ADDR"))
+	cfg.AdminToken = strings.TrimSpace(envSecret("ADMIN_TOKEN", &errs))
+	cfg.GRPCAddr = strings.TrimSpace(os.Getenv("GRPC_ADDR"))
+
+	// Optional: METRICS_ADDR (Prometheus counters at /metrics, no auth)
+	cfg.MetricsAddr = strings.TrimSpace(os.Getenv("METRICS_ADDR"))
+	if (cfg.AdminAddr != "" || cfg.GRPCAddr != "") && len(cfg.AdminToken) < 16 {
+		errs = append(errs, "ADMIN_TOKEN (at least 16 characters) is required when ADMIN_ADDR or GRPC_ADDR is set")
+	}
+
+	// Optional: SNAPSHOT_PATH / SNAPSHOT_INTERVAL (default: off / 5m)
+	cfg.SnapshotPath = strings.TrimSpace(os.Getenv("SNAPSHOT_PATH"))
+	cfg.SnapshotInterval = envDuration("SNAPSHOT_INTERVAL", 5*time.Minute, &errs)
+	if cfg.SnapshotPath != "" && cfg.SnapshotInterval == 0 {
+		errs = append(errs, "SNAPSHOT_INTERVAL must be greater than 0 when SNAPSHOT_PATH is set")
+	}
+
+	// Optional: TEMPLATES_DIR (alert templates, see telegram.LoadTemplates)
+	cfg.TemplatesDir = strings.TrimSpace(os.Getenv("TEMPLATES_DIR"))
+
+	// Optional: NETWORTH_INTERVAL (default: 1h, 0 = off)
+	cfg.NetWorthInterval = envDuration("NETWORTH_INTERVAL", time.Hour, &errs)
+
+	// Optional: DROPPED_ALERT_AFTER (default: 5m, 0 = off), DROPPED_ALERT_REPEAT (default: 30m, 0 = once)
+	cfg.DroppedAlertAfter = envDuration("DROPPED_ALERT_AFTER", 5*time.Minute, &errs)
+	cfg.DroppedAlertRepeat = envDuration("DROPPED_ALERT_REPEAT", 30*time.Minute, &errs)
+
+	// Optional: RPC_PROBE_INTERVAL (default: 1m, 0 = off)
+	cfg.ProbeInterval = envDuration("RPC_PROBE_INTERVAL", time.Minute, &errs)
+
+	// Optional: per-upstream HTTP clients (HELIUS_HTTP_*, RPC_HTTP_*, PRICE_HTTP_*;
+	// each _TIMEOUT, _RETRIES, _KEEPALIVE)
+	cfg.HeliusHTTP = envHTTPClient("HELIUS_HTTP", HTTPClient{Timeout: 20 * time.Second, KeepAlive: 90 * time.Second}, &errs)
+	cfg.RPCHTTP = envHTTPClient("RPC_HTTP", HTTPClient{Timeout: 20 * time.Second, KeepAlive: 90 * time.Second}, &errs)
+	cfg.PriceHTTP = envHTTPClient("PRICE_HTTP", HTTPClient{Timeout: 5 * time.Second, KeepAlive: 90 * time.Second}, &errs)
+
+	// Optional: EVENT_BUS_URL (nats://host/subject or redis://host/db?stream=key;
+	// tls:// and rediss:// over TLS)
+	cfg.EventBusURL = strings.TrimSpace(os.Getenv("EVENT_BUS_URL"))
+	if cfg.EventBusURL != "" {
+		switch scheme, _, _ := strings.Cut(cfg.EventBusURL, "://"); scheme {
+		case "nats", "tls", "redis", "rediss":
+		default:
+			errs = append(errs, "EVENT_BUS_URL must start with nats://, tls://, redis:// or rediss://")
+		}
+	}
+
+	// Optional: PLUGIN_DIR
+	cfg.PluginDir = strings.TrimSpace(os.Getenv("PLUGIN_DIR"))
+
+	// Optional: HOOK_COMMAND / HOOK_TIMEOUT / HOOK_CONCURRENCY
+	cfg.HookCommand = strings.TrimSpace(os.Getenv("HOOK_COMMAND"))
+	cfg.HookTimeout = envDuration("HOOK_TIMEOUT", 10*time.Second, &errs)
+	if cfg.HookCommand != "" && cfg.HookTimeout <= 0 {
+		errs = append(errs, "HOOK_TIMEOUT must be greater than 0")
+	}
+	cfg.HookConcurrency = 4
+	if v := strings.TrimSpace(os.Getenv("HOOK_CONCURRENCY")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			errs = append(errs, fmt.Sprintf("HOOK_CONCURRENCY must be a positive integer, got %q", v))
+		} else {
+			cfg.HookConcurrency = n
+		}
+	}
+
+	// Optional: DATABASE_URL / DB_MAX_CONNS (Postgres backend)
+	cfg.DatabaseURL = strings.TrimSpace(envSecret("DATABASE_URL", &errs))
+	cfg.DBMaxConns = 10
+	if v := strings.TrimSpace(os.Getenv("DB_MAX_CONNS")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			errs = append(errs, fmt.Sprintf("DB_MAX_CONNS must be a positive integer, got %q", v))
+		} else {
+			cfg.DBMaxConns = n
+		}
+	}
+	if cfg.DatabaseURL != "" && cfg.StoreEncryptionKey != "" {
+		errs = append(errs, "STORE_ENCRYPTION_KEY applies to the Bolt store only; unset it when DATABASE_URL is set")
+	}
+
+	// Optional: ALLOWED_USERS (comma-separated Telegram user/chat IDs)
+	for _, f := range strings.Split(os.Getenv("ALLOWED_USERS"), ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("ALLOWED_USERS must be comma-separated integers, got %q", f))
+			continue
+		}
+		cfg.AllowedUsers = append(cfg.AllowedUsers, id)
+	}
+
+	// Optional: SMTP_* / EMAIL_* (email sink)
+	cfg.SMTPHost = strings.TrimSpace(os.Getenv("SMTP_HOST"))
+	cfg.SMTPUser = strings.TrimSpace(os.Getenv("SMTP_USER"))
+	cfg.SMTPPassword = envSecret("SMTP_PASSWORD", &errs)
+	cfg.EmailFrom = strings.TrimSpace(os.Getenv("EMAIL_FROM"))
+	cfg.EmailRules = strings.TrimSpace(os.Getenv("EMAIL_RULES"))
+	for _, to := range strings.Split(os.Getenv("EMAIL_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			cfg.EmailTo = append(cfg.EmailTo, to)
+		}
+	}
+	cfg.SMTPPort = 587
+	if v := strings.TrimSpace(os.Getenv("SMTP_PORT")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Sprintf("SMTP_PORT must be a valid port, got %q", v))
+		} else {
+			cfg.SMTPPort = n
+		}
+	}
+	cfg.EmailDigestAt = -1
+	if v := strings.TrimSpace(os.Getenv("EMAIL_DIGEST_AT")); v != "" {
+		t, err := time.Parse("15:04", v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("EMAIL_DIGEST_AT must be HH:MM (UTC), got %q", v))
+		} else {
+			cfg.EmailDigestAt = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		}
+	}
+	if cfg.SMTPHost != "" && (cfg.EmailFrom == "" || len(cfg.EmailTo) == 0) {
+		errs = append(errs, "EMAIL_FROM and EMAIL_TO are required when SMTP_HOST is set")
+	}
+
+	// Optional: ANALYSIS_WORKERS (default: 8)
+	cfg.AnalysisWorkers = 8
+	if v := strings.TrimSpace(os.Getenv("ANALYSIS_WORKERS")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 256 {
+			errs = append(errs, fmt.Sprintf("ANALYSIS_WORKERS must be an integer between 1 and 256, got %q", v))
+		} else {
+			cfg.AnalysisWorkers = n
+		}
+	}
+
+	// Optional: CONFLUENCE_WALLETS (default: 3, 0 = off), CONFLUENCE_WINDOW (default: 1h)
+	cfg.ConfluenceWallets = 3
+	if v := strings.TrimSpace(os.Getenv("CONFLUENCE_WALLETS")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n == 1 {
+			errs = append(errs, fmt.Sprintf("CONFLUENCE_WALLETS must be 0 (off) or an integer of at least 2, got %q", v))
+		} else {
+			cfg.ConfluenceWallets = n
+		}
+	}
+	cfg.ConfluenceWindow = envDuration("CONFLUENCE_WINDOW", time.Hour, &errs)
+	if cfg.ConfluenceWindow == 0 {
+		cfg.ConfluenceWindow = time.Hour
+	}
+
+	// Optional: MAX_WALLETS_PER_USER (default: 50)
+	cfg.MaxWalletsPerUser = 50
+	if v := strings.TrimSpace(os.Getenv("MAX_WALLETS_PER_USER")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			errs = append(errs, fmt.Sprintf("MAX_WALLETS_PER_USER must be a positive integer, got %q", v))
+		} else {
+			cfg.MaxWalletsPerUser = n
+		}
+	}
+
+	// Optional: FIREHOSE_CHAT_ID, FIREHOSE_RATE_LIMIT (default: 3s)
+	if v := strings.TrimSpace(os.Getenv("FIREHOSE_CHAT_ID")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		switch {
+		case err != nil || id == 0:
+			errs = append(errs, fmt.Sprintf("FIREHOSE_CHAT_ID must be a valid integer, got %q", v))
+		case id == cfg.TelegramAdminChatID:
+			errs = append(errs, "FIREHOSE_CHAT_ID must differ from TELEGRAM_ADMIN_CHAT_ID")
+		default:
+			cfg.FirehoseChatID = id
+		}
+	}
+	cfg.FirehoseRateLimit = envDuration("FIREHOSE_RATE_LIMIT", 3*time.Second, &errs)
+
+	// Optional: DEBUG_CHAT_ID, DEBUG_ERROR_GAP (default: 5m)
+	if v := strings.TrimSpace(os.Getenv("DEBUG_CHAT_ID")); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id == 0 {
+			errs = append(errs, fmt.Sprintf("DEBUG_CHAT_ID must be a valid integer, got %q", v))
+		} else {
+			cfg.DebugChatID = id
+		}
+	}
+	cfg.DebugErrorGap = envDuration("DEBUG_ERROR_GAP", 5*time.Minute, &errs)
+
+	// Optional: ERROR_LOG_WINDOW (default: 5m, 0 = off)
+	cfg.ErrorLogWindow = envDuration("ERROR_LOG_WINDOW", 5*time.Minute, &errs)
+
+	// Optional: LIFECYCLE_NOTICES (default: true)
+	cfg.LifecycleNotices = envBool("LIFECYCLE_NOTICES", true, &errs)
+
+	// Optional: START_PAUSED (default: false)
+	cfg.StartPaused = envBool("START_PAUSED", false, &errs)
+
 	if len(errs) > 0 {
 		return Config{}, errors.New("config validation error:\n  - " + strings.Join(errs, "\n  - "))
 	}
@@ -124,6 +779,66 @@ func Load() (Config, error) {
 	return cfg, nil
 }
 
+// HTTPClient holds per-upstream HTTP client settings. Its layout matches
+// analyzer.ClientConfig so main can convert directly.
+type HTTPClient struct {
+	Timeout   time.Duration
+	Retries   int
+	KeepAlive time.Duration
+}
+
+// envHTTPClient reads <prefix>_TIMEOUT, <prefix>_RETRIES and
+// <prefix>_KEEPALIVE on top of def.
+func envHTTPClient(prefix string, def HTTPClient, errs *[]string) HTTPClient {
+	c := HTTPClient{
+		Timeout:   envDuration(prefix+"_TIMEOUT", def.Timeout, errs),
+		Retries:   def.Retries,
+		KeepAlive: envDuration(prefix+"_KEEPALIVE", def.KeepAlive, errs),
+	}
+	if v := strings.TrimSpace(os.Getenv(prefix + "_RETRIES")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 10 {
+			*errs = append(*errs, fmt.Sprintf("%s_RETRIES must be an integer between 0 and 10, got %q", prefix, v))
+		} else {
+			c.Retries = n
+		}
+	}
+	return c
+}
+
+func (c HTTPClient) String() string {
+	return fmt.Sprintf("%s/%dx/%s", c.Timeout, c.Retries, c.KeepAlive)
+}
+
+// envBool reads an optional boolean variable, recording a validation error
+// (and returning def) when it is set but unparsable.
+func envBool(name string, def bool, errs *[]string) bool {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		*errs = append(*errs, fmt.Sprintf("%s must be a boolean, got %q", name, v))
+		return def
+	}
+	return b
+}
+
+// envDuration reads an optional non-negative duration variable (e.g. "5m").
+func envDuration(name string, def time.Duration, errs *[]string) time.Duration {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		*errs = append(*errs, fmt.Sprintf("%s must be a non-negative duration (e.g. 5m, 24h), got %q", name, v))
+		return def
+	}
+	return d
+}
+
 // MustLoad is a convenience for main(): exit fast with a readable error.
 func MustLoad() Config {
 	cfg, err := Load()
@@ -139,15 +854,101 @@ func MustLoad() Config {
 // Useful to log at startup for quick debugging without leaking secrets.
 func (c Config) RedactedSummary() string {
 	return fmt.Sprintf(
-		"config{ commitment=%s, db=%s, helius_wss=%s, helius_api=%s, solana_rpc=%s, telegram_bot_token=%s, admin_chat_id=%d, log_level=%s }",
+		"config{ commitment=%s, finality_recheck=%t, subscribe_mode=%s, geyser=%s token=%s, subscribe_rate=%g, ws{ping=%s read_timeout=%s missed_pongs=%d idle_recycle=%s}, reconnect{initial=%s max=%s factor=%g jitter=%g storm=%d within %s}, backfill_limit=%d, db=%s, helius_network=%s, helius_keys=%d, helius_wss=%s, helius_api=%s, helius_rpc=%s, solana_rpc=%s weights=%v, telegram_bot_token=%s, admin_chat_id=%d, log_level=%s, mev_detection=%t, bot_rate_limit=%s, anomaly_factor=%g, compact_above=%g, early_buy=%t, sell_route_check=%t, holder_concentration=%g, market_data=%t, suppress_airdrops=%t, severity_usd=%v, analysis{timeout=%s fetch=%s metadata=%s prices=%s}, autotrack{min_usd=%g mode=%s trial=%s ignore=%d}, token_list=%s, marketplace_labels=%d, db_maintenance=%s, retention{history=%s metadata=%s metadata_negative=%s prices=%s every=%s}, store_encryption=%s, admin_addr=%q, admin_token=%s, grpc_addr=%q, metrics_addr=%q, config_file=%q, event_bus=%s, plugin_dir=%q, templates_dir=%q, hook=%t timeout=%s concurrency=%d, snapshot=%q every %s, database_url=%s, db_max_conns=%d, allowed_users=%d, max_wallets_per_user=%d, firehose=%d every %s, debug_chat=%d every %s, error_log_window=%s, lifecycle_notices=%t, start_paused=%t, smtp=%s:%d, email_to=%d, email_rules=%q, email_digest_at=%s, networth_every=%s, dropped_alert=%s repeat %s, probe_every=%s, workers=%d, confluence=%d within %s, http{helius=%s rpc=%s price=%s} }",
 		c.Commitment,
+		c.FinalityRecheck,
+		c.SubscribeMode,
+		c.GeyserURL,
+		redactToken(c.GeyserToken),
+		c.SubscribeRate,
+		c.WSPingInterval,
+		c.WSReadTimeout,
+		c.WSMaxMissedPongs,
+		c.WSIdleRecycle,
+		c.ReconnectInitial,
+		c.ReconnectMax,
+		c.ReconnectFactor,
+		c.ReconnectJitter,
+		c.ReconnectStorm,
+		c.ReconnectStormWindow,
+		c.BackfillLimit,
 		c.DBPath,
+		c.HeliusNetwork,
+		len(c.HeliusAPIKeys),
 		redactURL(c.HeliusWSS),
 		redactURL(c.HeliusAPIURL),
-		c.SolanaRPCURL, // Public RPCs don't need redaction
+		redactURL(c.HeliusRPCURL),
+		redactURLs(c.SolanaRPCURLs),
+		c.SolanaRPCWeights,
 		redactToken(c.TelegramBotToken),
 		c.TelegramAdminChatID,
 		c.LogLevel,
+		c.MEVDetection,
+		c.BotRateLimit,
+		c.AnomalyFactor,
+		c.CompactAbove,
+		c.EarlyBuy,
+		c.SellRouteCheck,
+		c.HolderConcentration,
+		c.MarketData,
+		c.SuppressAirdrops,
+		c.SeverityUSD,
+		c.AnalysisTimeout,
+		c.AnalysisFetch,
+		c.AnalysisMetadata,
+		c.AnalysisPrices,
+		c.AutoTrackMinUSD,
+		c.AutoTrackMode,
+		c.AutoTrackTrial,
+		len(c.AutoTrackIgnore),
+		c.TokenListInterval,
+		len(c.MarketplaceLabels),
+		c.DBMaintenanceInterval,
+		c.HistoryRetention,
+		c.MetadataTTL,
+		c.MetadataNegativeTTL,
+		c.PriceCacheTTL,
+		c.PruneInterval,
+		redactSecret(c.StoreEncryptionKey),
+		c.AdminAddr,
+		redactSecret(c.AdminToken),
+		c.GRPCAddr,
+		c.MetricsAddr,
+		c.ConfigFile,
+		redactDSN(c.EventBusURL),
+		c.PluginDir,
+		c.TemplatesDir,
+		c.HookCommand != "",
+		c.HookTimeout,
+		c.HookConcurrency,
+		c.SnapshotPath,
+		c.SnapshotInterval,
+		redactDSN(c.DatabaseURL),
+		c.DBMaxConns,
+		len(c.AllowedUsers),
+		c.MaxWalletsPerUser,
+		c.FirehoseChatID,
+		c.FirehoseRateLimit,
+		c.DebugChatID,
+		c.DebugErrorGap,
+		c.ErrorLogWindow,
+		c.LifecycleNotices,
+		c.StartPaused,
+		c.SMTPHost,
+		c.SMTPPort,
+		len(c.EmailTo),
+		c.EmailRules,
+		c.EmailDigestAt,
+		c.NetWorthInterval,
+		c.DroppedAlertAfter,
+		c.DroppedAlertRepeat,
+		c.ProbeInterval,
+		c.AnalysisWorkers,
+		c.ConfluenceWallets,
+		c.ConfluenceWindow,
+		c.HeliusHTTP,
+		c.RPCHTTP,
+		c.PriceHTTP,
 	)
 }
 
@@ -161,6 +962,31 @@ func redactToken(tok string) string {
 	return "***"
 }
 
+// redactSecret reports only whether a key or token is set; unlike
+// redactToken it shows no prefix.
+func redactSecret(s string) string {
+	if s == "" {
+		return "(empty)"
+	}
+	return "(set)"
+}
+
+// redactDSN hides the password in a postgres:// URL. Key=value DSNs are
+// not parsed and only reported as set.
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return "(empty)"
+	}
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" {
+		return "(set)"
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "***")
+	}
+	return u.String()
+}
+
 func redactURL(u string) string {
 	parts := strings.Split(u, "api-key=")
 	if len(parts) < 2 {
@@ -172,3 +998,12 @@ func redactURL(u string) string {
 	}
 	return strings.Replace(u, "api-key="+tail, "api-key=***", 1)
 }
+
+// redactURLs applies redactURL to each URL of a pool, comma-joined.
+func redactURLs(urls []string) string {
+	out := make([]string, len(urls))
+	for i, u := range urls {
+		out[i] = redactURL(u)
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// Keys lists every environment variable Load reads (secret _FILE/_CMD
+// variants aside). Each one is also available as a command-line flag.
+var Keys = []string{
+	"CONFIG_FILE",
+	"TELEGRAM_BOT_TOKEN", "TELEGRAM_ADMIN_CHAT_ID",
+	"HELIUS_API_KEY", "HELIUS_NETWORK", "HELIUS_WSS", "HELIUS_API_URL", "HELIUS_RPC_URL", "SOLANA_RPC_URL", "SOLANA_RPC_WEIGHTS",
+	"DB_PATH", "COMMITMENT", "FINALITY_RECHECK", "SUBSCRIBE_MODE", "GEYSER_URL", "GEYSER_TOKEN", "SUBSCRIBE_RATE",
+	"WS_PING_INTERVAL", "WS_READ_TIMEOUT", "WS_MAX_MISSED_PONGS", "WS_IDLE_RECYCLE",
+	"RECONNECT_INITIAL", "RECONNECT_MAX", "RECONNECT_FACTOR", "RECONNECT_JITTER", "RECONNECT_STORM_THRESHOLD", "RECONNECT_STORM_WINDOW", "BACKFILL_LIMIT", "LOG_LEVEL",
+	"MEV_DETECTION", "BOT_RATE_LIMIT", "ANOMALY_FACTOR", "COMPACT_AMOUNTS_ABOVE",
+	"EARLY_BUY_DETECTION", "SELL_ROUTE_CHECK", "HOLDER_CONCENTRATION_PCT", "MARKET_DATA", "SUPPRESS_AIRDROPS", "SEVERITY_USD",
+	"ANALYSIS_TIMEOUT", "ANALYSIS_FETCH_TIMEOUT", "ANALYSIS_METADATA_TIMEOUT", "ANALYSIS_PRICE_TIMEOUT",
+	"AUTOTRACK_MIN_USD", "AUTOTRACK_MODE", "AUTOTRACK_TRIAL", "AUTOTRACK_IGNORE",
+	"DB_MAINTENANCE_INTERVAL", "HISTORY_RETENTION", "METADATA_CACHE_TTL", "METADATA_NEGATIVE_TTL", "TOKEN_LIST_INTERVAL", "MARKETPLACE_LABELS", "PRICE_CACHE_TTL", "PRUNE_INTERVAL",
+	"STORE_ENCRYPTION_KEY",
+	"ADMIN_ADDR", "ADMIN_TOKEN", "GRPC_ADDR", "METRICS_ADDR", "SNAPSHOT_PATH", "SNAPSHOT_INTERVAL",
+	"TEMPLATES_DIR", "NETWORTH_INTERVAL", "DROPPED_ALERT_AFTER", "DROPPED_ALERT_REPEAT", "RPC_PROBE_INTERVAL",
+	"ANALYSIS_WORKERS", "CONFLUENCE_WALLETS", "CONFLUENCE_WINDOW",
+	"HELIUS_HTTP_TIMEOUT", "HELIUS_HTTP_RETRIES", "HELIUS_HTTP_KEEPALIVE",
+	"RPC_HTTP_TIMEOUT", "RPC_HTTP_RETRIES", "RPC_HTTP_KEEPALIVE",
+	"PRICE_HTTP_TIMEOUT", "PRICE_HTTP_RETRIES", "PRICE_HTTP_KEEPALIVE",
+	"EVENT_BUS_URL", "PLUGIN_DIR", "HOOK_COMMAND", "HOOK_TIMEOUT", "HOOK_CONCURRENCY",
+	"DATABASE_URL", "DB_MAX_CONNS", "ALLOWED_USERS", "MAX_WALLETS_PER_USER", "FIREHOSE_CHAT_ID", "FIREHOSE_RATE_LIMIT",
+	"DEBUG_CHAT_ID", "DEBUG_ERROR_GAP", "ERROR_LOG_WINDOW", "LIFECYCLE_NOTICES", "START_PAUSED",
+	"SMTP_HOST", "SMTP_PORT", "SMTP_USER", "SMTP_PASSWORD", "EMAIL_FROM", "EMAIL_TO", "EMAIL_RULES", "EMAIL_DIGEST_AT",
+}
+
+// secretKeys are the keys read through envSecret. As flags their values
+// show up in ps and shell history, which their usage text warns about.
+var secretKeys = map[string]bool{
+	"TELEGRAM_BOT_TOKEN": true, "HELIUS_API_KEY": true, "HELIUS_WSS": true, "HELIUS_API_URL": true, "HELIUS_RPC_URL": true,
+	"GEYSER_TOKEN": true, "STORE_ENCRYPTION_KEY": true, "ADMIN_TOKEN": true, "DATABASE_URL": true, "SMTP_PASSWORD": true,
+}
+
+// FlagName maps an environment variable to its flag: DB_PATH -> db-path.
+func FlagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// RegisterFlags defines a string flag for every key in Keys. After fs.Parse,
+// call the returned function to export the flags that were given into the
+// environment, so Load sees them with precedence
+// flags > environment > CONFIG_FILE (or .env) > defaults.
+//
+// A flag also blanks the key's _FILE and _CMD secret variants (see
+// envSecret), so it wins over them instead of conflicting. They are set
+// empty rather than unset so the config file can't fill them in again.
+func RegisterFlags(fs *flag.FlagSet) (apply func()) {
+	byFlag := make(map[string]string, len(Keys))
+	for _, key := range Keys {
+		name := FlagName(key)
+		byFlag[name] = key
+		usage := "overrides " + key
+		if secretKeys[key] {
+			usage += " (visible to other local users in ps; prefer " + key + "_FILE or " + key + "_CMD)"
+		}
+		fs.String(name, "", usage)
+	}
+	return func() {
+		fs.Visit(func(f *flag.Flag) {
+			if key, ok := byFlag[f.Name]; ok {
+				_ = os.Setenv(key, f.Value.String())
+				_ = os.Setenv(key+"_FILE", "")
+				_ = os.Setenv(key+"_CMD", "")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// secretCmdTimeout bounds a <NAME>_CMD secret lookup.
+const secretCmdTimeout = 30 * time.Second
+
+// envSecret reads a secret from exactly one of:
+//
+//	NAME       the value itself
+//	NAME_FILE  a file holding it (Docker/Kubernetes secrets)
+//	NAME_CMD   a shell command printing it, for secret managers, e.g.
+//	           vault kv get -field=token secret/solwatch
+//	           aws secretsmanager get-secret-value --secret-id solwatch --query SecretString --output text
+//
+// File and command output have trailing whitespace trimmed. Unset returns "".
+func envSecret(name string, errs *[]string) string {
+	val, hasVal := os.LookupEnv(name)
+	hasVal = hasVal && val != ""
+	path := strings.TrimSpace(os.Getenv(name + "_FILE"))
+	cmd := strings.TrimSpace(os.Getenv(name + "_CMD"))
+
+	set := 0
+	for _, ok := range []bool{hasVal, path != "", cmd != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		*errs = append(*errs, fmt.Sprintf("set only one of %s, %s_FILE and %s_CMD", name, name, name))
+		return ""
+	}
+
+	switch {
+	case path != "":
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			*errs = append(*errs, fmt.Sprintf("%s_FILE: %v", name, err))
+			return ""
+		}
+		return strings.TrimRight(string(raw), " \t\r\n")
+	case cmd != "":
+		ctx, cancel := context.WithTimeout(context.Background(), secretCmdTimeout)
+		defer cancel()
+		c := exec.CommandContext(ctx, "/bin/sh", "-c", cmd)
+		c.Stderr = os.Stderr
+		out, err := c.Output()
+		if err != nil {
+			// Don't echo the command: it may itself embed credentials.
+			*errs = append(*errs, fmt.Sprintf("%s_CMD failed: %v", name, err))
+			return ""
+		}
+		return strings.TrimRight(string(out), " \t\r\n")
+	}
+	return val
+}
@@ -0,0 +1,234 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	sendTimeout = time.Minute
+)
+
+// Config holds the SMTP and routing settings for the email sink.
+type Config struct {
+	Host     string
+	Port     int // 465 uses implicit TLS; anything else tries STARTTLS
+	User     string
+	Password string
+	From     string
+	To       []string
+	Rules    []Rule
+	// DigestAt switches to one daily email at this UTC time of day;
+	// negative sends each matching event immediately.
+	DigestAt time.Duration
+}
+
+// Notifier emails events that match its rules, one by one or as a daily
+// digest. The digest is held in memory and lost on restart.
+type Notifier struct {
+	cfg Config
+
+	mu      sync.Mutex
+	pending []events.Event
+}
+
+// New returns a Notifier for cfg.
+func New(cfg Config) *Notifier { return &Notifier{cfg: cfg} }
+
+// Run consumes bus until ctx is done.
+func (n *Notifier) Run(ctx context.Context, bus *events.Bus) {
+	ch, cancel := bus.Subscribe(64)
+	defer cancel()
+
+	var digest <-chan time.Time
+	if n.cfg.DigestAt >= 0 {
+		digest = time.After(untilNext(time.Now().UTC(), n.cfg.DigestAt))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !n.matches(ev) {
+				continue
+			}
+			if digest != nil {
+				n.mu.Lock()
+				n.pending = append(n.pending, ev)
+				n.mu.Unlock()
+				continue
+			}
+			subject := fmt.Sprintf("[solwatch] %s on %s", ev.Type, short(ev.Wallet))
+			n.send(subject, formatEvent(ev))
+		case <-digest:
+			n.flushDigest()
+			digest = time.After(untilNext(time.Now().UTC(), n.cfg.DigestAt))
+		}
+	}
+}
+
+func (n *Notifier) matches(ev events.Event) bool {
+	if len(n.cfg.Rules) == 0 {
+		return true
+	}
+	for _, r := range n.cfg.Rules {
+		if r.Match(ev) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notifier) flushDigest() {
+	n.mu.Lock()
+	list := n.pending
+	n.pending = nil
+	n.mu.Unlock()
+	if len(list) == 0 {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d matching transaction(s) in the last 24h:\n\n", len(list))
+	for _, ev := range list {
+		b.WriteString(formatEvent(ev))
+		b.WriteString("\n----\n\n")
+	}
+	n.send(fmt.Sprintf("[solwatch] daily digest: %d transaction(s)", len(list)), b.String())
+}
+
+func (n *Notifier) send(subject, body string) {
+	msg := "From: " + n.cfg.From + "\r\n" +
+		"To: " + strings.Join(n.cfg.To, ", ") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Date: " + time.Now().UTC().Format(time.RFC1123Z) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n\r\n" +
+		strings.ReplaceAll(body, "\n", "\r\n")
+	if err := n.sendMail([]byte(msg)); err != nil {
+		metrics.Inc("email.errors")
+		log.Printf("[email] send %q: %v", subject, err)
+		return
+	}
+	metrics.Inc("email.sent")
+}
+
+// sendMail delivers msg over one SMTP session. The whole exchange shares
+// sendTimeout so a stalled server can't hang the sender.
+func (n *Notifier) sendMail(msg []byte) error {
+	addr := net.JoinHostPort(n.cfg.Host, fmt.Sprint(n.cfg.Port))
+	var auth smtp.Auth
+	if n.cfg.User != "" {
+		auth = smtp.PlainAuth("", n.cfg.User, n.cfg.Password, n.cfg.Host)
+	}
+	tlsConfig := &tls.Config{ServerName: n.cfg.Host}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if n.cfg.Port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+	if n.cfg.Port != 465 {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range n.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func formatEvent(ev events.Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Wallet:  %s\nType:    %s (%s)\nTime:    %s\n", ev.Wallet, ev.Type, ev.Source, ev.Time.UTC().Format(time.RFC3339))
+	if ev.SizeUSD > 0 {
+		fmt.Fprintf(&b, "Size:    $%.2f\n", ev.SizeUSD)
+	}
+	if ev.Severity != "" {
+		fmt.Fprintf(&b, "Level:   %s\n", ev.Severity)
+	}
+	if ev.AnomalyFactor > 0 {
+		fmt.Fprintf(&b, "Unusual: %.1fx the wallet's median size\n", ev.AnomalyFactor)
+	}
+	for _, a := range ev.Sent {
+		fmt.Fprintf(&b, "Sent:    %g %s\n", a.Amount, a.Symbol)
+	}
+	for _, a := range ev.Received {
+		fmt.Fprintf(&b, "Recv:    %g %s\n", a.Amount, a.Symbol)
+	}
+	if ev.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", ev.Description)
+	}
+	fmt.Fprintf(&b, "\nhttps://solscan.io/tx/%s\n", ev.Signature)
+	return b.String()
+}
+
+// untilNext returns the wait from now until the next occurrence of the UTC
+// time of day at.
+func untilNext(now time.Time, at time.Duration) time.Duration {
+	next := now.Truncate(24 * time.Hour).Add(at)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next.Sub(now)
+}
+
+func short(addr string) string {
+	if len(addr) <= 8 {
+		return addr
+	}
+	return addr[:4] + "..." + addr[len(addr)-4:]
+}
@@ -0,0 +1,141 @@
+package email
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+)
+
+// Rule selects which events are emailed. All set conditions must hold; an
+// empty Rule matches everything.
+type Rule struct {
+	MinUSD      float64           // size_usd at least this much
+	AnomalyOnly bool              // only events flagged as unusual
+	MinSeverity analyzer.Severity // severity at least this level
+	Types       map[string]bool   // Helius transaction types, upper-case
+	Wallets     map[string]bool
+	Tokens      map[string]bool // mints on either leg, or only Side's leg
+	Side        string          // "buy" (received) or "sell" (sent); empty for any
+	Tags        map[string]bool // wallet tags (/tag), any of them
+}
+
+// Match reports whether ev satisfies r.
+func (r Rule) Match(ev events.Event) bool {
+	if r.MinUSD > 0 && ev.SizeUSD < r.MinUSD {
+		return false
+	}
+	if r.AnomalyOnly && ev.AnomalyFactor == 0 {
+		return false
+	}
+	if r.MinSeverity > analyzer.SeverityInfo {
+		if sev, err := analyzer.ParseSeverity(ev.Severity); err != nil || sev < r.MinSeverity {
+			return false
+		}
+	}
+	if len(r.Types) > 0 && !r.Types[strings.ToUpper(ev.Type)] {
+		return false
+	}
+	if len(r.Wallets) > 0 && !r.Wallets[ev.Wallet] {
+		return false
+	}
+	if len(r.Tags) > 0 && !r.anyTag(ev.Tags) {
+		return false
+	}
+	if len(r.Tokens) > 0 || r.Side != "" {
+		if _, ok := r.TokenLeg(ev); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// TokenLeg returns the first amount of ev that satisfies r's token and side
+// conditions.
+func (r Rule) TokenLeg(ev events.Event) (events.Amount, bool) {
+	for _, a := range r.legs(ev) {
+		if len(r.Tokens) == 0 || r.Tokens[a.Mint] {
+			return a, true
+		}
+	}
+	return events.Amount{}, false
+}
+
+func (r Rule) anyTag(tags []string) bool {
+	for _, t := range tags {
+		if r.Tags[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// legs returns the amounts Side looks at.
+func (r Rule) legs(ev events.Event) []events.Amount {
+	switch r.Side {
+	case "buy":
+		return ev.Received
+	case "sell":
+		return ev.Sent
+	}
+	return append(append([]events.Amount(nil), ev.Received...), ev.Sent...)
+}
+
+// ParseRules parses EMAIL_RULES: rules separated by ';', conditions within a
+// rule by ','. An event is emailed if any rule matches. Example:
+//
+//	min_usd=10000;anomaly;type=SWAP|TRANSFER,wallet=<addr>;token=<mint>,buy;severity=critical;tag=sniper|whale
+func ParseRules(s string) ([]Rule, error) {
+	var rules []Rule
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		var r Rule
+		for _, cond := range strings.Split(part, ",") {
+			key, val, _ := strings.Cut(strings.TrimSpace(cond), "=")
+			switch strings.ToLower(key) {
+			case "min_usd":
+				f, err := strconv.ParseFloat(val, 64)
+				if err != nil || f < 0 {
+					return nil, fmt.Errorf("min_usd must be a non-negative number, got %q", val)
+				}
+				r.MinUSD = f
+			case "anomaly":
+				r.AnomalyOnly = true
+			case "severity":
+				sev, err := analyzer.ParseSeverity(val)
+				if err != nil {
+					return nil, err
+				}
+				r.MinSeverity = sev
+			case "type":
+				r.Types = splitSet(strings.ToUpper(val))
+			case "wallet":
+				r.Wallets = splitSet(val)
+			case "token":
+				r.Tokens = splitSet(val)
+			case "tag":
+				r.Tags = splitSet(strings.ToLower(val))
+			case "buy", "sell":
+				r.Side = strings.ToLower(key)
+			default:
+				return nil, fmt.Errorf("unknown condition %q", key)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func splitSet(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, v := range strings.Split(s, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			out[v] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// Event is the structured, JSON-friendly form of one analysis result, for
+// programmatic consumers (gRPC, WebSocket, message buses).
+type Event struct {
+	Signature     string    `json:"signature"`
+	Wallet        string    `json:"wallet"`
+	Type          string    `json:"type"`
+	Source        string    `json:"source"`
+	Slot          uint64    `json:"slot"`
+	Time          time.Time `json:"time"`
+	Description   string    `json:"description,omitempty"`
+	Sent          []Amount  `json:"sent,omitempty"`
+	Received      []Amount  `json:"received,omitempty"`
+	SizeUSD       float64   `json:"size_usd,omitempty"`
+	Severity      string    `json:"severity"`
+	AnomalyFactor float64   `json:"anomaly_factor,omitempty"`
+	LaunchAgeSec  float64   `json:"launch_age_sec,omitempty"`
+	RentSOL       float64   `json:"rent_sol,omitempty"`
+	Airdrop       bool      `json:"airdrop,omitempty"`
+	Programs      []string  `json:"programs,omitempty"`
+	Notes         []string  `json:"notes,omitempty"` // HTML fragments
+	Tags          []string  `json:"tags,omitempty"`  // the wallet's tags (/tag)
+}
+
+// Amount is one token leg of an Event.
+type Amount struct {
+	Mint   string  `json:"mint"`
+	Symbol string  `json:"symbol"`
+	Amount float64 `json:"amount"`
+	USD    float64 `json:"usd,omitempty"`
+}
+
+// FromResult converts an analysis result into an Event.
+func FromResult(r *analyzer.Result) Event {
+	conv := func(list []analyzer.Amount) []Amount {
+		out := make([]Amount, 0, len(list))
+		for _, a := range list {
+			out = append(out, Amount{Mint: a.Mint, Symbol: a.Symbol, Amount: a.Amount, USD: a.USD})
+		}
+		return out
+	}
+	return Event{
+		Signature:     r.Signature,
+		Wallet:        r.Wallet,
+		Type:          r.Type,
+		Source:        r.Source,
+		Slot:          r.Slot,
+		Time:          r.Timestamp,
+		Description:   r.Description,
+		Sent:          conv(r.Sent),
+		Received:      conv(r.Received),
+		SizeUSD:       r.SizeUSD,
+		Severity:      r.Severity.String(),
+		AnomalyFactor: r.AnomalyFactor,
+		LaunchAgeSec:  r.LaunchAge.Seconds(),
+		RentSOL:       r.RentSOL,
+		Airdrop:       r.Airdrop,
+		Programs:      r.Programs,
+		Notes:         r.Notes,
+		Tags:          r.Tags,
+	}
+}
+
+// Bus fans events out to any number of subscribers. Publish never blocks:
+// a subscriber that falls behind loses events (counted as events.dropped)
+// rather than stalling alert delivery.
+type Bus struct {
+	mu   sync.RWMutex
+	next int
+	subs map[int]chan Event
+}
+
+// NewBus returns an empty Bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]chan Event)}
+}
+
+// Subscribe returns a channel of future events and a cancel func that must be
+// called to release it. buffer sizes the channel.
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.subs[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers e to every subscriber that has room for it.
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			metrics.Inc("events.dropped")
+		}
+	}
+	metrics.Inc("events.published")
+}
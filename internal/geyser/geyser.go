@@ -0,0 +1,204 @@
+// Package geyser subscribes to wallets through a Yellowstone (Geyser) gRPC
+// endpoint, as offered by Triton, Helius and others, instead of WebSocket
+// logsSubscribe. It implements tracker.Source.
+package geyser
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/0xsamyy/solwatch-v2/internal/tracker"
+)
+
+const subscribeMethod = "/geyser.Geyser/Subscribe"
+
+// Source is a Yellowstone gRPC tracker.Source.
+type Source struct {
+	// URL is the endpoint, e.g. https://example.rpcpool.com:443; https
+	// (the default without a scheme) uses TLS, http doesn't.
+	URL string
+	// Token is sent as the x-token header when set.
+	Token string
+	// PingInterval is the gap between application-level pings, which also
+	// keep load balancers from closing a quiet stream (default 20s).
+	PingInterval time.Duration
+	// ReadTimeout drops a stream that delivered nothing, pongs included,
+	// this long (default 60s).
+	ReadTimeout time.Duration
+
+	once    sync.Once
+	conn    *grpc.ClientConn
+	connErr error
+}
+
+var _ tracker.Source = (*Source)(nil)
+
+func (s *Source) Name() string { return "geyser" }
+
+// dial creates the shared client connection; gRPC reconnects it on its own.
+func (s *Source) dial() (*grpc.ClientConn, error) {
+	s.once.Do(func() {
+		target, secure, err := parseURL(s.URL)
+		if err != nil {
+			s.connErr = err
+			return
+		}
+		creds := insecure.NewCredentials()
+		if secure {
+			creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		}
+		s.conn, s.connErr = grpc.NewClient(target,
+			grpc.WithTransportCredentials(creds),
+			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(64<<20)),
+		)
+	})
+	return s.conn, s.connErr
+}
+
+// parseURL returns the host:port to dial and whether to use TLS.
+func parseURL(raw string) (target string, secure bool, err error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		if u, err = url.Parse("https://" + raw); err != nil || u.Host == "" {
+			return "", false, fmt.Errorf("geyser: invalid URL %q", raw)
+		}
+	}
+	switch u.Scheme {
+	case "https", "grpcs":
+		secure = true
+	case "http", "grpc":
+	default:
+		return "", false, fmt.Errorf("geyser: unsupported scheme %q", u.Scheme)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if secure {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), secure, nil
+}
+
+// Subscribe implements tracker.Source.
+func (s *Source) Subscribe(ctx context.Context, commitment string, wallets []string) (tracker.SourceStream, error) {
+	if len(wallets) == 0 {
+		return nil, errors.New("geyser: no wallets to subscribe") // would stream the whole chain
+	}
+	conn, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	ping, timeout := s.PingInterval, s.ReadTimeout
+	if ping <= 0 {
+		ping = 20 * time.Second
+	}
+	if timeout <= ping {
+		timeout = max(60*time.Second, 2*ping)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	if s.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-token", s.Token)
+	}
+	cs, err := conn.NewStream(ctx, &grpc.StreamDesc{StreamName: "Subscribe", ServerStreams: true, ClientStreams: true},
+		subscribeMethod, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	st := &stream{cs: cs, cancel: cancel, commitment: commitment, timeout: timeout}
+	if err := st.send(subscribeRequest(commitment, wallets)); err != nil {
+		cancel()
+		return nil, err
+	}
+	st.last.Store(time.Now().UnixNano())
+	go st.keepAlive(ctx, ping, timeout)
+	return st, nil
+}
+
+// stream is an open Subscribe call.
+type stream struct {
+	cs         grpc.ClientStream
+	cancel     context.CancelFunc
+	commitment string
+	timeout    time.Duration
+	sendMu     sync.Mutex
+	last       atomic.Int64 // unix nanos of the last message received
+	stale      atomic.Bool  // set when keepAlive gave up on the stream
+}
+
+func (st *stream) send(f frame) error {
+	st.sendMu.Lock()
+	defer st.sendMu.Unlock()
+	return st.cs.SendMsg(&f)
+}
+
+// keepAlive pings every interval and cancels the stream once nothing has
+// arrived for timeout.
+func (st *stream) keepAlive(ctx context.Context, interval, timeout time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	var id int32
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if time.Since(time.Unix(0, st.last.Load())) >= timeout {
+			st.stale.Store(true)
+			st.cancel()
+			return
+		}
+		id++
+		if err := st.send(pingRequest(id)); err != nil {
+			st.cancel()
+			return
+		}
+	}
+}
+
+// Recv implements tracker.SourceStream.
+func (st *stream) Recv() (tracker.SourceTx, error) {
+	for {
+		var f frame
+		if err := st.cs.RecvMsg(&f); err != nil {
+			st.cancel()
+			if st.stale.Load() {
+				return tracker.SourceTx{}, fmt.Errorf("geyser: nothing received for %s", st.timeout)
+			}
+			return tracker.SourceTx{}, err
+		}
+		st.last.Store(time.Now().UnixNano())
+		tx, ok, err := decodeUpdate(f)
+		if err != nil {
+			st.cancel()
+			return tracker.SourceTx{}, err
+		}
+		if ok {
+			return tx, nil
+		}
+	}
+}
+
+// Update implements tracker.SourceStream; Yellowstone replaces the filters
+// of a stream with those of each new request.
+func (st *stream) Update(wallets []string) error {
+	if len(wallets) == 0 {
+		return errors.New("geyser: no wallets to subscribe")
+	}
+	return st.send(subscribeRequest(st.commitment, wallets))
+}
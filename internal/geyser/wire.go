@@ -0,0 +1,208 @@
+package geyser
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/0xsamyy/solwatch-v2/internal/tracker"
+)
+
+// Yellowstone's messages are encoded by hand with protowire rather than
+// generated from geyser.proto, which would pull in the whole schema for the
+// handful of fields used here. Field numbers follow geyser.proto and
+// solana-storage.proto of yellowstone-grpc.
+
+// frame is one raw protobuf message on the stream.
+type frame []byte
+
+// rawCodec passes frames through untouched. It is named "proto" so the
+// content type stays application/grpc+proto.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, fmt.Errorf("geyser: cannot marshal %T", v)
+	}
+	return *f, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return fmt.Errorf("geyser: cannot unmarshal into %T", v)
+	}
+	*f = append((*f)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
+
+// CommitmentLevel values.
+var commitments = map[string]uint64{"processed": 0, "confirmed": 1, "finalized": 2}
+
+const filterName = "solwatch"
+
+// subscribeRequest encodes a SubscribeRequest for the successful, non-vote
+// transactions mentioning one of wallets:
+//
+//	transactions = 3 (map<string, SubscribeRequestFilterTransactions>)
+//	commitment   = 6
+func subscribeRequest(commitment string, wallets []string) frame {
+	var filter []byte
+	filter = protowire.AppendTag(filter, 1, protowire.VarintType) // vote
+	filter = protowire.AppendVarint(filter, 0)
+	filter = protowire.AppendTag(filter, 2, protowire.VarintType) // failed
+	filter = protowire.AppendVarint(filter, 0)
+	for _, w := range wallets {
+		filter = protowire.AppendTag(filter, 3, protowire.BytesType) // account_include
+		filter = protowire.AppendString(filter, w)
+	}
+
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+	entry = protowire.AppendString(entry, filterName)
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, filter)
+
+	var req []byte
+	req = protowire.AppendTag(req, 3, protowire.BytesType)
+	req = protowire.AppendBytes(req, entry)
+	req = protowire.AppendTag(req, 6, protowire.VarintType)
+	req = protowire.AppendVarint(req, commitments[commitment])
+	return req
+}
+
+// pingRequest encodes a SubscribeRequest carrying only ping = 9 {id = 1},
+// which the server answers with a pong without touching the filters.
+func pingRequest(id int32) frame {
+	var ping []byte
+	ping = protowire.AppendTag(ping, 1, protowire.VarintType)
+	ping = protowire.AppendVarint(ping, uint64(id))
+	var req []byte
+	req = protowire.AppendTag(req, 9, protowire.BytesType)
+	req = protowire.AppendBytes(req, ping)
+	return req
+}
+
+var errMalformed = errors.New("geyser: malformed message")
+
+// eachField calls fn for every field of the message b; fn gets the raw
+// value for bytes fields and the number for varints. Other wire types are
+// skipped.
+func eachField(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error) error {
+	for len(b) > 0 {
+		num, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			return errMalformed
+		}
+		b = b[l:]
+		var err error
+		switch typ {
+		case protowire.BytesType:
+			v, l := protowire.ConsumeBytes(b)
+			if l < 0 {
+				return errMalformed
+			}
+			err, b = fn(num, typ, v, 0), b[l:]
+		case protowire.VarintType:
+			n, l := protowire.ConsumeVarint(b)
+			if l < 0 {
+				return errMalformed
+			}
+			err, b = fn(num, typ, nil, n), b[l:]
+		default:
+			l := protowire.ConsumeFieldValue(num, typ, b)
+			if l < 0 {
+				return errMalformed
+			}
+			b = b[l:]
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeUpdate reads a SubscribeUpdate. ok is false for anything but a
+// transaction = 4 update (pings, pongs and other kinds).
+func decodeUpdate(b []byte) (tx tracker.SourceTx, ok bool, err error) {
+	err = eachField(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if num != 4 || typ != protowire.BytesType {
+			return nil
+		}
+		ok = true
+		return decodeTransactionUpdate(v, &tx)
+	})
+	return tx, ok, err
+}
+
+// decodeTransactionUpdate reads SubscribeUpdateTransaction:
+// transaction = 1 (SubscribeUpdateTransactionInfo), slot = 2.
+func decodeTransactionUpdate(b []byte, tx *tracker.SourceTx) error {
+	return eachField(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			return decodeTransactionInfo(v, tx)
+		case num == 2 && typ == protowire.VarintType:
+			tx.Slot = n
+		}
+		return nil
+	})
+}
+
+// decodeTransactionInfo reads SubscribeUpdateTransactionInfo: signature =
+// 1, transaction = 3 (Transaction), meta = 4 (TransactionStatusMeta).
+func decodeTransactionInfo(b []byte, tx *tracker.SourceTx) error {
+	return eachField(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			tx.Signature = base58.Encode(v)
+		case 3:
+			return decodeTransaction(v, tx)
+		case 4:
+			return decodeMeta(v, tx)
+		}
+		return nil
+	})
+}
+
+// decodeTransaction reads the account_keys = 2 of Transaction.message = 2.
+func decodeTransaction(b []byte, tx *tracker.SourceTx) error {
+	return eachField(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if num != 2 || typ != protowire.BytesType {
+			return nil
+		}
+		return eachField(v, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+			if num == 2 && typ == protowire.BytesType {
+				tx.Accounts = append(tx.Accounts, base58.Encode(v))
+			}
+			return nil
+		})
+	})
+}
+
+// decodeMeta reads TransactionStatusMeta: log_messages = 6 and the
+// loaded_writable_addresses = 12 / loaded_readonly_addresses = 13 of
+// address lookup tables.
+func decodeMeta(b []byte, tx *tracker.SourceTx) error {
+	return eachField(b, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 6:
+			tx.Logs = append(tx.Logs, string(v))
+		case 12, 13:
+			tx.Accounts = append(tx.Accounts, base58.Encode(v))
+		}
+		return nil
+	})
+}
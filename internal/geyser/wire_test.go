@@ -0,0 +1,151 @@
+package geyser
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// transactionUpdate is a SubscribeUpdate for filter "solwatch" laid out as
+// Yellowstone sends it: transaction = 4 {transaction = 1 {signature,
+// is_vote, transaction {signatures, message {header, account_keys,
+// recent_blockhash, instructions, versioned}}, meta {fee, pre_balances,
+// log_messages, loaded_writable_addresses, compute_units_consumed},
+// index}, slot = 312345678}, created_at = 11. Fields the decoder doesn't
+// use are there to check they are skipped.
+const transactionUpdate = "0a08736f6c776174636822c0030ab7030a40e069ef2fbe487298f22ffecbed57a45de555160d7152f1f9c3269cde05e428fcaa5e622f727c45cbd5446af507ae6394cab8e3a4e0787289698822583b479c0f10001abe010a40e069ef2fbe487298f22ffecbed57a45de555160d7152f1f9c3269cde05e428fcaa5e622f727c45cbd5446af507ae6394cab8e3a4e0787289698822583b479c0f127a0a040801180112207e8c088760bfde1dddcf32c17f209b8242ee52aaf131facd88d0ea2c6d0b06f212200479d55bf231c06eee74c56ece681507fdb1b2dea3f48e5102b1cda256bc138f1a200000000000000000000000000000000000000000000000000000000000000000220a08011201001a03010203280122ad011088271a04c0843d0a323e50726f6772616d204a5550364c6b625a626a53316a4b4b77617064484e7937347a635a33744c555a6f6935514e79565461563420696e766f6b65205b315d323b50726f6772616d204a5550364c6b625a626a53316a4b4b77617064484e7937347a635a33744c555a6f6935514e79565461563420737563636573736220069b8857feab8184fb687f634618c035dac439dc1aeb3b5598a0f00000000001800188a401282a10ce88f894015a0b0880f09dc70610c0a9d33a"
+
+// pongUpdate is a SubscribeUpdate carrying pong = 9 {id = 7}.
+const pongUpdate = "0a08736f6c77617463684a020807"
+
+func frameHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDecodeUpdate(t *testing.T) {
+	tx, ok, err := decodeUpdate(frameHex(t, transactionUpdate))
+	if err != nil || !ok {
+		t.Fatalf("decodeUpdate: ok=%v err=%v", ok, err)
+	}
+	if want := "5VERv8NMvzbJMEkV8xnrLkEaWRtSz9CosKDYjCJjBRnbJLgp8uirBgmQpjKhoR4tjF3ZpRzrFmBV6UjKdiSZkQUW"; tx.Signature != want {
+		t.Errorf("signature = %s, want %s", tx.Signature, want)
+	}
+	if tx.Slot != 312345678 {
+		t.Errorf("slot = %d, want 312345678", tx.Slot)
+	}
+	wantAccounts := []string{
+		"9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM",
+		"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4",
+		"So11111111111111111111111111111111111111112", // from the lookup table
+	}
+	if !reflect.DeepEqual(tx.Accounts, wantAccounts) {
+		t.Errorf("accounts = %v, want %v", tx.Accounts, wantAccounts)
+	}
+	wantLogs := []string{
+		"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 invoke [1]",
+		"Program JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4 success",
+	}
+	if !reflect.DeepEqual(tx.Logs, wantLogs) {
+		t.Errorf("logs = %q, want %q", tx.Logs, wantLogs)
+	}
+}
+
+func TestDecodeUpdateSkipsOtherKinds(t *testing.T) {
+	for name, f := range map[string][]byte{
+		"pong":  frameHex(t, pongUpdate),
+		"empty": nil,
+	} {
+		if _, ok, err := decodeUpdate(f); ok || err != nil {
+			t.Errorf("%s: ok=%v err=%v, want a skipped update", name, ok, err)
+		}
+	}
+}
+
+func TestDecodeUpdateMalformed(t *testing.T) {
+	f := frameHex(t, transactionUpdate)
+	for _, n := range []int{1, 12, len(f) / 2, len(f) - 1} {
+		if _, _, err := decodeUpdate(f[:n]); err == nil {
+			t.Errorf("truncated to %d bytes: no error", n)
+		}
+	}
+}
+
+// fields collects the top-level fields of b by number: varints as uint64,
+// bytes fields as []byte.
+func fields(t *testing.T, b []byte) map[protowire.Number][]any {
+	t.Helper()
+	out := make(map[protowire.Number][]any)
+	err := eachField(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		if typ == protowire.VarintType {
+			out[num] = append(out[num], n)
+		} else {
+			out[num] = append(out[num], v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestSubscribeRequest(t *testing.T) {
+	wallets := []string{"9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM", "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"}
+	req := fields(t, subscribeRequest("finalized", wallets))
+	if got := req[6]; len(got) != 1 || got[0] != uint64(2) {
+		t.Errorf("commitment = %v, want [2]", got)
+	}
+	if len(req[3]) != 1 {
+		t.Fatalf("transactions = %d entries, want 1", len(req[3]))
+	}
+	entry := fields(t, req[3][0].([]byte))
+	if got := string(entry[1][0].([]byte)); got != filterName {
+		t.Errorf("filter name = %q, want %q", got, filterName)
+	}
+	filter := fields(t, entry[2][0].([]byte))
+	if !reflect.DeepEqual(filter[1], []any{uint64(0)}) || !reflect.DeepEqual(filter[2], []any{uint64(0)}) {
+		t.Errorf("vote = %v, failed = %v, want both false", filter[1], filter[2])
+	}
+	var include []string
+	for _, v := range filter[3] {
+		include = append(include, string(v.([]byte)))
+	}
+	if !reflect.DeepEqual(include, wallets) {
+		t.Errorf("account_include = %v, want %v", include, wallets)
+	}
+}
+
+func TestPingRequest(t *testing.T) {
+	req := fields(t, pingRequest(7))
+	if len(req) != 1 || len(req[9]) != 1 {
+		t.Fatalf("request = %v, want only ping", req)
+	}
+	if ping := fields(t, req[9][0].([]byte)); !reflect.DeepEqual(ping[1], []any{uint64(7)}) {
+		t.Errorf("ping id = %v, want 7", ping[1])
+	}
+}
+
+func TestRawCodec(t *testing.T) {
+	in := frame(frameHex(t, transactionUpdate))
+	raw, err := rawCodec{}.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out frame
+	if err := (rawCodec{}).Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Error("frame changed on the way through the codec")
+	}
+	if _, err := (rawCodec{}).Marshal("not a frame"); err == nil {
+		t.Error("marshal of a non-frame: no error")
+	}
+}
@@ -0,0 +1,189 @@
+// Package grpcapi serves the Solwatch gRPC service described in
+// proto/solwatch/v1/solwatch.proto.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/0xsamyy/solwatch-v2 --go-grpc_out=../.. --go-grpc_opt=module=github.com/0xsamyy/solwatch-v2 solwatch/v1/solwatch.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/grpcapi/solwatchpb"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ServiceName is the fully-qualified gRPC service name.
+const ServiceName = "solwatch.v1.Solwatch"
+
+// Wallets is the wallet management the API exposes. Calls act on behalf of
+// the configured owner (the admin's watchlist).
+type Wallets interface {
+	WalletsOf(ctx context.Context, owner int64) ([]string, error)
+	TrackAs(ctx context.Context, owner int64, addr string) error
+	UntrackAs(ctx context.Context, owner int64, addr string) error
+}
+
+// Server implements the Solwatch service.
+type Server struct {
+	solwatchpb.UnimplementedSolwatchServer
+
+	addr    string
+	token   string
+	owner   int64
+	wallets Wallets
+	bus     *events.Bus
+}
+
+// New builds the gRPC server. Every call must carry
+// "authorization: Bearer <token>" metadata.
+func New(addr, token string, owner int64, wallets Wallets, bus *events.Bus) *Server {
+	return &Server{addr: addr, token: token, owner: owner, wallets: wallets, bus: bus}
+}
+
+// Run serves until ctx is done.
+func (s *Server) Run(ctx context.Context) {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		log.Printf("[grpc] listen %s: %v", s.addr, err)
+		return
+	}
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			if err := s.authorize(ctx); err != nil {
+				return nil, err
+			}
+			return h(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			if err := s.authorize(ss.Context()); err != nil {
+				return err
+			}
+			return h(srv, ss)
+		}),
+	)
+	solwatchpb.RegisterSolwatchServer(srv, s)
+
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+	log.Printf("[grpc] listening on %s", s.addr)
+	if err := srv.Serve(lis); err != nil {
+		log.Printf("[grpc] serve: %v", err)
+	}
+}
+
+func (s *Server) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var got string
+	if v := md.Get("authorization"); len(v) > 0 {
+		got = strings.TrimPrefix(v[0], "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return nil
+}
+
+// ListWallets returns the owner's watchlist.
+func (s *Server) ListWallets(ctx context.Context, _ *solwatchpb.Empty) (*solwatchpb.WalletList, error) {
+	list, err := s.wallets.WalletsOf(ctx, s.owner)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &solwatchpb.WalletList{Addresses: list}, nil
+}
+
+// TrackWallet adds a wallet to the owner's watchlist.
+func (s *Server) TrackWallet(ctx context.Context, req *solwatchpb.WalletRequest) (*solwatchpb.Empty, error) {
+	if err := s.wallets.TrackAs(ctx, s.owner, strings.TrimSpace(req.GetAddress())); err != nil {
+		return nil, status.Error(walletCode(err), err.Error())
+	}
+	return &solwatchpb.Empty{}, nil
+}
+
+// UntrackWallet removes a wallet from the owner's watchlist.
+func (s *Server) UntrackWallet(ctx context.Context, req *solwatchpb.WalletRequest) (*solwatchpb.Empty, error) {
+	if err := s.wallets.UntrackAs(ctx, s.owner, strings.TrimSpace(req.GetAddress())); err != nil {
+		return nil, status.Error(walletCode(err), err.Error())
+	}
+	return &solwatchpb.Empty{}, nil
+}
+
+// walletCode maps a watchlist error to its gRPC status code.
+func walletCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, store.ErrWalletAlreadyTracked):
+		return codes.AlreadyExists
+	case errors.Is(err, store.ErrWalletNotFound):
+		return codes.NotFound
+	}
+	return codes.InvalidArgument
+}
+
+// SubscribeEvents streams analysis results until the client goes away.
+func (s *Server) SubscribeEvents(req *solwatchpb.SubscribeRequest, stream grpc.ServerStreamingServer[solwatchpb.Event]) error {
+	filter := make(map[string]bool, len(req.GetWallets()))
+	for _, w := range req.GetWallets() {
+		filter[w] = true
+	}
+	ch, cancel := s.bus.Subscribe(64)
+	defer cancel()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if len(filter) > 0 && !filter[ev.Wallet] {
+				continue
+			}
+			if err := stream.Send(eventMessage(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eventMessage converts ev to its wire form.
+func eventMessage(ev events.Event) *solwatchpb.Event {
+	amounts := func(list []events.Amount) []*solwatchpb.Amount {
+		out := make([]*solwatchpb.Amount, 0, len(list))
+		for _, a := range list {
+			out = append(out, &solwatchpb.Amount{Mint: a.Mint, Symbol: a.Symbol, Amount: a.Amount, Usd: a.USD})
+		}
+		return out
+	}
+	return &solwatchpb.Event{
+		Signature:     ev.Signature,
+		Wallet:        ev.Wallet,
+		Type:          ev.Type,
+		Source:        ev.Source,
+		Slot:          ev.Slot,
+		Time:          timestamppb.New(ev.Time),
+		Description:   ev.Description,
+		Sent:          amounts(ev.Sent),
+		Received:      amounts(ev.Received),
+		SizeUsd:       ev.SizeUSD,
+		Severity:      ev.Severity,
+		AnomalyFactor: ev.AnomalyFactor,
+		LaunchAgeSec:  ev.LaunchAgeSec,
+		RentSol:       ev.RentSOL,
+		Airdrop:       ev.Airdrop,
+		Programs:      ev.Programs,
+		Notes:         ev.Notes,
+		Tags:          ev.Tags,
+	}
+}
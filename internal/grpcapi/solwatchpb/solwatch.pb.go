@@ -0,0 +1,551 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: solwatch/v1/solwatch.proto
+
+package solwatchpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_solwatch_v1_solwatch_proto_rawDescGZIP(), []int{0}
+}
+
+type WalletRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletRequest) Reset() {
+	*x = WalletRequest{}
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletRequest) ProtoMessage() {}
+
+func (x *WalletRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletRequest.ProtoReflect.Descriptor instead.
+func (*WalletRequest) Descriptor() ([]byte, []int) {
+	return file_solwatch_v1_solwatch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WalletRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type WalletList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addresses     []string               `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletList) Reset() {
+	*x = WalletList{}
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletList) ProtoMessage() {}
+
+func (x *WalletList) ProtoReflect() protoreflect.Message {
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletList.ProtoReflect.Descriptor instead.
+func (*WalletList) Descriptor() ([]byte, []int) {
+	return file_solwatch_v1_solwatch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WalletList) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters the stream; empty means every tracked wallet.
+	Wallets       []string `protobuf:"bytes,1,rep,name=wallets,proto3" json:"wallets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_solwatch_v1_solwatch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SubscribeRequest) GetWallets() []string {
+	if x != nil {
+		return x.Wallets
+	}
+	return nil
+}
+
+// Event is one analyzed transaction.
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signature     string                 `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	Wallet        string                 `protobuf:"bytes,2,opt,name=wallet,proto3" json:"wallet,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	Slot          uint64                 `protobuf:"varint,5,opt,name=slot,proto3" json:"slot,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=time,proto3" json:"time,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	Sent          []*Amount              `protobuf:"bytes,8,rep,name=sent,proto3" json:"sent,omitempty"`
+	Received      []*Amount              `protobuf:"bytes,9,rep,name=received,proto3" json:"received,omitempty"`
+	SizeUsd       float64                `protobuf:"fixed64,10,opt,name=size_usd,json=sizeUsd,proto3" json:"size_usd,omitempty"`
+	Severity      string                 `protobuf:"bytes,11,opt,name=severity,proto3" json:"severity,omitempty"`
+	AnomalyFactor float64                `protobuf:"fixed64,12,opt,name=anomaly_factor,json=anomalyFactor,proto3" json:"anomaly_factor,omitempty"`
+	LaunchAgeSec  float64                `protobuf:"fixed64,13,opt,name=launch_age_sec,json=launchAgeSec,proto3" json:"launch_age_sec,omitempty"`
+	RentSol       float64                `protobuf:"fixed64,14,opt,name=rent_sol,json=rentSol,proto3" json:"rent_sol,omitempty"`
+	Airdrop       bool                   `protobuf:"varint,15,opt,name=airdrop,proto3" json:"airdrop,omitempty"`
+	Programs      []string               `protobuf:"bytes,16,rep,name=programs,proto3" json:"programs,omitempty"`
+	Notes         []string               `protobuf:"bytes,17,rep,name=notes,proto3" json:"notes,omitempty"` // HTML fragments
+	Tags          []string               `protobuf:"bytes,18,rep,name=tags,proto3" json:"tags,omitempty"`   // the wallet's tags (/tag)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_solwatch_v1_solwatch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Event) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *Event) GetWallet() string {
+	if x != nil {
+		return x.Wallet
+	}
+	return ""
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Event) GetSlot() uint64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Event) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Event) GetSent() []*Amount {
+	if x != nil {
+		return x.Sent
+	}
+	return nil
+}
+
+func (x *Event) GetReceived() []*Amount {
+	if x != nil {
+		return x.Received
+	}
+	return nil
+}
+
+func (x *Event) GetSizeUsd() float64 {
+	if x != nil {
+		return x.SizeUsd
+	}
+	return 0
+}
+
+func (x *Event) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Event) GetAnomalyFactor() float64 {
+	if x != nil {
+		return x.AnomalyFactor
+	}
+	return 0
+}
+
+func (x *Event) GetLaunchAgeSec() float64 {
+	if x != nil {
+		return x.LaunchAgeSec
+	}
+	return 0
+}
+
+func (x *Event) GetRentSol() float64 {
+	if x != nil {
+		return x.RentSol
+	}
+	return 0
+}
+
+func (x *Event) GetAirdrop() bool {
+	if x != nil {
+		return x.Airdrop
+	}
+	return false
+}
+
+func (x *Event) GetPrograms() []string {
+	if x != nil {
+		return x.Programs
+	}
+	return nil
+}
+
+func (x *Event) GetNotes() []string {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+func (x *Event) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// Amount is one token leg of an Event.
+type Amount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mint          string                 `protobuf:"bytes,1,opt,name=mint,proto3" json:"mint,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Usd           float64                `protobuf:"fixed64,4,opt,name=usd,proto3" json:"usd,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Amount) Reset() {
+	*x = Amount{}
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Amount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Amount) ProtoMessage() {}
+
+func (x *Amount) ProtoReflect() protoreflect.Message {
+	mi := &file_solwatch_v1_solwatch_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Amount.ProtoReflect.Descriptor instead.
+func (*Amount) Descriptor() ([]byte, []int) {
+	return file_solwatch_v1_solwatch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Amount) GetMint() string {
+	if x != nil {
+		return x.Mint
+	}
+	return ""
+}
+
+func (x *Amount) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Amount) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Amount) GetUsd() float64 {
+	if x != nil {
+		return x.Usd
+	}
+	return 0
+}
+
+var File_solwatch_v1_solwatch_proto protoreflect.FileDescriptor
+
+const file_solwatch_v1_solwatch_proto_rawDesc = "" +
+	"\n" +
+	"\x1asolwatch/v1/solwatch.proto\x12\vsolwatch.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\")\n" +
+	"\rWalletRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\"*\n" +
+	"\n" +
+	"WalletList\x12\x1c\n" +
+	"\taddresses\x18\x01 \x03(\tR\taddresses\",\n" +
+	"\x10SubscribeRequest\x12\x18\n" +
+	"\awallets\x18\x01 \x03(\tR\awallets\"\xa8\x04\n" +
+	"\x05Event\x12\x1c\n" +
+	"\tsignature\x18\x01 \x01(\tR\tsignature\x12\x16\n" +
+	"\x06wallet\x18\x02 \x01(\tR\x06wallet\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x12\n" +
+	"\x04slot\x18\x05 \x01(\x04R\x04slot\x12.\n" +
+	"\x04time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12'\n" +
+	"\x04sent\x18\b \x03(\v2\x13.solwatch.v1.AmountR\x04sent\x12/\n" +
+	"\breceived\x18\t \x03(\v2\x13.solwatch.v1.AmountR\breceived\x12\x19\n" +
+	"\bsize_usd\x18\n" +
+	" \x01(\x01R\asizeUsd\x12\x1a\n" +
+	"\bseverity\x18\v \x01(\tR\bseverity\x12%\n" +
+	"\x0eanomaly_factor\x18\f \x01(\x01R\ranomalyFactor\x12$\n" +
+	"\x0elaunch_age_sec\x18\r \x01(\x01R\flaunchAgeSec\x12\x19\n" +
+	"\brent_sol\x18\x0e \x01(\x01R\arentSol\x12\x18\n" +
+	"\aairdrop\x18\x0f \x01(\bR\aairdrop\x12\x1a\n" +
+	"\bprograms\x18\x10 \x03(\tR\bprograms\x12\x14\n" +
+	"\x05notes\x18\x11 \x03(\tR\x05notes\x12\x12\n" +
+	"\x04tags\x18\x12 \x03(\tR\x04tags\"^\n" +
+	"\x06Amount\x12\x12\n" +
+	"\x04mint\x18\x01 \x01(\tR\x04mint\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x10\n" +
+	"\x03usd\x18\x04 \x01(\x01R\x03usd2\x8e\x02\n" +
+	"\bSolwatch\x12:\n" +
+	"\vListWallets\x12\x12.solwatch.v1.Empty\x1a\x17.solwatch.v1.WalletList\x12=\n" +
+	"\vTrackWallet\x12\x1a.solwatch.v1.WalletRequest\x1a\x12.solwatch.v1.Empty\x12?\n" +
+	"\rUntrackWallet\x12\x1a.solwatch.v1.WalletRequest\x1a\x12.solwatch.v1.Empty\x12F\n" +
+	"\x0fSubscribeEvents\x12\x1d.solwatch.v1.SubscribeRequest\x1a\x12.solwatch.v1.Event0\x01B<Z:github.com/0xsamyy/solwatch-v2/internal/grpcapi/solwatchpbb\x06proto3"
+
+var (
+	file_solwatch_v1_solwatch_proto_rawDescOnce sync.Once
+	file_solwatch_v1_solwatch_proto_rawDescData []byte
+)
+
+func file_solwatch_v1_solwatch_proto_rawDescGZIP() []byte {
+	file_solwatch_v1_solwatch_proto_rawDescOnce.Do(func() {
+		file_solwatch_v1_solwatch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_solwatch_v1_solwatch_proto_rawDesc), len(file_solwatch_v1_solwatch_proto_rawDesc)))
+	})
+	return file_solwatch_v1_solwatch_proto_rawDescData
+}
+
+var file_solwatch_v1_solwatch_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_solwatch_v1_solwatch_proto_goTypes = []any{
+	(*Empty)(nil),                 // 0: solwatch.v1.Empty
+	(*WalletRequest)(nil),         // 1: solwatch.v1.WalletRequest
+	(*WalletList)(nil),            // 2: solwatch.v1.WalletList
+	(*SubscribeRequest)(nil),      // 3: solwatch.v1.SubscribeRequest
+	(*Event)(nil),                 // 4: solwatch.v1.Event
+	(*Amount)(nil),                // 5: solwatch.v1.Amount
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+}
+var file_solwatch_v1_solwatch_proto_depIdxs = []int32{
+	6, // 0: solwatch.v1.Event.time:type_name -> google.protobuf.Timestamp
+	5, // 1: solwatch.v1.Event.sent:type_name -> solwatch.v1.Amount
+	5, // 2: solwatch.v1.Event.received:type_name -> solwatch.v1.Amount
+	0, // 3: solwatch.v1.Solwatch.ListWallets:input_type -> solwatch.v1.Empty
+	1, // 4: solwatch.v1.Solwatch.TrackWallet:input_type -> solwatch.v1.WalletRequest
+	1, // 5: solwatch.v1.Solwatch.UntrackWallet:input_type -> solwatch.v1.WalletRequest
+	3, // 6: solwatch.v1.Solwatch.SubscribeEvents:input_type -> solwatch.v1.SubscribeRequest
+	2, // 7: solwatch.v1.Solwatch.ListWallets:output_type -> solwatch.v1.WalletList
+	0, // 8: solwatch.v1.Solwatch.TrackWallet:output_type -> solwatch.v1.Empty
+	0, // 9: solwatch.v1.Solwatch.UntrackWallet:output_type -> solwatch.v1.Empty
+	4, // 10: solwatch.v1.Solwatch.SubscribeEvents:output_type -> solwatch.v1.Event
+	7, // [7:11] is the sub-list for method output_type
+	3, // [3:7] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_solwatch_v1_solwatch_proto_init() }
+func file_solwatch_v1_solwatch_proto_init() {
+	if File_solwatch_v1_solwatch_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_solwatch_v1_solwatch_proto_rawDesc), len(file_solwatch_v1_solwatch_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_solwatch_v1_solwatch_proto_goTypes,
+		DependencyIndexes: file_solwatch_v1_solwatch_proto_depIdxs,
+		MessageInfos:      file_solwatch_v1_solwatch_proto_msgTypes,
+	}.Build()
+	File_solwatch_v1_solwatch_proto = out.File
+	file_solwatch_v1_solwatch_proto_goTypes = nil
+	file_solwatch_v1_solwatch_proto_depIdxs = nil
+}
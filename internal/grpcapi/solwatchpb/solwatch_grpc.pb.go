@@ -0,0 +1,245 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: solwatch/v1/solwatch.proto
+
+package solwatchpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Solwatch_ListWallets_FullMethodName     = "/solwatch.v1.Solwatch/ListWallets"
+	Solwatch_TrackWallet_FullMethodName     = "/solwatch.v1.Solwatch/TrackWallet"
+	Solwatch_UntrackWallet_FullMethodName   = "/solwatch.v1.Solwatch/UntrackWallet"
+	Solwatch_SubscribeEvents_FullMethodName = "/solwatch.v1.Solwatch/SubscribeEvents"
+)
+
+// SolwatchClient is the client API for Solwatch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Solwatch manages the admin's watchlist and streams analysis results.
+// Every call needs "authorization: Bearer <ADMIN_TOKEN>" metadata.
+type SolwatchClient interface {
+	ListWallets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*WalletList, error)
+	TrackWallet(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*Empty, error)
+	UntrackWallet(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*Empty, error)
+	SubscribeEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type solwatchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSolwatchClient(cc grpc.ClientConnInterface) SolwatchClient {
+	return &solwatchClient{cc}
+}
+
+func (c *solwatchClient) ListWallets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*WalletList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalletList)
+	err := c.cc.Invoke(ctx, Solwatch_ListWallets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *solwatchClient) TrackWallet(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Solwatch_TrackWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *solwatchClient) UntrackWallet(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Solwatch_UntrackWallet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *solwatchClient) SubscribeEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Solwatch_ServiceDesc.Streams[0], Solwatch_SubscribeEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Solwatch_SubscribeEventsClient = grpc.ServerStreamingClient[Event]
+
+// SolwatchServer is the server API for Solwatch service.
+// All implementations must embed UnimplementedSolwatchServer
+// for forward compatibility.
+//
+// Solwatch manages the admin's watchlist and streams analysis results.
+// Every call needs "authorization: Bearer <ADMIN_TOKEN>" metadata.
+type SolwatchServer interface {
+	ListWallets(context.Context, *Empty) (*WalletList, error)
+	TrackWallet(context.Context, *WalletRequest) (*Empty, error)
+	UntrackWallet(context.Context, *WalletRequest) (*Empty, error)
+	SubscribeEvents(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedSolwatchServer()
+}
+
+// UnimplementedSolwatchServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSolwatchServer struct{}
+
+func (UnimplementedSolwatchServer) ListWallets(context.Context, *Empty) (*WalletList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWallets not implemented")
+}
+func (UnimplementedSolwatchServer) TrackWallet(context.Context, *WalletRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TrackWallet not implemented")
+}
+func (UnimplementedSolwatchServer) UntrackWallet(context.Context, *WalletRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UntrackWallet not implemented")
+}
+func (UnimplementedSolwatchServer) SubscribeEvents(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedSolwatchServer) mustEmbedUnimplementedSolwatchServer() {}
+func (UnimplementedSolwatchServer) testEmbeddedByValue()                  {}
+
+// UnsafeSolwatchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SolwatchServer will
+// result in compilation errors.
+type UnsafeSolwatchServer interface {
+	mustEmbedUnimplementedSolwatchServer()
+}
+
+func RegisterSolwatchServer(s grpc.ServiceRegistrar, srv SolwatchServer) {
+	// If the following call pancis, it indicates UnimplementedSolwatchServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Solwatch_ServiceDesc, srv)
+}
+
+func _Solwatch_ListWallets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SolwatchServer).ListWallets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Solwatch_ListWallets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SolwatchServer).ListWallets(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Solwatch_TrackWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SolwatchServer).TrackWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Solwatch_TrackWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SolwatchServer).TrackWallet(ctx, req.(*WalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Solwatch_UntrackWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SolwatchServer).UntrackWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Solwatch_UntrackWallet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SolwatchServer).UntrackWallet(ctx, req.(*WalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Solwatch_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SolwatchServer).SubscribeEvents(m, &grpc.GenericServerStream[SubscribeRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Solwatch_SubscribeEventsServer = grpc.ServerStreamingServer[Event]
+
+// Solwatch_ServiceDesc is the grpc.ServiceDesc for Solwatch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Solwatch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "solwatch.v1.Solwatch",
+	HandlerType: (*SolwatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListWallets",
+			Handler:    _Solwatch_ListWallets_Handler,
+		},
+		{
+			MethodName: "TrackWallet",
+			Handler:    _Solwatch_TrackWallet_Handler,
+		},
+		{
+			MethodName: "UntrackWallet",
+			Handler:    _Solwatch_UntrackWallet_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _Solwatch_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "solwatch/v1/solwatch.proto",
+}
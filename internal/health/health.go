@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
 	"github.com/0xsamyy/solwatch-v2/internal/tracker"
 )
 
@@ -17,7 +18,8 @@ type Health struct {
 	tm  *tracker.Manager
 	st  WalletLister
 
-	// Future: counters/metrics (e.g., reconnects, errors) can be injected here.
+	// Prober, when set, contributes upstream endpoint status to reports.
+	Prober *Prober
 }
 
 // New returns a Health aggregator bound to the tracker manager and store.
@@ -34,10 +36,20 @@ type Report struct {
 	Open    int      `json:"open_subscriptions"`
 	Dropped []string `json:"dropped_subscriptions"`
 
+	// Last logs notification per tracked wallet; zero = none since start.
+	LastEvents map[string]time.Time `json:"last_events"`
+
+	// Per-wallet subscription counters (notifications, reconnects, errors).
+	Subscribers map[string]tracker.SubscriberStats `json:"subscribers"`
+
 	// From persistent store
 	TrackedPersisted int `json:"tracked_in_store"`
 
-	// Future: add counters like Reconnects, Errors, etc.
+	// Upstream RPC/API probe results (empty when probing is off).
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+
+	// Process-wide counters from the metrics package (e.g. prune.history).
+	Counters map[string]int64 `json:"counters"`
 }
 
 // Snapshot gathers a point-in-time report. It does not block for long operations.
@@ -56,6 +68,10 @@ func (h *Health) Snapshot(ctx context.Context) Report {
 		Tracked:          tracked,
 		Open:             open,
 		Dropped:          append([]string(nil), dropped...), // defensive copy
+		LastEvents:       h.tm.LastEvents(),
+		Subscribers:      h.tm.SubscriberStats(),
 		TrackedPersisted: persistedCount,
+		Endpoints:        h.Prober.Statuses(),
+		Counters:         metrics.Snapshot(),
 	}
 }
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+)
+
+// Endpoint is an upstream the Prober checks. JSON-RPC endpoints are sent a
+// getHealth call; anything else gets a plain GET.
+type Endpoint struct {
+	Name    string
+	URL     string
+	JSONRPC bool
+}
+
+// EndpointStatus is the outcome of the most recent probe of one endpoint.
+type EndpointStatus struct {
+	Name      string        `json:"name"`
+	OK        bool          `json:"ok"`
+	Latency   time.Duration `json:"latency_ns"`
+	Error     string        `json:"error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Prober periodically checks upstream endpoints so /health can tell an
+// upstream outage apart from a bug in the analyzer.
+type Prober struct {
+	endpoints []Endpoint
+	client    *http.Client
+
+	mu     sync.RWMutex
+	status map[string]EndpointStatus
+}
+
+// NewProber returns a Prober for the given endpoints; empty URLs are skipped.
+func NewProber(endpoints ...Endpoint) *Prober {
+	p := &Prober{
+		client: &http.Client{Timeout: 10 * time.Second},
+		status: make(map[string]EndpointStatus),
+	}
+	for _, e := range endpoints {
+		if strings.TrimSpace(e.URL) != "" {
+			p.endpoints = append(p.endpoints, e)
+		}
+	}
+	return p
+}
+
+// Run probes every endpoint immediately and then every interval until ctx
+// is done.
+func (p *Prober) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.probeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Statuses returns the latest results in configuration order. Endpoints not
+// probed yet are omitted.
+func (p *Prober) Statuses() []EndpointStatus {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		if st, ok := p.status[e.Name]; ok {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+func (p *Prober) probeAll(ctx context.Context) {
+	for _, e := range p.endpoints {
+		st := p.Probe(ctx, e)
+		if !st.OK {
+			log.Printf("[health] %s probe failed: %s", e.Name, st.Error)
+		}
+		p.mu.Lock()
+		prev, seen := p.status[e.Name]
+		p.status[e.Name] = st
+		p.mu.Unlock()
+		if seen && !prev.OK && st.OK {
+			log.Printf("[health] %s recovered (%s)", e.Name, st.Latency.Round(time.Millisecond))
+		}
+	}
+}
+
+// Probe checks one endpoint once.
+func (p *Prober) Probe(ctx context.Context, e Endpoint) EndpointStatus {
+	st := EndpointStatus{Name: e.Name, CheckedAt: time.Now().UTC()}
+	if e.JSONRPC {
+		start := time.Now()
+		err := solanarpc.New(e.URL, p.client).GetHealth(ctx)
+		st.Latency = time.Since(start)
+		var rpcErr *solanarpc.RPCError
+		var statusErr *solanarpc.StatusError
+		switch {
+		case errors.As(err, &rpcErr):
+			// getHealth reports a lagging node as a JSON-RPC error.
+			st.Error = "unhealthy: " + rpcErr.Error()
+		case errors.As(err, &statusErr):
+			st.Error = fmt.Sprintf("HTTP %d", statusErr.Code)
+		case err != nil:
+			// Never echo the URL back: it usually carries the API key.
+			st.Error = strings.ReplaceAll(err.Error(), e.URL, e.Name)
+		default:
+			st.OK = true
+		}
+		return st
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.URL, nil)
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	st.Latency = time.Since(start)
+	if err != nil {
+		st.Error = strings.ReplaceAll(err.Error(), e.URL, e.Name)
+		return st
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusTooManyRequests:
+		st.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	default:
+		st.OK = true
+	}
+	return st
+}
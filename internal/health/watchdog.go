@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// DroppedSub is a subscription that should be open but is not.
+type DroppedSub struct {
+	Address string
+	Since   time.Time
+}
+
+// Alert is what the Watchdog hands to its notifier. Repeat counts how many
+// times the current outage has been reported before (0 = first alert);
+// Resolved marks the all-clear once every subscription is back.
+type Alert struct {
+	Dropped  []DroppedSub
+	Repeat   int
+	Resolved bool
+	Outage   time.Duration // time since the oldest drop
+}
+
+// Watchdog polls the tracker and pushes alerts when subscriptions stay
+// dropped for longer than After, repeating every Repeat until resolved.
+type Watchdog struct {
+	H      *Health
+	After  time.Duration // a subscription must be dropped this long to alert
+	Repeat time.Duration // 0 = alert once per outage
+	Notify func(ctx context.Context, a Alert)
+
+	since   map[string]time.Time
+	alerted int
+	last    time.Time
+}
+
+// Run blocks until ctx is done.
+func (w *Watchdog) Run(ctx context.Context) {
+	poll := w.After / 4
+	if poll > 30*time.Second {
+		poll = 30 * time.Second
+	}
+	if poll < time.Second {
+		poll = time.Second
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	w.since = make(map[string]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.check(ctx, now)
+		}
+	}
+}
+
+func (w *Watchdog) check(ctx context.Context, now time.Time) {
+	_, _, dropped := w.H.tm.Stats()
+
+	current := make(map[string]bool, len(dropped))
+	for _, addr := range dropped {
+		current[addr] = true
+		if _, ok := w.since[addr]; !ok {
+			w.since[addr] = now
+		}
+	}
+	for addr := range w.since {
+		if !current[addr] {
+			delete(w.since, addr)
+		}
+	}
+
+	var stale []DroppedSub
+	var oldest time.Time
+	for _, addr := range dropped {
+		since := w.since[addr]
+		if now.Sub(since) < w.After {
+			continue
+		}
+		stale = append(stale, DroppedSub{Address: addr, Since: since})
+		if oldest.IsZero() || since.Before(oldest) {
+			oldest = since
+		}
+	}
+
+	if len(stale) == 0 {
+		if w.alerted > 0 {
+			log.Printf("[health] all subscriptions recovered")
+			w.Notify(ctx, Alert{Resolved: true, Repeat: w.alerted})
+			w.alerted = 0
+		}
+		return
+	}
+	if w.alerted > 0 && (w.Repeat <= 0 || now.Sub(w.last) < w.Repeat) {
+		return
+	}
+
+	log.Printf("[health] %d subscription(s) dropped for over %s (alert #%d)", len(stale), w.After, w.alerted+1)
+	metrics.Inc("health.dropped_alerts")
+	w.Notify(ctx, Alert{Dropped: stale, Repeat: w.alerted, Outage: now.Sub(oldest)})
+	w.alerted++
+	w.last = now
+}
@@ -0,0 +1,85 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// Runner executes an external command for every event, with the event JSON
+// on stdin. The command runs through `sh -c`, so pipes and arguments work.
+type Runner struct {
+	Command     string
+	Timeout     time.Duration
+	Concurrency int
+}
+
+// Run consumes bus until ctx is done. At most Concurrency commands run at
+// once; while all slots are busy new events queue in the bus subscription
+// and are dropped by the bus once that fills up.
+func (r Runner) Run(ctx context.Context, bus *events.Bus) {
+	ch, cancel := bus.Subscribe(64)
+	defer cancel()
+
+	slots := make(chan struct{}, max(r.Concurrency, 1))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(sig string) {
+				defer func() { <-slots }()
+				defer util.Recover("hooks")
+				r.exec(ctx, sig, payload)
+			}(ev.Signature)
+		}
+	}
+}
+
+func (r Runner) exec(ctx context.Context, sig string, payload []byte) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", r.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second // don't hang on grandchildren holding the pipes
+
+	err := cmd.Run()
+	if err == nil {
+		metrics.Inc("hooks.ok")
+		return
+	}
+	metrics.Inc("hooks.failed")
+	if ctx.Err() == context.DeadlineExceeded {
+		log.Printf("[hooks] %s: timed out after %s", sig, r.Timeout)
+		return
+	}
+	msg := strings.TrimSpace(out.String())
+	if len(msg) > 300 {
+		msg = msg[:300] + "..."
+	}
+	log.Printf("[hooks] %s: %v: %s", sig, err, msg)
+}
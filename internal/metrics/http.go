@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// promName turns a counter name into a Prometheus metric name:
+// "prune.history" -> "solwatch_prune_history_total".
+func promName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		ok := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+		if !ok {
+			b[i] = '_'
+		}
+	}
+	return "solwatch_" + strings.ToLower(string(b)) + "_total"
+}
+
+// Handler serves every counter in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		for _, name := range Names() {
+			m := promName(name)
+			fmt.Fprintf(w, "# TYPE %s counter\n%s %d\n", m, m, Get(name))
+		}
+	})
+}
+
+// Serve exposes Handler at /metrics on addr until ctx is done. The
+// endpoint is unauthenticated; bind it to a private address.
+func Serve(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	log.Printf("[metrics] listening on %s", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("[metrics] server error: %v", err)
+	}
+}
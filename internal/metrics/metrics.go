@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// counters is a process-wide registry of monotonically increasing counters,
+// keyed by dotted names like "prune.history". Reads are lock-free.
+var counters sync.Map // name -> *atomic.Int64
+
+func counter(name string) *atomic.Int64 {
+	if c, ok := counters.Load(name); ok {
+		return c.(*atomic.Int64)
+	}
+	c, _ := counters.LoadOrStore(name, new(atomic.Int64))
+	return c.(*atomic.Int64)
+}
+
+// Inc adds one to the named counter.
+func Inc(name string) { counter(name).Add(1) }
+
+// Add adds n to the named counter.
+func Add(name string, n int64) { counter(name).Add(n) }
+
+// Get returns the current value of the named counter (0 if never touched).
+func Get(name string) int64 {
+	if c, ok := counters.Load(name); ok {
+		return c.(*atomic.Int64).Load()
+	}
+	return 0
+}
+
+// Snapshot returns a copy of all counters.
+func Snapshot() map[string]int64 {
+	out := make(map[string]int64)
+	counters.Range(func(k, v any) bool {
+		out[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return out
+}
+
+// Names returns the registered counter names in sorted order.
+func Names() []string {
+	var names []string
+	counters.Range(func(k, _ any) bool {
+		names = append(names, k.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,145 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/pkg/sdk"
+)
+
+// Host holds loaded extensions and calls them defensively: a panicking
+// plugin is logged and skipped, never crashing the bot.
+type Host struct {
+	names        []string
+	interpreters []named[sdk.Interpreter]
+	filters      []named[sdk.Filter]
+	sinks        []named[sdk.Sink]
+}
+
+type named[T any] struct {
+	name string
+	impl T
+}
+
+// Load opens every *.so file in dir. A missing dir yields an empty Host.
+func Load(dir string) (*Host, error) {
+	h := &Host{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return h, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		p, err := open(path)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+		}
+		h.add(p)
+		log.Printf("[plugins] loaded %s from %s", p.Name(), filepath.Base(path))
+	}
+	return h, nil
+}
+
+func open(path string) (sdk.Plugin, error) {
+	so, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := so.Lookup("Plugin")
+	if err != nil {
+		return nil, err
+	}
+	switch v := sym.(type) {
+	case *sdk.Plugin: // exported as a variable
+		if *v == nil {
+			return nil, fmt.Errorf("Plugin is nil")
+		}
+		return *v, nil
+	case sdk.Plugin:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("Plugin symbol has type %T, want sdk.Plugin", sym)
+	}
+}
+
+func (h *Host) add(p sdk.Plugin) {
+	h.names = append(h.names, p.Name())
+	if v, ok := p.(sdk.Interpreter); ok {
+		h.interpreters = append(h.interpreters, named[sdk.Interpreter]{p.Name(), v})
+	}
+	if v, ok := p.(sdk.Filter); ok {
+		h.filters = append(h.filters, named[sdk.Filter]{p.Name(), v})
+	}
+	if v, ok := p.(sdk.Sink); ok {
+		h.sinks = append(h.sinks, named[sdk.Sink]{p.Name(), v})
+	}
+}
+
+// Names returns the loaded plugins' names, in load order.
+func (h *Host) Names() []string { return h.names }
+
+// Keep reports whether every filter accepts ev.
+func (h *Host) Keep(ctx context.Context, ev events.Event) bool {
+	for _, f := range h.filters {
+		keep := true
+		guard(f.name, func() { keep = f.impl.Keep(ctx, ev) })
+		if !keep {
+			return false
+		}
+	}
+	return true
+}
+
+// Interpret collects note lines from every interpreter.
+func (h *Host) Interpret(ctx context.Context, ev events.Event) []string {
+	var notes []string
+	for _, in := range h.interpreters {
+		guard(in.name, func() { notes = append(notes, in.impl.Interpret(ctx, ev)...) })
+	}
+	return notes
+}
+
+// RunSinks feeds every sink from bus until ctx is done.
+func (h *Host) RunSinks(ctx context.Context, bus *events.Bus) {
+	for _, s := range h.sinks {
+		go func(s named[sdk.Sink]) {
+			ch, cancel := bus.Subscribe(64)
+			defer cancel()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case ev, ok := <-ch:
+					if !ok {
+						return
+					}
+					guard(s.name, func() {
+						if err := s.impl.Send(ctx, ev); err != nil {
+							log.Printf("[plugins] %s: %v", s.name, err)
+						}
+					})
+				}
+			}
+		}(s)
+	}
+}
+
+func guard(name string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			metrics.Inc("plugins.panics")
+			log.Printf("[plugins] %s panicked: %v", name, r)
+		}
+	}()
+	fn()
+}
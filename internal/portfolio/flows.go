@@ -0,0 +1,117 @@
+package portfolio
+
+import (
+	"sort"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// solMint is wrapped SOL, the quote whose flow /flows reports.
+const solMint = "So11111111111111111111111111111111111111112"
+
+// WalletFlow is one wallet's trading in a token over a period.
+type WalletFlow struct {
+	Wallet string
+	Bought float64 // token amount received in trades
+	Sold   float64 // token amount sent in trades
+	SOLIn  float64 // SOL spent buying
+	SOLOut float64 // SOL received selling
+	USD    float64 // quote value bought minus sold
+	Trades int
+	LastAt time.Time
+}
+
+// NetSOL is SOL spent buying minus SOL received selling; positive means the
+// wallet moved SOL into the token.
+func (f WalletFlow) NetSOL() float64 { return f.SOLIn - f.SOLOut }
+
+// TokenFlow aggregates WalletFlows for one mint.
+type TokenFlow struct {
+	Mint    string
+	Symbol  string
+	Wallets []WalletFlow // largest net buyer first
+}
+
+// NetSOL sums the wallets' NetSOL.
+func (t TokenFlow) NetSOL() float64 {
+	var n float64
+	for _, w := range t.Wallets {
+		n += w.NetSOL()
+	}
+	return n
+}
+
+// NetUSD sums the wallets' quote value bought minus sold.
+func (t TokenFlow) NetUSD() float64 {
+	var n float64
+	for _, w := range t.Wallets {
+		n += w.USD
+	}
+	return n
+}
+
+// Flow adds wallet's trades in mint since the cutoff to t. Like Apply, only
+// trades count: the token against a quote (SOL/USDC) on the other side.
+// history must be newest first, as RecentHistory returns it.
+func (t *TokenFlow) Flow(wallet string, history []store.HistoryEntry, since time.Time) {
+	f := WalletFlow{Wallet: wallet}
+	for _, e := range history {
+		if e.Time.Before(since) {
+			break
+		}
+		got, gotQuote := legs(e.Received, t.Mint)
+		sent, sentQuote := legs(e.Sent, t.Mint)
+		switch {
+		case got != nil && len(sentQuote) > 0:
+			f.Bought += got.Amount
+			for _, q := range sentQuote {
+				f.USD += q.USD
+				if q.Mint == solMint {
+					f.SOLIn += q.Amount
+				}
+			}
+			t.symbol(got.Symbol)
+		case sent != nil && len(gotQuote) > 0:
+			f.Sold += sent.Amount
+			for _, q := range gotQuote {
+				f.USD -= q.USD
+				if q.Mint == solMint {
+					f.SOLOut += q.Amount
+				}
+			}
+			t.symbol(sent.Symbol)
+		default:
+			continue
+		}
+		f.Trades++
+		if e.Time.After(f.LastAt) {
+			f.LastAt = e.Time
+		}
+	}
+	if f.Trades == 0 {
+		return
+	}
+	t.Wallets = append(t.Wallets, f)
+	sort.SliceStable(t.Wallets, func(i, j int) bool { return t.Wallets[i].NetSOL() > t.Wallets[j].NetSOL() })
+}
+
+func (t *TokenFlow) symbol(s string) {
+	if t.Symbol == "" {
+		t.Symbol = s
+	}
+}
+
+// legs returns the leg of list in mint and its quote legs.
+func legs(list []store.HistoryAmount, mint string) (token *store.HistoryAmount, quotes []store.HistoryAmount) {
+	for i, a := range list {
+		switch {
+		case a.Mint == mint:
+			token = &list[i]
+		case analyzer.IsQuoteMint(a.Mint):
+			quotes = append(quotes, a)
+		}
+	}
+	return token, quotes
+}
@@ -0,0 +1,114 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// maxSamples caps the net worth series kept per wallet (30 days at 1h).
+const maxSamples = 720
+
+// Sample is one point of a wallet's net worth series.
+type Sample struct {
+	Time time.Time `json:"t"`
+	USD  float64   `json:"usd"`
+}
+
+// BalanceFunc returns a wallet's native SOL balance and its USD value.
+type BalanceFunc func(ctx context.Context, addr string) (sol, usd float64, err error)
+
+// SamplesKeyPrefix prefixes the settings key of each wallet's net worth
+// series; retention prunes series no longer written to.
+const SamplesKeyPrefix = "networth:"
+
+func samplesKey(addr string) string { return SamplesKeyPrefix + addr }
+
+// NetWorth estimates the wallet's value: SOL at the current price plus open
+// positions at cost basis, since unlisted tokens have no reliable price.
+func NetWorth(ctx context.Context, st store.Store, balance BalanceFunc, addr string) (float64, error) {
+	_, usd, err := balance(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+	open, err := st.ListPositions(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range open {
+		usd += p.CostUSD
+	}
+	return usd, nil
+}
+
+// Samples returns the recorded net worth series for addr, oldest first.
+func Samples(ctx context.Context, st store.Store, addr string) ([]Sample, error) {
+	raw, ok, err := st.GetSetting(ctx, samplesKey(addr))
+	if err != nil || !ok {
+		return nil, err
+	}
+	var out []Sample
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Forget deletes addr's net worth series, once nobody tracks it.
+func Forget(ctx context.Context, st store.Store, addr string) error {
+	return st.DeleteSetting(ctx, samplesKey(addr))
+}
+
+// Record appends a sample to addr's series, dropping the oldest past maxSamples.
+func Record(ctx context.Context, st store.Store, addr string, s Sample) error {
+	list, err := Samples(ctx, st, addr)
+	if err != nil {
+		list = nil // corrupt series: start over rather than never recording again
+	}
+	list = append(list, s)
+	if len(list) > maxSamples {
+		list = list[len(list)-maxSamples:]
+	}
+	val, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return st.SetSetting(ctx, samplesKey(addr), string(val))
+}
+
+// RunSampler records the net worth of every tracked wallet each interval
+// until ctx is cancelled. Charts have no history before the first run.
+func RunSampler(ctx context.Context, st store.Store, balance BalanceFunc, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		sampleAll(ctx, st, balance)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func sampleAll(ctx context.Context, st store.Store, balance BalanceFunc) {
+	wallets, err := st.ListWallets(ctx)
+	if err != nil {
+		log.Printf("[portfolio] list wallets: %v", err)
+		return
+	}
+	now := time.Now().UTC()
+	for _, w := range wallets {
+		usd, err := NetWorth(ctx, st, balance, w)
+		if err != nil {
+			log.Printf("[portfolio] net worth %s: %v", w, err)
+			continue
+		}
+		if err := Record(ctx, st, w, Sample{Time: now, USD: usd}); err != nil {
+			log.Printf("[portfolio] record %s: %v", w, err)
+		}
+	}
+}
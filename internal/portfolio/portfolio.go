@@ -0,0 +1,113 @@
+package portfolio
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// dustFraction is the share of the pre-sell holding below which a position
+// is considered fully sold and gets closed.
+const dustFraction = 0.001
+
+// PositionStore is the subset of store.Store used to maintain positions.
+type PositionStore interface {
+	GetPosition(ctx context.Context, wallet, mint string) (store.Position, bool, error)
+	PutPosition(ctx context.Context, p store.Position) error
+	ClosePosition(ctx context.Context, p store.Position) error
+}
+
+// Apply updates the wallet's positions from one analysis result.
+//
+// Only trades move cost basis: a token received while a quote token
+// (SOL/USDC) was spent is a buy; the reverse is a sell. The quote side
+// is split evenly across the token legs (multi-token swaps are rare).
+// Plain transfers in or out are ignored.
+func Apply(ctx context.Context, st PositionStore, res *analyzer.Result) error {
+	var spentUSD, gotUSD float64
+	var buys, sells []analyzer.Amount
+	for _, a := range res.Sent {
+		if analyzer.IsQuoteMint(a.Mint) {
+			spentUSD += a.USD
+		} else {
+			sells = append(sells, a)
+		}
+	}
+	for _, a := range res.Received {
+		if analyzer.IsQuoteMint(a.Mint) {
+			gotUSD += a.USD
+		} else {
+			buys = append(buys, a)
+		}
+	}
+
+	now := res.Timestamp
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+
+	if spentUSD > 0 && len(buys) > 0 {
+		share := spentUSD / float64(len(buys))
+		for _, a := range buys {
+			if err := buy(ctx, st, res.Wallet, a, share, now); err != nil {
+				return err
+			}
+		}
+	}
+	if gotUSD > 0 && len(sells) > 0 {
+		share := gotUSD / float64(len(sells))
+		for _, a := range sells {
+			if err := sell(ctx, st, res.Wallet, a, share, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func buy(ctx context.Context, st PositionStore, wallet string, a analyzer.Amount, costUSD float64, at time.Time) error {
+	p, ok, err := st.GetPosition(ctx, wallet, a.Mint)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		p = store.Position{Wallet: wallet, Mint: a.Mint, OpenedAt: at}
+	}
+	p.Symbol = a.Symbol
+	p.Amount += a.Amount
+	p.CostUSD += costUSD
+	p.InvestedUSD += costUSD
+	p.UpdatedAt = at
+	return st.PutPosition(ctx, p)
+}
+
+func sell(ctx context.Context, st PositionStore, wallet string, a analyzer.Amount, proceedsUSD float64, at time.Time) error {
+	p, ok, err := st.GetPosition(ctx, wallet, a.Mint)
+	if err != nil || !ok || p.Amount <= 0 {
+		return err // bought before we were tracking; nothing to book against
+	}
+
+	qty := a.Amount
+	if qty > p.Amount {
+		// Sold more than we saw bought: only book the known part.
+		proceedsUSD *= p.Amount / qty
+		qty = p.Amount
+	}
+	avgCost := p.CostUSD / p.Amount
+	before := p.Amount
+
+	p.RealizedUSD += proceedsUSD - avgCost*qty
+	p.Amount -= qty
+	p.CostUSD -= avgCost * qty
+	p.UpdatedAt = at
+
+	if p.Amount <= before*dustFraction {
+		p.Amount = 0
+		p.CostUSD = 0
+		p.ClosedAt = at
+		return st.ClosePosition(ctx, p)
+	}
+	return st.PutPosition(ctx, p)
+}
@@ -0,0 +1,70 @@
+package portfolio
+
+import (
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// TradeStats summarizes a wallet's closed positions.
+type TradeStats struct {
+	Trades      int
+	Wins        int
+	WinRate     float64       // share of trades with positive realized PnL
+	AvgHold     time.Duration // OpenedAt to ClosedAt
+	AvgReturn   float64       // mean RealizedUSD / InvestedUSD
+	RealizedUSD float64
+	BestUSD     float64
+	BestSymbol  string
+	WorstUSD    float64
+	WorstSymbol string
+}
+
+// Stats computes TradeStats from closed positions. Positions with no
+// recorded investment are counted for PnL but not for average return.
+func Stats(closed []store.Position) TradeStats {
+	var s TradeStats
+	var hold time.Duration
+	var retSum float64
+	var retN int
+	for i, p := range closed {
+		s.Trades++
+		s.RealizedUSD += p.RealizedUSD
+		if p.RealizedUSD > 0 {
+			s.Wins++
+		}
+		if !p.OpenedAt.IsZero() && p.ClosedAt.After(p.OpenedAt) {
+			hold += p.ClosedAt.Sub(p.OpenedAt)
+		}
+		if p.InvestedUSD > 0 {
+			retSum += p.RealizedUSD / p.InvestedUSD
+			retN++
+		}
+		if i == 0 || p.RealizedUSD > s.BestUSD {
+			s.BestUSD, s.BestSymbol = p.RealizedUSD, Label(p)
+		}
+		if i == 0 || p.RealizedUSD < s.WorstUSD {
+			s.WorstUSD, s.WorstSymbol = p.RealizedUSD, Label(p)
+		}
+	}
+	if s.Trades == 0 {
+		return s
+	}
+	s.WinRate = float64(s.Wins) / float64(s.Trades)
+	s.AvgHold = hold / time.Duration(s.Trades)
+	if retN > 0 {
+		s.AvgReturn = retSum / float64(retN)
+	}
+	return s
+}
+
+// Label names a position's token by symbol, falling back to a short mint.
+func Label(p store.Position) string {
+	if p.Symbol != "" {
+		return p.Symbol
+	}
+	if len(p.Mint) > 8 {
+		return p.Mint[:4] + "..." + p.Mint[len(p.Mint)-4:]
+	}
+	return p.Mint
+}
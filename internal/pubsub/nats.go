@@ -0,0 +1,159 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsPub speaks the minimal subset of the NATS client protocol needed to
+// publish: INFO/CONNECT on dial (upgrading to TLS for tls:// or when the
+// server requires it), PUB per event, PONG in reply to PING.
+type natsPub struct {
+	addr    string
+	host    string
+	tls     bool
+	subject string
+	user    string
+	pass    string
+	token   string
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+	// failed is the -ERR or read error seen on conn; PUB gets no reply, so
+	// the next Publish reconnects instead of writing into a dead link.
+	failed error
+}
+
+func newNATS(u *url.URL) *natsPub {
+	p := &natsPub{addr: u.Host, host: u.Hostname(), tls: u.Scheme == "tls", subject: strings.Trim(u.Path, "/")}
+	if p.subject == "" {
+		p.subject = "solwatch.events"
+	}
+	if !strings.Contains(p.addr, ":") {
+		p.addr += ":4222"
+	}
+	if u.User != nil {
+		if pw, ok := u.User.Password(); ok {
+			p.user, p.pass = u.User.Username(), pw
+		} else {
+			p.token = u.User.Username()
+		}
+	}
+	return p
+}
+
+func (p *natsPub) dial(ctx context.Context) error {
+	d := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := d.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		_ = conn.Close()
+		return fmt.Errorf("nats handshake: %q %v", strings.TrimSpace(line), err)
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "INFO ")), &info)
+	if p.tls || info.TLSRequired {
+		tc := tls.Client(conn, &tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("nats tls: %w", err)
+		}
+		conn, r = tc, bufio.NewReader(tc)
+	}
+	connect, _ := json.Marshal(map[string]any{
+		"verbose": false, "pedantic": false, "name": "solwatch", "lang": "go",
+		"user": p.user, "pass": p.pass, "auth_token": p.token,
+	})
+	// PING after CONNECT: the server answers PONG, or -ERR on bad auth.
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if line, err = r.ReadString('\n'); err != nil || !strings.HasPrefix(line, "PONG") {
+		_ = conn.Close()
+		return fmt.Errorf("nats connect: %q %v", strings.TrimSpace(line), err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	p.conn, p.r, p.failed = conn, r, nil
+	go p.readLoop(conn, r)
+	return nil
+}
+
+// readLoop answers server PINGs, which the server closes idle clients
+// without, and records -ERR replies and a lost connection.
+func (p *natsPub) readLoop(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			p.fail(conn, err)
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "PING"):
+			p.mu.Lock()
+			_, _ = conn.Write([]byte("PONG\r\n"))
+			p.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			p.fail(conn, fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR"))))
+		}
+	}
+}
+
+// fail records err for conn unless conn was replaced or closed meanwhile.
+func (p *natsPub) fail(conn net.Conn, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == conn && p.failed == nil {
+		p.failed = err
+	}
+}
+
+func (p *natsPub) Publish(ctx context.Context, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil && p.failed != nil {
+		log.Printf("[pubsub] nats connection failed: %v; reconnecting", p.failed)
+		_ = p.conn.Close()
+		p.conn = nil
+	}
+	if p.conn == nil {
+		if err := p.dial(ctx); err != nil {
+			return err
+		}
+	}
+	_ = p.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err := fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\n", p.subject, len(payload), payload); err != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (p *natsPub) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
@@ -0,0 +1,88 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// Publisher sends one encoded event to an external bus. Implementations
+// connect lazily and reconnect on the next Publish after a failure.
+type Publisher interface {
+	Publish(ctx context.Context, payload []byte) error
+	Close() error
+}
+
+// New returns a Publisher for rawURL:
+//
+//	nats://[user:pass@]host:4222/<subject>      (default subject "solwatch.events")
+//	redis://[:pass@]host:6379[/db]?stream=<key> (default stream "solwatch:events")
+//
+// tls:// and rediss:// are the same over TLS; nats:// also upgrades when
+// the server requires it.
+func New(rawURL string) (Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse event bus url: %w", err)
+	}
+	switch u.Scheme {
+	case "nats", "tls":
+		return newNATS(u), nil
+	case "redis", "rediss":
+		return newRedis(u)
+	default:
+		return nil, fmt.Errorf("unsupported event bus scheme %q (want nats, tls, redis or rediss)", u.Scheme)
+	}
+}
+
+// Run forwards every event on bus to p until ctx is done. Failed publishes
+// are retried with backoff; events arriving meanwhile may be dropped by the
+// bus (counted as events.dropped), never blocking alert delivery.
+func Run(ctx context.Context, bus *events.Bus, p Publisher) {
+	ch, cancel := bus.Subscribe(256)
+	defer cancel()
+	defer p.Close()
+
+	bo := util.NewBackoff(500*time.Millisecond, 30*time.Second, 2.0, 0.2)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			for attempt := 1; ; attempt++ {
+				err := p.Publish(ctx, payload)
+				if err == nil {
+					metrics.Inc("pubsub.published")
+					bo.Reset()
+					break
+				}
+				metrics.Inc("pubsub.errors")
+				if attempt >= 5 {
+					log.Printf("[pubsub] giving up on %s: %v", ev.Signature, err)
+					break
+				}
+				wait := bo.Next()
+				log.Printf("[pubsub] publish error: %v; retrying in %s", err, wait)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+		}
+	}
+}
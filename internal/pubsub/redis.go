@@ -0,0 +1,154 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisPub appends events to a Redis stream with XADD, speaking RESP
+// directly, over TLS for rediss://. Streams are capped approximately at
+// maxLen entries.
+type redisPub struct {
+	addr   string
+	host   string
+	tls    bool
+	pass   string
+	db     int
+	stream string
+	maxLen int
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newRedis(u *url.URL) (*redisPub, error) {
+	p := &redisPub{addr: u.Host, host: u.Hostname(), tls: u.Scheme == "rediss", stream: u.Query().Get("stream"), maxLen: 100000}
+	if p.stream == "" {
+		p.stream = "solwatch:events"
+	}
+	if !strings.Contains(p.addr, ":") {
+		p.addr += ":6379"
+	}
+	if u.User != nil {
+		p.pass, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		n, err := strconv.Atoi(db)
+		if err != nil {
+			return nil, fmt.Errorf("redis db must be a number, got %q", db)
+		}
+		p.db = n
+	}
+	if v := u.Query().Get("maxlen"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("redis maxlen must be a positive number, got %q", v)
+		}
+		p.maxLen = n
+	}
+	return p, nil
+}
+
+func (p *redisPub) dial(ctx context.Context) error {
+	d := &net.Dialer{Timeout: 5 * time.Second}
+	var conn net.Conn
+	var err error
+	if p.tls {
+		td := tls.Dialer{NetDialer: d, Config: &tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12}}
+		conn, err = td.DialContext(ctx, "tcp", p.addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", p.addr)
+	}
+	if err != nil {
+		return err
+	}
+	p.conn, p.r = conn, bufio.NewReader(conn)
+	if p.pass != "" {
+		if err := p.do("AUTH", p.pass); err != nil {
+			p.drop()
+			return fmt.Errorf("redis auth: %w", err)
+		}
+	}
+	if p.db != 0 {
+		if err := p.do("SELECT", strconv.Itoa(p.db)); err != nil {
+			p.drop()
+			return fmt.Errorf("redis select: %w", err)
+		}
+	}
+	return nil
+}
+
+// redisError is an error reply (-ERR, -WRONGTYPE, ...): the command failed
+// but the connection is still in step.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// do sends one command and reads a single-line reply, which is all XADD,
+// AUTH and SELECT return.
+func (p *redisPub) do(args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	_ = p.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := p.conn.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	switch line[0] {
+	case '-':
+		return redisError(strings.TrimSpace(line[1:]))
+	case '$': // bulk reply (XADD id): consume the payload line
+		if n, _ := strconv.Atoi(strings.TrimSpace(line[1:])); n >= 0 {
+			_, err = p.r.ReadString('\n')
+		}
+	}
+	return err
+}
+
+func (p *redisPub) drop() {
+	_ = p.conn.Close()
+	p.conn = nil
+}
+
+func (p *redisPub) Publish(ctx context.Context, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		if err := p.dial(ctx); err != nil {
+			return err
+		}
+	}
+	err := p.do("XADD", p.stream, "MAXLEN", "~", strconv.Itoa(p.maxLen), "*", "event", string(payload))
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		p.drop() // a write or read failure leaves the reply stream unknown
+	}
+	return err
+}
+
+func (p *redisPub) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
@@ -0,0 +1,121 @@
+package retention
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// HistoryPruner deletes persisted history older than a cutoff.
+type HistoryPruner interface {
+	PruneHistory(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// NotifiedPruner forgets alert dedupe markers older than a cutoff. A
+// HistoryPruner that also implements it is pruned for both.
+type NotifiedPruner interface {
+	PruneNotified(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// SettingsPruner deletes settings under a key prefix last set before a
+// cutoff. A HistoryPruner that also implements it is pruned for
+// Policy.Settings.
+type SettingsPruner interface {
+	PruneSettings(ctx context.Context, prefix string, cutoff time.Time) (int, error)
+}
+
+// CachePruner expires in-memory caches.
+type CachePruner interface {
+	PruneCaches(metadataTTL, priceTTL time.Duration) (metadata, mintAccounts, prices int)
+}
+
+// Policy is how long each kind of data is kept. Zero keeps it forever.
+type Policy struct {
+	History  time.Duration
+	Metadata time.Duration
+	Prices   time.Duration
+	Notified time.Duration // alert dedupe window
+	// Settings maps a settings key prefix (e.g. "symbol:") to how long
+	// such a setting is kept after it was last written.
+	Settings map[string]time.Duration
+}
+
+// Pruner periodically applies a Policy and records what it removed in the
+// prune.* counters.
+type Pruner struct {
+	policy  Policy
+	history HistoryPruner
+	caches  CachePruner
+}
+
+// New returns a Pruner. Either target may be nil.
+func New(p Policy, history HistoryPruner, caches CachePruner) *Pruner {
+	return &Pruner{policy: p, history: history, caches: caches}
+}
+
+// Run prunes once immediately and then every interval until ctx is done.
+func (p *Pruner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.Once(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Once applies the policy a single time.
+func (p *Pruner) Once(ctx context.Context) {
+	if p.history != nil && p.policy.History > 0 {
+		n, err := p.history.PruneHistory(ctx, time.Now().Add(-p.policy.History))
+		if err != nil {
+			log.Printf("[retention] prune history: %v", err)
+		} else if n > 0 {
+			metrics.Add("prune.history", int64(n))
+			log.Printf("[retention] pruned %d history entries", n)
+		}
+	}
+	if np, ok := p.history.(NotifiedPruner); ok && p.policy.Notified > 0 {
+		n, err := np.PruneNotified(ctx, time.Now().Add(-p.policy.Notified))
+		if err != nil {
+			log.Printf("[retention] prune notified: %v", err)
+		} else if n > 0 {
+			metrics.Add("prune.notified", int64(n))
+		}
+	}
+	if sp, ok := p.history.(SettingsPruner); ok {
+		for prefix, ttl := range p.policy.Settings {
+			if ttl <= 0 {
+				continue
+			}
+			n, err := sp.PruneSettings(ctx, prefix, time.Now().Add(-ttl))
+			if err != nil {
+				log.Printf("[retention] prune %s settings: %v", prefix, err)
+			} else if n > 0 {
+				metrics.Add("prune.settings."+strings.TrimSuffix(prefix, ":"), int64(n))
+				log.Printf("[retention] pruned %d %s settings", n, prefix)
+			}
+		}
+	}
+	if p.caches != nil {
+		meta, mints, prices := p.caches.PruneCaches(p.policy.Metadata, p.policy.Prices)
+		if meta > 0 {
+			metrics.Add("prune.metadata", int64(meta))
+		}
+		if mints > 0 {
+			metrics.Add("prune.mint_accounts", int64(mints))
+		}
+		if prices > 0 {
+			metrics.Add("prune.prices", int64(prices))
+		}
+		if meta+mints+prices > 0 {
+			log.Printf("[retention] expired %d metadata, %d mint account and %d price cache entries", meta, mints, prices)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package solanarpc is a small Solana JSON-RPC client: a generic Call plus
+// typed methods for the requests solwatch makes from several places
+// (analyzer, health probing).
+package solanarpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrAccountNotFound is returned when the RPC answers null for an
+	// account: it does not exist (yet) or the node has not seen it.
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrRateLimited matches errors caused by the node rate-limiting us: a
+	// JSON-RPC rate-limit error or an HTTP 429.
+	ErrRateLimited = errors.New("upstream rate limited")
+)
+
+// RPCError is the JSON-RPC error object of a failed call, e.g.
+// {"code":-32602,"message":"Invalid params"} or -32005 "Node is behind".
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	msg := fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+	if len(e.Data) > 0 && len(e.Data) <= 200 && string(e.Data) != "null" {
+		msg += " " + string(e.Data)
+	}
+	return msg
+}
+
+// Is makes JSON-RPC rate-limit errors (code 429 or -32429) match
+// ErrRateLimited.
+func (e *RPCError) Is(target error) bool {
+	return target == ErrRateLimited && (e.Code == 429 || e.Code == -32429)
+}
+
+// Transient reports whether the error is about the node rather than the
+// request: it is behind (-32005) or rate-limiting us, so another node may
+// well answer.
+func (e *RPCError) Transient() bool {
+	return e.Code == -32005 || e.Code == 429 || e.Code == -32429
+}
+
+// StatusError is a non-200 HTTP answer to a plain (Post-less) call.
+type StatusError struct {
+	Code int
+	Body string // the first bytes of the response
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %d", e.Code)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.Code, e.Body)
+}
+
+// Is makes a 429 match ErrRateLimited.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrRateLimited && e.Code == http.StatusTooManyRequests
+}
+
+// Client calls one JSON-RPC endpoint. The zero Commitment leaves the node's
+// default (finalized).
+type Client struct {
+	URL        string
+	HTTP       *http.Client
+	Commitment string
+	// Post, when set, sends each request body to URL instead of a plain
+	// POST; it returns only 200 responses. The analyzer uses it to add
+	// retries and per-upstream metrics.
+	Post func(ctx context.Context, url string, body []byte) (*http.Response, error)
+}
+
+// New returns a client for url. A nil client means http.DefaultClient.
+func New(url string, client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Client{URL: url, HTTP: client}
+}
+
+// request is the JSON-RPC request envelope.
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params,omitempty"`
+}
+
+// Call invokes method and decodes the response's result into result (which
+// may be nil). A JSON-RPC error object is returned as *RPCError.
+func (c *Client) Call(ctx context.Context, method string, params []any, result any) error {
+	body, err := json.Marshal(request{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	resp, err := c.post(ctx, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: reading response: %w", method, err)
+	}
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", method, err)
+	}
+	if envelope.Error != nil {
+		return envelope.Error
+	}
+	if result == nil || len(envelope.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return fmt.Errorf("%s: decoding result: %w", method, err)
+	}
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, body []byte) (*http.Response, error) {
+	if c.Post != nil {
+		return c.Post(ctx, c.URL, body)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, &StatusError{Code: resp.StatusCode, Body: string(bytes.TrimSpace(snippet))}
+	}
+	return resp, nil
+}
+
+// config returns the options object shared by the typed methods: the
+// commitment, if any, plus extra.
+func (c *Client) config(extra map[string]any) map[string]any {
+	cfg := make(map[string]any, len(extra)+1)
+	if c.Commitment != "" {
+		cfg["commitment"] = c.Commitment
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	return cfg
+}
@@ -0,0 +1,221 @@
+package solanarpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Well-known program IDs.
+const (
+	SystemProgramID    = "11111111111111111111111111111111"
+	TokenProgramID     = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	Token2022ProgramID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+)
+
+// Account is an account as returned by getAccountInfo, data decoded.
+type Account struct {
+	Lamports   uint64
+	Owner      string
+	Executable bool
+	Data       []byte
+}
+
+// GetAccountInfo returns addr's account, or ErrAccountNotFound.
+func (c *Client) GetAccountInfo(ctx context.Context, addr string) (*Account, error) {
+	var res struct {
+		Value *struct {
+			Lamports   uint64   `json:"lamports"`
+			Owner      string   `json:"owner"`
+			Executable bool     `json:"executable"`
+			Data       []string `json:"data"` // ["<base64>", "base64"]
+		} `json:"value"`
+	}
+	params := []any{addr, c.config(map[string]any{"encoding": "base64"})}
+	if err := c.Call(ctx, "getAccountInfo", params, &res); err != nil {
+		return nil, err
+	}
+	v := res.Value
+	if v == nil {
+		return nil, ErrAccountNotFound
+	}
+	acc := &Account{Lamports: v.Lamports, Owner: v.Owner, Executable: v.Executable}
+	if len(v.Data) > 0 {
+		data, err := base64.StdEncoding.DecodeString(v.Data[0])
+		if err != nil {
+			return nil, fmt.Errorf("getAccountInfo(%s): decoding data: %w", addr, err)
+		}
+		acc.Data = data
+	}
+	return acc, nil
+}
+
+// GetBalance returns addr's balance in lamports.
+func (c *Client) GetBalance(ctx context.Context, addr string) (uint64, error) {
+	var res struct {
+		Value uint64 `json:"value"`
+	}
+	if err := c.Call(ctx, "getBalance", []any{addr, c.config(nil)}, &res); err != nil {
+		return 0, err
+	}
+	return res.Value, nil
+}
+
+// SignatureInfo is one entry of getSignaturesForAddress, newest first.
+type SignatureInfo struct {
+	Signature string          `json:"signature"`
+	Slot      uint64          `json:"slot"`
+	BlockTime *int64          `json:"blockTime"`
+	Err       json.RawMessage `json:"err"` // null for successful transactions
+}
+
+// Failed reports whether the transaction failed on chain.
+func (s SignatureInfo) Failed() bool {
+	return len(s.Err) > 0 && string(s.Err) != "null"
+}
+
+// SignaturesOptions pages through getSignaturesForAddress: at most Limit
+// entries (0 = the node's default of 1000) older than Before and newer
+// than Until, both signatures.
+type SignaturesOptions struct {
+	Limit  int
+	Before string
+	Until  string
+}
+
+// GetSignaturesForAddress lists the signatures that touched addr, newest
+// first.
+func (c *Client) GetSignaturesForAddress(ctx context.Context, addr string, opts SignaturesOptions) ([]SignatureInfo, error) {
+	extra := make(map[string]any)
+	if opts.Limit > 0 {
+		extra["limit"] = opts.Limit
+	}
+	if opts.Before != "" {
+		extra["before"] = opts.Before
+	}
+	if opts.Until != "" {
+		extra["until"] = opts.Until
+	}
+	var res []SignatureInfo
+	if err := c.Call(ctx, "getSignaturesForAddress", []any{addr, c.config(extra)}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Transaction is a getTransaction result with encoding=json.
+type Transaction struct {
+	Slot      uint64 `json:"slot"`
+	BlockTime *int64 `json:"blockTime"`
+	Version   any    `json:"version"` // "legacy" or 0
+	Meta      *struct {
+		Err               json.RawMessage `json:"err"`
+		Fee               int64           `json:"fee"`
+		PreBalances       []int64         `json:"preBalances"`
+		PostBalances      []int64         `json:"postBalances"`
+		PreTokenBalances  []TokenBalance  `json:"preTokenBalances"`
+		PostTokenBalances []TokenBalance  `json:"postTokenBalances"`
+		LoadedAddresses   *struct {
+			Writable []string `json:"writable"`
+			Readonly []string `json:"readonly"`
+		} `json:"loadedAddresses"`
+		InnerInstructions []struct {
+			Index        int                   `json:"index"`
+			Instructions []CompiledInstruction `json:"instructions"`
+		} `json:"innerInstructions"`
+		LogMessages []string `json:"logMessages"`
+	} `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys  []string              `json:"accountKeys"`
+			Instructions []CompiledInstruction `json:"instructions"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+// CompiledInstruction is an instruction whose program and accounts are
+// indexes into the transaction's resolved account keys.
+type CompiledInstruction struct {
+	ProgramIDIndex int    `json:"programIdIndex"`
+	Accounts       []int  `json:"accounts"`
+	Data           string `json:"data"`
+}
+
+// TokenBalance is one pre/post token balance row of a transaction.
+type TokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UITokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals int    `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// GetTransaction returns the transaction, or nil when the node doesn't know
+// it (yet).
+func (c *Client) GetTransaction(ctx context.Context, signature string) (*Transaction, error) {
+	var tx *Transaction
+	params := []any{signature, c.config(map[string]any{"encoding": "json", "maxSupportedTransactionVersion": 0})}
+	if err := c.Call(ctx, "getTransaction", params, &tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// TokenAccount is one token account of an owner.
+type TokenAccount struct {
+	Pubkey   string
+	Mint     string
+	Amount   string // raw integer amount
+	Decimals int
+	UIAmount float64
+}
+
+// GetTokenAccountsByOwner lists owner's token accounts under programID
+// (TokenProgramID or Token2022ProgramID).
+func (c *Client) GetTokenAccountsByOwner(ctx context.Context, owner, programID string) ([]TokenAccount, error) {
+	var res struct {
+		Value []struct {
+			Pubkey  string `json:"pubkey"`
+			Account struct {
+				Data struct {
+					Parsed struct {
+						Info struct {
+							Mint        string `json:"mint"`
+							TokenAmount struct {
+								Amount   string  `json:"amount"`
+								Decimals int     `json:"decimals"`
+								UIAmount float64 `json:"uiAmount"`
+							} `json:"tokenAmount"`
+						} `json:"info"`
+					} `json:"parsed"`
+				} `json:"data"`
+			} `json:"account"`
+		} `json:"value"`
+	}
+	params := []any{owner, map[string]any{"programId": programID}, c.config(map[string]any{"encoding": "jsonParsed"})}
+	if err := c.Call(ctx, "getTokenAccountsByOwner", params, &res); err != nil {
+		return nil, err
+	}
+	out := make([]TokenAccount, 0, len(res.Value))
+	for _, v := range res.Value {
+		info := v.Account.Data.Parsed.Info
+		out = append(out, TokenAccount{
+			Pubkey:   v.Pubkey,
+			Mint:     info.Mint,
+			Amount:   info.TokenAmount.Amount,
+			Decimals: info.TokenAmount.Decimals,
+			UIAmount: info.TokenAmount.UIAmount,
+		})
+	}
+	return out, nil
+}
+
+// GetHealth returns nil when the node reports itself healthy; a lagging
+// node answers with an *RPCError.
+func (c *Client) GetHealth(ctx context.Context) error {
+	return c.Call(ctx, "getHealth", nil, nil)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	b58 "github.com/mr-tron/base58/base58"
@@ -13,16 +14,39 @@ import (
 )
 
 const (
-	walletsBucket = "wallets"
+	walletsBucket         = "wallets"
+	historyBucket         = "history"
+	settingsBucket        = "settings"
+	positionsBucket       = "positions"
+	closedPositionsBucket = "closed_positions"
 )
 
 // Bolt wraps a bbolt DB for storing tracked wallets.
 type Bolt struct {
-	db *bbolt.DB
+	// mu guards the db handle itself: regular operations hold it shared,
+	// Compact holds it exclusively while it swaps in the compacted file.
+	mu  sync.RWMutex
+	db  *bbolt.DB
+	enc *cipherBox // nil when encryption at rest is off
 }
 
-// NewBolt opens (or creates) a Bolt DB at path and ensures the "wallets" bucket exists.
+// NewBolt opens (or creates) a Bolt DB at path and ensures the top-level buckets exist.
 func NewBolt(path string) (*Bolt, error) {
+	return openBolt(path, nil)
+}
+
+// NewEncryptedBolt is NewBolt with values sealed by AES-GCM and identifiers
+// hashed, using keys derived from secret. An existing plaintext DB is
+// encrypted in place on first open; a wrong secret is rejected.
+func NewEncryptedBolt(path string, secret []byte) (*Bolt, error) {
+	box, err := newCipherBox(secret)
+	if err != nil {
+		return nil, err
+	}
+	return openBolt(path, box)
+}
+
+func openBolt(path string, enc *cipherBox) (*Bolt, error) {
 	if strings.TrimSpace(path) == "" {
 		return nil, errors.New("empty DB path")
 	}
@@ -34,26 +58,52 @@ func NewBolt(path string) (*Bolt, error) {
 		return nil, fmt.Errorf("open bolt db: %w", err)
 	}
 
-	// Ensure bucket exists.
+	// Ensure buckets exist.
 	if err := db.Update(func(tx *bbolt.Tx) error {
-		_, e := tx.CreateBucketIfNotExists([]byte(walletsBucket))
-		return e
+		for _, name := range []string{walletsBucket, historyBucket, settingsBucket, settingTimesBucket, positionsBucket, closedPositionsBucket, userWalletsBucket, notifiedBucket} {
+			if _, e := tx.CreateBucketIfNotExists([]byte(name)); e != nil {
+				return e
+			}
+		}
+		return nil
 	}); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("ensure bucket: %w", err)
 	}
 
-	return &Bolt{db: db}, nil
+	b := &Bolt{db: db, enc: enc}
+	if err := b.checkEncryption(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return b, nil
 }
 
 // Close closes the underlying DB.
 func (b *Bolt) Close() error {
-	if b == nil || b.db == nil {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
 		return nil
 	}
 	return b.db.Close()
 }
 
+func (b *Bolt) view(fn func(*bbolt.Tx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.View(fn)
+}
+
+func (b *Bolt) update(fn func(*bbolt.Tx) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.Update(fn)
+}
+
 // AddWallet inserts the address if not present. Idempotent.
 // Value is an RFC3339 timestamp when it was added.
 func (b *Bolt) AddWallet(ctx context.Context, addr string) error {
@@ -69,17 +119,26 @@ func (b *Bolt) AddWallet(ctx context.Context, addr string) error {
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339Nano)
+	val := []byte(now)
+	if b.enc != nil {
+		// The key is a hash, so the address itself travels in the sealed value.
+		val = []byte(addr + "\n" + now)
+	}
+	val, err := b.seal(val)
+	if err != nil {
+		return err
+	}
 
-	return b.db.Update(func(tx *bbolt.Tx) error {
+	return b.update(func(tx *bbolt.Tx) error {
 		bkt := tx.Bucket([]byte(walletsBucket))
 		if bkt == nil {
 			return errors.New("wallets bucket missing")
 		}
-		if v := bkt.Get([]byte(addr)); v != nil {
+		if v := bkt.Get(b.name(addr)); v != nil {
 			// already present → idempotent success
 			return nil
 		}
-		return bkt.Put([]byte(addr), []byte(now))
+		return bkt.Put(b.name(addr), val)
 	})
 }
 
@@ -95,13 +154,13 @@ func (b *Bolt) RemoveWallet(ctx context.Context, addr string) error {
 	default:
 	}
 
-	return b.db.Update(func(tx *bbolt.Tx) error {
+	return b.update(func(tx *bbolt.Tx) error {
 		bkt := tx.Bucket([]byte(walletsBucket))
 		if bkt == nil {
 			return errors.New("wallets bucket missing")
 		}
 		// Delete returns nil whether or not the key existed.
-		return bkt.Delete([]byte(addr))
+		return bkt.Delete(b.name(addr))
 	})
 }
 
@@ -114,13 +173,23 @@ func (b *Bolt) ListWallets(ctx context.Context) ([]string, error) {
 	}
 
 	var addrs []string
-	err := b.db.View(func(tx *bbolt.Tx) error {
+	err := b.view(func(tx *bbolt.Tx) error {
 		bkt := tx.Bucket([]byte(walletsBucket))
 		if bkt == nil {
 			return errors.New("wallets bucket missing")
 		}
-		return bkt.ForEach(func(k, _ []byte) error {
-			addrs = append(addrs, string(k))
+		return bkt.ForEach(func(k, v []byte) error {
+			if b.enc == nil {
+				addrs = append(addrs, string(k))
+				return nil
+			}
+			plain, err := b.open(v)
+			if err != nil {
+				return fmt.Errorf("decrypt wallet: %w", err)
+			}
+			if addr, ok := splitWalletValue(plain); ok {
+				addrs = append(addrs, addr)
+			}
 			return nil
 		})
 	})
@@ -0,0 +1,267 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	metaBucket    = "meta"
+	encMarkerKey  = "encryption"
+	encMarkerText = "solwatch-encrypted-v1"
+)
+
+// cipherBox seals values with AES-256-GCM and hides identifiers (wallet
+// addresses, mints, signatures, setting keys) behind an HMAC so the file
+// reveals neither the watchlist nor its activity. Both keys are derived from
+// one user-supplied secret.
+type cipherBox struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+func newCipherBox(secret []byte) (*cipherBox, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) < 16 {
+		return nil, errors.New("encryption key must be at least 16 bytes")
+	}
+	encKey := sha256.Sum256(append([]byte("solwatch/enc/"), secret...))
+	macKey := sha256.Sum256(append([]byte("solwatch/mac/"), secret...))
+
+	block, err := aes.NewCipher(encKey[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &cipherBox{aead: aead, macKey: macKey[:]}, nil
+}
+
+// name maps an identifier to a stable opaque key.
+func (c *cipherBox) name(s string) []byte {
+	m := hmac.New(sha256.New, c.macKey)
+	m.Write([]byte(s))
+	return []byte(hex.EncodeToString(m.Sum(nil))[:32])
+}
+
+func (c *cipherBox) seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (c *cipherBox) open(sealed []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return c.aead.Open(nil, sealed[:n], sealed[n:], nil)
+}
+
+// ----- Bolt helpers: identity when encryption is off -----
+
+func (b *Bolt) name(s string) []byte {
+	if b.enc == nil {
+		return []byte(s)
+	}
+	return b.enc.name(s)
+}
+
+// timedName builds "<zero-padded unix nanos>|<name(s)>" keys so time order
+// survives encryption.
+func (b *Bolt) timedName(nanos int64, s string) []byte {
+	return append([]byte(fmt.Sprintf("%020d|", nanos)), b.name(s)...)
+}
+
+func (b *Bolt) seal(v []byte) ([]byte, error) {
+	if b.enc == nil {
+		return v, nil
+	}
+	return b.enc.seal(v)
+}
+
+func (b *Bolt) open(v []byte) ([]byte, error) {
+	if b.enc == nil {
+		return v, nil
+	}
+	return b.enc.open(v)
+}
+
+// checkEncryption verifies the DB matches the configured mode, migrating a
+// plaintext database in place the first time a key is supplied.
+func (b *Bolt) checkEncryption() error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists([]byte(metaBucket))
+		if err != nil {
+			return err
+		}
+		marker := meta.Get([]byte(encMarkerKey))
+
+		switch {
+		case b.enc == nil && marker == nil:
+			return nil
+		case b.enc == nil:
+			return errors.New("database is encrypted; set STORE_ENCRYPTION_KEY or STORE_ENCRYPTION_KEY_FILE")
+		case marker != nil:
+			plain, err := b.enc.open(marker)
+			if err != nil || string(plain) != encMarkerText {
+				return errors.New("wrong encryption key for this database")
+			}
+			return nil
+		}
+
+		if err := b.migrateToEncrypted(tx); err != nil {
+			return fmt.Errorf("encrypt existing data: %w", err)
+		}
+		sealed, err := b.enc.seal([]byte(encMarkerText))
+		if err != nil {
+			return err
+		}
+		return meta.Put([]byte(encMarkerKey), sealed)
+	})
+}
+
+type kv struct{ k, v []byte }
+
+// migrateToEncrypted rewrites every plaintext bucket in the encrypted layout.
+func (b *Bolt) migrateToEncrypted(tx *bbolt.Tx) error {
+	// timed keys "<nanos>|<id>" keep their time prefix.
+	timed := func(k []byte) ([]byte, error) {
+		i := bytes.IndexByte(k, '|')
+		if i < 0 {
+			return nil, fmt.Errorf("unexpected key %q", k)
+		}
+		return append(append([]byte(nil), k[:i+1]...), b.enc.name(string(k[i+1:]))...), nil
+	}
+	plainKey := func(k []byte) ([]byte, error) { return b.enc.name(string(k)), nil }
+
+	// Flat buckets.
+	if err := b.rewriteFlat(tx, walletsBucket, func(k, v []byte) ([]byte, []byte, error) {
+		sealed, err := b.enc.seal([]byte(string(k) + "\n" + string(v)))
+		return b.enc.name(string(k)), sealed, err
+	}); err != nil {
+		return err
+	}
+	for _, name := range []string{settingsBucket, settingTimesBucket} {
+		if err := b.rewriteFlat(tx, name, func(k, v []byte) ([]byte, []byte, error) {
+			sealed, err := b.enc.seal(v)
+			return b.enc.name(string(k)), sealed, err
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Dedupe markers are short-lived: start them over under hashed keys.
+	if err := tx.DeleteBucket([]byte(notifiedBucket)); err != nil && !errors.Is(err, bbolt.ErrBucketNotFound) {
+		return err
+	}
+	if _, err := tx.CreateBucket([]byte(notifiedBucket)); err != nil {
+		return err
+	}
+
+	// Nested buckets: <wallet>/<key>.
+	for name, keyFn := range map[string]func([]byte) ([]byte, error){
+		historyBucket:         timed,
+		positionsBucket:       plainKey,
+		closedPositionsBucket: timed,
+		userWalletsBucket:     plainKey,
+	} {
+		if err := b.rewriteNested(tx, name, keyFn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bolt) rewriteFlat(tx *bbolt.Tx, bucket string, fn func(k, v []byte) ([]byte, []byte, error)) error {
+	bkt := tx.Bucket([]byte(bucket))
+	if bkt == nil {
+		return nil
+	}
+	var rows []kv
+	if err := bkt.ForEach(func(k, v []byte) error {
+		nk, nv, err := fn(k, v)
+		rows = append(rows, kv{nk, nv})
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := tx.DeleteBucket([]byte(bucket)); err != nil {
+		return err
+	}
+	bkt, err := tx.CreateBucket([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := bkt.Put(r.k, r.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bolt) rewriteNested(tx *bbolt.Tx, bucket string, keyFn func([]byte) ([]byte, error)) error {
+	root := tx.Bucket([]byte(bucket))
+	if root == nil {
+		return nil
+	}
+	nested := make(map[string][]kv)
+	if err := root.ForEachBucket(func(wallet []byte) error {
+		return root.Bucket(wallet).ForEach(func(k, v []byte) error {
+			nk, err := keyFn(k)
+			if err != nil {
+				return err
+			}
+			nv, err := b.enc.seal(v)
+			if err != nil {
+				return err
+			}
+			w := string(wallet)
+			nested[w] = append(nested[w], kv{nk, nv})
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	if err := tx.DeleteBucket([]byte(bucket)); err != nil {
+		return err
+	}
+	root, err := tx.CreateBucket([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	for w, rows := range nested {
+		sub, err := root.CreateBucket(b.enc.name(w))
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := sub.Put(r.k, r.v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitWalletValue decodes an encrypted wallets row ("<addr>\n<added at>").
+func splitWalletValue(v []byte) (addr string, ok bool) {
+	addr, _, ok = strings.Cut(string(v), "\n")
+	return addr, ok
+}
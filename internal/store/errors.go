@@ -0,0 +1,12 @@
+package store
+
+import "errors"
+
+var (
+	// ErrWalletAlreadyTracked is returned when a wallet is added to a
+	// watchlist that already has it. Nothing is changed.
+	ErrWalletAlreadyTracked = errors.New("wallet already tracked")
+	// ErrWalletNotFound is returned when a wallet is removed from a
+	// watchlist that doesn't have it.
+	ErrWalletNotFound = errors.New("wallet not tracked")
+)
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Export is a read-only, JSON-friendly copy of the store contents. It lets
+// operators inspect state while the bot holds Bolt's exclusive file lock.
+type Export struct {
+	GeneratedAt time.Time                 `json:"generated_at"`
+	Wallets     []string                  `json:"wallets"`
+	History     map[string][]HistoryEntry `json:"history"`
+	Positions   map[string][]Position     `json:"positions"`
+	Closed      map[string][]Position     `json:"closed_positions"`
+}
+
+// ExportAll builds an Export from st. historyLimit caps entries per wallet
+// (newest first); <= 0 exports everything.
+func ExportAll(ctx context.Context, st Store, historyLimit int) (Export, error) {
+	wallets, err := st.ListWallets(ctx)
+	if err != nil {
+		return Export{}, fmt.Errorf("list wallets: %w", err)
+	}
+	return ExportWallets(ctx, st, wallets, historyLimit)
+}
+
+// ExportWallets is ExportAll limited to wallets.
+func ExportWallets(ctx context.Context, st Store, wallets []string, historyLimit int) (Export, error) {
+	var err error
+	out := Export{
+		GeneratedAt: time.Now().UTC(),
+		Wallets:     wallets,
+		History:     make(map[string][]HistoryEntry, len(wallets)),
+		Positions:   make(map[string][]Position, len(wallets)),
+		Closed:      make(map[string][]Position, len(wallets)),
+	}
+	for _, w := range wallets {
+		if out.History[w], err = st.RecentHistory(ctx, w, historyLimit); err != nil {
+			return Export{}, fmt.Errorf("history %s: %w", w, err)
+		}
+		if out.Positions[w], err = st.ListPositions(ctx, w); err != nil {
+			return Export{}, fmt.Errorf("positions %s: %w", w, err)
+		}
+		if out.Closed[w], err = st.ListClosedPositions(ctx, w); err != nil {
+			return Export{}, fmt.Errorf("closed positions %s: %w", w, err)
+		}
+	}
+	return out, nil
+}
+
+// sealedPrefix starts a sealed export; the base64 ciphertext follows.
+const sealedPrefix = "solwatch-sealed-v1:"
+
+// SealExport encrypts an export with the key derived from secret (the
+// STORE_ENCRYPTION_KEY) the way the encrypted Bolt store seals values. The
+// result is text: sealedPrefix and the base64 AES-GCM ciphertext.
+func SealExport(data, secret []byte) ([]byte, error) {
+	box, err := newCipherBox(secret)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := box.seal(data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(sealedPrefix)+base64.StdEncoding.EncodedLen(len(sealed))+1)
+	out = append(out, sealedPrefix...)
+	out = base64.StdEncoding.AppendEncode(out, sealed)
+	return append(out, '\n'), nil
+}
+
+// OpenExport reverses SealExport.
+func OpenExport(data, secret []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(bytes.TrimSpace(data), []byte(sealedPrefix))
+	if !ok {
+		return nil, errors.New("not a sealed export")
+	}
+	sealed, err := base64.StdEncoding.AppendDecode(nil, rest)
+	if err != nil {
+		return nil, fmt.Errorf("decode sealed export: %w", err)
+	}
+	box, err := newCipherBox(secret)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := box.open(sealed)
+	if err != nil {
+		return nil, errors.New("sealed export: wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+// WriteSnapshotFile writes an Export to path atomically (temp file + rename),
+// so sidecar readers never see a partial file. With a secret the file is
+// sealed (see SealExport).
+func WriteSnapshotFile(ctx context.Context, st Store, path string, historyLimit int, secret []byte) error {
+	exp, err := ExportAll(ctx, st, historyLimit)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(exp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if len(secret) > 0 {
+		if data, err = SealExport(data, secret); err != nil {
+			return err
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// sizeScanFactor bounds RecentSizes to the limit*sizeScanFactor newest
+// entries, priced or not, so a wallet with years of unpriced history costs
+// no more than a busy one.
+const sizeScanFactor = 4
+
+// HistoryEntry is one analyzed transaction for a tracked wallet.
+// Entries live in history/<wallet>/<zero-padded unix nanos>|<signature>
+// so a cursor walk returns them in time order. With encryption on, wallet
+// and signature are replaced by their HMAC names (see cipherBox).
+type HistoryEntry struct {
+	Signature string          `json:"signature"`
+	Wallet    string          `json:"wallet"`
+	Time      time.Time       `json:"time"`
+	Type      string          `json:"type"`
+	Source    string          `json:"source"`
+	SizeUSD   float64         `json:"size_usd"` // 0 when no priced leg was involved
+	Sent      []HistoryAmount `json:"sent,omitempty"`
+	Received  []HistoryAmount `json:"received,omitempty"`
+}
+
+// HistoryAmount is a single token leg of a HistoryEntry.
+type HistoryAmount struct {
+	Mint   string  `json:"mint"`
+	Symbol string  `json:"symbol"`
+	Amount float64 `json:"amount"`
+	USD    float64 `json:"usd,omitempty"`
+}
+
+// AddHistory appends an entry to the wallet's history. Re-adding the same
+// signature at the same time overwrites the previous value.
+func (b *Bolt) AddHistory(ctx context.Context, e HistoryEntry) error {
+	e.Wallet = strings.TrimSpace(e.Wallet)
+	if e.Wallet == "" || e.Signature == "" {
+		return errors.New("history entry needs wallet and signature")
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	val, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode history: %w", err)
+	}
+	if val, err = b.seal(val); err != nil {
+		return err
+	}
+
+	return b.update(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(historyBucket))
+		if root == nil {
+			return errors.New("history bucket missing")
+		}
+		bkt, err := root.CreateBucketIfNotExists(b.name(e.Wallet))
+		if err != nil {
+			return err
+		}
+		return bkt.Put(b.timedName(e.Time.UnixNano(), e.Signature), val)
+	})
+}
+
+// RecentHistory returns up to limit entries for wallet, newest first.
+// A limit <= 0 returns everything.
+func (b *Bolt) RecentHistory(ctx context.Context, wallet string, limit int) ([]HistoryEntry, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	var out []HistoryEntry
+	err := b.view(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(historyBucket))
+		if root == nil {
+			return errors.New("history bucket missing")
+		}
+		bkt := root.Bucket(b.name(wallet))
+		if bkt == nil {
+			return nil
+		}
+		c := bkt.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var e HistoryEntry
+			plain, err := b.open(v)
+			if err != nil {
+				return fmt.Errorf("decrypt history: %w", err)
+			}
+			if err := json.Unmarshal(plain, &e); err != nil {
+				continue // skip corrupt rows rather than failing the whole read
+			}
+			out = append(out, e)
+			if limit > 0 && len(out) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RecentSizes returns the USD sizes of up to limit of the wallet's most recent
+// priced transactions. Unpriced entries are skipped; only the newest
+// limit*sizeScanFactor entries are looked at.
+func (b *Bolt) RecentSizes(ctx context.Context, wallet string, limit int) ([]float64, error) {
+	entries, err := b.RecentHistory(ctx, wallet, limit*sizeScanFactor)
+	if err != nil {
+		return nil, err
+	}
+	var sizes []float64
+	for _, e := range entries {
+		if e.SizeUSD <= 0 {
+			continue
+		}
+		sizes = append(sizes, e.SizeUSD)
+		if limit > 0 && len(sizes) >= limit {
+			break
+		}
+	}
+	return sizes, nil
+}
+
+// PruneHistory deletes entries older than cutoff across all wallets and
+// returns how many were removed. Wallet sub-buckets left empty are dropped.
+func (b *Bolt) PruneHistory(ctx context.Context, cutoff time.Time) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	limit := []byte(fmt.Sprintf("%020d|", cutoff.UnixNano()))
+	deleted := 0
+	err := b.update(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(historyBucket))
+		if root == nil {
+			return errors.New("history bucket missing")
+		}
+		var empty [][]byte
+		err := root.ForEachBucket(func(wallet []byte) error {
+			bkt := root.Bucket(wallet)
+			// Collect first: deleting through a live cursor skips keys.
+			var stale [][]byte
+			c := bkt.Cursor()
+			for k, _ := c.First(); k != nil && string(k) < string(limit); k, _ = c.Next() {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			for _, k := range stale {
+				if err := bkt.Delete(k); err != nil {
+					return err
+				}
+			}
+			deleted += len(stale)
+			if k, _ := bkt.Cursor().First(); k == nil {
+				empty = append(empty, append([]byte(nil), wallet...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, w := range empty {
+			if err := root.DeleteBucket(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
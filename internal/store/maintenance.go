@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// compactTxMaxSize bounds how much is copied per transaction during Compact.
+const compactTxMaxSize = 64 << 20
+
+// DBStats is a snapshot of the Bolt file for the /db stats command.
+type DBStats struct {
+	Path       string
+	FileSize   int64
+	PageSize   int
+	FreePages  int            // pages on the freelist, reusable but still in the file
+	PendingPgs int            // pages freed by still-open read transactions
+	Buckets    map[string]int // top-level bucket -> key count (incl. nested)
+}
+
+// FreeRatio is the share of the file occupied by free pages.
+func (s DBStats) FreeRatio() float64 {
+	if s.FileSize == 0 {
+		return 0
+	}
+	return float64((s.FreePages+s.PendingPgs)*s.PageSize) / float64(s.FileSize)
+}
+
+// BucketNames returns the bucket names in sorted order.
+func (s DBStats) BucketNames() []string {
+	names := make([]string, 0, len(s.Buckets))
+	for n := range s.Buckets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats reports file size, free pages and per-bucket key counts.
+func (b *Bolt) Stats(ctx context.Context) (DBStats, error) {
+	if err := ctx.Err(); err != nil {
+		return DBStats{}, err
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	st := b.db.Stats()
+	out := DBStats{
+		Path:       b.db.Path(),
+		PageSize:   b.db.Info().PageSize,
+		FreePages:  st.FreePageN,
+		PendingPgs: st.PendingPageN,
+		Buckets:    make(map[string]int),
+	}
+	if fi, err := os.Stat(out.Path); err == nil {
+		out.FileSize = fi.Size()
+	}
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, bkt *bbolt.Bucket) error {
+			out.Buckets[string(name)] = bkt.Stats().KeyN
+			return nil
+		})
+	})
+	return out, err
+}
+
+// Compact rewrites the database into a fresh file and swaps it in place,
+// reclaiming free pages. Other operations block while it runs. Returns the
+// file size before and after.
+func (b *Bolt) Compact(ctx context.Context) (before, after int64, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path := b.db.Path()
+	if fi, err := os.Stat(path); err == nil {
+		before = fi.Size()
+	}
+
+	tmpPath := path + ".compact"
+	_ = os.Remove(tmpPath)
+	dst, err := bbolt.Open(tmpPath, 0o600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return before, 0, fmt.Errorf("open compact target: %w", err)
+	}
+	if err := bbolt.Compact(dst, b.db, compactTxMaxSize); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmpPath)
+		return before, 0, fmt.Errorf("compact: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return before, 0, fmt.Errorf("close compact target: %w", err)
+	}
+
+	if err := b.db.Close(); err != nil {
+		return before, 0, fmt.Errorf("close db: %w", err)
+	}
+	renameErr := os.Rename(tmpPath, path)
+	// Reopen whichever file is now at path; the service must keep a handle.
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return before, 0, fmt.Errorf("reopen db: %w", err)
+	}
+	b.db = db
+	if renameErr != nil {
+		_ = os.Remove(tmpPath)
+		return before, 0, fmt.Errorf("swap compacted file: %w", renameErr)
+	}
+
+	if fi, err := os.Stat(path); err == nil {
+		after = fi.Size()
+	}
+	return before, after, nil
+}
+
+// RunMaintenance logs database stats every interval and compacts the file
+// when free pages exceed minFreeRatio of its size. Blocks until ctx is done.
+func (b *Bolt) RunMaintenance(ctx context.Context, interval time.Duration, minFreeRatio float64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		st, err := b.Stats(ctx)
+		if err != nil {
+			log.Printf("[store] maintenance stats: %v", err)
+			continue
+		}
+		log.Printf("[store] db size=%d free_pages=%d free_ratio=%.2f", st.FileSize, st.FreePages, st.FreeRatio())
+		if st.FreeRatio() < minFreeRatio {
+			continue
+		}
+		before, after, err := b.Compact(ctx)
+		if err != nil {
+			log.Printf("[store] maintenance compact: %v", err)
+			continue
+		}
+		log.Printf("[store] compacted %d -> %d bytes", before, after)
+	}
+}
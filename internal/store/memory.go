@@ -0,0 +1,414 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process Store. Nothing survives a restart; it backs unit
+// tests and the --no-persist run mode.
+type Memory struct {
+	mu       sync.RWMutex
+	wallets  map[string]time.Time
+	users    map[int64]map[string]time.Time // user -> watchlist
+	settings map[string]string
+	setAt    map[string]time.Time      // settings key -> last set
+	history  map[string][]HistoryEntry // wallet -> entries, oldest first
+	open     map[string]map[string]Position
+	closed   map[string][]Position
+	notified map[string]time.Time // "<wallet>|<signature>" -> when
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		wallets:  make(map[string]time.Time),
+		users:    make(map[int64]map[string]time.Time),
+		settings: make(map[string]string),
+		setAt:    make(map[string]time.Time),
+		history:  make(map[string][]HistoryEntry),
+		open:     make(map[string]map[string]Position),
+		closed:   make(map[string][]Position),
+		notified: make(map[string]time.Time),
+	}
+}
+
+// Close is a no-op.
+func (m *Memory) Close() error { return nil }
+
+// AddWallet inserts the address if not present. Idempotent.
+func (m *Memory) AddWallet(ctx context.Context, addr string) error {
+	addr = strings.TrimSpace(addr)
+	if err := validateSolanaAddress(addr); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.wallets[addr]; !ok {
+		m.wallets[addr] = time.Now().UTC()
+	}
+	return nil
+}
+
+// RemoveWallet deletes the address if present. Idempotent.
+func (m *Memory) RemoveWallet(ctx context.Context, addr string) error {
+	addr = strings.TrimSpace(addr)
+	if err := validateSolanaAddress(addr); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	delete(m.wallets, addr)
+	m.mu.Unlock()
+	return nil
+}
+
+// ListWallets returns all tracked addresses, sorted lexicographically.
+func (m *Memory) ListWallets(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]string, 0, len(m.wallets))
+	for a := range m.wallets {
+		out = append(out, a)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// AddUserWallet adds addr to user's watchlist; see Store for the errors.
+func (m *Memory) AddUserWallet(ctx context.Context, user int64, addr string) error {
+	addr = strings.TrimSpace(addr)
+	if err := validateSolanaAddress(addr); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.users[user] == nil {
+		m.users[user] = make(map[string]time.Time)
+	}
+	if _, ok := m.users[user][addr]; ok {
+		return ErrWalletAlreadyTracked
+	}
+	m.users[user][addr] = time.Now().UTC()
+	return nil
+}
+
+// RemoveUserWallet drops addr from user's watchlist; see Store for the errors.
+func (m *Memory) RemoveUserWallet(ctx context.Context, user int64, addr string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	addr = strings.TrimSpace(addr)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[user][addr]; !ok {
+		return ErrWalletNotFound
+	}
+	delete(m.users[user], addr)
+	return nil
+}
+
+// ListUserWallets returns user's watchlist, sorted lexicographically.
+func (m *Memory) ListUserWallets(ctx context.Context, user int64) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]string, 0, len(m.users[user]))
+	for a := range m.users[user] {
+		out = append(out, a)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// ListWalletUsers returns the users watching addr, in ascending order.
+func (m *Memory) ListWalletUsers(ctx context.Context, addr string) ([]int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	addr = strings.TrimSpace(addr)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []int64
+	for u, list := range m.users {
+		if _, ok := list[addr]; ok {
+			out = append(out, u)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+// GetSetting returns the value stored under key and whether it exists.
+func (m *Memory) GetSetting(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.settings[key]
+	return v, ok, nil
+}
+
+// SetSetting stores value under key.
+func (m *Memory) SetSetting(ctx context.Context, key, value string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return errors.New("empty setting key")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.settings[key] = value
+	m.setAt[key] = time.Now()
+	m.mu.Unlock()
+	return nil
+}
+
+// DeleteSetting removes key. Idempotent.
+func (m *Memory) DeleteSetting(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	delete(m.settings, key)
+	delete(m.setAt, key)
+	m.mu.Unlock()
+	return nil
+}
+
+// PruneSettings deletes the settings under prefix last set before cutoff
+// and returns how many.
+func (m *Memory) PruneSettings(ctx context.Context, prefix string, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for key, at := range m.setAt {
+		if strings.HasPrefix(key, prefix) && at.Before(cutoff) {
+			delete(m.settings, key)
+			delete(m.setAt, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// AddHistory appends an entry, replacing one with the same time and signature.
+func (m *Memory) AddHistory(ctx context.Context, e HistoryEntry) error {
+	e.Wallet = strings.TrimSpace(e.Wallet)
+	if e.Wallet == "" || e.Signature == "" {
+		return errors.New("history entry needs wallet and signature")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := m.history[e.Wallet]
+	for i := range list {
+		if list[i].Signature == e.Signature && list[i].Time.Equal(e.Time) {
+			list[i] = e
+			return nil
+		}
+	}
+	list = append(list, e)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+	m.history[e.Wallet] = list
+	return nil
+}
+
+// RecentHistory returns up to limit entries for wallet, newest first.
+func (m *Memory) RecentHistory(ctx context.Context, wallet string, limit int) ([]HistoryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := m.history[wallet]
+	var out []HistoryEntry
+	for i := len(list) - 1; i >= 0; i-- {
+		out = append(out, list[i])
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// RecentSizes returns the USD sizes of up to limit recent priced entries
+// among the newest limit*sizeScanFactor.
+func (m *Memory) RecentSizes(ctx context.Context, wallet string, limit int) ([]float64, error) {
+	entries, err := m.RecentHistory(ctx, wallet, limit*sizeScanFactor)
+	if err != nil {
+		return nil, err
+	}
+	var sizes []float64
+	for _, e := range entries {
+		if e.SizeUSD <= 0 {
+			continue
+		}
+		sizes = append(sizes, e.SizeUSD)
+		if limit > 0 && len(sizes) >= limit {
+			break
+		}
+	}
+	return sizes, nil
+}
+
+// PruneHistory deletes entries older than cutoff and returns how many.
+func (m *Memory) PruneHistory(ctx context.Context, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deleted := 0
+	for w, list := range m.history {
+		i := sort.Search(len(list), func(i int) bool { return !list[i].Time.Before(cutoff) })
+		deleted += i
+		if i == len(list) {
+			delete(m.history, w)
+			continue
+		}
+		m.history[w] = append([]HistoryEntry(nil), list[i:]...)
+	}
+	return deleted, nil
+}
+
+// GetPosition returns the open position for wallet/mint, if any.
+func (m *Memory) GetPosition(ctx context.Context, wallet, mint string) (Position, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return Position{}, false, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.open[wallet][mint]
+	return p, ok, nil
+}
+
+// PutPosition creates or replaces the open position for p.Wallet/p.Mint.
+func (m *Memory) PutPosition(ctx context.Context, p Position) error {
+	if p.Wallet == "" || p.Mint == "" {
+		return errors.New("position needs wallet and mint")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.open[p.Wallet] == nil {
+		m.open[p.Wallet] = make(map[string]Position)
+	}
+	m.open[p.Wallet][p.Mint] = p
+	return nil
+}
+
+// ClosePosition removes the open position and archives p as closed.
+func (m *Memory) ClosePosition(ctx context.Context, p Position) error {
+	if p.Wallet == "" || p.Mint == "" {
+		return errors.New("position needs wallet and mint")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.ClosedAt.IsZero() {
+		p.ClosedAt = time.Now().UTC()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.open[p.Wallet], p.Mint)
+	m.closed[p.Wallet] = append(m.closed[p.Wallet], p)
+	return nil
+}
+
+// ListPositions returns the wallet's open positions, ordered by mint.
+func (m *Memory) ListPositions(ctx context.Context, wallet string) ([]Position, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Position, 0, len(m.open[wallet]))
+	for _, p := range m.open[wallet] {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Mint < out[j].Mint })
+	return out, nil
+}
+
+// ListClosedPositions returns the wallet's closed positions, oldest first.
+func (m *Memory) ListClosedPositions(ctx context.Context, wallet string) ([]Position, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Position(nil), m.closed[wallet]...), nil
+}
+
+// MarkNotified records that signature was handled for wallet at at.
+func (m *Memory) MarkNotified(ctx context.Context, wallet, signature string, at time.Time) error {
+	if wallet == "" || signature == "" {
+		return errors.New("notified entry needs wallet and signature")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.notified[wallet+"|"+signature] = at
+	m.mu.Unlock()
+	return nil
+}
+
+// NotifiedSince reports whether signature was handled for wallet since since.
+func (m *Memory) NotifiedSince(ctx context.Context, wallet, signature string, since time.Time) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	at, ok := m.notified[wallet+"|"+signature]
+	return ok && !at.Before(since), nil
+}
+
+// PruneNotified forgets entries older than cutoff and returns how many.
+func (m *Memory) PruneNotified(ctx context.Context, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for k, at := range m.notified {
+		if at.Before(cutoff) {
+			delete(m.notified, k)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	walletA = "7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU"
+	walletB = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+)
+
+func TestMemoryUserWallets(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	if err := m.AddUserWallet(ctx, 1, walletA); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddUserWallet(ctx, 2, walletA); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddUserWallet(ctx, 1, walletB); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddUserWallet(ctx, 1, walletA); !errors.Is(err, ErrWalletAlreadyTracked) {
+		t.Errorf("second add = %v, want ErrWalletAlreadyTracked", err)
+	}
+	if err := m.AddUserWallet(ctx, 1, "not-an-address"); err == nil {
+		t.Error("invalid address accepted")
+	}
+
+	if got, _ := m.ListUserWallets(ctx, 1); !slices.Equal(got, []string{walletA, walletB}) {
+		t.Errorf("user 1 watchlist = %v", got)
+	}
+	if got, _ := m.ListWalletUsers(ctx, walletA); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("owners of A = %v, want [1 2]", got)
+	}
+
+	if err := m.RemoveUserWallet(ctx, 2, walletB); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("removing another user's wallet = %v, want ErrWalletNotFound", err)
+	}
+	if err := m.RemoveUserWallet(ctx, 1, walletA); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.ListWalletUsers(ctx, walletA); !slices.Equal(got, []int64{2}) {
+		t.Errorf("owners of A after removal = %v, want [2]", got)
+	}
+	if got, _ := m.ListUserWallets(ctx, 1); !slices.Equal(got, []string{walletB}) {
+		t.Errorf("user 1 watchlist after removal = %v", got)
+	}
+}
+
+func TestMemoryRecentHistory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// Added out of order; RecentHistory sorts by time.
+	for _, i := range []int{2, 0, 3, 1} {
+		e := HistoryEntry{Wallet: walletA, Signature: string(rune('a' + i)), Time: base.Add(time.Duration(i) * time.Minute)}
+		if err := m.AddHistory(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Same signature and time replaces rather than duplicates.
+	if err := m.AddHistory(ctx, HistoryEntry{Wallet: walletA, Signature: "d", Time: base.Add(3 * time.Minute), Type: "SWAP"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sigs := func(list []HistoryEntry) string {
+		var s string
+		for _, e := range list {
+			s += e.Signature
+		}
+		return s
+	}
+	tests := []struct {
+		limit int
+		want  string
+	}{
+		{0, "dcba"},
+		{2, "dc"},
+		{10, "dcba"},
+	}
+	for _, tt := range tests {
+		got, err := m.RecentHistory(ctx, walletA, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sigs(got) != tt.want {
+			t.Errorf("RecentHistory(limit %d) = %q, want %q", tt.limit, sigs(got), tt.want)
+		}
+	}
+	if got, _ := m.RecentHistory(ctx, walletA, 1); got[0].Type != "SWAP" {
+		t.Errorf("replaced entry type = %q, want SWAP", got[0].Type)
+	}
+}
+
+func TestMemoryPositions(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	p := Position{Wallet: walletA, Mint: walletB, Symbol: "USDC", Amount: 10, CostUSD: 10}
+	if err := m.PutPosition(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := m.GetPosition(ctx, walletA, walletB)
+	if err != nil || !ok || got.Amount != 10 {
+		t.Fatalf("GetPosition = %+v, %t, %v", got, ok, err)
+	}
+
+	p.Amount, p.RealizedUSD = 0, 2.5
+	if err := m.ClosePosition(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := m.GetPosition(ctx, walletA, walletB); ok {
+		t.Error("position still open after close")
+	}
+	if open, _ := m.ListPositions(ctx, walletA); len(open) != 0 {
+		t.Errorf("open positions = %v", open)
+	}
+	closed, _ := m.ListClosedPositions(ctx, walletA)
+	if len(closed) != 1 || closed[0].RealizedUSD != 2.5 || closed[0].ClosedAt.IsZero() {
+		t.Errorf("closed positions = %+v", closed)
+	}
+	if err := m.PutPosition(ctx, Position{Wallet: walletA}); err == nil {
+		t.Error("position without mint accepted")
+	}
+}
+
+func TestMemoryPrune(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	now := time.Now().UTC()
+	for i, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, time.Hour} {
+		e := HistoryEntry{Wallet: walletA, Signature: string(rune('a' + i)), Time: now.Add(-age)}
+		if err := m.AddHistory(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = m.AddHistory(ctx, HistoryEntry{Wallet: walletB, Signature: "old", Time: now.Add(-96 * time.Hour)})
+
+	n, err := m.PruneHistory(ctx, now.Add(-24*time.Hour))
+	if err != nil || n != 3 {
+		t.Fatalf("PruneHistory = %d, %v; want 3", n, err)
+	}
+	if got, _ := m.RecentHistory(ctx, walletA, 0); len(got) != 1 || got[0].Signature != "c" {
+		t.Errorf("history of A after prune = %v", got)
+	}
+	if got, _ := m.RecentHistory(ctx, walletB, 0); len(got) != 0 {
+		t.Errorf("history of B after prune = %v", got)
+	}
+
+	for _, key := range []string{"networth:a", "networth:b", "symbol:x"} {
+		if err := m.SetSetting(ctx, key, "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := m.PruneSettings(ctx, "networth:", now.Add(-time.Hour)); n != 0 {
+		t.Errorf("PruneSettings with a past cutoff removed %d", n)
+	}
+	if n, _ := m.PruneSettings(ctx, "networth:", time.Now().Add(time.Second)); n != 2 {
+		t.Errorf("PruneSettings removed %d, want 2", n)
+	}
+	if _, ok, _ := m.GetSetting(ctx, "networth:a"); ok {
+		t.Error("networth:a survived the prune")
+	}
+	if _, ok, _ := m.GetSetting(ctx, "symbol:x"); !ok {
+		t.Error("symbol:x outside the prefix was pruned")
+	}
+}
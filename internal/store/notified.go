@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// notifiedBucket remembers which signatures were alerted for which wallet,
+// keyed by name("<wallet>|<signature>") with an 8-byte unix-nanos value, so
+// replays after a restart are not alerted twice. Entries are short-lived and
+// pruned by the retention loop.
+const notifiedBucket = "notified"
+
+// MarkNotified records that signature was handled for wallet at at.
+func (b *Bolt) MarkNotified(ctx context.Context, wallet, signature string, at time.Time) error {
+	if wallet == "" || signature == "" {
+		return errors.New("notified entry needs wallet and signature")
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	val := make([]byte, 8)
+	binary.BigEndian.PutUint64(val, uint64(at.UnixNano()))
+	return b.update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(notifiedBucket))
+		if bkt == nil {
+			return errors.New("notified bucket missing")
+		}
+		return bkt.Put(b.name(wallet+"|"+signature), val)
+	})
+}
+
+// NotifiedSince reports whether signature was handled for wallet at or
+// after since.
+func (b *Bolt) NotifiedSince(ctx context.Context, wallet, signature string, since time.Time) (bool, error) {
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+	var seen bool
+	err := b.view(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(notifiedBucket))
+		if bkt == nil {
+			return errors.New("notified bucket missing")
+		}
+		v := bkt.Get(b.name(wallet + "|" + signature))
+		seen = len(v) == 8 && int64(binary.BigEndian.Uint64(v)) >= since.UnixNano()
+		return nil
+	})
+	return seen, err
+}
+
+// PruneNotified forgets entries older than cutoff and returns how many.
+func (b *Bolt) PruneNotified(ctx context.Context, cutoff time.Time) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+	deleted := 0
+	err := b.update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(notifiedBucket))
+		if bkt == nil {
+			return errors.New("notified bucket missing")
+		}
+		var stale [][]byte
+		if err := bkt.ForEach(func(k, v []byte) error {
+			if len(v) != 8 || int64(binary.BigEndian.Uint64(v)) < cutoff.UnixNano() {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, k := range stale {
+			if err := bkt.Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted = len(stale)
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Open positions live in positions/<wallet>/<mint>; closed ones are moved to
+// closed_positions/<wallet>/<zero-padded closed unix nanos>|<mint>. With
+// encryption on, wallet and mint are HMAC names and values are sealed.
+
+// GetPosition returns the open position for wallet/mint, if any.
+func (b *Bolt) GetPosition(ctx context.Context, wallet, mint string) (Position, bool, error) {
+	select {
+	case <-ctx.Done():
+		return Position{}, false, ctx.Err()
+	default:
+	}
+
+	var (
+		p     Position
+		found bool
+	)
+	err := b.view(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(positionsBucket))
+		if root == nil {
+			return errors.New("positions bucket missing")
+		}
+		bkt := root.Bucket(b.name(wallet))
+		if bkt == nil {
+			return nil
+		}
+		v := bkt.Get(b.name(mint))
+		if v == nil {
+			return nil
+		}
+		plain, err := b.open(v)
+		if err != nil {
+			return fmt.Errorf("decrypt position: %w", err)
+		}
+		found = true
+		return json.Unmarshal(plain, &p)
+	})
+	return p, found, err
+}
+
+// PutPosition creates or replaces the open position for p.Wallet/p.Mint.
+func (b *Bolt) PutPosition(ctx context.Context, p Position) error {
+	if p.Wallet == "" || p.Mint == "" {
+		return errors.New("position needs wallet and mint")
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	val, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("encode position: %w", err)
+	}
+	if val, err = b.seal(val); err != nil {
+		return err
+	}
+
+	return b.update(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(positionsBucket))
+		if root == nil {
+			return errors.New("positions bucket missing")
+		}
+		bkt, err := root.CreateBucketIfNotExists(b.name(p.Wallet))
+		if err != nil {
+			return err
+		}
+		return bkt.Put(b.name(p.Mint), val)
+	})
+}
+
+// ClosePosition removes the open position and archives p as closed.
+func (b *Bolt) ClosePosition(ctx context.Context, p Position) error {
+	if p.Wallet == "" || p.Mint == "" {
+		return errors.New("position needs wallet and mint")
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if p.ClosedAt.IsZero() {
+		p.ClosedAt = time.Now().UTC()
+	}
+	val, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("encode position: %w", err)
+	}
+	if val, err = b.seal(val); err != nil {
+		return err
+	}
+
+	return b.update(func(tx *bbolt.Tx) error {
+		open := tx.Bucket([]byte(positionsBucket))
+		closed := tx.Bucket([]byte(closedPositionsBucket))
+		if open == nil || closed == nil {
+			return errors.New("positions buckets missing")
+		}
+		if bkt := open.Bucket(b.name(p.Wallet)); bkt != nil {
+			if err := bkt.Delete(b.name(p.Mint)); err != nil {
+				return err
+			}
+		}
+		bkt, err := closed.CreateBucketIfNotExists(b.name(p.Wallet))
+		if err != nil {
+			return err
+		}
+		return bkt.Put(b.timedName(p.ClosedAt.UnixNano(), p.Mint), val)
+	})
+}
+
+// ListPositions returns the wallet's open positions, ordered by mint.
+func (b *Bolt) ListPositions(ctx context.Context, wallet string) ([]Position, error) {
+	return b.listPositions(ctx, positionsBucket, wallet)
+}
+
+// ListClosedPositions returns the wallet's closed positions, oldest first.
+func (b *Bolt) ListClosedPositions(ctx context.Context, wallet string) ([]Position, error) {
+	return b.listPositions(ctx, closedPositionsBucket, wallet)
+}
+
+func (b *Bolt) listPositions(ctx context.Context, bucket, wallet string) ([]Position, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	var out []Position
+	err := b.view(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(bucket))
+		if root == nil {
+			return fmt.Errorf("%s bucket missing", bucket)
+		}
+		bkt := root.Bucket(b.name(wallet))
+		if bkt == nil {
+			return nil
+		}
+		return bkt.ForEach(func(_, v []byte) error {
+			var p Position
+			plain, err := b.open(v)
+			if err != nil {
+				return fmt.Errorf("decrypt position: %w", err)
+			}
+			if err := json.Unmarshal(plain, &p); err != nil {
+				return nil // skip corrupt rows
+			}
+			out = append(out, p)
+			return nil
+		})
+	})
+	return out, err
+}
@@ -0,0 +1,497 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq" // registers the "postgres" driver
+)
+
+// Postgres is a Store backed by PostgreSQL. Unlike Bolt it can be shared by
+// several bot instances and companion tools at once.
+type Postgres struct {
+	db *sql.DB
+}
+
+// migrations are applied in order; each index is a schema version. Append
+// only — never edit a migration that has shipped.
+var migrations = []string{
+	// 1: initial schema
+	`CREATE TABLE wallets (
+		address  TEXT PRIMARY KEY,
+		added_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE TABLE settings (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	CREATE TABLE history (
+		wallet    TEXT        NOT NULL,
+		signature TEXT        NOT NULL,
+		time      TIMESTAMPTZ NOT NULL,
+		size_usd  DOUBLE PRECISION NOT NULL DEFAULT 0,
+		entry     JSONB       NOT NULL,
+		PRIMARY KEY (wallet, time, signature)
+	);
+	CREATE INDEX history_time_idx ON history (time);
+	CREATE TABLE positions (
+		wallet   TEXT NOT NULL,
+		mint     TEXT NOT NULL,
+		position JSONB NOT NULL,
+		PRIMARY KEY (wallet, mint)
+	);
+	CREATE TABLE closed_positions (
+		id        BIGSERIAL PRIMARY KEY,
+		wallet    TEXT        NOT NULL,
+		mint      TEXT        NOT NULL,
+		closed_at TIMESTAMPTZ NOT NULL,
+		position  JSONB       NOT NULL
+	);
+	CREATE INDEX closed_positions_wallet_idx ON closed_positions (wallet, closed_at);`,
+
+	// 2: per-user watchlists
+	`CREATE TABLE user_wallets (
+		user_id  BIGINT      NOT NULL,
+		address  TEXT        NOT NULL,
+		added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (user_id, address)
+	);
+	CREATE INDEX user_wallets_address_idx ON user_wallets (address);`,
+
+	// 3: alert dedupe across restarts
+	`CREATE TABLE notified (
+		wallet    TEXT        NOT NULL,
+		signature TEXT        NOT NULL,
+		at        TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (wallet, signature)
+	);
+	CREATE INDEX notified_at_idx ON notified (at);`,
+
+	// 4: settings pruning
+	`ALTER TABLE settings ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+	CREATE INDEX settings_updated_at_idx ON settings (updated_at);`,
+}
+
+// migrationLockID serialises migrations across instances starting together.
+const migrationLockID = 0x736f6c77 // "solw"
+
+// NewPostgres connects to dsn, sizes the connection pool and applies any
+// pending migrations.
+func NewPostgres(ctx context.Context, dsn string, maxConns int) (*Postgres, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	if maxConns > 0 {
+		db.SetMaxOpenConns(maxConns)
+		db.SetMaxIdleConns(maxConns)
+	}
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("connect postgres: %w", err)
+	}
+	p := &Postgres{db: db}
+	if err := p.migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrate postgres: %w", err)
+	}
+	return p, nil
+}
+
+func (p *Postgres) migrate(ctx context.Context) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return err
+	}
+	var current int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema v%d is newer than this binary (v%d)", current, len(migrations))
+	}
+	for v := current + 1; v <= len(migrations); v++ {
+		if _, err := tx.ExecContext(ctx, migrations[v-1]); err != nil {
+			return fmt.Errorf("v%d: %w", v, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, v); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close releases the connection pool.
+func (p *Postgres) Close() error { return p.db.Close() }
+
+// AddWallet inserts the address if not present. Idempotent.
+func (p *Postgres) AddWallet(ctx context.Context, addr string) error {
+	addr = strings.TrimSpace(addr)
+	if err := validateSolanaAddress(addr); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO wallets (address) VALUES ($1) ON CONFLICT DO NOTHING`, addr)
+	return err
+}
+
+// RemoveWallet deletes the address if present. Idempotent.
+func (p *Postgres) RemoveWallet(ctx context.Context, addr string) error {
+	addr = strings.TrimSpace(addr)
+	if err := validateSolanaAddress(addr); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	_, err := p.db.ExecContext(ctx, `DELETE FROM wallets WHERE address = $1`, addr)
+	return err
+}
+
+// ListWallets returns all tracked addresses, sorted lexicographically.
+func (p *Postgres) ListWallets(ctx context.Context) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT address FROM wallets ORDER BY address`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// AddUserWallet adds addr to user's watchlist; see Store for the errors.
+func (p *Postgres) AddUserWallet(ctx context.Context, user int64, addr string) error {
+	addr = strings.TrimSpace(addr)
+	if err := validateSolanaAddress(addr); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	res, err := p.db.ExecContext(ctx,
+		`INSERT INTO user_wallets (user_id, address) VALUES ($1, $2) ON CONFLICT DO NOTHING`, user, addr)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrWalletAlreadyTracked
+	}
+	return nil
+}
+
+// RemoveUserWallet drops addr from user's watchlist; see Store for the errors.
+func (p *Postgres) RemoveUserWallet(ctx context.Context, user int64, addr string) error {
+	res, err := p.db.ExecContext(ctx,
+		`DELETE FROM user_wallets WHERE user_id = $1 AND address = $2`, user, strings.TrimSpace(addr))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrWalletNotFound
+	}
+	return nil
+}
+
+// ListUserWallets returns user's watchlist, sorted lexicographically.
+func (p *Postgres) ListUserWallets(ctx context.Context, user int64) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT address FROM user_wallets WHERE user_id = $1 ORDER BY address`, user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var a string
+		if err := rows.Scan(&a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// ListWalletUsers returns the users watching addr, in ascending order.
+func (p *Postgres) ListWalletUsers(ctx context.Context, addr string) ([]int64, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT user_id FROM user_wallets WHERE address = $1 ORDER BY user_id`, strings.TrimSpace(addr))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var u int64
+		if err := rows.Scan(&u); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// GetSetting returns the value stored under key and whether it exists.
+func (p *Postgres) GetSetting(ctx context.Context, key string) (string, bool, error) {
+	var v string
+	err := p.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = $1`, key).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	return v, err == nil, err
+}
+
+// SetSetting stores value under key.
+func (p *Postgres) SetSetting(ctx context.Context, key, value string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return errors.New("empty setting key")
+	}
+	_, err := p.db.ExecContext(ctx, `INSERT INTO settings (key, value) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = now()`, key, value)
+	return err
+}
+
+// DeleteSetting removes key. Idempotent.
+func (p *Postgres) DeleteSetting(ctx context.Context, key string) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM settings WHERE key = $1`, key)
+	return err
+}
+
+// PruneSettings deletes the settings under prefix last set before cutoff
+// and returns how many.
+func (p *Postgres) PruneSettings(ctx context.Context, prefix string, cutoff time.Time) (int, error) {
+	res, err := p.db.ExecContext(ctx, `DELETE FROM settings WHERE left(key, length($1)) = $1 AND updated_at < $2`, prefix, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// AddHistory appends an entry, replacing one with the same time and signature.
+func (p *Postgres) AddHistory(ctx context.Context, e HistoryEntry) error {
+	e.Wallet = strings.TrimSpace(e.Wallet)
+	if e.Wallet == "" || e.Signature == "" {
+		return errors.New("history entry needs wallet and signature")
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	val, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode history: %w", err)
+	}
+	_, err = p.db.ExecContext(ctx, `INSERT INTO history (wallet, signature, time, size_usd, entry)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet, time, signature) DO UPDATE SET size_usd = EXCLUDED.size_usd, entry = EXCLUDED.entry`,
+		e.Wallet, e.Signature, e.Time, e.SizeUSD, val)
+	return err
+}
+
+// RecentHistory returns up to limit entries for wallet, newest first.
+// A limit <= 0 returns everything.
+func (p *Postgres) RecentHistory(ctx context.Context, wallet string, limit int) ([]HistoryEntry, error) {
+	q := `SELECT entry FROM history WHERE wallet = $1 ORDER BY time DESC`
+	args := []any{wallet}
+	if limit > 0 {
+		q += ` LIMIT $2`
+		args = append(args, limit)
+	}
+	rows, err := p.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []HistoryEntry
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			continue // skip corrupt rows rather than failing the whole read
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// RecentSizes returns the USD sizes of up to limit of the wallet's most recent
+// priced transactions. Unpriced entries are skipped.
+func (p *Postgres) RecentSizes(ctx context.Context, wallet string, limit int) ([]float64, error) {
+	q := `SELECT size_usd FROM history WHERE wallet = $1 AND size_usd > 0 ORDER BY time DESC`
+	args := []any{wallet}
+	if limit > 0 {
+		q += ` LIMIT $2`
+		args = append(args, limit)
+	}
+	rows, err := p.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sizes []float64
+	for rows.Next() {
+		var s float64
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, s)
+	}
+	return sizes, rows.Err()
+}
+
+// PruneHistory deletes entries older than cutoff and returns how many.
+func (p *Postgres) PruneHistory(ctx context.Context, cutoff time.Time) (int, error) {
+	res, err := p.db.ExecContext(ctx, `DELETE FROM history WHERE time < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// GetPosition returns the open position for wallet/mint, if any.
+func (p *Postgres) GetPosition(ctx context.Context, wallet, mint string) (Position, bool, error) {
+	var raw []byte
+	err := p.db.QueryRowContext(ctx,
+		`SELECT position FROM positions WHERE wallet = $1 AND mint = $2`, wallet, mint).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Position{}, false, nil
+	}
+	if err != nil {
+		return Position{}, false, err
+	}
+	var pos Position
+	if err := json.Unmarshal(raw, &pos); err != nil {
+		return Position{}, false, err
+	}
+	return pos, true, nil
+}
+
+// PutPosition creates or replaces the open position for pos.Wallet/pos.Mint.
+func (p *Postgres) PutPosition(ctx context.Context, pos Position) error {
+	if pos.Wallet == "" || pos.Mint == "" {
+		return errors.New("position needs wallet and mint")
+	}
+	val, err := json.Marshal(pos)
+	if err != nil {
+		return fmt.Errorf("encode position: %w", err)
+	}
+	_, err = p.db.ExecContext(ctx, `INSERT INTO positions (wallet, mint, position) VALUES ($1, $2, $3)
+		ON CONFLICT (wallet, mint) DO UPDATE SET position = EXCLUDED.position`,
+		pos.Wallet, pos.Mint, val)
+	return err
+}
+
+// ClosePosition removes the open position and archives pos as closed.
+func (p *Postgres) ClosePosition(ctx context.Context, pos Position) error {
+	if pos.Wallet == "" || pos.Mint == "" {
+		return errors.New("position needs wallet and mint")
+	}
+	if pos.ClosedAt.IsZero() {
+		pos.ClosedAt = time.Now().UTC()
+	}
+	val, err := json.Marshal(pos)
+	if err != nil {
+		return fmt.Errorf("encode position: %w", err)
+	}
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM positions WHERE wallet = $1 AND mint = $2`, pos.Wallet, pos.Mint); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO closed_positions (wallet, mint, closed_at, position) VALUES ($1, $2, $3, $4)`,
+		pos.Wallet, pos.Mint, pos.ClosedAt, val); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListPositions returns the wallet's open positions, ordered by mint.
+func (p *Postgres) ListPositions(ctx context.Context, wallet string) ([]Position, error) {
+	return p.queryPositions(ctx,
+		`SELECT position FROM positions WHERE wallet = $1 ORDER BY mint`, wallet)
+}
+
+// ListClosedPositions returns the wallet's closed positions, oldest first.
+func (p *Postgres) ListClosedPositions(ctx context.Context, wallet string) ([]Position, error) {
+	return p.queryPositions(ctx,
+		`SELECT position FROM closed_positions WHERE wallet = $1 ORDER BY closed_at, id`, wallet)
+}
+
+func (p *Postgres) queryPositions(ctx context.Context, q, wallet string) ([]Position, error) {
+	rows, err := p.db.QueryContext(ctx, q, wallet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Position
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var pos Position
+		if err := json.Unmarshal(raw, &pos); err != nil {
+			return nil, fmt.Errorf("decode position: %w", err)
+		}
+		out = append(out, pos)
+	}
+	return out, rows.Err()
+}
+
+// MarkNotified records that signature was handled for wallet at at.
+func (p *Postgres) MarkNotified(ctx context.Context, wallet, signature string, at time.Time) error {
+	if wallet == "" || signature == "" {
+		return errors.New("notified entry needs wallet and signature")
+	}
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO notified (wallet, signature, at) VALUES ($1, $2, $3)
+		 ON CONFLICT (wallet, signature) DO UPDATE SET at = EXCLUDED.at`, wallet, signature, at)
+	return err
+}
+
+// NotifiedSince reports whether signature was handled for wallet since since.
+func (p *Postgres) NotifiedSince(ctx context.Context, wallet, signature string, since time.Time) (bool, error) {
+	var seen bool
+	err := p.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM notified WHERE wallet = $1 AND signature = $2 AND at >= $3)`,
+		wallet, signature, since).Scan(&seen)
+	return seen, err
+}
+
+// PruneNotified forgets entries older than cutoff and returns how many.
+func (p *Postgres) PruneNotified(ctx context.Context, cutoff time.Time) (int, error) {
+	res, err := p.db.ExecContext(ctx, `DELETE FROM notified WHERE at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// settingTimesBucket records when each setting was last set, under the same
+// key as in settingsBucket, as "<unix nanos>\n<key>" (sealed when
+// encrypted) so PruneSettings can match prefixes of hashed keys. Settings
+// written before it existed have no entry and are never pruned.
+const settingTimesBucket = "setting_times"
+
+// GetSetting returns the value stored under key and whether it exists.
+func (b *Bolt) GetSetting(ctx context.Context, key string) (string, bool, error) {
+	select {
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	default:
+	}
+
+	var (
+		val   string
+		found bool
+	)
+	err := b.view(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(settingsBucket))
+		if bkt == nil {
+			return errors.New("settings bucket missing")
+		}
+		v := bkt.Get(b.name(key))
+		if v == nil {
+			return nil
+		}
+		plain, err := b.open(v)
+		if err != nil {
+			return fmt.Errorf("decrypt setting: %w", err)
+		}
+		val, found = string(plain), true
+		return nil
+	})
+	return val, found, err
+}
+
+// SetSetting stores value under key, replacing any previous value.
+func (b *Bolt) SetSetting(ctx context.Context, key, value string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return errors.New("empty setting key")
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	val, err := b.seal([]byte(value))
+	if err != nil {
+		return err
+	}
+	at, err := b.seal([]byte(strconv.FormatInt(time.Now().UnixNano(), 10) + "\n" + key))
+	if err != nil {
+		return err
+	}
+
+	return b.update(func(tx *bbolt.Tx) error {
+		bkt, times := tx.Bucket([]byte(settingsBucket)), tx.Bucket([]byte(settingTimesBucket))
+		if bkt == nil || times == nil {
+			return errors.New("settings bucket missing")
+		}
+		if err := times.Put(b.name(key), at); err != nil {
+			return err
+		}
+		return bkt.Put(b.name(key), val)
+	})
+}
+
+// DeleteSetting removes key. Idempotent.
+func (b *Bolt) DeleteSetting(ctx context.Context, key string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	return b.update(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(settingsBucket))
+		if bkt == nil {
+			return errors.New("settings bucket missing")
+		}
+		if times := tx.Bucket([]byte(settingTimesBucket)); times != nil {
+			if err := times.Delete(b.name(key)); err != nil {
+				return err
+			}
+		}
+		return bkt.Delete(b.name(key))
+	})
+}
+
+// PruneSettings deletes the settings under prefix last set before cutoff
+// and returns how many.
+func (b *Bolt) PruneSettings(ctx context.Context, prefix string, cutoff time.Time) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+	deleted := 0
+	err := b.update(func(tx *bbolt.Tx) error {
+		bkt, times := tx.Bucket([]byte(settingsBucket)), tx.Bucket([]byte(settingTimesBucket))
+		if bkt == nil || times == nil {
+			return errors.New("settings bucket missing")
+		}
+		var stale [][]byte
+		if err := times.ForEach(func(k, v []byte) error {
+			plain, err := b.open(v)
+			if err != nil {
+				return fmt.Errorf("decrypt setting time: %w", err)
+			}
+			nanos, key, ok := strings.Cut(string(plain), "\n")
+			if !ok || !strings.HasPrefix(key, prefix) {
+				return nil
+			}
+			if n, err := strconv.ParseInt(nanos, 10, 64); err == nil && n < cutoff.UnixNano() {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, k := range stale {
+			if err := times.Delete(k); err != nil {
+				return err
+			}
+			if err := bkt.Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted = len(stale)
+		return nil
+	})
+	return deleted, err
+}
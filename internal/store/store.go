@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Store is everything the service persists. Bolt is the on-disk
+// implementation, Postgres the shared one for multi-instance deployments, and
+// Memory an ephemeral one for tests and --no-persist runs.
+type Store interface {
+	// Wallets
+	AddWallet(ctx context.Context, addr string) error
+	RemoveWallet(ctx context.Context, addr string) error
+	ListWallets(ctx context.Context) ([]string, error)
+
+	// Per-user watchlists (multi-tenant mode). ListWallets is their union.
+	// Adding a wallet twice returns ErrWalletAlreadyTracked, removing one
+	// that isn't there ErrWalletNotFound; both leave the list as it was.
+	AddUserWallet(ctx context.Context, user int64, addr string) error
+	RemoveUserWallet(ctx context.Context, user int64, addr string) error
+	ListUserWallets(ctx context.Context, user int64) ([]string, error)
+	ListWalletUsers(ctx context.Context, addr string) ([]int64, error)
+
+	// Settings (free-form key/value). PruneSettings deletes the settings
+	// whose key starts with prefix and that were last set before cutoff.
+	GetSetting(ctx context.Context, key string) (string, bool, error)
+	SetSetting(ctx context.Context, key, value string) error
+	DeleteSetting(ctx context.Context, key string) error
+	PruneSettings(ctx context.Context, prefix string, cutoff time.Time) (int, error)
+
+	// History
+	AddHistory(ctx context.Context, e HistoryEntry) error
+	RecentHistory(ctx context.Context, wallet string, limit int) ([]HistoryEntry, error)
+	RecentSizes(ctx context.Context, wallet string, limit int) ([]float64, error)
+	PruneHistory(ctx context.Context, cutoff time.Time) (int, error)
+
+	// Positions
+	GetPosition(ctx context.Context, wallet, mint string) (Position, bool, error)
+	PutPosition(ctx context.Context, p Position) error
+	ClosePosition(ctx context.Context, p Position) error
+	ListPositions(ctx context.Context, wallet string) ([]Position, error)
+	ListClosedPositions(ctx context.Context, wallet string) ([]Position, error)
+
+	// Alert dedupe across restarts
+	MarkNotified(ctx context.Context, wallet, signature string, at time.Time) error
+	NotifiedSince(ctx context.Context, wallet, signature string, since time.Time) (bool, error)
+	PruneNotified(ctx context.Context, cutoff time.Time) (int, error)
+
+	Close() error
+}
+
+// Position is a wallet's holding of one token as reconstructed from the
+// trades we observed. Only amounts bought while tracking are known.
+type Position struct {
+	Wallet      string    `json:"wallet"`
+	Mint        string    `json:"mint"`
+	Symbol      string    `json:"symbol"`
+	Amount      float64   `json:"amount"`       // tokens currently held
+	CostUSD     float64   `json:"cost_usd"`     // cost basis of Amount
+	InvestedUSD float64   `json:"invested_usd"` // total USD spent buying
+	RealizedUSD float64   `json:"realized_usd"` // profit/loss booked on sells
+	OpenedAt    time.Time `json:"opened_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ClosedAt    time.Time `json:"closed_at,omitempty"` // zero while open
+}
+
+var (
+	_ Store = (*Bolt)(nil)
+	_ Store = (*Memory)(nil)
+	_ Store = (*Postgres)(nil)
+)
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Per-user watchlists live in user_wallets/<user id>/<address>. The value
+// repeats user and address so both survive encryption (where the keys are
+// HMAC names). The global wallets bucket stays the union of all watchlists.
+const userWalletsBucket = "user_wallets"
+
+type userWallet struct {
+	User    int64     `json:"user"`
+	Addr    string    `json:"addr"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// UserSettingKey namespaces a setting key to one user so Get/SetSetting can
+// hold per-user preferences next to global ones.
+func UserSettingKey(user int64, key string) string {
+	return "user:" + strconv.FormatInt(user, 10) + ":" + key
+}
+
+// AddUserWallet adds addr to user's watchlist; see Store for the errors.
+func (b *Bolt) AddUserWallet(ctx context.Context, user int64, addr string) error {
+	addr = strings.TrimSpace(addr)
+	if err := validateSolanaAddress(addr); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	val, err := json.Marshal(userWallet{User: user, Addr: addr, AddedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	if val, err = b.seal(val); err != nil {
+		return err
+	}
+
+	return b.update(func(tx *bbolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists([]byte(userWalletsBucket))
+		if err != nil {
+			return err
+		}
+		bkt, err := root.CreateBucketIfNotExists(b.name(strconv.FormatInt(user, 10)))
+		if err != nil {
+			return err
+		}
+		if bkt.Get(b.name(addr)) != nil {
+			return ErrWalletAlreadyTracked
+		}
+		return bkt.Put(b.name(addr), val)
+	})
+}
+
+// RemoveUserWallet drops addr from user's watchlist; see Store for the errors.
+func (b *Bolt) RemoveUserWallet(ctx context.Context, user int64, addr string) error {
+	addr = strings.TrimSpace(addr)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	return b.update(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(userWalletsBucket))
+		if root == nil {
+			return ErrWalletNotFound
+		}
+		bkt := root.Bucket(b.name(strconv.FormatInt(user, 10)))
+		if bkt == nil || bkt.Get(b.name(addr)) == nil {
+			return ErrWalletNotFound
+		}
+		return bkt.Delete(b.name(addr))
+	})
+}
+
+// ListUserWallets returns user's watchlist, sorted lexicographically.
+func (b *Bolt) ListUserWallets(ctx context.Context, user int64) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	var out []string
+	err := b.view(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(userWalletsBucket))
+		if root == nil {
+			return nil
+		}
+		bkt := root.Bucket(b.name(strconv.FormatInt(user, 10)))
+		if bkt == nil {
+			return nil
+		}
+		return bkt.ForEach(func(_, v []byte) error {
+			uw, err := b.decodeUserWallet(v)
+			if err != nil {
+				return err
+			}
+			out = append(out, uw.Addr)
+			return nil
+		})
+	})
+	sort.Strings(out)
+	return out, err
+}
+
+// ListWalletUsers returns the users watching addr, in ascending order.
+func (b *Bolt) ListWalletUsers(ctx context.Context, addr string) ([]int64, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	key := b.name(strings.TrimSpace(addr))
+	var out []int64
+	err := b.view(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(userWalletsBucket))
+		if root == nil {
+			return nil
+		}
+		return root.ForEachBucket(func(user []byte) error {
+			v := root.Bucket(user).Get(key)
+			if v == nil {
+				return nil
+			}
+			uw, err := b.decodeUserWallet(v)
+			if err != nil {
+				return err
+			}
+			out = append(out, uw.User)
+			return nil
+		})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, err
+}
+
+func (b *Bolt) decodeUserWallet(v []byte) (userWallet, error) {
+	var uw userWallet
+	plain, err := b.open(v)
+	if err != nil {
+		return uw, fmt.Errorf("decrypt user wallet: %w", err)
+	}
+	if err := json.Unmarshal(plain, &uw); err != nil {
+		return uw, errors.New("corrupt user wallet row")
+	}
+	return uw, nil
+}
@@ -0,0 +1,152 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+	tg "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// trialCallback prefixes the inline button data of a counterparty offer.
+const trialCallback = "trial:"
+
+// offerLog remembers which counterparties were offered to which chat so a
+// wallet moving funds back and forth doesn't re-offer on every transfer.
+type offerLog struct {
+	mu   sync.Mutex
+	seen map[string]time.Time // chat:addr -> offered at
+}
+
+// claim reports whether addr may be offered to chatID, at most once per ttl.
+func (o *offerLog) claim(chatID int64, addr string, ttl time.Duration) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.seen == nil {
+		o.seen = make(map[string]time.Time)
+	}
+	now := time.Now()
+	for k, t := range o.seen {
+		if now.Sub(t) >= ttl {
+			delete(o.seen, k)
+		}
+	}
+	key := fmt.Sprintf("%d:%s", chatID, addr)
+	if _, ok := o.seen[key]; ok {
+		return false
+	}
+	o.seen[key] = now
+	return true
+}
+
+func (h *Handler) trialLength() time.Duration {
+	if h.AutoTrackTrial > 0 {
+		return h.AutoTrackTrial
+	}
+	return DefaultTrial
+}
+
+// considerCounterparty offers, or with AutoTrack starts, a trial of the
+// wallet on the other side of a large plain transfer. Exchanges, programs
+// and wallets in AutoTrackIgnore are skipped, and so are transfers of a
+// chat's own trial wallets, so trials don't spawn further trials.
+func (h *Handler) considerCounterparty(res *analyzer.Result, chats []int64) {
+	cp := res.Counterparty
+	if h.AutoTrackMinUSD <= 0 || cp == "" || res.SizeUSD < h.AutoTrackMinUSD || h.AutoTrackIgnore[cp] {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var todo []int64
+	owners := h.tm.Owners(cp)
+	for _, chatID := range chats {
+		if _, onTrial := h.trialsOf(ctx, chatID)[res.Wallet]; onTrial {
+			continue
+		}
+		if !contains64(owners, chatID) && h.offers.claim(chatID, cp, h.trialLength()) {
+			todo = append(todo, chatID)
+		}
+	}
+	if len(todo) == 0 {
+		return
+	}
+	ok, reason, err := h.analyzer.Personal(ctx, cp)
+	if err != nil {
+		log.Printf("[handler] counterparty check for %s: %v", cp, err)
+		return
+	}
+	if !ok {
+		log.Printf("[handler] not offering counterparty %s: %s", cp, reason)
+		return
+	}
+
+	verb, prep := "sent", "to"
+	if len(res.Sent) == 0 {
+		verb, prep = "received", "from"
+	}
+	what := fmt.Sprintf("<code>%s</code> %s $%.0f %s <code>%s</code>",
+		escapeHTML(shortAddr(res.Wallet)), verb, res.SizeUSD, prep, escapeHTML(cp))
+	length := holdString(h.trialLength())
+
+	for _, chatID := range todo {
+		if !h.AutoTrack {
+			metrics.Inc("autotrack.offered")
+			h.sendButton(ctx, chatID, fmt.Sprintf("🧭 %s.\nTrack the counterparty for %s? It's dropped again if it stays quiet.", what, length),
+				"🔭 Track for "+length, trialCallback+cp)
+			continue
+		}
+		now := time.Now()
+		if err := h.startTrial(ctx, trial{User: chatID, Addr: cp, Started: now, Until: now.Add(h.trialLength()), IfQuiet: true}); err != nil {
+			log.Printf("[handler] auto-track %s for %d: %v", cp, chatID, err)
+			continue
+		}
+		metrics.Inc("autotrack.started")
+		h.sendHTML(ctx, chatID, fmt.Sprintf("🧭 %s.\nTracking the counterparty for %s; it's dropped again if it stays quiet. <code>/track %s</code> keeps it for good.",
+			what, length, escapeHTML(cp)))
+	}
+}
+
+// handleTrialButton starts the trial offered by considerCounterparty.
+func (h *Handler) handleTrialButton(ctx context.Context, q *models.CallbackQuery) {
+	chatID := q.From.ID
+	if q.Message.Message != nil {
+		chatID = q.Message.Message.Chat.ID
+	}
+	answer := "tracking for " + holdString(h.trialLength())
+	addr := strings.TrimPrefix(q.Data, trialCallback)
+	now := time.Now()
+	switch {
+	case !h.isAllowed(chatID):
+		return
+	case !isBase58Len(addr, 32):
+		answer = "invalid address"
+	case contains64(h.tm.Owners(addr), chatID):
+		answer = "already tracked"
+	default:
+		if err := h.startTrial(ctx, trial{User: chatID, Addr: addr, Started: now, Until: now.Add(h.trialLength()), IfQuiet: true}); err != nil {
+			answer = "track failed: " + util.RedactURLs(err.Error())
+		} else {
+			metrics.Inc("autotrack.accepted")
+		}
+	}
+	if _, err := h.bot.AnswerCallbackQuery(ctx, &tg.AnswerCallbackQueryParams{CallbackQueryID: q.ID, Text: answer}); err != nil {
+		log.Printf("[telegram] answer callback: %v", err)
+	}
+}
+
+func contains64(list []int64, v int64) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tg "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// /untrack, /mute, /unmute and /export take either an address or a
+// tag:<tag> selector (group:<tag> is an alias) for every wallet of the chat
+// with that tag. A selector first replies with how many wallets it
+// resolved to and a confirm button; the operation runs on that set once
+// confirmed, within bulkConfirmTTL.
+
+const (
+	bulkCallback   = "bulk:" // inline button data confirming a pending bulk operation
+	bulkConfirmTTL = 5 * time.Minute
+	bulkPreview    = 10  // wallets listed in a confirmation
+	exportHistory  = 500 // history entries per wallet in /export
+)
+
+// bulkOp is one command applied to the wallets selector resolves to.
+type bulkOp struct {
+	verb     string        // "untrack", "mute", "unmute" or "export"
+	selector string        // an address, or tag:<tag>
+	period   time.Duration // mute length
+
+	chatID  int64
+	wallets []string
+	expires time.Time
+}
+
+// bulkLog holds bulk operations awaiting confirmation.
+type bulkLog struct {
+	mu      sync.Mutex
+	next    int
+	pending map[string]bulkOp
+}
+
+// put stores op and returns its callback id.
+func (l *bulkLog) put(op bulkOp) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending == nil {
+		l.pending = make(map[string]bulkOp)
+	}
+	now := time.Now()
+	for id, p := range l.pending {
+		if now.After(p.expires) {
+			delete(l.pending, id)
+		}
+	}
+	l.next++
+	id := strconv.Itoa(l.next)
+	op.expires = now.Add(bulkConfirmTTL)
+	l.pending[id] = op
+	return id
+}
+
+// take removes and returns chatID's pending op id, if it hasn't expired.
+func (l *bulkLog) take(id string, chatID int64) (bulkOp, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	op, ok := l.pending[id]
+	if !ok || op.chatID != chatID {
+		return bulkOp{}, false
+	}
+	delete(l.pending, id)
+	return op, time.Now().Before(op.expires)
+}
+
+// selectorTag returns the tag of a tag:/group: selector.
+func selectorTag(sel string) (string, bool) {
+	lower := strings.ToLower(sel)
+	for _, prefix := range []string{"tag:", "group:"} {
+		if tag, ok := strings.CutPrefix(lower, prefix); ok {
+			return strings.TrimPrefix(tag, "#"), true
+		}
+	}
+	return "", false
+}
+
+// runBulk resolves op.selector among chatID's wallets and applies op, at
+// once for an address and after confirmation for a tag.
+func (h *Handler) runBulk(ctx context.Context, chatID int64, op bulkOp) {
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, op.verb+" failed: "+errorText(err))
+		return
+	}
+	op.chatID = chatID
+	tag, isTag := selectorTag(op.selector)
+	if !isTag {
+		if !contains(wallets, op.selector) {
+			h.sendHTML(ctx, chatID, "that wallet isn't tracked. see <code>/tracked</code>")
+			return
+		}
+		op.wallets = []string{op.selector}
+		h.applyBulk(ctx, op)
+		return
+	}
+	for _, a := range wallets {
+		if contains(h.walletTags(ctx, chatID, a), tag) {
+			op.wallets = append(op.wallets, a)
+		}
+	}
+	if len(op.wallets) == 0 {
+		h.sendHTML(ctx, chatID, fmt.Sprintf("no wallet tagged #%s", escapeHTML(tag)))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠️ <b>%s %d wallet(s)</b> tagged #%s", bulkVerb(op), len(op.wallets), escapeHTML(tag))
+	b.WriteString(":\n")
+	for i, a := range op.wallets {
+		if i == bulkPreview {
+			fmt.Fprintf(&b, "<i>…and %d more</i>\n", len(op.wallets)-bulkPreview)
+			break
+		}
+		fmt.Fprintf(&b, "- <code>%s</code>\n", escapeHTML(a))
+	}
+	fmt.Fprintf(&b, "\nConfirm within %s; otherwise nothing changes.", holdString(bulkConfirmTTL))
+	id := h.bulk.put(op)
+	h.sendButton(ctx, chatID, b.String(), fmt.Sprintf("✅ %s %d", bulkVerb(op), len(op.wallets)), bulkCallback+id)
+}
+
+// bulkVerb describes op for the confirmation, e.g. "Mute for 12h".
+func bulkVerb(op bulkOp) string {
+	switch op.verb {
+	case "mute":
+		return "Mute for " + holdString(op.period)
+	case "unmute":
+		return "Unmute"
+	case "export":
+		return "Export"
+	}
+	return "Untrack"
+}
+
+// applyBulk runs op on op.wallets and reports the outcome.
+func (h *Handler) applyBulk(ctx context.Context, op bulkOp) {
+	chatID := op.chatID
+	if op.verb == "export" {
+		h.sendExport(ctx, chatID, op.wallets)
+		return
+	}
+	var done, failed int
+	var lastErr error
+	for _, a := range op.wallets {
+		var err error
+		switch op.verb {
+		case "untrack":
+			err = h.untrackFor(ctx, chatID, a)
+		case "mute":
+			err = h.mute(ctx, chatID, a, op.period)
+		case "unmute":
+			err = h.mute(ctx, chatID, a, 0)
+		}
+		if err != nil {
+			failed++
+			lastErr = err
+			continue
+		}
+		done++
+	}
+	// An address selector gets a one-line reply; /untrack <address> is
+	// handled on its own and never comes here.
+	if _, isTag := selectorTag(op.selector); !isTag {
+		if lastErr != nil {
+			h.sendHTML(ctx, chatID, op.verb+" failed: "+errorText(lastErr))
+			return
+		}
+		a := escapeHTML(op.wallets[0])
+		switch op.verb {
+		case "mute":
+			h.sendHTML(ctx, chatID, fmt.Sprintf("🔇 <code>%s</code> muted for %s", a, holdString(op.period)))
+		case "unmute":
+			h.sendHTML(ctx, chatID, fmt.Sprintf("🔔 <code>%s</code> unmuted", a))
+		}
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("%s done: %d wallet(s), failed=%d", op.verb, done, failed))
+}
+
+// sendExport uploads the history and positions of wallets as JSON.
+func (h *Handler) sendExport(ctx context.Context, chatID int64, wallets []string) {
+	exp, err := store.ExportWallets(ctx, h.st, wallets, exportHistory)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "export failed: "+errorText(err))
+		return
+	}
+	raw, err := json.MarshalIndent(exp, "", "  ")
+	if err != nil {
+		h.sendHTML(ctx, chatID, "export failed: "+errorText(err))
+		return
+	}
+	name := fmt.Sprintf("solwatch-export-%s.json", exp.GeneratedAt.Format("20060102-150405"))
+	_, err = h.bot.SendDocument(ctx, &tg.SendDocumentParams{
+		ChatID:    chatID,
+		Document:  &models.InputFileUpload{Filename: name, Data: bytes.NewReader(raw)},
+		Caption:   fmt.Sprintf("📦 %d wallet(s), up to %d history entries each", len(wallets), exportHistory),
+		ParseMode: models.ParseModeHTML,
+	})
+	if err != nil {
+		log.Printf("[telegram] send document error: %v", err)
+	}
+}
+
+// handleExport sends a JSON export of wallets.
+//
+//	/export <address|tag:<tag>>
+func (h *Handler) handleExport(ctx context.Context, chatID int64, args []string) {
+	if len(args) != 1 {
+		h.sendHTML(ctx, chatID, "usage: <code>/export &lt;address|tag:&lt;tag&gt;&gt;</code>")
+		return
+	}
+	h.runBulk(ctx, chatID, bulkOp{verb: "export", selector: args[0]})
+}
+
+// handleBulkButton runs the bulk operation a confirm button refers to.
+func (h *Handler) handleBulkButton(ctx context.Context, q *models.CallbackQuery) {
+	chatID := q.From.ID
+	if q.Message.Message != nil {
+		chatID = q.Message.Message.Chat.ID
+	}
+	if !h.isAllowed(chatID) {
+		return
+	}
+	op, ok := h.bulk.take(strings.TrimPrefix(q.Data, bulkCallback), chatID)
+	answer := "confirmed"
+	if !ok {
+		answer = "expired or already done; run the command again"
+	}
+	if _, err := h.bot.AnswerCallbackQuery(ctx, &tg.AnswerCallbackQueryParams{CallbackQueryID: q.ID, Text: answer}); err != nil {
+		log.Printf("[telegram] answer callback: %v", err)
+	}
+	if ok {
+		h.applyBulk(ctx, op)
+	}
+}
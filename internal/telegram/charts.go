@@ -0,0 +1,160 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/chart"
+	"github.com/0xsamyy/solwatch-v2/internal/portfolio"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// maxPnLBars keeps the token PnL chart readable.
+const maxPnLBars = 15
+
+// chartWallets resolves the optional address argument of /pnl and /networth,
+// defaulting to the chat's whole watchlist. An address must be on the
+// watchlist. It replies with usage or the reason and returns nil when there
+// is nothing to chart.
+func (h *Handler) chartWallets(ctx context.Context, chatID int64, args []string, usage string) ([]string, string) {
+	list, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "chart failed: "+errorText(err))
+		return nil, ""
+	}
+	if len(args) > 0 {
+		addrs := parseAddressArg(args[0])
+		switch {
+		case len(addrs) == 0:
+			h.sendHTML(ctx, chatID, usage)
+			return nil, ""
+		case !contains(list, addrs[0]):
+			h.sendHTML(ctx, chatID, "that wallet isn't tracked. see <code>/tracked</code>")
+			return nil, ""
+		}
+		return addrs[:1], shortAddr(addrs[0])
+	}
+	if len(list) == 0 {
+		h.sendHTML(ctx, chatID, usage)
+		return nil, ""
+	}
+	return list, fmt.Sprintf("%d wallets", len(list))
+}
+
+// handlePnL charts realized PnL per token and, with enough closed positions,
+// cumulative realized PnL over time.
+func (h *Handler) handlePnL(ctx context.Context, chatID int64, args []string) {
+	wallets, label := h.chartWallets(ctx, chatID, args, "usage: <code>/pnl [address]</code> (defaults to your tracked wallets)")
+	if wallets == nil {
+		return
+	}
+
+	byToken := make(map[string]float64)
+	var closed []store.Position
+	for _, w := range wallets {
+		cl, err := h.st.ListClosedPositions(ctx, w)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "pnl failed: "+errorText(err))
+			return
+		}
+		open, err := h.st.ListPositions(ctx, w)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "pnl failed: "+errorText(err))
+			return
+		}
+		closed = append(closed, cl...)
+		for _, p := range append(cl, open...) {
+			if p.RealizedUSD != 0 {
+				byToken[portfolio.Label(p)] += p.RealizedUSD
+			}
+		}
+	}
+	if len(byToken) == 0 {
+		h.sendHTML(ctx, chatID, "no realized PnL yet (positions are only booked for trades seen while tracking)")
+		return
+	}
+
+	tokens := make([]string, 0, len(byToken))
+	var total float64
+	for t, v := range byToken {
+		tokens = append(tokens, t)
+		total += v
+	}
+	sort.Slice(tokens, func(i, j int) bool { return math.Abs(byToken[tokens[i]]) > math.Abs(byToken[tokens[j]]) })
+	if len(tokens) > maxPnLBars {
+		tokens = tokens[:maxPnLBars]
+	}
+	values := make([]float64, len(tokens))
+	for i, t := range tokens {
+		values[i] = byToken[t]
+	}
+	png, err := chart.Bars("Realized PnL by token (USD)", tokens, values)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "chart failed: "+errorText(err))
+		return
+	}
+	h.sendPhoto(ctx, chatID, "pnl.png", png, fmt.Sprintf("💹 <b>PnL · %s</b>\nrealized: <code>%+.2f USD</code>", escapeHTML(label), total))
+
+	if len(closed) < 2 {
+		return
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].ClosedAt.Before(closed[j].ClosedAt) })
+	ts := make([]time.Time, len(closed))
+	cum := make([]float64, len(closed))
+	var run float64
+	for i, p := range closed {
+		run += p.RealizedUSD
+		ts[i], cum[i] = p.ClosedAt, run
+	}
+	if png, err = chart.TimeLine("Cumulative realized PnL (USD)", ts, cum); err == nil {
+		h.sendPhoto(ctx, chatID, "pnl-cumulative.png", png, "")
+	}
+}
+
+// handleNetWorth charts the sampled net worth series. Samples from one pass
+// share a timestamp, so a watchlist is summed point by point.
+func (h *Handler) handleNetWorth(ctx context.Context, chatID int64, args []string) {
+	wallets, label := h.chartWallets(ctx, chatID, args, "usage: <code>/networth [address]</code> (defaults to your tracked wallets)")
+	if wallets == nil {
+		return
+	}
+
+	sum := make(map[time.Time]float64)
+	for _, w := range wallets {
+		samples, err := portfolio.Samples(ctx, h.st, w)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "networth failed: "+errorText(err))
+			return
+		}
+		for _, s := range samples {
+			sum[s.Time] += s.USD
+		}
+	}
+	if len(sum) < 2 {
+		h.sendHTML(ctx, chatID, "not enough net worth samples yet; they are recorded every <code>NETWORTH_INTERVAL</code>")
+		return
+	}
+
+	ts := make([]time.Time, 0, len(sum))
+	for t := range sum {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+	usd := make([]float64, len(ts))
+	for i, t := range ts {
+		usd[i] = sum[t]
+	}
+	png, err := chart.TimeLine("Net worth (USD)", ts, usd)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "chart failed: "+errorText(err))
+		return
+	}
+	first, last := usd[0], usd[len(usd)-1]
+	h.sendPhoto(ctx, chatID, "networth.png", png, fmt.Sprintf(
+		"💰 <b>Net worth · %s</b>\nnow: <code>%.2f USD</code> (%+.2f since %s)\n<i>SOL at market price, tokens at cost basis</i>",
+		escapeHTML(label), last, last-first, ts[0].Format("Jan 02"),
+	))
+}
@@ -0,0 +1,165 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// DefaultConfluenceWindow is used when ConfluenceWindow is zero.
+const DefaultConfluenceWindow = time.Hour
+
+// confluenceBuy is one tracked wallet buying a token.
+type confluenceBuy struct {
+	wallet string
+	symbol string
+	usd    float64
+	at     time.Time
+}
+
+// confluenceTracker remembers recent buys per mint so several tracked
+// wallets piling into the same token can be reported together. State is in
+// memory only; a restart starts every window afresh.
+type confluenceTracker struct {
+	mu    sync.Mutex
+	buys  map[string][]confluenceBuy // mint -> buys inside the window, oldest first
+	fired map[string]time.Time       // chat:mint -> last confluence alert
+}
+
+func newConfluenceTracker() *confluenceTracker {
+	return &confluenceTracker{
+		buys:  make(map[string][]confluenceBuy),
+		fired: make(map[string]time.Time),
+	}
+}
+
+// record adds b for mint, drops buys older than window and returns a copy
+// of what is left for mint.
+func (c *confluenceTracker) record(mint string, b confluenceBuy, window time.Duration) []confluenceBuy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := b.at.Add(-window)
+	for m, list := range c.buys {
+		i := 0
+		for i < len(list) && list[i].at.Before(cutoff) {
+			i++
+		}
+		if i == len(list) {
+			delete(c.buys, m)
+		} else {
+			c.buys[m] = list[i:]
+		}
+	}
+	for k, t := range c.fired {
+		if t.Before(cutoff) {
+			delete(c.fired, k)
+		}
+	}
+	c.buys[mint] = append(c.buys[mint], b)
+	return append([]confluenceBuy(nil), c.buys[mint]...)
+}
+
+// claim reports whether chat may be alerted about mint now, at most once
+// per window.
+func (c *confluenceTracker) claim(chatID int64, mint string, now time.Time, window time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%d:%s", chatID, mint)
+	if last, ok := c.fired[key]; ok && now.Sub(last) < window {
+		return false
+	}
+	c.fired[key] = now
+	return true
+}
+
+// checkConfluence records res's buys and alerts every chat in which at
+// least ConfluenceWallets of its own tracked wallets bought the same token
+// within ConfluenceWindow. Only a chat's own wallets are counted and listed,
+// so tenants never see each other's watchlists.
+func (h *Handler) checkConfluence(ctx context.Context, res *analyzer.Result, chats []int64) {
+	if h.ConfluenceWallets < 2 {
+		return
+	}
+	window := h.ConfluenceWindow
+	if window <= 0 {
+		window = DefaultConfluenceWindow
+	}
+	now := time.Now()
+	for _, amt := range res.Bought() {
+		buys := h.confluence.record(amt.Mint, confluenceBuy{wallet: res.Wallet, symbol: amt.Symbol, usd: amt.USD, at: now}, window)
+		for _, chatID := range chats {
+			own := h.ownBuys(chatID, buys)
+			if len(own) < h.ConfluenceWallets || !h.confluence.claim(chatID, amt.Mint, now, window) {
+				continue
+			}
+			metrics.Inc("confluence.alerts")
+			log.Printf("[handler] confluence on %s: %d wallets for chat %d", amt.Mint, len(own), chatID)
+			h.sendHTML(ctx, chatID, confluenceText(amt, own, window, now))
+		}
+	}
+}
+
+// ownBuys keeps the buys by wallets chatID tracks, merged per wallet, in
+// order of first buy.
+func (h *Handler) ownBuys(chatID int64, buys []confluenceBuy) []confluenceBuy {
+	var out []confluenceBuy
+	idx := make(map[string]int)
+	for _, b := range buys {
+		if i, ok := idx[b.wallet]; ok {
+			out[i].usd += b.usd
+			continue
+		}
+		if !h.owns(chatID, b.wallet) {
+			continue
+		}
+		idx[b.wallet] = len(out)
+		out = append(out, b)
+	}
+	return out
+}
+
+// owns mirrors recipients: unowned wallets belong to the admin.
+func (h *Handler) owns(chatID int64, wallet string) bool {
+	owners := h.tm.Owners(wallet)
+	if len(owners) == 0 {
+		return chatID == h.adminID
+	}
+	for _, u := range owners {
+		if u == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+func confluenceText(amt analyzer.Amount, buys []confluenceBuy, window time.Duration, now time.Time) string {
+	sort.SliceStable(buys, func(i, j int) bool { return buys[i].usd > buys[j].usd })
+	var (
+		b     strings.Builder
+		total float64
+	)
+	fmt.Fprintf(&b, "🧲🧲 <b>CONFLUENCE: %d wallets bought %s</b> within %s 🧲🧲\n\n",
+		len(buys), escapeHTML(amt.Symbol), holdString(window))
+	for _, buy := range buys {
+		total += buy.usd
+		size := "size unknown"
+		if buy.usd > 0 {
+			size = fmt.Sprintf("$%.2f", buy.usd)
+		}
+		fmt.Fprintf(&b, "- <code>%s</code> %s (%s ago)\n", escapeHTML(shortAddr(buy.wallet)), size, holdString(now.Sub(buy.at)))
+	}
+	if total > 0 {
+		fmt.Fprintf(&b, "\nCombined: <b>$%.2f</b>", total)
+	}
+	fmt.Fprintf(&b, "\n<code>%s</code>", escapeHTML(amt.Mint))
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// dbMaintainer is implemented by stores backed by a file (Bolt).
+type dbMaintainer interface {
+	Stats(ctx context.Context) (store.DBStats, error)
+	Compact(ctx context.Context) (before, after int64, err error)
+}
+
+func (h *Handler) handleDB(ctx context.Context, chatID int64, args []string) {
+	dbm, ok := h.st.(dbMaintainer)
+	if !ok {
+		h.sendHTML(ctx, chatID, "database maintenance is not available for the in-memory store")
+		return
+	}
+	if len(args) != 1 {
+		h.sendHTML(ctx, chatID, "usage: <code>/db stats</code> or <code>/db compact</code>")
+		return
+	}
+
+	switch args[0] {
+	case "stats":
+		st, err := dbm.Stats(ctx)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "db stats failed: "+errorText(err))
+			return
+		}
+		var b strings.Builder
+		b.WriteString("🗄 <b>Database</b>\n")
+		b.WriteString(fmt.Sprintf("- File: <code>%s</code>\n", escapeHTML(st.Path)))
+		b.WriteString(fmt.Sprintf("- Size: <code>%s</code>\n", humanBytes(st.FileSize)))
+		b.WriteString(fmt.Sprintf("- Free pages: <code>%d</code> (+%d pending, %.0f%% of file)\n", st.FreePages, st.PendingPgs, st.FreeRatio()*100))
+		b.WriteString("<b>Keys per bucket:</b>\n")
+		for _, name := range st.BucketNames() {
+			b.WriteString(fmt.Sprintf("- %s: <code>%d</code>\n", escapeHTML(name), st.Buckets[name]))
+		}
+		h.sendHTML(ctx, chatID, b.String())
+
+	case "compact":
+		h.sendHTML(ctx, chatID, "🧹 compacting database...")
+		before, after, err := dbm.Compact(ctx)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "compact failed: "+errorText(err))
+			return
+		}
+		h.sendHTML(ctx, chatID, fmt.Sprintf("compacted: <code>%s</code> → <code>%s</code>", humanBytes(before), humanBytes(after)))
+
+	default:
+		h.sendHTML(ctx, chatID, "usage: <code>/db stats</code> or <code>/db compact</code>")
+	}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// DefaultDebugErrorGap is how often one kind of error is reported to the
+// debug chat when DebugErrorGap is not set.
+const DefaultDebugErrorGap = 5 * time.Minute
+
+// errorNotices groups NotifyError calls by component and error shape.
+type errorNotices struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+	held map[string]int
+}
+
+// NotifyError reports err to DebugChat; see util.ErrorHandler. Errors that
+// differ only in the signatures or addresses they mention count as the
+// same, and each is reported at most once per DebugErrorGap, with the
+// repeats in between counted into the next report.
+func (h *Handler) NotifyError(component string, err error) {
+	if h.DebugChat == 0 || err == nil {
+		return
+	}
+	gap := h.DebugErrorGap
+	if gap == 0 {
+		gap = DefaultDebugErrorGap
+	}
+	key := component + ": " + util.ErrorShape(err.Error())
+
+	n := &h.errorNotices
+	n.mu.Lock()
+	if n.last == nil {
+		n.last = make(map[string]time.Time)
+		n.held = make(map[string]int)
+	}
+	now := time.Now()
+	for k, t := range n.last {
+		if now.Sub(t) >= gap && n.held[k] == 0 {
+			delete(n.last, k)
+		}
+	}
+	if now.Sub(n.last[key]) < gap {
+		n.held[key]++
+		n.mu.Unlock()
+		return
+	}
+	held := n.held[key]
+	n.last[key] = now
+	delete(n.held, key)
+	n.mu.Unlock()
+
+	msg := fmt.Sprintf("🐞 <b>%s error</b>\n<code>%s</code>", escapeHTML(component), escapeHTML(util.RedactURLs(err.Error())))
+	if held > 0 {
+		msg += fmt.Sprintf("\n(+%d more like it since the last report)", held)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	h.sendHTML(ctx, h.DebugChat, msg)
+}
@@ -0,0 +1,31 @@
+package telegram
+
+import (
+	"errors"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// errorText explains err to a user as HTML: known domain errors get a plain
+// sentence, anything else is shown escaped in <code>, with URLs cut down to
+// their host so API keys in paths and query strings don't leak. Every error
+// shown in a reply goes through here.
+func errorText(err error) string {
+	switch {
+	case errors.Is(err, store.ErrWalletAlreadyTracked):
+		return "that wallet is already on your watchlist"
+	case errors.Is(err, store.ErrWalletNotFound):
+		return "that wallet isn't on your watchlist; see <code>/tracked</code>"
+	case errors.Is(err, errQuota):
+		return escapeHTML(util.RedactURLs(err.Error())) + "; <code>/untrack</code> a wallet first"
+	case errors.Is(err, analyzer.ErrUpstreamRateLimited):
+		return "the data provider is rate-limiting us; try again in a minute"
+	case errors.Is(err, analyzer.ErrTxNotIndexedYet):
+		return "that transaction isn't indexed yet; try again in a few seconds"
+	case errors.Is(err, analyzer.ErrAccountNotFound):
+		return "that account doesn't exist on chain"
+	}
+	return "<code>" + escapeHTML(util.RedactURLs(err.Error())) + "</code>"
+}
@@ -0,0 +1,160 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+	tg "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Alerts sent at "processed" commitment can describe a transaction that is
+// later dropped, fails, or (rarely, on a fork) lands differently. With
+// RecheckFinalized set, each alert is revisited once the transaction
+// finalizes: if the outcome materially changed the message is edited, and if
+// the transaction never finalizes it is retracted. Rechecks run on a fixed
+// pool of workers; alerts arriving while its queue is full are left as sent.
+
+const (
+	finalityPoll     = 10 * time.Second
+	finalityDeadline = 3 * time.Minute // finalization normally takes ~15s
+	// finalityWorkers is wide because a recheck mostly waits between polls.
+	finalityWorkers = 64
+	finalityQueue   = 256
+)
+
+// finalityJob is one alert awaiting its recheck.
+type finalityJob struct {
+	res    *analyzer.Result
+	sent   []sentAlert
+	footer string
+	queued time.Time
+}
+
+// queueFinality hands the delivered alerts of res to the recheck pool,
+// starting the pool on first use.
+func (h *Handler) queueFinality(res *analyzer.Result, sent []sentAlert, footer string) {
+	h.finalityOnce.Do(func() {
+		h.finalityJobs = make(chan finalityJob, finalityQueue)
+		for i := 0; i < finalityWorkers; i++ {
+			go util.Supervise(context.Background(), "finality", func(context.Context) {
+				for job := range h.finalityJobs {
+					h.recheckFinalized(job)
+				}
+			})
+		}
+	})
+	select {
+	case h.finalityJobs <- finalityJob{res: res, sent: sent, footer: footer, queued: time.Now()}:
+	default:
+		metrics.Inc("finality.skipped")
+		util.Errors.Printf("[handler] finality queue full, not rechecking %s", res.Signature)
+	}
+}
+
+// sentAlert is one delivered copy of an alert.
+type sentAlert struct {
+	chatID   int64
+	msgID    int
+	markdown bool
+}
+
+// recheckFinalized polls the transaction's status until it finalizes or the
+// deadline, counted from when the job was queued, passes; then it edits or
+// retracts the delivered alerts.
+func (h *Handler) recheckFinalized(job finalityJob) {
+	res, sent, footer := job.res, job.sent, job.footer
+	deadline := job.queued.Add(finalityDeadline)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline.Add(30*time.Second))
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(finalityPoll):
+		}
+
+		st, err := h.analyzer.SignatureStatus(ctx, res.Signature)
+		if err != nil {
+			log.Printf("[handler] finality check for %s: %v", res.Signature, err)
+		}
+		switch {
+		case err == nil && st.Found && st.Failed:
+			metrics.Inc("finality.failed")
+			h.retract(ctx, res, sent, "❌ <b>Transaction failed on-chain</b> — alert retracted")
+			return
+		case err == nil && st.Level == "finalized":
+			h.compareFinalized(ctx, res, sent, footer)
+			return
+		case time.Now().After(deadline):
+			if err != nil {
+				return // the RPC is unreachable; don't retract on our own failure
+			}
+			metrics.Inc("finality.dropped")
+			h.retract(ctx, res, sent, "🗑 <b>Transaction dropped</b> (never finalized) — alert retracted")
+			return
+		}
+	}
+}
+
+// compareFinalized re-analyzes the finalized transaction and edits the
+// alerts when the outcome differs from what was announced.
+func (h *Handler) compareFinalized(ctx context.Context, res *analyzer.Result, sent []sentAlert, footer string) {
+	final, err := h.analyzer.Reanalyze(ctx, res.Signature, res.Wallet)
+	if err != nil {
+		log.Printf("[handler] finalized re-analysis of %s: %v", res.Signature, err)
+		return
+	}
+	if final == nil || !analyzer.MateriallyDifferent(res, final) {
+		metrics.Inc("finality.confirmed")
+		return
+	}
+	metrics.Inc("finality.changed")
+	log.Printf("[handler] %s changed after finalization; editing %d alert(s)", res.Signature, len(sent))
+	footer += "\n\n✏️ <i>updated after finalization</i>"
+	for _, s := range sent {
+		text, markdown := h.renderAlert(ctx, s.chatID, final)
+		h.editAlert(ctx, s, text, markdown, footer)
+	}
+}
+
+func (h *Handler) retract(ctx context.Context, res *analyzer.Result, sent []sentAlert, reason string) {
+	log.Printf("[handler] retracting %d alert(s) for %s", len(sent), res.Signature)
+	text := fmt.Sprintf("%s\n\nwallet <code>%s</code>\ntx <code>%s</code>",
+		reason, escapeHTML(shortAddr(res.Wallet)), escapeHTML(shortAddr(res.Signature)))
+	for _, s := range sent {
+		if s.markdown {
+			h.editAlert(ctx, s, htmlToMarkdownV2(text), true, "")
+		} else {
+			h.editAlert(ctx, s, text, false, "")
+		}
+	}
+}
+
+// editAlert replaces a delivered alert's text; footer is HTML.
+func (h *Handler) editAlert(ctx context.Context, s sentAlert, text string, markdown bool, footer string) {
+	mode := models.ParseModeHTML
+	if markdown {
+		mode = models.ParseModeMarkdown
+		footer = htmlToMarkdownV2(footer)
+	}
+	disable := true
+	_, err := h.bot.EditMessageText(ctx, &tg.EditMessageTextParams{
+		ChatID:    s.chatID,
+		MessageID: s.msgID,
+		Text:      text + footer,
+		ParseMode: mode,
+		LinkPreviewOptions: &models.LinkPreviewOptions{
+			IsDisabled: &disable,
+		},
+	})
+	if err != nil {
+		log.Printf("[telegram] edit error: %v", err)
+	}
+}
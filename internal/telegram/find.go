@@ -0,0 +1,59 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const maxFindResults = 30
+
+// findMatch reports whether every query word matches addr: a prefix of the
+// address, one of its tags or text in its note, ignoring case.
+func findMatch(words []string, addr string, tags []string, note string) bool {
+	addr, note = strings.ToLower(addr), strings.ToLower(note)
+	for _, w := range words {
+		tag := strings.TrimPrefix(w, "#")
+		if !strings.HasPrefix(addr, w) && !contains(tags, tag) && !strings.Contains(note, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// handleFind searches the chat's watchlist by address prefix, tag and note.
+//
+//	/find <query>
+func (h *Handler) handleFind(ctx context.Context, chatID int64, query string) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		h.sendHTML(ctx, chatID, "usage: <code>/find &lt;address prefix|tag|note text&gt;...</code>")
+		return
+	}
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "find failed: "+errorText(err))
+		return
+	}
+	var b strings.Builder
+	var n int
+	for _, a := range wallets {
+		tags, note := h.walletTags(ctx, chatID, a), h.walletNote(ctx, chatID, a)
+		if !findMatch(words, a, tags, note) {
+			continue
+		}
+		if n++; n > maxFindResults {
+			continue // keep counting
+		}
+		fmt.Fprintf(&b, "- <code>%s</code>%s\n%s", escapeHTML(a), tagSuffix(tags), noteLine(note))
+	}
+	if n == 0 {
+		h.sendHTML(ctx, chatID, fmt.Sprintf("no tracked wallet matches <code>%s</code>", escapeHTML(query)))
+		return
+	}
+	head := fmt.Sprintf("🔎 <b>%d wallet(s) match:</b>\n", n)
+	if n > maxFindResults {
+		b.WriteString(fmt.Sprintf("<i>…and %d more; narrow the query</i>\n", n-maxFindResults))
+	}
+	h.sendHTML(ctx, chatID, head+b.String())
+}
@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+	"github.com/go-telegram/bot/models"
+)
+
+// DefaultFirehoseRateLimit keeps a firehose chat under Telegram's limit of
+// about 20 messages a minute per group.
+const DefaultFirehoseRateLimit = 3 * time.Second
+
+// firehoseKey is the firehose's single walletLimiter slot: the limit is per
+// chat, not per wallet.
+const firehoseKey = "firehose"
+
+// sendFirehose copies res to FirehoseChat, if set, before any of the
+// per-chat filters (settings, severity, plugins, the bot rate limit) run.
+// The firehose has its own rate limit; events over it are counted
+// (firehose.skipped), logged and noted on the next one that goes out. Messages are silent, and the chat's
+// own /set options apply when it is also a user of the bot.
+func (h *Handler) sendFirehose(ctx context.Context, res *analyzer.Result) {
+	if h.FirehoseChat == 0 {
+		return
+	}
+	every := h.FirehoseRateLimit
+	if every == 0 {
+		every = DefaultFirehoseRateLimit
+	}
+	ok, skipped := h.firehose.allow(firehoseKey, every)
+	if !ok {
+		metrics.Inc("firehose.skipped")
+		util.Errors.Printf("[telegram] firehose rate limit: dropped %s %s of %s", res.Type, res.Signature, res.Wallet)
+		return
+	}
+
+	text, markdown := h.renderAlert(ctx, h.FirehoseChat, res)
+	extra := fmt.Sprintf("\n\n🗂 <i>%s</i>", res.Severity)
+	if skipped > 0 {
+		log.Printf("[telegram] firehose: %d event(s) dropped by the rate limit since the last one sent", skipped)
+		extra += fmt.Sprintf(" · <i>+%d event(s) skipped (firehose rate limit)</i>", skipped)
+	}
+	mode := models.ParseModeHTML
+	if markdown {
+		extra, mode = htmlToMarkdownV2(extra), models.ParseModeMarkdown
+	}
+	if h.sendMessage(ctx, h.FirehoseChat, text+extra, mode, true) != 0 {
+		metrics.Inc("firehose.sent")
+	}
+}
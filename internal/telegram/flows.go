@@ -0,0 +1,133 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/portfolio"
+)
+
+// defaultFlowPeriod is the /flows window when none is given.
+const defaultFlowPeriod = 24 * time.Hour
+
+// parsePeriod reads a look-back period: Go durations ("6h", "90m") plus
+// whole days ("7d").
+func parsePeriod(s string) (time.Duration, bool) {
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, err := strconv.Atoi(n)
+		return time.Duration(days) * 24 * time.Hour, err == nil && days > 0
+	}
+	d, err := time.ParseDuration(s)
+	return d, err == nil && d > 0
+}
+
+// handleFlows summarizes net buying and selling of a token across the
+// chat's tracked wallets, from the history store.
+//
+//	/flows <mint> [period]
+func (h *Handler) handleFlows(ctx context.Context, chatID int64, args []string) {
+	const usage = "usage: <code>/flows &lt;mint&gt; [period]</code> (e.g. <code>24h</code>, <code>7d</code>)"
+	if len(args) < 1 || len(args) > 2 || !isBase58Len(args[0], 32) {
+		h.sendHTML(ctx, chatID, usage)
+		return
+	}
+	period := defaultFlowPeriod
+	if len(args) == 2 {
+		d, ok := parsePeriod(args[1])
+		if !ok {
+			h.sendHTML(ctx, chatID, usage)
+			return
+		}
+		period = d
+	}
+
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "flows failed: "+errorText(err))
+		return
+	}
+	since := time.Now().Add(-period)
+	flow := portfolio.TokenFlow{Mint: args[0]}
+	for _, w := range wallets {
+		hist, err := h.st.RecentHistory(ctx, w, maxScanHistory)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "flows failed: "+errorText(err))
+			return
+		}
+		flow.Flow(w, hist, since)
+	}
+	if meta, ok := h.analyzer.CachedMetadata(flow.Mint); ok && !meta.Failed {
+		flow.Symbol = meta.Symbol
+	}
+	if flow.Symbol == "" {
+		flow.Symbol = shortAddr(flow.Mint)
+	}
+	h.sendHTML(ctx, chatID, flowsText(flow, period, h.numberFormat(ctx, chatID)))
+}
+
+func flowsText(flow portfolio.TokenFlow, period time.Duration, nf analyzer.NumberFormat) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🌊 <b>Flows for %s</b> · last %s\n<code>%s</code>\n\n",
+		escapeHTML(flow.Symbol), holdString(period), escapeHTML(flow.Mint))
+	if len(flow.Wallets) == 0 {
+		b.WriteString("No tracked wallet traded it in this period.")
+		return b.String()
+	}
+
+	net := flow.NetSOL()
+	dir := "into"
+	if net < 0 {
+		dir = "out of"
+	}
+	fmt.Fprintf(&b, "Net: <b>%s SOL</b> %s %s", signed(net, nf), dir, escapeHTML(flow.Symbol))
+	if usd := flow.NetUSD(); usd != 0 {
+		fmt.Fprintf(&b, " (%s$%s)", sign(usd), nf.Fixed(math.Abs(usd), 2))
+	}
+	b.WriteString("\n")
+
+	var buyers, sellers []portfolio.WalletFlow
+	for _, w := range flow.Wallets {
+		if w.NetSOL() > 0 || w.NetSOL() == 0 && w.Bought >= w.Sold {
+			buyers = append(buyers, w)
+		} else {
+			sellers = append(sellers, w)
+		}
+	}
+	writeFlows := func(title string, list []portfolio.WalletFlow) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n<b>%s (%d):</b>\n", title, len(list))
+		for _, w := range list {
+			fmt.Fprintf(&b, "- <code>%s</code> ", escapeHTML(shortAddr(w.Wallet)))
+			var parts []string
+			if w.Bought > 0 {
+				parts = append(parts, "bought "+escapeHTML(analyzer.Amount{Symbol: flow.Symbol, Amount: w.Bought}.Format(nf)))
+			}
+			if w.Sold > 0 {
+				parts = append(parts, "sold "+escapeHTML(analyzer.Amount{Symbol: flow.Symbol, Amount: w.Sold}.Format(nf)))
+			}
+			fmt.Fprintf(&b, "%s · %s SOL · %d trade(s), last %s ago\n",
+				strings.Join(parts, ", "), signed(w.NetSOL(), nf), w.Trades, holdString(time.Since(w.LastAt)))
+		}
+	}
+	writeFlows("Net buyers", buyers)
+	writeFlows("Net sellers", sellers)
+	return b.String()
+}
+
+func signed(v float64, nf analyzer.NumberFormat) string {
+	return sign(v) + nf.Fixed(math.Abs(v), 2)
+}
+
+func sign(v float64) string {
+	if v < 0 {
+		return "−"
+	}
+	return "+"
+}
@@ -2,88 +2,282 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/events"
 	"github.com/0xsamyy/solwatch-v2/internal/health"
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/plugins"
+	"github.com/0xsamyy/solwatch-v2/internal/portfolio"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
 	"github.com/0xsamyy/solwatch-v2/internal/tracker"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
 	tg "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
-type WalletStore interface {
-	AddWallet(ctx context.Context, addr string) error
-	RemoveWallet(ctx context.Context, addr string) error
-	ListWallets(ctx context.Context) ([]string, error)
-}
+// NotifiedWindow is how long handled signatures are remembered in the store,
+// so replays after a restart (backfill, gap recovery) are not alerted again.
+const NotifiedWindow = time.Hour
 
 // Handler coordinates Telegram <-> tracker/store/health.
 type Handler struct {
 	bot      *tg.Bot
 	adminID  int64
 	tm       *tracker.Manager
-	st       WalletStore
+	st       store.Store
 	hlth     *health.Health
 	analyzer *analyzer.Analyzer
 	killFn   func()
+
+	// BotRateLimit is the minimum gap between alerts for wallets the analyzer
+	// classifies as likely bots. Zero disables throttling.
+	BotRateLimit time.Duration
+	// SuppressAirdrops drops alerts for unsolicited token receipts.
+	SuppressAirdrops bool
+	// MaxWalletsPerUser caps each non-admin watchlist. Zero means no cap.
+	MaxWalletsPerUser int
+	// CompactAbove makes token amounts at or above it compact ("1.25B") in
+	// alerts, with the exact value in an expandable quote. Zero disables.
+	CompactAbove float64
+	// RecheckFinalized revisits each alert once its transaction finalizes,
+	// editing or retracting it (see recheckFinalized).
+	RecheckFinalized bool
+	// AnalysisTimeout bounds each HandleSignature call, lookups and sends
+	// included (0 = DefaultAnalysisTimeout).
+	AnalysisTimeout time.Duration
+	// Workers is the analysis pool size (0 = DefaultWorkers).
+	Workers int
+	// ConfluenceWallets, when at least 2, sends a confluence alert once that
+	// many of a chat's wallets buy the same token within ConfluenceWindow
+	// (0 = DefaultConfluenceWindow).
+	ConfluenceWallets int
+	ConfluenceWindow  time.Duration
+	// AutoTrackMinUSD, when positive, offers a trial of the counterparty of
+	// any plain transfer at least this large. AutoTrack starts the trial
+	// without asking; AutoTrackTrial is its length (0 = DefaultTrial).
+	// Wallets in AutoTrackIgnore (exchanges, known services) are skipped.
+	AutoTrackMinUSD float64
+	AutoTrack       bool
+	AutoTrackTrial  time.Duration
+	AutoTrackIgnore map[string]bool
+	// FirehoseChat, when set, gets a copy of every analysis result,
+	// unfiltered, at most one per FirehoseRateLimit
+	// (0 = DefaultFirehoseRateLimit).
+	FirehoseChat      int64
+	FirehoseRateLimit time.Duration
+	// DebugChat, when set, gets analyzer and subscriber errors (see
+	// NotifyError), each kind at most once per DebugErrorGap
+	// (0 = DefaultDebugErrorGap).
+	DebugChat     int64
+	DebugErrorGap time.Duration
+	// Events, when set, receives every analysis result that is kept.
+	Events *events.Bus
+	// Plugins, when set, can veto and annotate alerts.
+	Plugins *plugins.Host
+	// Templates are server-wide alert templates by event type (see
+	// LoadTemplates); chats can override them with /template.
+	Templates     map[string]*template.Template
+	chatTemplates templateCache
+	limiter       *walletLimiter
+	firehose      *walletLimiter
+	confluence    *confluenceTracker
+	offers        offerLog
+	bulk          bulkLog
+	trialsMu      sync.Mutex
+	finalityOnce  sync.Once
+	finalityJobs  chan finalityJob
+	allowed       map[int64]bool
+	panics        panicNotices
+	errorNotices  errorNotices
+	started       time.Time // set by AnnounceStart
 }
 
-// New constructs the Telegram Handler and wires the notification callback.
-func New(bot *tg.Bot, tm *tracker.Manager, st WalletStore, hlth *health.Health, an *analyzer.Analyzer, adminID int64, killFn func()) *Handler {
+// New constructs the Telegram Handler. Feed it signatures with Consume.
+func New(bot *tg.Bot, tm *tracker.Manager, st store.Store, hlth *health.Health, an *analyzer.Analyzer, adminID int64, killFn func()) *Handler {
 	h := &Handler{
-		bot:      bot,
-		adminID:  adminID,
-		tm:       tm,
-		st:       st,
-		hlth:     hlth,
-		analyzer: an,
-		killFn:   killFn,
+		bot:        bot,
+		adminID:    adminID,
+		tm:         tm,
+		st:         st,
+		hlth:       hlth,
+		analyzer:   an,
+		killFn:     killFn,
+		limiter:    newWalletLimiter(),
+		firehose:   newWalletLimiter(),
+		confluence: newConfluenceTracker(),
 	}
+	return h
+}
 
-	tracker.SignatureNotify = func(signature string, trackedAddr string) {
-		log.Printf("[handler] analyzing signature %s for wallet %s", signature, trackedAddr)
-		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-		defer cancel()
+// DefaultAnalysisTimeout bounds one HandleSignature call when
+// Handler.AnalysisTimeout is unset.
+const DefaultAnalysisTimeout = 20 * time.Second
 
-		summary, err := h.analyzer.AnalyzeSignature(ctx, signature, trackedAddr)
-		if err != nil {
-			log.Printf("[analyzer] error for %s: %v", signature, err)
-			return
+// HandleSignature analyzes one signature seen for trackedAddr and sends the
+// resulting alert to every recipient.
+func (h *Handler) HandleSignature(signature string, trackedAddr string) {
+	// A panic on one odd transaction must not take the consumer down.
+	defer util.Recover("analysis")
+	timeout := h.AnalysisTimeout
+	if timeout <= 0 {
+		timeout = DefaultAnalysisTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if seen, err := h.st.NotifiedSince(ctx, trackedAddr, signature, time.Now().Add(-NotifiedWindow)); err != nil {
+		log.Printf("[handler] dedupe lookup for %s: %v", signature, err)
+	} else if seen {
+		metrics.Inc("dedupe.replayed")
+		log.Printf("[handler] %s for %s already handled, skipping replay", signature, trackedAddr)
+		return
+	}
+
+	if h.tm.Paused() {
+		metrics.Inc("paused.dropped")
+		log.Printf("[handler] paused, dropping %s for %s", signature, trackedAddr)
+		return
+	}
+
+	log.Printf("[handler] analyzing signature %s for wallet %s", signature, trackedAddr)
+	res, err := h.analyzer.Analyze(ctx, signature, trackedAddr)
+	if err != nil {
+		util.Errors.Printf("[analyzer] error for %s: %v", signature, err)
+		if !errors.Is(err, analyzer.ErrTxNotIndexedYet) {
+			util.ReportError("analyzer", err) // indexing lag isn't an upstream problem
 		}
+		return
+	}
 
-		if summary == "" {
-			log.Printf("[analyzer] signature %s filtered, no notification sent.", signature)
+	if res == nil {
+		log.Printf("[analyzer] signature %s filtered, no notification sent.", signature)
+		return
+	}
+
+	// Mark before any side effect so a restart mid-way neither
+	// re-alerts nor books the trade into positions twice.
+	if err := h.st.MarkNotified(ctx, trackedAddr, signature, time.Now()); err != nil {
+		log.Printf("[handler] dedupe mark for %s: %v", signature, err)
+	}
+	h.sendFirehose(ctx, res)
+
+	res.Tags = h.ownerTags(ctx, trackedAddr)
+	isBot := h.analyzer.Classify(trackedAddr).IsBot()
+	recipients := h.recipients(ctx, res, isBot)
+	if res.Airdrop && len(recipients) == 0 {
+		log.Printf("[handler] airdrop %s to %s suppressed", signature, trackedAddr)
+		return
+	}
+
+	if h.Plugins != nil {
+		ev := events.FromResult(res)
+		if !h.Plugins.Keep(ctx, ev) {
+			log.Printf("[handler] %s dropped by plugin filter", signature)
 			return
 		}
+		for _, note := range h.Plugins.Interpret(ctx, ev) {
+			res.Notes = append(res.Notes, "🧩 "+escapeHTML(note))
+		}
+	}
 
-		shortAddr := trackedAddr[:4] + "..." + trackedAddr[len(trackedAddr)-4:]
-		finalMessage := fmt.Sprintf("🚨 <b>Activity on %s</b>\n\n%s", shortAddr, summary)
-		h.sendHTML(ctx, h.adminID, finalMessage)
+	if err := h.st.AddHistory(ctx, historyEntry(res)); err != nil {
+		log.Printf("[handler] history write for %s: %v", signature, err)
+	}
+	if err := portfolio.Apply(ctx, h.st, res); err != nil {
+		log.Printf("[handler] position update for %s: %v", signature, err)
+	}
+	if h.Events != nil {
+		h.Events.Publish(events.FromResult(res))
 	}
 
-	return h
+	if len(recipients) == 0 {
+		log.Printf("[handler] %s on %s muted by every owner's settings", signature, trackedAddr)
+		return
+	}
+
+	var suppressed int
+	if h.BotRateLimit > 0 && isBot && !h.anyHighPriority(ctx, recipients, trackedAddr) {
+		ok, n := h.limiter.allow(trackedAddr, h.BotRateLimit)
+		if !ok {
+			log.Printf("[handler] rate-limited bot wallet %s, suppressing %s", trackedAddr, signature)
+			return
+		}
+		suppressed = n
+	}
+
+	var footer string
+	if suppressed > 0 {
+		footer = fmt.Sprintf("\n\n🤖 <i>+%d more alert(s) suppressed (bot rate limit)</i>", suppressed)
+	}
+	var sent []sentAlert
+	for _, chatID := range recipients {
+		if id, markdown := h.sendAlert(ctx, chatID, res, footer); id != 0 {
+			sent = append(sent, sentAlert{chatID: chatID, msgID: id, markdown: markdown})
+		}
+	}
+	if h.RecheckFinalized && len(sent) > 0 {
+		h.queueFinality(res, sent, footer)
+	}
+	if h.analyzer.DetectSandwich && res.Type == "SWAP" && len(sent) > 0 {
+		util.Go("sandwich", func() { h.annotateSandwich(res, sent, footer) })
+	}
+	h.checkConfluence(ctx, res, recipients)
+	if res.Counterparty != "" {
+		util.Go("counterparty", func() { h.considerCounterparty(res, recipients) })
+	}
 }
 
 // Run starts long-polling and handles updates until ctx is done.
 func (h *Handler) Run(ctx context.Context) {
 	h.bot.RegisterHandler(tg.HandlerTypeMessageText, "", tg.MatchTypePrefix, func(c context.Context, b *tg.Bot, u *models.Update) {
-		if u.Message == nil || u.Message.Chat.ID != h.adminID {
+		defer util.Recover("telegram")
+		if u.Message == nil || !h.isAllowed(u.Message.Chat.ID) {
 			return
 		}
 		h.handleCommand(c, u.Message)
 	})
+	h.bot.RegisterHandler(tg.HandlerTypeCallbackQueryData, keepCallback, tg.MatchTypePrefix, func(c context.Context, b *tg.Bot, u *models.Update) {
+		defer util.Recover("telegram")
+		if u.CallbackQuery != nil {
+			h.handleKeepButton(c, u.CallbackQuery)
+		}
+	})
+	h.bot.RegisterHandler(tg.HandlerTypeCallbackQueryData, trialCallback, tg.MatchTypePrefix, func(c context.Context, b *tg.Bot, u *models.Update) {
+		defer util.Recover("telegram")
+		if u.CallbackQuery != nil {
+			h.handleTrialButton(c, u.CallbackQuery)
+		}
+	})
+	h.bot.RegisterHandler(tg.HandlerTypeCallbackQueryData, bulkCallback, tg.MatchTypePrefix, func(c context.Context, b *tg.Bot, u *models.Update) {
+		defer util.Recover("telegram")
+		if u.CallbackQuery != nil {
+			h.handleBulkButton(c, u.CallbackQuery)
+		}
+	})
 	h.bot.Start(ctx)
 }
 
 func (h *Handler) handleCommand(ctx context.Context, m *models.Message) {
 	raw := strings.TrimSpace(m.Text)
 	lower := strings.ToLower(raw)
-	if idx := strings.IndexRune(lower, '@'); idx != -1 {
-		lower = lower[:idx]
-		raw = raw[:idx]
+	// Strip a "/cmd@botname" suffix from the command word only; arguments
+	// (templates in particular) may contain '@'.
+	cmdEnd := strings.IndexAny(raw, " \n")
+	if cmdEnd == -1 {
+		cmdEnd = len(raw)
+	}
+	if idx := strings.IndexRune(raw[:cmdEnd], '@'); idx != -1 {
+		raw = raw[:idx] + raw[cmdEnd:]
+		lower = strings.ToLower(raw)
 	}
 	switch {
 	case lower == "/help":
@@ -102,7 +296,7 @@ func (h *Handler) handleCommand(ctx context.Context, m *models.Message) {
 
 		summary, err := h.analyzer.AnalyzeSignature(ctx, signature, walletAddr)
 		if err != nil {
-			errMsg := fmt.Sprintf("<b>Analysis Failed:</b>\n<code>%v</code>", err)
+			errMsg := "<b>Analysis Failed:</b>\n" + errorText(err)
 			h.sendHTML(ctx, m.Chat.ID, errMsg)
 			return
 		}
@@ -116,55 +310,49 @@ func (h *Handler) handleCommand(ctx context.Context, m *models.Message) {
 		finalMessage := fmt.Sprintf("🧪 <b>Test Result for %s</b>\n\n%s", shortAddr, summary)
 		h.sendHTML(ctx, m.Chat.ID, finalMessage)
 
+	case strings.HasPrefix(lower, "/analyze "):
+		h.handleAnalyze(ctx, m.Chat.ID, strings.Fields(raw[len("/analyze"):]))
+
 	case strings.HasPrefix(lower, "/track "):
-		arg := strings.TrimSpace(raw[len("/track"):])
-		if arg == "" {
-			h.sendHTML(ctx, m.Chat.ID, "usage: <code>/track &lt;address&gt;</code>")
-			return
-		}
-		if err := h.st.AddWallet(ctx, arg); err != nil {
-			h.sendHTML(ctx, m.Chat.ID, fmt.Sprintf("track failed: <code>%v</code>", err))
-			return
-		}
-		if err := h.tm.Track(ctx, arg); err != nil {
-			h.sendHTML(ctx, m.Chat.ID, fmt.Sprintf("subscriber failed: <code>%v</code>", err))
-			return
-		}
-		h.sendHTML(ctx, m.Chat.ID, "tracking <b>"+escapeHTML(arg)+"</b>")
+		h.handleTrack(ctx, m.Chat.ID, strings.Fields(raw[len("/track"):]))
 
 	case strings.HasPrefix(lower, "/untrack "):
 		arg := strings.TrimSpace(raw[len("/untrack"):])
 		if arg == "" {
-			h.sendHTML(ctx, m.Chat.ID, "usage: <code>/untrack &lt;address&gt;</code>")
+			h.sendHTML(ctx, m.Chat.ID, "usage: <code>/untrack &lt;address|tag:&lt;tag&gt;&gt;</code>")
+			return
+		}
+		if _, ok := selectorTag(arg); ok {
+			h.runBulk(ctx, m.Chat.ID, bulkOp{verb: "untrack", selector: arg})
 			return
 		}
-		_ = h.tm.Untrack(ctx, arg)
-		if err := h.st.RemoveWallet(ctx, arg); err != nil {
-			h.sendHTML(ctx, m.Chat.ID, fmt.Sprintf("untrack failed: <code>%v</code>", err))
+		if err := h.untrackFor(ctx, m.Chat.ID, arg); err != nil {
+			h.sendHTML(ctx, m.Chat.ID, "untrack failed: "+errorText(err))
 			return
 		}
 		h.sendHTML(ctx, m.Chat.ID, "untracked <b>"+escapeHTML(arg)+"</b>")
 
 	case strings.HasPrefix(lower, "/trackmany "):
-		args := strings.Fields(raw[len("/trackmany"):])
+		var args []string
+		for _, f := range strings.Fields(raw[len("/trackmany"):]) {
+			args = append(args, parseAddressArg(f)...)
+		}
 		if len(args) == 0 {
 			h.sendHTML(ctx, m.Chat.ID, "usage: <code>/trackmany &lt;addr1&gt; &lt;addr2&gt; ...</code>")
 			return
 		}
-		var added, failed int
+		var added, already, failed int
 		for _, addr := range args {
-			if err := h.st.AddWallet(ctx, addr); err != nil {
+			switch err := h.trackFor(ctx, m.Chat.ID, addr); {
+			case errors.Is(err, store.ErrWalletAlreadyTracked):
+				already++
+			case err != nil:
 				failed++
-				continue
+			default:
+				added++
 			}
-			if err := h.tm.Track(ctx, addr); err != nil {
-				_ = h.st.RemoveWallet(ctx, addr)
-				failed++
-				continue
-			}
-			added++
 		}
-		summary := fmt.Sprintf("trackmany done: added=%d failed=%d", added, failed)
+		summary := fmt.Sprintf("trackmany done: added=%d already=%d failed=%d", added, already, failed)
 		h.sendHTML(ctx, m.Chat.ID, summary)
 
 	case strings.HasPrefix(lower, "/untrackmany "):
@@ -175,8 +363,7 @@ func (h *Handler) handleCommand(ctx context.Context, m *models.Message) {
 		}
 		var removed, failed int
 		for _, addr := range args {
-			_ = h.tm.Untrack(ctx, addr)
-			if err := h.st.RemoveWallet(ctx, addr); err != nil {
+			if err := h.untrackFor(ctx, m.Chat.ID, addr); err != nil {
 				failed++
 				continue
 			}
@@ -185,21 +372,184 @@ func (h *Handler) handleCommand(ctx context.Context, m *models.Message) {
 		summary := fmt.Sprintf("untrackmany done: removed=%d failed=%d", removed, failed)
 		h.sendHTML(ctx, m.Chat.ID, summary)
 
-	case lower == "/tracked":
-		list := h.tm.List()
+	case lower == "/tracked" || strings.HasPrefix(lower, "/tracked "):
+		list, err := h.st.ListUserWallets(ctx, m.Chat.ID)
+		if err != nil {
+			h.sendHTML(ctx, m.Chat.ID, "list failed: "+errorText(err))
+			return
+		}
+		var only string // tag:<tag> filter
+		var verbose bool
+		for _, arg := range strings.Fields(lower[len("/tracked"):]) {
+			tag, ok := strings.CutPrefix(arg, "tag:")
+			switch {
+			case arg == "verbose":
+				verbose = true
+			case ok && tag != "":
+				only = strings.TrimPrefix(tag, "#")
+			default:
+				h.sendHTML(ctx, m.Chat.ID, "usage: <code>/tracked [verbose] [tag:&lt;tag&gt;]</code>")
+				return
+			}
+		}
 		if len(list) == 0 {
 			h.sendHTML(ctx, m.Chat.ID, "<b>No wallets tracked.</b>")
 			return
 		}
+		last := h.tm.LastEvents()
+		trials := h.trialsOf(ctx, m.Chat.ID)
 		var b strings.Builder
 		b.WriteString("📋 <b>Tracked Wallets:</b>\n")
+		if h.tm.Paused() {
+			b.WriteString("⏸ <i>all subscriptions are paused</i>\n")
+		}
+		var shown int
 		for _, a := range list {
+			tags := h.walletTags(ctx, m.Chat.ID, a)
+			if only != "" && !contains(tags, only) {
+				continue
+			}
+			shown++
 			b.WriteString("- <code>")
 			b.WriteString(escapeHTML(a))
-			b.WriteString("</code>\n")
+			b.WriteString("</code>")
+			b.WriteString(classTag(h.analyzer.Classify(a)))
+			b.WriteString(tagSuffix(tags))
+			b.WriteString(" · <i>")
+			b.WriteString(lastEventString(last[a]))
+			if !h.tm.Paused() && !h.tm.IsOpen(a) {
+				b.WriteString(" · ⏳ subscription pending")
+			}
+			if t, ok := trials[a]; ok {
+				b.WriteString(" · ⏳ trial ends in ")
+				b.WriteString(holdString(time.Until(t.Until)))
+			}
+			b.WriteString("</i>\n")
+			if verbose {
+				b.WriteString(noteLine(h.walletNote(ctx, m.Chat.ID, a)))
+			}
+		}
+		if shown == 0 {
+			b.WriteString(fmt.Sprintf("no wallet tagged #%s\n", escapeHTML(only)))
+		}
+		h.sendHTML(ctx, m.Chat.ID, b.String())
+
+	case lower == "/stats" || strings.HasPrefix(lower, "/stats "):
+		list, _ := h.st.ListUserWallets(ctx, m.Chat.ID)
+		if arg := strings.TrimSpace(raw[len("/stats"):]); arg != "" {
+			addrs := parseAddressArg(arg)
+			switch {
+			case len(addrs) == 0 || !isBase58Len(addrs[0], 32):
+				h.sendHTML(ctx, m.Chat.ID, "that is not a valid Solana address")
+				return
+			case !contains(list, addrs[0]):
+				h.sendHTML(ctx, m.Chat.ID, "that wallet isn't tracked. see <code>/tracked</code>")
+				return
+			}
+			list = addrs[:1]
+		}
+		if len(list) == 0 {
+			h.sendHTML(ctx, m.Chat.ID, "<b>No wallets tracked.</b>")
+			return
+		}
+		var b strings.Builder
+		b.WriteString("📈 <b>Wallet Stats:</b>\n")
+		for _, a := range list {
+			c := h.analyzer.Classify(a)
+			b.WriteString(fmt.Sprintf("- <code>%s</code>%s\n", escapeHTML(shortAddr(a)), classTag(c)))
+			b.WriteString(noteLine(h.walletNote(ctx, m.Chat.ID, a)))
+			if c.Samples == 0 {
+				b.WriteString("  no activity observed yet\n")
+			} else {
+				b.WriteString(fmt.Sprintf(
+					"  txs=%d · %.1f/h · programs=%d (top %.0f%%) · median fee %.6f SOL · score=%d\n",
+					c.Samples, c.TxPerHour, c.Sources, c.TopShare*100, float64(c.MedianFee)/1e9, c.Score,
+				))
+			}
+			if closed, err := h.st.ListClosedPositions(ctx, a); err == nil && len(closed) > 0 {
+				b.WriteString(tradeStatsLine(portfolio.Stats(closed)))
+			}
 		}
 		h.sendHTML(ctx, m.Chat.ID, b.String())
 
+	case lower == "/pnl" || strings.HasPrefix(lower, "/pnl "):
+		h.handlePnL(ctx, m.Chat.ID, strings.Fields(raw[len("/pnl"):]))
+
+	case lower == "/networth" || strings.HasPrefix(lower, "/networth "):
+		h.handleNetWorth(ctx, m.Chat.ID, strings.Fields(raw[len("/networth"):]))
+
+	case lower == "/holdings" || strings.HasPrefix(lower, "/holdings "):
+		h.handleHoldings(ctx, m.Chat.ID, strings.Fields(raw[len("/holdings"):]))
+
+	case lower == "/recent" || strings.HasPrefix(lower, "/recent "):
+		h.handleRecent(ctx, m.Chat.ID, strings.Fields(raw[len("/recent"):]))
+
+	case strings.HasPrefix(lower, "/flows "):
+		h.handleFlows(ctx, m.Chat.ID, strings.Fields(raw[len("/flows"):]))
+
+	case lower == "/refreshmeta" || strings.HasPrefix(lower, "/refreshmeta "):
+		h.handleRefreshMeta(ctx, m.Chat.ID, strings.Fields(raw[len("/refreshmeta"):]))
+
+	case lower == "/token" || strings.HasPrefix(lower, "/token "):
+		h.handleToken(ctx, m.Chat.ID, strings.Fields(raw[len("/token"):]))
+
+	case strings.HasPrefix(lower, "/qr "):
+		h.handleQR(ctx, m.Chat.ID, strings.Fields(raw[len("/qr"):]))
+
+	case strings.HasPrefix(lower, "/watchtoken "):
+		h.handleWatchToken(ctx, m.Chat.ID, strings.Fields(raw[len("/watchtoken"):]))
+
+	case strings.HasPrefix(lower, "/unwatchtoken "):
+		h.handleUnwatchToken(ctx, m.Chat.ID, strings.Fields(raw[len("/unwatchtoken"):]))
+
+	case lower == "/watchtokens":
+		h.listWatchedTokens(ctx, m.Chat.ID)
+
+	case lower == "/template" || strings.HasPrefix(lower, "/template "):
+		h.handleTemplate(ctx, m.Chat.ID, raw[len("/template"):])
+
+	case lower == "/mute" || strings.HasPrefix(lower, "/mute "):
+		h.handleMute(ctx, m.Chat.ID, strings.Fields(raw[len("/mute"):]))
+
+	case lower == "/unmute" || strings.HasPrefix(lower, "/unmute "):
+		h.handleUnmute(ctx, m.Chat.ID, strings.Fields(raw[len("/unmute"):]))
+
+	case lower == "/export" || strings.HasPrefix(lower, "/export "):
+		h.handleExport(ctx, m.Chat.ID, strings.Fields(raw[len("/export"):]))
+
+	case lower == "/find" || strings.HasPrefix(lower, "/find "):
+		h.handleFind(ctx, m.Chat.ID, raw[len("/find"):])
+
+	case lower == "/note" || strings.HasPrefix(lower, "/note ") || strings.HasPrefix(lower, "/note\n"):
+		h.handleNote(ctx, m.Chat.ID, raw[len("/note"):])
+
+	case lower == "/tag" || strings.HasPrefix(lower, "/tag "):
+		h.handleTag(ctx, m.Chat.ID, strings.Fields(raw[len("/tag"):]))
+
+	case lower == "/tagroute" || strings.HasPrefix(lower, "/tagroute "):
+		h.handleTagRoute(ctx, m.Chat.ID, strings.Fields(raw[len("/tagroute"):]))
+
+	case lower == "/priority" || strings.HasPrefix(lower, "/priority "):
+		h.handlePriority(ctx, m.Chat.ID, strings.Fields(raw[len("/priority"):]))
+
+	case lower == "/logfilter" || strings.HasPrefix(lower, "/logfilter "):
+		h.handleLogFilter(ctx, m.Chat.ID, strings.Fields(raw[len("/logfilter"):]))
+
+	case lower == "/layout" || strings.HasPrefix(lower, "/layout "):
+		h.handleLayout(ctx, m.Chat.ID, strings.Fields(raw[len("/layout"):]))
+
+	case lower == "/settings":
+		h.handleSettings(ctx, m.Chat.ID)
+
+	case strings.HasPrefix(lower, "/set "):
+		h.handleSet(ctx, m.Chat.ID, strings.Fields(lower)[1:])
+
+	case !h.isAdmin(m.Chat.ID) && (lower == "/health" || lower == "/health verbose" || lower == "/kill" || lower == "/pauseall" || lower == "/resumeall" || lower == "/db" || strings.HasPrefix(lower, "/db ")):
+		h.sendHTML(ctx, m.Chat.ID, "this command is admin-only")
+
+	case lower == "/health verbose":
+		h.handleHealthVerbose(ctx, m.Chat.ID)
+
 	case lower == "/health":
 		rep := h.hlth.Snapshot(ctx)
 		msg := fmt.Sprintf(
@@ -211,8 +561,62 @@ func (h *Handler) handleCommand(ctx context.Context, m *models.Message) {
 				"- Time: <code>%s</code>",
 			rep.Tracked, rep.Open, len(rep.Dropped), rep.TrackedPersisted, rep.GeneratedAt.Format(time.RFC3339),
 		)
+		if load := h.tm.ShardLoad(); len(load) > 1 {
+			msg += "\n<b>Helius keys:</b>"
+			for i, n := range load {
+				msg += fmt.Sprintf("\n- key%d: <code>%d</code> subs · <code>%d</code> tx · <code>%d</code> das", i+1, n,
+					metrics.Get(fmt.Sprintf("helius.key%d.tx", i+1)), metrics.Get(fmt.Sprintf("helius.key%d.das", i+1)))
+			}
+		}
+		if eps := h.analyzer.RPCEndpoints(); len(eps) > 0 {
+			msg += "\n<b>Solana RPC pool:</b>"
+			for _, e := range eps {
+				state := "in rotation"
+				if e.Benched > 0 {
+					state = fmt.Sprintf("benched %s after %d failures", e.Benched.Round(time.Second), e.Fails)
+				}
+				msg += fmt.Sprintf("\n- %s (weight %d): <code>%d</code> ok · %s", e.Name, e.Weight, metrics.Get("upstream."+e.Name+".ok"), state)
+			}
+		}
+		if n := h.tm.Dialing(); n > 0 {
+			msg += fmt.Sprintf("\n- Waiting to dial: <code>%d</code> (SUBSCRIBE_RATE)", n)
+		}
+		if h.tm.Paused() {
+			msg += "\n⏸ <b>Paused</b>: <code>/resumeall</code> to resume"
+		}
+		if len(rep.Endpoints) > 0 {
+			msg += "\n<b>Upstreams:</b>"
+			for _, e := range rep.Endpoints {
+				if e.OK {
+					msg += fmt.Sprintf("\n- ✅ %s <code>%s</code>", escapeHTML(e.Name), e.Latency.Round(time.Millisecond))
+				} else {
+					msg += fmt.Sprintf("\n- ❌ %s: <code>%s</code>", escapeHTML(e.Name), escapeHTML(e.Error))
+				}
+			}
+		}
+		if quiet := quietest(rep.LastEvents, 5); len(quiet) > 0 {
+			msg += "\n<b>Quietest wallets:</b>"
+			for _, addr := range quiet {
+				msg += fmt.Sprintf("\n- <code>%s</code> %s", escapeHTML(shortAddr(addr)), lastEventString(rep.LastEvents[addr]))
+			}
+		}
+		if len(rep.Counters) > 0 {
+			msg += "\n<b>Counters:</b>"
+			for _, name := range sortedKeys(rep.Counters) {
+				msg += fmt.Sprintf("\n- %s: <code>%d</code>", escapeHTML(name), rep.Counters[name])
+			}
+		}
 		h.sendHTML(ctx, m.Chat.ID, msg)
 
+	case lower == "/db" || strings.HasPrefix(lower, "/db "):
+		h.handleDB(ctx, m.Chat.ID, strings.Fields(lower)[1:])
+
+	case lower == "/pauseall":
+		h.handlePauseAll(ctx, m.Chat.ID)
+
+	case lower == "/resumeall":
+		h.handleResumeAll(ctx, m.Chat.ID)
+
 	case lower == "/kill":
 		h.sendHTML(ctx, m.Chat.ID, "🛑 shutting down...")
 		go func() {
@@ -229,26 +633,114 @@ func (h *Handler) handleCommand(ctx context.Context, m *models.Message) {
 	}
 }
 
+// handleAnalyze runs the analyzer on an arbitrary signature or explorer tx
+// link, from wallet's point of view when given, else the fee payer's.
+func (h *Handler) handleAnalyze(ctx context.Context, chatID int64, args []string) {
+	if len(args) == 0 || len(args) > 2 {
+		h.sendHTML(ctx, chatID, "usage: <code>/analyze &lt;tx link|signature&gt; [wallet]</code>")
+		return
+	}
+	sig, ok := parseSignatureArg(args[0])
+	if !ok {
+		h.sendHTML(ctx, chatID, "no transaction signature found in that link")
+		return
+	}
+	var wallet string
+	if len(args) == 2 {
+		if addrs := parseAddressArg(args[1]); len(addrs) > 0 {
+			wallet = addrs[0]
+		}
+	}
+
+	res, err := h.analyzer.Analyze(ctx, sig, wallet)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "<b>Analysis Failed:</b>\n"+errorText(err))
+		return
+	}
+	if res == nil {
+		h.sendHTML(ctx, chatID, "✅ <b>Analysis Complete:</b>\nTransaction was filtered (likely spam or dust).")
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("🔎 <b>Analysis for %s</b>\n\n%s", escapeHTML(shortAddr(res.Wallet)), res.SummaryWith(h.numberFormat(ctx, chatID))))
+}
+
 func (h *Handler) replyHelp(ctx context.Context, chatID int64) {
 	help := strings.TrimSpace(`
 🛠 <b>solwatch v2</b>
 
 <b>Commands:</b>
-- <code>/track &lt;address&gt;</code> - Start tracking a wallet
-- <code>/untrack &lt;address&gt;</code> - Stop tracking a wallet
+- <code>/track &lt;address|link&gt; [--for 48h]</code> - Start tracking a wallet (Solscan/Birdeye links work), optionally as a trial
+- <code>/untrack &lt;address|tag:x&gt;</code> - Stop tracking a wallet, or all with a tag
 - <code>/trackmany &lt;...&gt;</code> - Add multiple wallets
 - <code>/untrackmany &lt;...&gt;</code> - Remove multiple wallets
-- <code>/tracked</code> - List tracked wallets
-- <code>/health</code> - Show service health
+- <code>/tracked [verbose] [tag:&lt;tag&gt;]</code> - List tracked wallets and their last event (verbose: with notes)
+- <code>/recent [hours]</code> - Which wallets were active lately (default 12h)
+- <code>/stats [address]</code> - Activity profile, bot/human tag and trade stats
+- <code>/pnl [address]</code> - Realized PnL charts
+- <code>/networth [address]</code> - Net worth over time chart
+- <code>/holdings &lt;address&gt;</code> - Current SOL and token balances
+- <code>/flows &lt;mint&gt; [period]</code> - Who bought/sold a token and net SOL flow (default 24h)
+- <code>/qr &lt;address&gt; [pay|amount]</code> - QR code / Solana Pay link
+- <code>/refreshmeta &lt;mint&gt;</code> - Re-resolve a token's symbol/decimals
+- <code>/token &lt;mint&gt;</code> - Show a token's name, creators, description and image
+- <code>/watchtoken &lt;mint&gt; [buy|sell]</code> - Loud alert when a tracked wallet trades this token
+- <code>/unwatchtoken &lt;mint&gt;</code> - Stop watching a token
+- <code>/watchtokens</code> - List watched tokens
+- <code>/template [set|clear|preview]</code> - Customize alert messages
+- <code>/priority [address high|normal|low]</code> - Loud, normal or silent one-line alerts per wallet
+- <code>/mute [address|tag:x period]</code> / <code>/unmute &lt;address|tag:x&gt;</code> - Silence wallets for a while, e.g. 12h
+- <code>/export &lt;address|tag:x&gt;</code> - History and positions as a JSON file
+- <code>/find &lt;query&gt;</code> - Search your wallets by address prefix, tag and note
+- <code>/note [address text|off]</code> - Remember why you track a wallet
+- <code>/tag [address tag...|off]</code> - Tag wallets (sniper, nft, whale, ...)
+- <code>/tagroute [tag high|normal|low|mute|off]</code> - Route alerts of tagged wallets
+- <code>/layout &lt;address&gt; full|compact|default</code> - One-line alerts for a busy wallet
+- <code>/logfilter &lt;address&gt; &lt;program id&gt;...|off</code> - Only transactions whose logs mention a program
+- <code>/settings</code> - Show your alert settings
+- <code>/set &lt;name&gt; on|off</code> - Change an alert setting
+- <code>/health [verbose]</code> - Show service health (verbose: per-wallet counters)
+- <code>/db stats|compact</code> - Database size and compaction
+- <code>/pauseall</code> / <code>/resumeall</code> - Suspend and resume all subscriptions
 - <code>/kill</code> - Shutdown the service
 
 <b>Debug:</b>
+- <code>/analyze &lt;tx link|sig&gt; [addr]</code> - Analyze any transaction (default: fee payer's view)
 - <code>/test &lt;sig&gt; &lt;addr&gt;</code> - Test analysis of a signature for a given wallet
 `)
 	h.sendHTML(ctx, chatID, help)
 }
 
 func (h *Handler) sendHTML(ctx context.Context, chatID int64, html string) {
+	h.send(ctx, chatID, html, models.ParseModeHTML)
+}
+
+// send delivers text in the given parse mode and returns the message ID,
+// or 0 when sending failed.
+func (h *Handler) send(ctx context.Context, chatID int64, text string, mode models.ParseMode) int {
+	return h.sendMessage(ctx, chatID, text, mode, false)
+}
+
+// sendMessage is send with optional silent delivery (no notification sound).
+func (h *Handler) sendMessage(ctx context.Context, chatID int64, text string, mode models.ParseMode, silent bool) int {
+	disable := true
+	msg, err := h.bot.SendMessage(ctx, &tg.SendMessageParams{
+		ChatID:              chatID,
+		Text:                text,
+		ParseMode:           mode,
+		DisableNotification: silent,
+		LinkPreviewOptions: &models.LinkPreviewOptions{
+			IsDisabled: &disable,
+		},
+	})
+	if err != nil {
+		log.Printf("[telegram] send error: %v", err)
+		return 0
+	}
+	return msg.ID
+}
+
+// sendButton sends html with one inline button whose callback carries data.
+func (h *Handler) sendButton(ctx context.Context, chatID int64, html, label, data string) {
 	disable := true
 	_, err := h.bot.SendMessage(ctx, &tg.SendMessageParams{
 		ChatID:    chatID,
@@ -257,18 +749,109 @@ func (h *Handler) sendHTML(ctx context.Context, chatID int64, html string) {
 		LinkPreviewOptions: &models.LinkPreviewOptions{
 			IsDisabled: &disable,
 		},
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{{
+			{Text: label, CallbackData: data},
+		}}},
 	})
 	if err != nil {
 		log.Printf("[telegram] send error: %v", err)
 	}
 }
 
-func escapeHTML(s string) string {
-	replacer := strings.NewReplacer(
-		`&`, "&amp;",
-		`<`, "&lt;",
-		`>`, "&gt;",
-		`"`, "&quot;",
+// historyEntry converts an analysis result into its persisted form.
+func historyEntry(r *analyzer.Result) store.HistoryEntry {
+	conv := func(list []analyzer.Amount) []store.HistoryAmount {
+		out := make([]store.HistoryAmount, 0, len(list))
+		for _, a := range list {
+			out = append(out, store.HistoryAmount{Mint: a.Mint, Symbol: a.Symbol, Amount: a.Amount, USD: a.USD})
+		}
+		return out
+	}
+	return store.HistoryEntry{
+		Signature: r.Signature,
+		Wallet:    r.Wallet,
+		Time:      r.Timestamp,
+		Type:      r.Type,
+		Source:    r.Source,
+		SizeUSD:   r.SizeUSD,
+		Sent:      conv(r.Sent),
+		Received:  conv(r.Received),
+	}
+}
+
+// forgetWallet clears per-wallet in-memory state after an untrack.
+func (h *Handler) forgetWallet(addr string) {
+	h.analyzer.ForgetWallet(addr)
+	h.limiter.forget(addr)
+}
+
+// classTag renders a short suffix for a wallet's bot/human classification.
+func classTag(c analyzer.Classification) string {
+	switch c.Label {
+	case analyzer.LabelBot:
+		return " 🤖 likely bot"
+	case analyzer.LabelHuman:
+		return " 👤 human"
+	default:
+		return ""
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tradeStatsLine renders closed-position stats for /stats.
+func tradeStatsLine(s portfolio.TradeStats) string {
+	return fmt.Sprintf(
+		"  trades=%d · win %.0f%% · avg hold %s · avg return %+.1f%% · pnl %+.2f USD\n  best %s %+.2f · worst %s %+.2f\n",
+		s.Trades, s.WinRate*100, holdString(s.AvgHold), s.AvgReturn*100, s.RealizedUSD,
+		escapeHTML(s.BestSymbol), s.BestUSD, escapeHTML(s.WorstSymbol), s.WorstUSD,
 	)
-	return replacer.Replace(s)
 }
+
+// holdString renders a hold time coarsely ("45m", "6h", "3d").
+func holdString(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// lastEventString describes a subscriber's last notification time.
+func lastEventString(t time.Time) string {
+	if t.IsZero() {
+		return "no events since start"
+	}
+	return "last event " + holdString(time.Since(t)) + " ago"
+}
+
+// quietest returns up to n addresses ordered by oldest last event; wallets
+// without any event since start come first.
+func quietest(last map[string]time.Time, n int) []string {
+	addrs := sortedKeys(last)
+	sort.SliceStable(addrs, func(i, j int) bool { return last[addrs[i]].Before(last[addrs[j]]) })
+	if len(addrs) > n {
+		addrs = addrs[:n]
+	}
+	return addrs
+}
+
+func shortAddr(addr string) string {
+	if len(addr) <= 8 {
+		return addr
+	}
+	return addr[:4] + "..." + addr[len(addr)-4:]
+}
+
+// escapeHTML defers to the analyzer's sanitizer so every message shares it.
+func escapeHTML(s string) string { return analyzer.EscapeHTML(s) }
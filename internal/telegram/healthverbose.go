@@ -0,0 +1,58 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxHealthChunk keeps each /health verbose message under Telegram's
+// 4096-character limit.
+const maxHealthChunk = 3500
+
+// maxErrorText is how much of a subscription's last error is shown.
+const maxErrorText = 120
+
+// handleHealthVerbose lists every subscription's counters, busiest
+// reconnectors first, split over as many messages as it takes.
+func (h *Handler) handleHealthVerbose(ctx context.Context, chatID int64) {
+	stats := h.hlth.Snapshot(ctx).Subscribers
+	if len(stats) == 0 {
+		h.sendHTML(ctx, chatID, "<b>No subscriptions running.</b>")
+		return
+	}
+	addrs := sortedKeys(stats)
+	sort.SliceStable(addrs, func(i, j int) bool { return stats[addrs[i]].Reconnects > stats[addrs[j]].Reconnects })
+
+	var b strings.Builder
+	b.WriteString("📊 <b>Subscriptions</b> (notifications · signatures · reconnects)\n")
+	for _, a := range addrs {
+		st := stats[a]
+		state := "🟢"
+		if !st.Open {
+			state = "🔴"
+		}
+		line := fmt.Sprintf("%s <code>%s</code> %d · %d · %d", state, escapeHTML(shortAddr(a)), st.Notifications, st.Signatures, st.Reconnects)
+		if st.Filtered > 0 {
+			line += fmt.Sprintf(" · %d filtered", st.Filtered)
+		}
+		if !st.LastConnect.IsZero() {
+			line += " · connected " + holdString(time.Since(st.LastConnect)) + " ago"
+		}
+		if msg := st.LastError; msg != "" {
+			if len(msg) > maxErrorText {
+				msg = msg[:maxErrorText] + "…"
+			}
+			line += fmt.Sprintf("\n   ⚠️ %s ago: <code>%s</code>", holdString(time.Since(st.LastErrorAt)), escapeHTML(msg))
+		}
+		if b.Len()+len(line) > maxHealthChunk {
+			h.sendHTML(ctx, chatID, b.String())
+			b.Reset()
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	h.sendHTML(ctx, chatID, b.String())
+}
@@ -0,0 +1,55 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxHoldingsLines caps the /holdings reply; dust-heavy wallets can own
+// hundreds of token accounts.
+const maxHoldingsLines = 25
+
+// handleHoldings lists the token balances of a wallet on the watchlist.
+//
+//	/holdings <address>
+func (h *Handler) handleHoldings(ctx context.Context, chatID int64, args []string) {
+	const usage = "usage: <code>/holdings &lt;address&gt;</code>"
+	if len(args) != 1 {
+		h.sendHTML(ctx, chatID, usage)
+		return
+	}
+	wallets, _ := h.chartWallets(ctx, chatID, args, usage)
+	if wallets == nil {
+		return
+	}
+	addr := wallets[0]
+
+	holdings, err := h.analyzer.Holdings(ctx, addr)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "holdings failed: "+errorText(err))
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🎒 <b>Holdings · %s</b>\n", escapeHTML(shortAddr(addr)))
+	if sol, usd, err := h.analyzer.SOLBalance(ctx, addr); err == nil {
+		fmt.Fprintf(&b, "SOL: <code>%.4f</code> (%.2f USD)\n", sol, usd)
+	}
+	if len(holdings) == 0 {
+		b.WriteString("no token balances")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+	for i, t := range holdings {
+		if i == maxHoldingsLines {
+			fmt.Fprintf(&b, "… and %d more", len(holdings)-i)
+			break
+		}
+		name := t.Symbol
+		if name == "" {
+			name = shortAddr(t.Mint)
+		}
+		fmt.Fprintf(&b, "%s: <code>%g</code>\n", escapeHTML(name), t.Amount)
+	}
+	h.sendHTML(ctx, chatID, strings.TrimRight(b.String(), "\n"))
+}
@@ -0,0 +1,100 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// Alerts come in two layouts: the full multi-line block (or the chat's
+// template) and a compact single line for high-volume wallets. The layout
+// resolves per wallet: an explicit /layout override, then the wallet's
+// priority (low is compact, high is full), then the chat's "layout" setting.
+
+const (
+	layoutFull    = "full"
+	layoutCompact = "compact"
+)
+
+func layoutKey(addr string) string { return "layout:" + addr }
+
+// walletLayout returns the layout chatID's alerts for addr use.
+func (h *Handler) walletLayout(ctx context.Context, chatID int64, addr string) string {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, layoutKey(addr)))
+	if err == nil && ok && (v == layoutFull || v == layoutCompact) {
+		return v
+	}
+	switch h.walletPriority(ctx, chatID, addr) {
+	case priorityLow:
+		return layoutCompact
+	case priorityHigh:
+		return layoutFull
+	}
+	return h.userValue(ctx, chatID, "layout")
+}
+
+// handleLayout sets a wallet's layout, overriding the chat setting and the
+// wallet's priority.
+//
+//	/layout <address> full|compact|default
+func (h *Handler) handleLayout(ctx context.Context, chatID int64, args []string) {
+	const usage = "usage: <code>/layout &lt;address&gt; full|compact|default</code> (the chat-wide default is <code>/set layout</code>)"
+	if len(args) != 2 {
+		h.sendHTML(ctx, chatID, usage)
+		return
+	}
+	addr, v := args[0], strings.ToLower(args[1])
+	if v != layoutFull && v != layoutCompact && v != "default" {
+		h.sendHTML(ctx, chatID, usage)
+		return
+	}
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "layout failed: "+errorText(err))
+		return
+	}
+	if !contains(wallets, addr) {
+		h.sendHTML(ctx, chatID, "that wallet isn't tracked. see <code>/tracked</code>")
+		return
+	}
+	key := store.UserSettingKey(chatID, layoutKey(addr))
+	if v == "default" {
+		err = h.st.DeleteSetting(ctx, key)
+	} else {
+		err = h.st.SetSetting(ctx, key, v)
+	}
+	if err != nil {
+		h.sendHTML(ctx, chatID, "layout failed: "+errorText(err))
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("alerts for <code>%s</code> now use the <b>%s</b> layout",
+		escapeHTML(shortAddr(addr)), h.walletLayout(ctx, chatID, addr)))
+}
+
+// compactAlert renders res as one line: wallet, action, amounts, tx link.
+func compactAlert(res *analyzer.Result, nf analyzer.NumberFormat) string {
+	join := func(list []analyzer.Amount) string {
+		parts := make([]string, len(list))
+		for i, a := range list {
+			parts[i] = a.Format(nf)
+		}
+		return strings.Join(parts, " + ")
+	}
+	var legs string
+	switch sent, got := join(res.Sent), join(res.Received); {
+	case sent != "" && got != "":
+		legs = sent + " → " + got
+	case sent != "":
+		legs = "sent " + sent
+	case got != "":
+		legs = "received " + got
+	}
+	line := fmt.Sprintf("▫️ <code>%s</code> %s", escapeHTML(shortAddr(res.Wallet)), escapeHTML(res.Type))
+	if legs != "" {
+		line += ": " + escapeHTML(legs)
+	}
+	return line + fmt.Sprintf(" · <a href=\"https://solscan.io/tx/%s\">tx</a>", res.Signature)
+}
@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxListedFailures caps how many failed wallets the startup message names.
+const maxListedFailures = 10
+
+// ResubscribeReport is what Resubscribe did at startup.
+type ResubscribeReport struct {
+	Wallets int              // wallets resubscribed
+	Failed  map[string]error // wallet -> why it couldn't be resubscribed
+}
+
+// AnnounceStart tells the admin chat the service is up, which version it
+// runs and how resubscribing went, so restarts show without the server logs.
+// startErr is an error that stopped Resubscribe as a whole, if any.
+func (h *Handler) AnnounceStart(ctx context.Context, version string, rep ResubscribeReport, startErr error) {
+	if h.adminID == 0 {
+		return
+	}
+	h.started = time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "🟢 <b>solwatch started</b> (%s)\n", escapeHTML(version))
+	if startErr != nil {
+		fmt.Fprintf(&b, "⚠️ resubscribe failed: <code>%s</code>", escapeHTML(startErr.Error()))
+		h.sendHTML(ctx, h.adminID, b.String())
+		return
+	}
+	fmt.Fprintf(&b, "%d wallet(s) resubscribed", rep.Wallets)
+	if h.tm.Paused() {
+		b.WriteString(" (paused; <code>/resumeall</code> to start them)")
+	}
+	if len(rep.Failed) > 0 {
+		fmt.Fprintf(&b, ", <b>%d failed</b>:", len(rep.Failed))
+		addrs := make([]string, 0, len(rep.Failed))
+		for a := range rep.Failed {
+			addrs = append(addrs, a)
+		}
+		sort.Strings(addrs)
+		for i, a := range addrs {
+			if i == maxListedFailures {
+				fmt.Fprintf(&b, "\n… and %d more", len(addrs)-i)
+				break
+			}
+			fmt.Fprintf(&b, "\n• <code>%s</code>: %s", escapeHTML(shortAddr(a)), escapeHTML(rep.Failed[a].Error()))
+		}
+	}
+	h.sendHTML(ctx, h.adminID, b.String())
+}
+
+// ReportRampUp logs, every interval, how far the subscriptions started by
+// Resubscribe have got while SUBSCRIBE_RATE paces their dials, and once
+// none is waiting any more logs (and with notify tells the admin chat) how
+// long it took. It stays quiet when everything dialed within one interval.
+func (h *Handler) ReportRampUp(ctx context.Context, every time.Duration, notify bool) {
+	start := time.Now()
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		tracked, open, _ := h.tm.Stats()
+		waiting := h.tm.Dialing()
+		if waiting > 0 {
+			log.Printf("[handler] ramp-up: %d/%d subscriptions open, %d waiting to dial", open, tracked, waiting)
+			continue
+		}
+		if time.Since(start) < 2*every {
+			return
+		}
+		took := time.Since(start).Round(time.Second)
+		log.Printf("[handler] ramp-up done in %s: %d/%d subscriptions open", took, open, tracked)
+		if notify && h.adminID != 0 {
+			h.sendHTML(ctx, h.adminID, fmt.Sprintf("📶 all subscriptions dialed in %s: %d/%d open", took, open, tracked))
+		}
+		return
+	}
+}
+
+// AnnounceStop tells the admin chat the service is shutting down cleanly.
+// It is called after the main context is cancelled, so it sends with its
+// own short timeout.
+func (h *Handler) AnnounceStop(version string) {
+	if h.adminID == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg := fmt.Sprintf("🔴 <b>solwatch stopping</b> (%s)", escapeHTML(version))
+	if !h.started.IsZero() {
+		msg += fmt.Sprintf(" after %s", time.Since(h.started).Round(time.Second))
+	}
+	h.sendHTML(ctx, h.adminID, msg)
+}
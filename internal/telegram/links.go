@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"net/url"
+	"strings"
+
+	b58 "github.com/mr-tron/base58/base58"
+)
+
+// Explorer links pasted into /track and /analyze. Paths are matched by the
+// segment that precedes the value, which covers Solscan, Birdeye,
+// Solana Explorer, SolanaFM and XRAY:
+//
+//	https://solscan.io/account/<addr>        https://solscan.io/tx/<sig>
+//	https://birdeye.so/profile/<addr>        https://explorer.solana.com/tx/<sig>
+//	https://solana.fm/address/<addr>         https://xray.helius.xyz/tx/<sig>
+var (
+	accountSegments = map[string]bool{"account": true, "address": true, "profile": true, "portfolio": true, "wallet": true}
+	txSegments      = map[string]bool{"tx": true, "transaction": true}
+	addressParams   = []string{"address", "wallet", "wallets", "addresses"}
+)
+
+// parseAddressArg turns a /track argument into wallet addresses: a plain
+// address, or an explorer/portfolio URL (possibly listing several).
+func parseAddressArg(arg string) []string {
+	u, ok := parseLink(arg)
+	if !ok {
+		return []string{arg}
+	}
+	var out []string
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segs); i++ {
+		if accountSegments[strings.ToLower(segs[i])] && isBase58Len(segs[i+1], 32) {
+			out = append(out, segs[i+1])
+		}
+	}
+	q := u.Query()
+	for _, p := range addressParams {
+		for _, v := range q[p] {
+			for _, a := range strings.Split(v, ",") {
+				if a = strings.TrimSpace(a); isBase58Len(a, 32) {
+					out = append(out, a)
+				}
+			}
+		}
+	}
+	return dedupe(out)
+}
+
+// parseSignatureArg extracts a transaction signature from a plain signature
+// or an explorer tx URL. ok is false when nothing usable was found.
+func parseSignatureArg(arg string) (string, bool) {
+	u, isURL := parseLink(arg)
+	if !isURL {
+		return arg, isBase58Len(arg, 64)
+	}
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segs); i++ {
+		if txSegments[strings.ToLower(segs[i])] && isBase58Len(segs[i+1], 64) {
+			return segs[i+1], true
+		}
+	}
+	return "", false
+}
+
+func parseLink(s string) (*url.URL, bool) {
+	if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+		return nil, false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+func isBase58Len(s string, n int) bool {
+	b, err := b58.Decode(s)
+	return err == nil && len(b) == n
+}
+
+func dedupe(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	out := list[:0]
+	for _, v := range list {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
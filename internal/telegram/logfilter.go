@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// Each chat can limit a wallet to transactions whose logs mention given
+// terms, usually a program ID, so wallets where only one protocol matters
+// don't cost a transaction fetch for everything else. The subscription is
+// shared, so the tracker filters with the union of the owners' terms, and
+// only while every owner has set some.
+
+func logFilterKey(addr string) string { return "logfilter:" + addr }
+
+// chatLogFilter returns chatID's filter terms for addr.
+func (h *Handler) chatLogFilter(ctx context.Context, chatID int64, addr string) []string {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, logFilterKey(addr)))
+	if err != nil || !ok {
+		return nil
+	}
+	return strings.Fields(v)
+}
+
+// syncLogFilter hands the tracker addr's effective filter after its owners
+// or their filters changed.
+func (h *Handler) syncLogFilter(ctx context.Context, addr string) {
+	var union []string
+	owners := h.tm.Owners(addr)
+	for _, u := range owners {
+		terms := h.chatLogFilter(ctx, u, addr)
+		if len(terms) == 0 {
+			union = nil
+			break
+		}
+		for _, t := range terms {
+			if !contains(union, t) {
+				union = append(union, t)
+			}
+		}
+	}
+	h.tm.SetLogFilter(addr, union)
+}
+
+// handleLogFilter shows or sets log filters.
+//
+//	/logfilter                          list filtered wallets
+//	/logfilter <address> <term>...      only transactions whose logs mention a term
+//	/logfilter <address> off
+func (h *Handler) handleLogFilter(ctx context.Context, chatID int64, args []string) {
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "logfilter failed: "+errorText(err))
+		return
+	}
+	if len(args) == 0 {
+		var b strings.Builder
+		b.WriteString("🔎 <b>Log filters</b> (other wallets get every transaction):\n")
+		var n int
+		for _, a := range wallets {
+			if terms := h.chatLogFilter(ctx, chatID, a); len(terms) > 0 {
+				fmt.Fprintf(&b, "- <code>%s</code>: <code>%s</code>", escapeHTML(shortAddr(a)), escapeHTML(strings.Join(terms, " ")))
+				if len(h.tm.LogFilter(a)) == 0 {
+					b.WriteString(" <i>(inactive: another owner watches everything)</i>")
+				}
+				b.WriteString("\n")
+				n++
+			}
+		}
+		if n == 0 {
+			b.WriteString("none set\n")
+		}
+		b.WriteString("\nChange with <code>/logfilter &lt;address&gt; &lt;program id&gt;... | off</code>")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+	if len(args) < 2 {
+		h.sendHTML(ctx, chatID, "usage: <code>/logfilter &lt;address&gt; &lt;program id|text&gt;... | off</code>")
+		return
+	}
+	addr := args[0]
+	if !contains(wallets, addr) {
+		h.sendHTML(ctx, chatID, "that wallet isn't tracked. see <code>/tracked</code>")
+		return
+	}
+	key := store.UserSettingKey(chatID, logFilterKey(addr))
+	off := len(args) == 2 && strings.EqualFold(args[1], "off")
+	if off {
+		err = h.st.DeleteSetting(ctx, key)
+	} else {
+		err = h.st.SetSetting(ctx, key, strings.Join(args[1:], " "))
+	}
+	if err != nil {
+		h.sendHTML(ctx, chatID, "logfilter failed: "+errorText(err))
+		return
+	}
+	h.syncLogFilter(ctx, addr)
+	if off {
+		h.sendHTML(ctx, chatID, fmt.Sprintf("<code>%s</code> gets every transaction again", escapeHTML(shortAddr(addr))))
+		return
+	}
+	msg := fmt.Sprintf("<code>%s</code> now only alerts when its logs mention <code>%s</code>", escapeHTML(shortAddr(addr)), escapeHTML(strings.Join(args[1:], " ")))
+	if len(h.tm.LogFilter(addr)) == 0 {
+		msg += "\n<i>Inactive for now: another chat watching this wallet has no filter.</i>"
+	}
+	h.sendHTML(ctx, chatID, msg)
+}
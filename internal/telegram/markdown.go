@@ -0,0 +1,137 @@
+package telegram
+
+import (
+	"html"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+)
+
+// Chats with the "markdown" setting on receive alerts in Telegram
+// MarkdownV2. The built-in layout is produced as HTML and converted here;
+// templates run with MarkdownV2-aware helpers instead (see templateFuncs).
+
+// markdownV2Specials must be backslash-escaped in MarkdownV2 text.
+const markdownV2Specials = "_*[]()~`>#+-=|{}.!\\"
+
+// escapeMarkdownV2 escapes s for use as MarkdownV2 text.
+func escapeMarkdownV2(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for _, r := range analyzer.SanitizeText(s) {
+		if strings.ContainsRune(markdownV2Specials, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeMarkdownV2Code escapes s inside `code` or ```pre``` entities, where
+// only backquote and backslash are special.
+func escapeMarkdownV2Code(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(analyzer.SanitizeText(s))
+}
+
+// escapeMarkdownV2URL escapes s inside the (...) part of an inline link.
+func escapeMarkdownV2URL(s string) string {
+	return strings.NewReplacer("\\", "\\\\", ")", "\\)").Replace(s)
+}
+
+// htmlToMarkdownV2 converts the Telegram HTML subset the formatter emits
+// (b, strong, i, em, u, s, code, pre, a, blockquote) to MarkdownV2. Unknown
+// tags are dropped and their text kept.
+func htmlToMarkdownV2(src string) string {
+	var (
+		b       strings.Builder
+		inCode  bool
+		inQuote bool
+		// expandable quotes open with "**>" and close with "||"
+		expandable bool
+		hrefs      []string // open <a> targets
+	)
+	for len(src) > 0 {
+		lt := strings.IndexByte(src, '<')
+		if lt == -1 {
+			lt = len(src)
+		}
+		if lt > 0 {
+			text := html.UnescapeString(src[:lt])
+			if inCode {
+				text = escapeMarkdownV2Code(text)
+			} else {
+				text = escapeMarkdownV2(text)
+			}
+			if inQuote {
+				// Every quoted line needs its own '>'.
+				text = strings.ReplaceAll(text, "\n", "\n>")
+			}
+			b.WriteString(text)
+			src = src[lt:]
+			continue
+		}
+		gt := strings.IndexByte(src, '>')
+		if gt == -1 {
+			// A stray '<' rather than a tag.
+			b.WriteString(escapeMarkdownV2(src))
+			break
+		}
+		tag := src[1:gt]
+		src = src[gt+1:]
+
+		closing := strings.HasPrefix(tag, "/")
+		name, attrs, _ := strings.Cut(strings.TrimPrefix(tag, "/"), " ")
+		switch strings.ToLower(name) {
+		case "b", "strong":
+			b.WriteString("*")
+		case "i", "em":
+			b.WriteString("_")
+		case "u", "ins":
+			b.WriteString("__")
+		case "s", "strike", "del":
+			b.WriteString("~")
+		case "code":
+			inCode = !closing
+			b.WriteString("`")
+		case "pre":
+			inCode = !closing
+			b.WriteString("```\n")
+		case "blockquote":
+			switch {
+			case !closing && strings.Contains(attrs, "expandable"):
+				inQuote, expandable = true, true
+				b.WriteString("**>")
+			case !closing:
+				inQuote = true
+				b.WriteString(">")
+			default:
+				if expandable {
+					b.WriteString("||")
+				}
+				inQuote, expandable = false, false
+			}
+		case "a":
+			if !closing {
+				hrefs = append(hrefs, html.UnescapeString(attrValue(attrs, "href")))
+				b.WriteString("[")
+			} else if n := len(hrefs); n > 0 {
+				b.WriteString("](" + escapeMarkdownV2URL(hrefs[n-1]) + ")")
+				hrefs = hrefs[:n-1]
+			}
+		}
+	}
+	return b.String()
+}
+
+// attrValue extracts name="value" from a tag's attribute string.
+func attrValue(attrs, name string) string {
+	i := strings.Index(attrs, name+`="`)
+	if i == -1 {
+		return ""
+	}
+	v := attrs[i+len(name)+2:]
+	if j := strings.IndexByte(v, '"'); j != -1 {
+		return v[:j]
+	}
+	return v
+}
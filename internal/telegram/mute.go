@@ -0,0 +1,90 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// A chat can mute a wallet for a while: its alerts are dropped for that
+// chat until the mute expires, while the subscription, history and other
+// owners' alerts carry on. The expiry is stored so mutes survive restarts.
+
+func muteKey(addr string) string { return "mute:" + addr }
+
+// mutedUntil returns when chatID's mute of addr ends; zero when addr isn't
+// muted.
+func (h *Handler) mutedUntil(ctx context.Context, chatID int64, addr string) time.Time {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, muteKey(addr)))
+	if err != nil || !ok {
+		return time.Time{}
+	}
+	unix, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || time.Now().Unix() >= unix {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
+
+// mute silences addr for chatID for d; d == 0 lifts the mute.
+func (h *Handler) mute(ctx context.Context, chatID int64, addr string, d time.Duration) error {
+	key := store.UserSettingKey(chatID, muteKey(addr))
+	if d == 0 {
+		return h.st.DeleteSetting(ctx, key)
+	}
+	return h.st.SetSetting(ctx, key, strconv.FormatInt(time.Now().Add(d).Unix(), 10))
+}
+
+// handleMute shows or sets wallet mutes.
+//
+//	/mute                                  list muted wallets
+//	/mute <address|tag:<tag>> <period>     e.g. 12h, 7d
+func (h *Handler) handleMute(ctx context.Context, chatID int64, args []string) {
+	if len(args) == 0 {
+		wallets, err := h.st.ListUserWallets(ctx, chatID)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "mute failed: "+errorText(err))
+			return
+		}
+		var b strings.Builder
+		b.WriteString("🔇 <b>Muted wallets:</b>\n")
+		var n int
+		for _, a := range wallets {
+			if until := h.mutedUntil(ctx, chatID, a); !until.IsZero() {
+				fmt.Fprintf(&b, "- <code>%s</code> for %s\n", escapeHTML(a), holdString(time.Until(until)))
+				n++
+			}
+		}
+		if n == 0 {
+			b.WriteString("none\n")
+		}
+		b.WriteString("\nMute with <code>/mute &lt;address|tag:&lt;tag&gt;&gt; &lt;period&gt;</code>, lift with <code>/unmute</code>")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+	var d time.Duration
+	ok := len(args) == 2
+	if ok {
+		d, ok = parsePeriod(strings.ToLower(args[1]))
+	}
+	if !ok {
+		h.sendHTML(ctx, chatID, "usage: <code>/mute &lt;address|tag:&lt;tag&gt;&gt; &lt;period&gt;</code>, e.g. <code>/mute tag:nft 12h</code>")
+		return
+	}
+	h.runBulk(ctx, chatID, bulkOp{verb: "mute", selector: args[0], period: d})
+}
+
+// handleUnmute lifts wallet mutes.
+//
+//	/unmute <address|tag:<tag>>
+func (h *Handler) handleUnmute(ctx context.Context, chatID int64, args []string) {
+	if len(args) != 1 {
+		h.sendHTML(ctx, chatID, "usage: <code>/unmute &lt;address|tag:&lt;tag&gt;&gt;</code>")
+		return
+	}
+	h.runBulk(ctx, chatID, bulkOp{verb: "unmute", selector: args[0]})
+}
@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// Each chat can keep a free-text note per wallet (why it was tracked, who
+// it belongs to). Notes show in /tracked verbose and /stats and go when
+// the wallet is untracked.
+
+const maxNoteLen = 500 // characters
+
+func noteKey(addr string) string { return "note:" + addr }
+
+// walletNote returns chatID's note for addr, or "".
+func (h *Handler) walletNote(ctx context.Context, chatID int64, addr string) string {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, noteKey(addr)))
+	if err != nil || !ok {
+		return ""
+	}
+	return v
+}
+
+// noteLine renders a wallet's note as an indented line, or "" without one.
+func noteLine(note string) string {
+	if note == "" {
+		return ""
+	}
+	return "  📝 <i>" + escapeHTML(note) + "</i>\n"
+}
+
+// handleNote shows or sets wallet notes. args is everything after the
+// command, so the text keeps its case and line breaks.
+//
+//	/note                     list notes
+//	/note <address>           show the wallet's note
+//	/note <address> <text>    replace it
+//	/note <address> off
+func (h *Handler) handleNote(ctx context.Context, chatID int64, args string) {
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "note failed: "+errorText(err))
+		return
+	}
+	args = strings.TrimSpace(args)
+	if args == "" {
+		var b strings.Builder
+		b.WriteString("📝 <b>Wallet notes:</b>\n")
+		var n int
+		for _, a := range wallets {
+			if note := h.walletNote(ctx, chatID, a); note != "" {
+				fmt.Fprintf(&b, "- <code>%s</code>\n%s", escapeHTML(a), noteLine(note))
+				n++
+			}
+		}
+		if n == 0 {
+			b.WriteString("none set\n")
+		}
+		b.WriteString("\nSet with <code>/note &lt;address&gt; &lt;text&gt;</code>")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+
+	addr, text := args, ""
+	if i := strings.IndexAny(args, " \n"); i != -1 {
+		addr, text = args[:i], strings.TrimSpace(args[i:])
+	}
+	if !contains(wallets, addr) {
+		h.sendHTML(ctx, chatID, "that wallet isn't tracked. see <code>/tracked</code>")
+		return
+	}
+	key := store.UserSettingKey(chatID, noteKey(addr))
+	switch {
+	case text == "":
+		note := h.walletNote(ctx, chatID, addr)
+		if note == "" {
+			h.sendHTML(ctx, chatID, fmt.Sprintf("<code>%s</code> has no note", escapeHTML(shortAddr(addr))))
+			return
+		}
+		h.sendHTML(ctx, chatID, fmt.Sprintf("<code>%s</code>\n%s", escapeHTML(shortAddr(addr)), noteLine(note)))
+		return
+	case strings.EqualFold(text, "off"):
+		err = h.st.DeleteSetting(ctx, key)
+	case utf8.RuneCountInString(text) > maxNoteLen:
+		h.sendHTML(ctx, chatID, fmt.Sprintf("notes are limited to %d characters", maxNoteLen))
+		return
+	default:
+		err = h.st.SetSetting(ctx, key, text)
+	}
+	if err != nil {
+		h.sendHTML(ctx, chatID, "note failed: "+errorText(err))
+		return
+	}
+	if strings.EqualFold(text, "off") {
+		h.sendHTML(ctx, chatID, fmt.Sprintf("note removed from <code>%s</code>", escapeHTML(shortAddr(addr))))
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("note saved for <code>%s</code>", escapeHTML(shortAddr(addr))))
+}
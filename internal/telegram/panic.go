@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// panicNotifyGap limits admin messages to one per component per gap;
+	// panics in between are counted into the next message.
+	panicNotifyGap = 5 * time.Minute
+	maxPanicStack  = 2500
+)
+
+// panicNotices throttles NotifyPanic per component.
+type panicNotices struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+	held map[string]int
+}
+
+// NotifyPanic reports a recovered panic to the admin chat; see
+// util.PanicHandler.
+func (h *Handler) NotifyPanic(component string, value any, stack []byte) {
+	panicState := &h.panics
+	panicState.mu.Lock()
+	if panicState.last == nil {
+		panicState.last = make(map[string]time.Time)
+		panicState.held = make(map[string]int)
+	}
+	if time.Since(panicState.last[component]) < panicNotifyGap {
+		panicState.held[component]++
+		panicState.mu.Unlock()
+		return
+	}
+	held := panicState.held[component]
+	panicState.last[component] = time.Now()
+	panicState.held[component] = 0
+	panicState.mu.Unlock()
+
+	trace := string(stack)
+	if len(trace) > maxPanicStack {
+		trace = trace[:maxPanicStack] + "\n…"
+	}
+	msg := fmt.Sprintf("💥 <b>Recovered panic in %s</b>\n<code>%s</code>", escapeHTML(component), escapeHTML(fmt.Sprint(value)))
+	if held > 0 {
+		msg += fmt.Sprintf("\n(+%d more since the last report)", held)
+	}
+	msg += "\n<pre>" + escapeHTML(trace) + "</pre>"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	h.sendHTML(ctx, h.adminID, msg)
+}
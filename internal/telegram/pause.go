@@ -0,0 +1,32 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// handlePauseAll suspends every subscription for maintenance (an RPC
+// provider outage, a server move). Watchlists, trials and settings are
+// untouched, and signatures already queued are dropped without alerting.
+func (h *Handler) handlePauseAll(ctx context.Context, chatID int64) {
+	if h.tm.Paused() {
+		h.sendHTML(ctx, chatID, "already paused; <code>/resumeall</code> to resume")
+		return
+	}
+	n := h.tm.Pause()
+	log.Printf("[handler] paused %d subscription(s)", n)
+	h.sendHTML(ctx, chatID, fmt.Sprintf("⏸ paused %d subscription(s); no alerts until <code>/resumeall</code>", n))
+}
+
+// handleResumeAll restarts the subscriptions handlePauseAll (or a start
+// with START_PAUSED) suspended, including wallets tracked in between.
+func (h *Handler) handleResumeAll(ctx context.Context, chatID int64) {
+	if !h.tm.Paused() {
+		h.sendHTML(ctx, chatID, "not paused")
+		return
+	}
+	n := h.tm.Resume(ctx)
+	log.Printf("[handler] resumed %d subscription(s)", n)
+	h.sendHTML(ctx, chatID, fmt.Sprintf("▶️ resumed %d subscription(s)", n))
+}
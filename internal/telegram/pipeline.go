@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/tracker"
+)
+
+// DefaultWorkers is the analysis pool size used when Handler.Workers is 0.
+const DefaultWorkers = 8
+
+// walletQueues serializes work per wallet: while a worker handles one of a
+// wallet's signatures, later ones wait here and the same worker takes them
+// in arrival order, so a buy and an immediate sell can't be alerted out of
+// order. Different wallets still run in parallel.
+type walletQueues struct {
+	mu      sync.Mutex
+	pending map[string][]tracker.Signature // present = a worker owns the wallet
+}
+
+// claim reports whether the caller now owns ev's wallet; otherwise ev was
+// queued behind the signature in flight.
+func (q *walletQueues) claim(ev tracker.Signature) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if list, busy := q.pending[ev.Wallet]; busy {
+		q.pending[ev.Wallet] = append(list, ev)
+		metrics.Inc("pipeline.queued")
+		return false
+	}
+	q.pending[ev.Wallet] = nil
+	return true
+}
+
+// next pops the wallet's next queued signature, releasing the wallet when
+// there is none.
+func (q *walletQueues) next(wallet string) (tracker.Signature, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	list := q.pending[wallet]
+	if len(list) == 0 {
+		delete(q.pending, wallet)
+		return tracker.Signature{}, false
+	}
+	q.pending[wallet] = list[1:]
+	return list[0], true
+}
+
+// Consume analyzes and alerts on every signature from sigs until ctx is
+// done or sigs is closed, using a pool of Workers with per-wallet ordering.
+func (h *Handler) Consume(ctx context.Context, sigs <-chan tracker.Signature) {
+	workers := h.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	queues := &walletQueues{pending: make(map[string][]tracker.Signature)}
+	ready := make(chan tracker.Signature)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(ready)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ev := range ready {
+				for ok := true; ok; ev, ok = queues.next(ev.Wallet) {
+					h.HandleSignature(ev.Signature, ev.Wallet)
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sigs:
+			if !ok {
+				return
+			}
+			if !queues.claim(ev) {
+				continue
+			}
+			select {
+			case ready <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
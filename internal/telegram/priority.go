@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// Each chat can rank its wallets. High-priority alerts get a loud header and
+// skip the bot rate limit; low-priority ones are delivered silently and
+// default to the compact layout. Normal is the default and is not stored.
+
+const (
+	priorityHigh   = "high"
+	priorityNormal = "normal"
+	priorityLow    = "low"
+)
+
+func priorityKey(addr string) string { return "priority:" + addr }
+
+// walletPriority returns chatID's priority for addr; without one of its
+// own, a wallet takes its tags' route (see /tagroute).
+func (h *Handler) walletPriority(ctx context.Context, chatID int64, addr string) string {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, priorityKey(addr)))
+	if err != nil || !ok {
+		v = h.tagRoute(ctx, chatID, addr)
+	}
+	if v != priorityHigh && v != priorityLow {
+		return priorityNormal
+	}
+	return v
+}
+
+// anyHighPriority reports whether one of chats ranks addr high.
+func (h *Handler) anyHighPriority(ctx context.Context, chats []int64, addr string) bool {
+	for _, chatID := range chats {
+		if h.walletPriority(ctx, chatID, addr) == priorityHigh {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePriority shows or sets wallet priorities.
+//
+//	/priority                          list non-normal wallets
+//	/priority <address> high|normal|low
+func (h *Handler) handlePriority(ctx context.Context, chatID int64, args []string) {
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "priority failed: "+errorText(err))
+		return
+	}
+	if len(args) == 0 {
+		var b strings.Builder
+		b.WriteString("🎚 <b>Wallet priorities</b> (others are normal):\n")
+		var n int
+		for _, a := range wallets {
+			if p := h.walletPriority(ctx, chatID, a); p != priorityNormal {
+				fmt.Fprintf(&b, "- <code>%s</code>: <b>%s</b>\n", escapeHTML(a), p)
+				n++
+			}
+		}
+		if n == 0 {
+			b.WriteString("none set\n")
+		}
+		b.WriteString("\nChange with <code>/priority &lt;address&gt; high|normal|low</code>")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+
+	p := ""
+	if len(args) == 2 {
+		p = strings.ToLower(args[1])
+	}
+	if p != priorityHigh && p != priorityNormal && p != priorityLow {
+		h.sendHTML(ctx, chatID, "usage: <code>/priority &lt;address&gt; high|normal|low</code>")
+		return
+	}
+	addr := args[0]
+	if !contains(wallets, addr) {
+		h.sendHTML(ctx, chatID, "that wallet isn't tracked. see <code>/tracked</code>")
+		return
+	}
+	key := store.UserSettingKey(chatID, priorityKey(addr))
+	if p == priorityNormal {
+		err = h.st.DeleteSetting(ctx, key)
+	} else {
+		err = h.st.SetSetting(ctx, key, p)
+	}
+	if err != nil {
+		h.sendHTML(ctx, chatID, "priority failed: "+errorText(err))
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("<code>%s</code> is now <b>%s</b> priority", escapeHTML(shortAddr(addr)), p))
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+
+	tg "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// handleQR sends a QR code for an address. With "pay" or an amount (SOL) it
+// encodes a Solana Pay transfer URI instead of the bare address, so mobile
+// wallets open a prefilled send screen.
+//
+//	/qr <address>
+//	/qr <address> pay
+//	/qr <address> 0.5
+func (h *Handler) handleQR(ctx context.Context, chatID int64, args []string) {
+	if len(args) == 0 || len(args) > 2 {
+		h.sendHTML(ctx, chatID, "usage: <code>/qr &lt;address&gt; [pay|amount]</code>")
+		return
+	}
+	addrs := parseAddressArg(args[0])
+	if len(addrs) == 0 || !isBase58Len(addrs[0], 32) {
+		h.sendHTML(ctx, chatID, "that is not a valid Solana address")
+		return
+	}
+	addr := addrs[0]
+
+	content, caption := addr, "<code>"+escapeHTML(addr)+"</code>"
+	if len(args) == 2 {
+		q := url.Values{}
+		q.Set("label", "solwatch")
+		if args[1] != "pay" {
+			amt, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || amt <= 0 {
+				h.sendHTML(ctx, chatID, "amount must be a positive number of SOL")
+				return
+			}
+			q.Set("amount", strconv.FormatFloat(amt, 'f', -1, 64))
+		}
+		content = "solana:" + addr + "?" + q.Encode()
+		caption = fmt.Sprintf("Solana Pay: <code>%s</code>", escapeHTML(content))
+	}
+
+	png, err := qrcode.Encode(content, qrcode.Medium, 512)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "qr failed: "+errorText(err))
+		return
+	}
+	h.sendPhoto(ctx, chatID, "qr.png", png, caption)
+}
+
+// sendPhoto uploads a PNG with an HTML caption.
+func (h *Handler) sendPhoto(ctx context.Context, chatID int64, name string, png []byte, caption string) {
+	_, err := h.bot.SendPhoto(ctx, &tg.SendPhotoParams{
+		ChatID:    chatID,
+		Photo:     &models.InputFileUpload{Filename: name, Data: bytes.NewReader(png)},
+		Caption:   caption,
+		ParseMode: models.ParseModeHTML,
+	})
+	if err != nil {
+		log.Printf("[telegram] send photo error: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+)
+
+// walletLimiter throttles alerts per wallet. Suppressed alerts are counted so
+// the next delivered message can say how many were folded into it.
+type walletLimiter struct {
+	mu         sync.Mutex
+	last       map[string]time.Time
+	suppressed map[string]int
+}
+
+func newWalletLimiter() *walletLimiter {
+	return &walletLimiter{
+		last:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// allow reports whether an alert for addr may be sent now given a minimum gap
+// of every. When allowed, it also returns (and resets) the suppressed count.
+func (l *walletLimiter) allow(addr string, every time.Duration) (ok bool, suppressed int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if last, seen := l.last[addr]; seen && now.Sub(last) < every {
+		l.suppressed[addr]++
+		return false, 0
+	}
+	l.last[addr] = now
+	suppressed = l.suppressed[addr]
+	delete(l.suppressed, addr)
+	return true, suppressed
+}
+
+// forget drops limiter state for addr.
+func (l *walletLimiter) forget(addr string) {
+	l.mu.Lock()
+	delete(l.last, addr)
+	delete(l.suppressed, addr)
+	l.mu.Unlock()
+}
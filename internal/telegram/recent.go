@@ -0,0 +1,153 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+const (
+	defaultRecentHours = 12
+	maxRecentHours     = 24 * 30
+	maxRecentWallets   = 40 // keeps the reply under Telegram's message limit
+	// maxScanHistory bounds the history entries /recent and /flows read
+	// per wallet; older ones of a busier wallet are left out.
+	maxScanHistory = 2000
+)
+
+// walletActivity is one wallet's history inside the /recent window.
+type walletActivity struct {
+	wallet  string
+	entries []store.HistoryEntry // newest first
+	usd     float64
+}
+
+// handleRecent lists the chat's wallets that were active in the last N
+// hours, one line each, busiest first.
+//
+//	/recent [hours]
+func (h *Handler) handleRecent(ctx context.Context, chatID int64, args []string) {
+	hours := defaultRecentHours
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > maxRecentHours || len(args) > 1 {
+			h.sendHTML(ctx, chatID, fmt.Sprintf("usage: <code>/recent [hours]</code> (1–%d, default %d)", maxRecentHours, defaultRecentHours))
+			return
+		}
+		hours = n
+	}
+
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "recent failed: "+errorText(err))
+		return
+	}
+	if len(wallets) == 0 {
+		h.sendHTML(ctx, chatID, "<b>No wallets tracked.</b>")
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	var active []walletActivity
+	for _, w := range wallets {
+		hist, err := h.st.RecentHistory(ctx, w, maxScanHistory)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "recent failed: "+errorText(err))
+			return
+		}
+		a := walletActivity{wallet: w}
+		for _, e := range hist {
+			if e.Time.Before(since) {
+				break
+			}
+			a.entries = append(a.entries, e)
+			a.usd += e.SizeUSD
+		}
+		if len(a.entries) > 0 {
+			active = append(active, a)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if len(active[i].entries) != len(active[j].entries) {
+			return len(active[i].entries) > len(active[j].entries)
+		}
+		return active[i].entries[0].Time.After(active[j].entries[0].Time)
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🕒 <b>Last %dh:</b> %d of %d wallet(s) active\n", hours, len(active), len(wallets))
+	if len(active) == 0 {
+		b.WriteString("\nNothing happened.")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+	nf := h.numberFormat(ctx, chatID)
+	var total int
+	for i, a := range active {
+		total += len(a.entries)
+		if i >= maxRecentWallets {
+			continue
+		}
+		b.WriteString("\n")
+		b.WriteString(recentLine(a, nf))
+	}
+	if len(active) > maxRecentWallets {
+		fmt.Fprintf(&b, "\n\n<i>+%d more active wallet(s)</i>", len(active)-maxRecentWallets)
+	}
+	fmt.Fprintf(&b, "\n\n%d transaction(s) in total", total)
+	h.sendHTML(ctx, chatID, b.String())
+}
+
+// recentLine renders "- <wallet> 3 tx (2 SWAP, 1 TRANSFER) · $1,200 ·
+// 2h ago: <latest legs>".
+func recentLine(a walletActivity, nf analyzer.NumberFormat) string {
+	types := make(map[string]int)
+	for _, e := range a.entries {
+		types[e.Type]++
+	}
+	keys := sortedKeys(types)
+	sort.SliceStable(keys, func(i, j int) bool { return types[keys[i]] > types[keys[j]] })
+	parts := make([]string, 0, len(keys))
+	for _, t := range keys {
+		parts = append(parts, fmt.Sprintf("%d %s", types[t], escapeHTML(t)))
+	}
+
+	latest := a.entries[0]
+	line := fmt.Sprintf("- <code>%s</code> <b>%d tx</b> (%s)", escapeHTML(shortAddr(a.wallet)), len(a.entries), strings.Join(parts, ", "))
+	if a.usd > 0 {
+		line += fmt.Sprintf(" · $%s", nf.Fixed(math.Round(a.usd), 0))
+	}
+	line += fmt.Sprintf(" · %s ago", holdString(time.Since(latest.Time)))
+	if legs := historyLegs(latest, nf); legs != "" {
+		line += ": " + legs
+	}
+	return line
+}
+
+// historyLegs summarizes an entry's amounts as "2 SOL → 1,000,000 WIF".
+func historyLegs(e store.HistoryEntry, nf analyzer.NumberFormat) string {
+	join := func(list []store.HistoryAmount) string {
+		out := make([]string, 0, len(list))
+		for _, a := range list {
+			out = append(out, escapeHTML(analyzer.Amount{Symbol: a.Symbol, Amount: a.Amount}.Format(nf)))
+		}
+		return strings.Join(out, " + ")
+	}
+	sent, got := join(e.Sent), join(e.Received)
+	switch {
+	case sent != "" && got != "":
+		return sent + " → " + got
+	case sent != "":
+		return "sent " + sent
+	case got != "":
+		return "received " + got
+	}
+	return ""
+}
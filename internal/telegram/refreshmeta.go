@@ -0,0 +1,33 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+)
+
+// handleRefreshMeta re-resolves a mint's metadata, replacing a stale or
+// fallback ("Mint(...)") entry without a restart.
+//
+//	/refreshmeta <mint>
+func (h *Handler) handleRefreshMeta(ctx context.Context, chatID int64, args []string) {
+	if len(args) != 1 || !isBase58Len(args[0], 32) {
+		h.sendHTML(ctx, chatID, "usage: <code>/refreshmeta &lt;mint&gt;</code>")
+		return
+	}
+	mint := args[0]
+	old, hadOld := h.analyzer.CachedMetadata(mint)
+	meta, err := h.analyzer.RefreshMetadata(ctx, mint)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "refresh failed: "+errorText(err))
+		return
+	}
+
+	msg := fmt.Sprintf("🔄 <code>%s</code>\n<b>%s</b> (%d decimals)", escapeHTML(mint), escapeHTML(meta.Symbol), meta.Decimals)
+	switch {
+	case hadOld && (old.Symbol != meta.Symbol || old.Decimals != meta.Decimals):
+		msg += fmt.Sprintf(", was %s (%d decimals)", escapeHTML(old.Symbol), old.Decimals)
+	case hadOld:
+		msg += ", unchanged"
+	}
+	h.sendHTML(ctx, chatID, msg)
+}
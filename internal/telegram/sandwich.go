@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// With sandwich detection on, a swap alert goes out without waiting for
+// getBlock; the check runs afterwards and a find is added to the delivered
+// alerts as a footer line. A later finality edit drops that line again.
+
+// sandwichDeadline bounds the retries of one check.
+const sandwichDeadline = time.Minute
+
+// annotateSandwich checks res for a sandwich and edits the sent alerts when
+// one is found.
+func (h *Handler) annotateSandwich(res *analyzer.Result, sent []sentAlert, footer string) {
+	ctx, cancel := context.WithTimeout(context.Background(), sandwichDeadline)
+	defer cancel()
+
+	note, err := h.analyzer.SandwichNote(ctx, res)
+	if err != nil {
+		util.Errors.Printf("[analyzer] sandwich check for %s failed: %v", res.Signature, err)
+		return
+	}
+	if note == "" {
+		return
+	}
+	for _, s := range sent {
+		text, markdown := h.renderAlert(ctx, s.chatID, res)
+		h.editAlert(ctx, s, text, markdown, footer+"\n\n"+note)
+	}
+}
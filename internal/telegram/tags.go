@@ -0,0 +1,265 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// Each chat can tag its wallets with archetypes (sniper, nft, whale, ...).
+// Tags filter /tracked, route alerts through /tagroute and travel with the
+// event (Result.Tags), so EMAIL_RULES and plugins can match on them.
+
+const (
+	maxTags   = 8
+	maxTagLen = 24
+	routeMute = "mute"
+)
+
+func tagsKey(addr string) string    { return "tags:" + addr }
+func tagRouteKey(tag string) string { return "tagroute:" + tag }
+
+// routeRank orders tag routes; the loudest route among a wallet's tags wins.
+var routeRank = map[string]int{routeMute: 0, priorityLow: 1, priorityNormal: 2, priorityHigh: 3}
+
+// walletTags returns chatID's tags for addr.
+func (h *Handler) walletTags(ctx context.Context, chatID int64, addr string) []string {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, tagsKey(addr)))
+	if err != nil || !ok {
+		return nil
+	}
+	return strings.Fields(v)
+}
+
+// setWalletTag adds tag to or removes it from chatID's tags for addr. A
+// wallet already at maxTags isn't given another.
+func (h *Handler) setWalletTag(ctx context.Context, chatID int64, addr, tag string, on bool) error {
+	tags := h.walletTags(ctx, chatID, addr)
+	var out []string
+	for _, t := range tags {
+		if t != tag {
+			out = append(out, t)
+		}
+	}
+	if on && len(out) < maxTags {
+		out = append(out, tag)
+	}
+	if slices.Equal(out, tags) {
+		return nil
+	}
+	key := store.UserSettingKey(chatID, tagsKey(addr))
+	if len(out) == 0 {
+		return h.st.DeleteSetting(ctx, key)
+	}
+	return h.st.SetSetting(ctx, key, strings.Join(out, " "))
+}
+
+// ownerTags returns the union of the tags addr's owners gave it, sorted.
+func (h *Handler) ownerTags(ctx context.Context, addr string) []string {
+	var out []string
+	for _, u := range h.tm.Owners(addr) {
+		for _, t := range h.walletTags(ctx, u, addr) {
+			if !contains(out, t) {
+				out = append(out, t)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// tagRoute returns the loudest of chatID's routes for addr's tags, or ""
+// when none of them is routed.
+func (h *Handler) tagRoute(ctx context.Context, chatID int64, addr string) string {
+	best := ""
+	for _, t := range h.walletTags(ctx, chatID, addr) {
+		v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, tagRouteKey(t)))
+		if err != nil || !ok {
+			continue
+		}
+		if _, known := routeRank[v]; known && (best == "" || routeRank[v] > routeRank[best]) {
+			best = v
+		}
+	}
+	return best
+}
+
+// tagMuted reports whether chatID muted addr through a tag route. A
+// wallet's own high or low /priority overrides its tags.
+func (h *Handler) tagMuted(ctx context.Context, chatID int64, addr string) bool {
+	if _, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, priorityKey(addr))); err == nil && ok {
+		return false
+	}
+	return h.tagRoute(ctx, chatID, addr) == routeMute
+}
+
+// parseTags normalizes /tag arguments: lower-case, '#' stripped, no
+// duplicates.
+func parseTags(args []string) ([]string, error) {
+	var tags []string
+	for _, a := range args {
+		t := strings.ToLower(strings.TrimPrefix(a, "#"))
+		if !validTag(t) {
+			return nil, fmt.Errorf("invalid tag %q: use up to %d letters, digits, - or _", a, maxTagLen)
+		}
+		if !contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("at most %d tags per wallet", maxTags)
+	}
+	return tags, nil
+}
+
+func validTag(t string) bool {
+	if t == "" || len(t) > maxTagLen {
+		return false
+	}
+	for _, r := range t {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// tagSuffix renders tags for a wallet line, e.g. " #sniper #nft".
+func tagSuffix(tags []string) string {
+	var b strings.Builder
+	for _, t := range tags {
+		b.WriteString(" #")
+		b.WriteString(escapeHTML(t))
+	}
+	return b.String()
+}
+
+// handleTag shows or sets wallet tags.
+//
+//	/tag                          list tagged wallets
+//	/tag <address> <tag>...       replace the wallet's tags
+//	/tag <address> off
+func (h *Handler) handleTag(ctx context.Context, chatID int64, args []string) {
+	wallets, err := h.st.ListUserWallets(ctx, chatID)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "tag failed: "+errorText(err))
+		return
+	}
+	if len(args) == 0 {
+		var b strings.Builder
+		b.WriteString("🏷 <b>Wallet tags:</b>\n")
+		var n int
+		for _, a := range wallets {
+			if tags := h.walletTags(ctx, chatID, a); len(tags) > 0 {
+				fmt.Fprintf(&b, "- <code>%s</code>%s\n", escapeHTML(a), tagSuffix(tags))
+				n++
+			}
+		}
+		if n == 0 {
+			b.WriteString("none set\n")
+		}
+		b.WriteString("\nSet with <code>/tag &lt;address&gt; &lt;tag&gt;...</code>, filter with <code>/tracked tag:&lt;tag&gt;</code>, route with <code>/tagroute</code>")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+	if len(args) < 2 {
+		h.sendHTML(ctx, chatID, "usage: <code>/tag &lt;address&gt; &lt;tag&gt;...|off</code>")
+		return
+	}
+	addr := args[0]
+	if !contains(wallets, addr) {
+		h.sendHTML(ctx, chatID, "that wallet isn't tracked. see <code>/tracked</code>")
+		return
+	}
+	key := store.UserSettingKey(chatID, tagsKey(addr))
+	if len(args) == 2 && strings.EqualFold(args[1], "off") {
+		if err := h.st.DeleteSetting(ctx, key); err != nil {
+			h.sendHTML(ctx, chatID, "tag failed: "+errorText(err))
+			return
+		}
+		h.sendHTML(ctx, chatID, fmt.Sprintf("tags removed from <code>%s</code>", escapeHTML(shortAddr(addr))))
+		return
+	}
+	tags, err := parseTags(args[1:])
+	if err != nil {
+		h.sendHTML(ctx, chatID, escapeHTML(err.Error()))
+		return
+	}
+	if err := h.st.SetSetting(ctx, key, strings.Join(tags, " ")); err != nil {
+		h.sendHTML(ctx, chatID, "tag failed: "+errorText(err))
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("<code>%s</code> tagged%s", escapeHTML(shortAddr(addr)), tagSuffix(tags)))
+}
+
+// handleTagRoute shows or sets how alerts of tagged wallets are routed.
+//
+//	/tagroute                             list routes
+//	/tagroute <tag> high|normal|low|mute
+//	/tagroute <tag> off
+func (h *Handler) handleTagRoute(ctx context.Context, chatID int64, args []string) {
+	if len(args) == 0 {
+		wallets, err := h.st.ListUserWallets(ctx, chatID)
+		if err != nil {
+			h.sendHTML(ctx, chatID, "tagroute failed: "+errorText(err))
+			return
+		}
+		var tags []string
+		for _, a := range wallets {
+			for _, t := range h.walletTags(ctx, chatID, a) {
+				if !contains(tags, t) {
+					tags = append(tags, t)
+				}
+			}
+		}
+		sort.Strings(tags)
+		var b strings.Builder
+		b.WriteString("🔀 <b>Tag routes</b> (others are unrouted):\n")
+		var n int
+		for _, t := range tags {
+			if v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, tagRouteKey(t))); err == nil && ok {
+				fmt.Fprintf(&b, "- #%s: <b>%s</b>\n", escapeHTML(t), escapeHTML(v))
+				n++
+			}
+		}
+		if n == 0 {
+			b.WriteString("none set\n")
+		}
+		b.WriteString("\nChange with <code>/tagroute &lt;tag&gt; high|normal|low|mute|off</code>. The loudest route among a wallet's tags applies; a wallet's own /priority overrides it.")
+		h.sendHTML(ctx, chatID, b.String())
+		return
+	}
+
+	route := ""
+	if len(args) == 2 {
+		route = strings.ToLower(args[1])
+	}
+	if _, ok := routeRank[route]; !ok && route != "off" {
+		h.sendHTML(ctx, chatID, "usage: <code>/tagroute &lt;tag&gt; high|normal|low|mute|off</code>")
+		return
+	}
+	tags, err := parseTags(args[:1])
+	if err != nil {
+		h.sendHTML(ctx, chatID, escapeHTML(err.Error()))
+		return
+	}
+	key := store.UserSettingKey(chatID, tagRouteKey(tags[0]))
+	if route == "off" {
+		err = h.st.DeleteSetting(ctx, key)
+	} else {
+		err = h.st.SetSetting(ctx, key, route)
+	}
+	if err != nil {
+		h.sendHTML(ctx, chatID, "tagroute failed: "+errorText(err))
+		return
+	}
+	if route == "off" {
+		h.sendHTML(ctx, chatID, fmt.Sprintf("#%s is no longer routed", escapeHTML(tags[0])))
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("wallets tagged #%s are now routed <b>%s</b>", escapeHTML(tags[0]), route))
+}
@@ -0,0 +1,424 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+	"github.com/go-telegram/bot/models"
+)
+
+// Alerts can be rendered with Go text/template instead of the built-in
+// layout. Templates are keyed by event type (SWAP, TRANSFER, ...) or
+// "default", and resolve per chat in this order: the chat's stored template
+// for the type, the chat's stored default, TEMPLATES_DIR/<TYPE>.tmpl,
+// TEMPLATES_DIR/default.tmpl, then the built-in layout. The output is
+// Telegram HTML, so templates should pass on-chain text through esc.
+//
+// Chats with the "markdown" setting on get MarkdownV2 instead: their own
+// templates are written in MarkdownV2 (esc and the other helpers escape for
+// it), server templates named <TYPE>.md.tmpl are preferred, and HTML output
+// is converted.
+
+const (
+	templatesSetting   = "templates" // per-chat JSON object: key -> template text
+	defaultTemplateKey = "default"
+	maxTemplateLen     = 3000
+	markdownSuffix     = ".md"
+)
+
+// templateData is what alert templates execute against. The embedded Result
+// exposes .Type, .Sent, .Received, .SizeUSD, .Notes and the other fields.
+type templateData struct {
+	*analyzer.Result
+	Short   string // shortened wallet address
+	Unusual bool   // AnomalyFactor > 0
+	Builtin string // the built-in summary body, for templates that only wrap it
+	// Priority is the chat's priority for the wallet: high, normal or low.
+	Priority string
+}
+
+// templateFuncs returns the helpers for HTML or MarkdownV2 templates. Every
+// helper that emits text escapes it for the target markup; numbers follow nf.
+func templateFuncs(markdown bool, nf analyzer.NumberFormat) template.FuncMap {
+	esc := escapeHTML
+	if markdown {
+		esc = escapeMarkdownV2
+	}
+	return template.FuncMap{
+		"esc":   esc,
+		"short": func(s string) string { return esc(shortAddr(s)) },
+		"amounts": func(list []analyzer.Amount) string {
+			parts := make([]string, len(list))
+			for i, a := range list {
+				parts[i] = esc(a.Format(nf))
+			}
+			return strings.Join(parts, ", ")
+		},
+		"usd": func(v float64) string { return esc("$" + nf.Fixed(v, 2)) },
+		"time": func(t time.Time) string {
+			return esc(t.UTC().Format("2006-01-02 15:04:05 UTC"))
+		},
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+func parseTemplate(name, text string, markdown bool) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs(markdown, analyzer.DefaultNumberFormat)).Option("missingkey=zero").Parse(text)
+}
+
+// templateCache holds the parsed form of each chat's stored templates so
+// alerts don't re-parse them. An entry is reused while its text is
+// unchanged and dropped when the chat saves its templates.
+type templateCache struct {
+	mu     sync.Mutex
+	parsed map[templateCacheKey]cachedTemplate
+}
+
+type templateCacheKey struct {
+	chatID   int64
+	key      string
+	markdown bool
+}
+
+type cachedTemplate struct {
+	text string
+	t    *template.Template // nil when text doesn't parse
+}
+
+// get returns the parsed template for text, parsing it on a miss.
+func (c *templateCache) get(chatID int64, key, text string, markdown bool) *template.Template {
+	k := templateCacheKey{chatID, key, markdown}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.parsed[k]; ok && e.text == text {
+		return e.t
+	}
+	if c.parsed == nil {
+		c.parsed = make(map[templateCacheKey]cachedTemplate)
+	}
+	t, err := parseTemplate(key, text, markdown)
+	if err != nil {
+		t = nil
+	}
+	c.parsed[k] = cachedTemplate{text: text, t: t}
+	return t
+}
+
+// forget drops chatID's entries.
+func (c *templateCache) forget(chatID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.parsed {
+		if k.chatID == chatID {
+			delete(c.parsed, k)
+		}
+	}
+}
+
+// LoadTemplates parses every <key>.tmpl (HTML) and <key>.md.tmpl
+// (MarkdownV2, stored under "<key>.md") in dir. An empty dir returns nil.
+func LoadTemplates(dir string) (map[string]*template.Template, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*template.Template, len(paths))
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		base := strings.TrimSuffix(filepath.Base(p), ".tmpl")
+		md := strings.HasSuffix(base, markdownSuffix)
+		key := templateKey(strings.TrimSuffix(base, markdownSuffix))
+		if md {
+			key += markdownSuffix
+		}
+		t, err := parseTemplate(key, string(raw), md)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(p), err)
+		}
+		if err := t.Execute(&bytes.Buffer{}, sampleData(md)); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(p), err)
+		}
+		out[key] = t
+	}
+	return out, nil
+}
+
+// templateKey normalizes an event type or "default".
+func templateKey(s string) string {
+	if strings.EqualFold(s, defaultTemplateKey) {
+		return defaultTemplateKey
+	}
+	return strings.ToUpper(s)
+}
+
+// alertTemplate finds the template for chatID and event type, or nil for
+// the built-in layout. md reports whether the template emits MarkdownV2.
+func (h *Handler) alertTemplate(ctx context.Context, chatID int64, typ string, markdown bool) (t *template.Template, md bool) {
+	keys := []string{templateKey(typ), defaultTemplateKey}
+	stored := h.storedTemplates(ctx, chatID)
+	for _, key := range keys {
+		if raw := stored[key]; raw != "" {
+			if t := h.chatTemplates.get(chatID, key, raw, markdown); t != nil {
+				return t, markdown
+			}
+		}
+	}
+	for _, key := range keys {
+		if markdown && h.Templates[key+markdownSuffix] != nil {
+			return h.Templates[key+markdownSuffix], true
+		}
+		if t := h.Templates[key]; t != nil {
+			return t, false
+		}
+	}
+	return nil, false
+}
+
+func (h *Handler) storedTemplates(ctx context.Context, chatID int64) map[string]string {
+	raw, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, templatesSetting))
+	if err != nil || !ok {
+		return nil
+	}
+	var m map[string]string
+	_ = json.Unmarshal([]byte(raw), &m)
+	return m
+}
+
+func (h *Handler) saveTemplates(ctx context.Context, chatID int64, m map[string]string) error {
+	h.chatTemplates.forget(chatID)
+	key := store.UserSettingKey(chatID, templatesSetting)
+	if len(m) == 0 {
+		return h.st.DeleteSetting(ctx, key)
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return h.st.SetSetting(ctx, key, string(raw))
+}
+
+// renderAlert builds the alert text for one chat in the chat's markup;
+// markdown reports which one that is. Alerts on a watched token lead with
+// its banner.
+func (h *Handler) renderAlert(ctx context.Context, chatID int64, res *analyzer.Result) (text string, markdown bool) {
+	text, markdown = h.renderAlertBody(ctx, chatID, res)
+	if banner := h.tokenBanner(ctx, chatID, res); banner != "" {
+		if markdown {
+			banner = htmlToMarkdownV2(banner)
+		}
+		text = banner + text
+	}
+	return text, markdown
+}
+
+func (h *Handler) renderAlertBody(ctx context.Context, chatID int64, res *analyzer.Result) (text string, markdown bool) {
+	markdown = h.userFlag(ctx, chatID, "markdown", false)
+	nf := h.numberFormat(ctx, chatID)
+	prio := h.walletPriority(ctx, chatID, res.Wallet)
+	if h.walletLayout(ctx, chatID, res.Wallet) == layoutCompact {
+		out := compactAlert(res, nf)
+		if markdown {
+			return htmlToMarkdownV2(out), true
+		}
+		return out, false
+	}
+	if t, md := h.alertTemplate(ctx, chatID, res.Type, markdown); t != nil {
+		var buf bytes.Buffer
+		// Clone: server templates are shared and Funcs mutates.
+		t, err := t.Clone()
+		if err == nil {
+			d := newTemplateData(res, md, nf)
+			d.Priority = prio
+			err = t.Funcs(templateFuncs(md, nf)).Execute(&buf, d)
+		}
+		switch {
+		case err != nil:
+			log.Printf("[handler] template %s for chat %d: %v", t.Name(), chatID, err)
+		case strings.TrimSpace(buf.String()) == "":
+		case markdown && !md:
+			return htmlToMarkdownV2(buf.String()), true
+		default:
+			return buf.String(), markdown
+		}
+	}
+	d := newTemplateData(res, false, nf)
+	d.Priority = prio
+	out := builtinAlert(d)
+	if markdown {
+		return htmlToMarkdownV2(out), true
+	}
+	return out, false
+}
+
+// sendAlert renders res for chatID and sends it with an optional HTML
+// footer. It returns the message ID (0 on failure) and whether the message
+// is MarkdownV2.
+func (h *Handler) sendAlert(ctx context.Context, chatID int64, res *analyzer.Result, footer string) (int, bool) {
+	text, markdown := h.renderAlert(ctx, chatID, res)
+	silent := h.walletPriority(ctx, chatID, res.Wallet) == priorityLow
+	if !markdown {
+		return h.sendMessage(ctx, chatID, text+footer, models.ParseModeHTML, silent), false
+	}
+	return h.sendMessage(ctx, chatID, text+htmlToMarkdownV2(footer), models.ParseModeMarkdown, silent), true
+}
+
+func newTemplateData(res *analyzer.Result, markdown bool, nf analyzer.NumberFormat) templateData {
+	d := templateData{
+		Result:  res,
+		Short:   shortAddr(res.Wallet),
+		Unusual: res.AnomalyFactor > 0,
+		Builtin: res.SummaryWith(nf),
+	}
+	if markdown {
+		d.Short = escapeMarkdownV2(d.Short)
+		d.Builtin = htmlToMarkdownV2(d.Builtin)
+	}
+	return d
+}
+
+func builtinAlert(d templateData) string {
+	header := "🚨 <b>Activity on %s</b>"
+	switch {
+	case d.Unusual:
+		header = "⚡️⚡️ <b>UNUSUAL activity on %s</b> ⚡️⚡️"
+	case d.Priority == priorityHigh:
+		header = "🔴🔴 <b>Activity on %s</b> 🔴🔴"
+	}
+	return fmt.Sprintf(header+"\n\n%s", d.Short, d.Builtin)
+}
+
+// sampleData is a representative swap used to validate and preview templates.
+func sampleData(markdown bool) templateData {
+	return newTemplateData(&analyzer.Result{
+		Signature:      "5xSampLeSignatureSampLeSignatureSampLeSignatureSampLeSignature1111",
+		Wallet:         "7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU",
+		Type:           "SWAP",
+		Source:         "JUPITER",
+		Timestamp:      time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Interpretation: "🔄 Swapped 1.5 SOL for 1,250,000 BONK",
+		Description:    "swapped 1.5 SOL for 1250000 BONK",
+		Sent:           []analyzer.Amount{{Symbol: "SOL", Amount: 1.5, USD: 225}},
+		Received:       []analyzer.Amount{{Symbol: "BONK", Amount: 1250000}},
+		SizeUSD:        225,
+		Severity:       analyzer.SeverityInfo,
+	}, markdown, analyzer.DefaultNumberFormat)
+}
+
+// handleTemplate manages the chat's stored templates.
+//
+//	/template                         list overrides
+//	/template set <TYPE|default> ...  store a template (rest of the message)
+//	/template clear <TYPE|default>
+//	/template preview [TYPE|default]  render against a sample swap
+func (h *Handler) handleTemplate(ctx context.Context, chatID int64, raw string) {
+	args := strings.Fields(raw)
+	usage := "usage: <code>/template [set &lt;TYPE|default&gt; &lt;text&gt;|clear &lt;TYPE|default&gt;|preview [TYPE]]</code>"
+	if len(args) == 0 {
+		h.listTemplates(ctx, chatID)
+		return
+	}
+	switch strings.ToLower(args[0]) {
+	case "set":
+		if len(args) < 3 {
+			h.sendHTML(ctx, chatID, usage)
+			return
+		}
+		key := templateKey(args[1])
+		// Keep the body's own line breaks: cut "set" and the key off raw.
+		body := strings.TrimSpace(raw)
+		body = strings.TrimSpace(body[len(args[0]):])
+		body = strings.TrimSpace(body[len(args[1]):])
+		if len(body) > maxTemplateLen {
+			h.sendHTML(ctx, chatID, fmt.Sprintf("template too long (max %d characters)", maxTemplateLen))
+			return
+		}
+		markdown := h.userFlag(ctx, chatID, "markdown", false)
+		t, err := parseTemplate(key, body, markdown)
+		if err == nil {
+			err = t.Execute(&bytes.Buffer{}, sampleData(markdown))
+		}
+		if err != nil {
+			h.sendHTML(ctx, chatID, "template error: "+errorText(err))
+			return
+		}
+		m := h.storedTemplates(ctx, chatID)
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[key] = body
+		if err := h.saveTemplates(ctx, chatID, m); err != nil {
+			h.sendHTML(ctx, chatID, "save failed: "+errorText(err))
+			return
+		}
+		h.sendHTML(ctx, chatID, fmt.Sprintf("template <b>%s</b> saved. try <code>/template preview %s</code>", escapeHTML(key), escapeHTML(key)))
+
+	case "clear":
+		if len(args) != 2 {
+			h.sendHTML(ctx, chatID, usage)
+			return
+		}
+		key := templateKey(args[1])
+		m := h.storedTemplates(ctx, chatID)
+		delete(m, key)
+		if err := h.saveTemplates(ctx, chatID, m); err != nil {
+			h.sendHTML(ctx, chatID, "clear failed: "+errorText(err))
+			return
+		}
+		h.sendHTML(ctx, chatID, fmt.Sprintf("template <b>%s</b> cleared", escapeHTML(key)))
+
+	case "preview":
+		d := sampleData(false)
+		if len(args) > 1 {
+			d.Type = templateKey(args[1])
+		}
+		h.sendAlert(ctx, chatID, d.Result, "")
+
+	default:
+		h.sendHTML(ctx, chatID, usage)
+	}
+}
+
+func (h *Handler) listTemplates(ctx context.Context, chatID int64) {
+	stored := h.storedTemplates(ctx, chatID)
+	var b strings.Builder
+	b.WriteString("📝 <b>Templates</b>\n")
+	if len(stored) == 0 && len(h.Templates) == 0 {
+		b.WriteString("using the built-in layout\n")
+	}
+	for _, k := range sortedKeys(stored) {
+		b.WriteString(fmt.Sprintf("- <code>%s</code> (yours)\n", escapeHTML(k)))
+	}
+	for _, k := range sortedKeys(h.Templates) {
+		b.WriteString(fmt.Sprintf("- <code>%s</code> (server)\n", escapeHTML(k)))
+	}
+	b.WriteString("\nfields: <code>.Type .Source .Short .Wallet .Signature .Interpretation .Description .Sent .Received .SizeUSD .Unusual .AnomalyFactor .Notes .Timestamp .Builtin .Priority .Severity</code>\n")
+	b.WriteString("funcs: <code>esc short amounts usd time join upper lower</code>\n")
+	if h.userFlag(ctx, chatID, "markdown", false) {
+		b.WriteString("markup: <b>MarkdownV2</b> (<code>/set markdown off</code> for HTML)")
+	} else {
+		b.WriteString("markup: <b>HTML</b> (<code>/set markdown on</code> for MarkdownV2)")
+	}
+	h.sendHTML(ctx, chatID, b.String())
+}
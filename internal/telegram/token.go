@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDescription caps the token description shown by /token, in runes.
+const maxDescription = 300
+
+// handleToken shows a token's metadata: name, symbol, decimals, Jupiter
+// verification, royalty, creators and, from its off-chain JSON or the
+// token list, description and image.
+//
+//	/token <mint>
+func (h *Handler) handleToken(ctx context.Context, chatID int64, args []string) {
+	if len(args) != 1 || !isBase58Len(args[0], 32) {
+		h.sendHTML(ctx, chatID, "usage: <code>/token &lt;mint&gt;</code>")
+		return
+	}
+	mint := args[0]
+	meta, err := h.analyzer.TokenDetails(ctx, mint)
+	if err != nil {
+		h.sendHTML(ctx, chatID, "lookup failed: "+errorText(err))
+		return
+	}
+
+	title := escapeHTML(meta.Symbol)
+	if meta.Name != "" && meta.Name != meta.Symbol {
+		title = fmt.Sprintf("%s (%s)", escapeHTML(meta.Name), escapeHTML(meta.Symbol))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🪙 <b>%s</b>\n<code>%s</code>\nDecimals: <code>%d</code>", title, escapeHTML(mint), meta.Decimals)
+	if verified, known := h.analyzer.Verified(mint); known {
+		if verified {
+			b.WriteString("\n✅ Verified on Jupiter")
+		} else {
+			b.WriteString("\n❔ Not on Jupiter's verified list")
+		}
+	}
+	if len(meta.Tags) > 0 {
+		fmt.Fprintf(&b, "\nTags: %s", escapeHTML(strings.Join(meta.Tags, ", ")))
+	}
+	if meta.SellerFeeBps > 0 {
+		fmt.Fprintf(&b, "\nRoyalty: <code>%.2f%%</code>", float64(meta.SellerFeeBps)/100)
+	}
+	if len(meta.Creators) > 0 {
+		b.WriteString("\n<b>Creators:</b>")
+		for _, c := range meta.Creators {
+			mark := "unverified"
+			if c.Verified {
+				mark = "✅"
+			}
+			fmt.Fprintf(&b, "\n- <code>%s</code> %s · %d%%", escapeHTML(shortAddr(c.Address)), mark, c.Share)
+		}
+	}
+	image := meta.Logo
+	if off := meta.OffChain; off != nil {
+		if d := off.Description; d != "" {
+			if utf8.RuneCountInString(d) > maxDescription {
+				d = string([]rune(d)[:maxDescription]) + "…"
+			}
+			b.WriteString("\n\n" + escapeHTML(d))
+		}
+		if off.Image != "" {
+			image = off.Image
+		}
+	}
+	var links []string
+	if isWebURL(image) {
+		links = append(links, fmt.Sprintf(`<a href="%s">image</a>`, escapeHTML(image)))
+	}
+	if meta.OffChain != nil && isWebURL(meta.OffChain.ExternalURL) {
+		links = append(links, fmt.Sprintf(`<a href="%s">website</a>`, escapeHTML(meta.OffChain.ExternalURL)))
+	}
+	if len(links) > 0 {
+		b.WriteString("\n" + strings.Join(links, " · "))
+	}
+	h.sendHTML(ctx, chatID, b.String())
+}
+
+// isWebURL reports whether s is an http(s) URL, the only kind put in links.
+func isWebURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
@@ -0,0 +1,387 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+	tg "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// A trial is a wallet tracked for a limited time: started with
+// /track <addr> --for <period>, or by accepting a counterparty offer. The
+// sweeper untracks it once Until passes unless the user kept it (the Keep
+// button, or a plain /track). IfQuiet trials are instead kept automatically
+// when the wallet was active. Trials are one JSON list in the settings store
+// so they survive restarts on every backend.
+
+// DefaultTrial is the trial length when none is configured.
+const DefaultTrial = 48 * time.Hour
+
+const (
+	trialsSetting = "trials"
+	keepCallback  = "keep:" // inline button data that ends a trial, keeping the wallet
+	trialTag      = "trial" // tag a wallet carries while on trial
+	// maxReminderLead caps how long before expiry the keep-or-drop reminder
+	// goes out; shorter trials are reminded a quarter of their length ahead.
+	maxReminderLead = time.Hour
+)
+
+type trial struct {
+	User    int64     `json:"user"`
+	Addr    string    `json:"addr"`
+	Started time.Time `json:"started"`
+	Until   time.Time `json:"until"`
+	IfQuiet bool      `json:"if_quiet,omitempty"`
+	// Reminded is set once the keep-or-drop reminder was sent.
+	Reminded bool `json:"reminded,omitempty"`
+}
+
+// reminderDue reports whether t's reminder should go out at now.
+func (t trial) reminderDue(now time.Time) bool {
+	lead := t.Until.Sub(t.Started) / 4
+	if lead > maxReminderLead {
+		lead = maxReminderLead
+	}
+	return !t.IfQuiet && !t.Reminded && !now.Before(t.Until.Add(-lead))
+}
+
+// cutForFlag removes a "--for <period>" (or "--for=<period>") option from
+// args and returns the rest and the period, which is 0 without the option.
+func cutForFlag(args []string) ([]string, time.Duration, error) {
+	var (
+		rest   []string
+		period time.Duration
+	)
+	for i := 0; i < len(args); i++ {
+		v, isFlag := strings.CutPrefix(strings.ToLower(args[i]), "--for")
+		if !isFlag || v != "" && v[0] != '=' {
+			rest = append(rest, args[i])
+			continue
+		}
+		if v == "" {
+			if i+1 == len(args) {
+				return nil, 0, fmt.Errorf("--for needs a period such as 48h or 7d")
+			}
+			i++
+			v = args[i]
+		} else {
+			v = v[1:]
+		}
+		d, ok := parsePeriod(v)
+		if !ok {
+			return nil, 0, fmt.Errorf("--for needs a period such as 48h or 7d, got %q", v)
+		}
+		period = d
+	}
+	return rest, period, nil
+}
+
+// trialsOf returns user's trials by address.
+func (h *Handler) trialsOf(ctx context.Context, user int64) map[string]trial {
+	h.trialsMu.Lock()
+	list, err := h.loadTrials(ctx)
+	h.trialsMu.Unlock()
+	if err != nil {
+		log.Printf("[handler] loading trials: %v", err)
+		return nil
+	}
+	out := make(map[string]trial)
+	for _, t := range list {
+		if t.User == user {
+			out[t.Addr] = t
+		}
+	}
+	return out
+}
+
+// handleTrack is /track: it tracks each address, for good or, with --for,
+// for a trial. Tracking a trial wallet without --for keeps it.
+//
+//	/track <address|link>... [--for <period>]
+func (h *Handler) handleTrack(ctx context.Context, chatID int64, args []string) {
+	args, period, err := cutForFlag(args)
+	if err != nil {
+		h.sendHTML(ctx, chatID, escapeHTML(err.Error()))
+		return
+	}
+	if len(args) == 0 {
+		h.sendHTML(ctx, chatID, "usage: <code>/track &lt;address|solscan/birdeye link&gt; [--for 48h]</code>")
+		return
+	}
+	addrs := parseAddressArg(strings.Join(args, " "))
+	if len(addrs) == 0 {
+		h.sendHTML(ctx, chatID, "no wallet address found in that link")
+		return
+	}
+	for _, addr := range addrs {
+		if period == 0 {
+			err := h.trackFor(ctx, chatID, addr)
+			already := errors.Is(err, store.ErrWalletAlreadyTracked)
+			if err != nil && !already {
+				h.sendHTML(ctx, chatID, fmt.Sprintf("track %s failed: %s", escapeHTML(shortAddr(addr)), errorText(err)))
+				continue
+			}
+			kept, err := h.endTrial(ctx, chatID, addr)
+			if err != nil {
+				log.Printf("[handler] ending trial of %s: %v", addr, err)
+			}
+			switch {
+			case kept:
+				h.sendTrackReply(ctx, chatID, addr, "📌 keeping <b>%s</b> (trial ended)")
+			case already:
+				h.sendTrackReply(ctx, chatID, addr, "👌 already tracking <b>%s</b>")
+			default:
+				h.sendTrackReply(ctx, chatID, addr, "✅ tracking <b>%s</b>")
+			}
+			continue
+		}
+
+		if _, onTrial := h.trialsOf(ctx, chatID)[addr]; !onTrial && contains64(h.tm.Owners(addr), chatID) {
+			h.sendHTML(ctx, chatID, fmt.Sprintf("<b>%s</b> is already tracked for good; <code>/untrack</code> it first to start a trial", escapeHTML(addr)))
+			continue
+		}
+		now := time.Now()
+		if err := h.startTrial(ctx, trial{User: chatID, Addr: addr, Started: now, Until: now.Add(period)}); err != nil {
+			h.sendHTML(ctx, chatID, fmt.Sprintf("track %s failed: %s", escapeHTML(shortAddr(addr)), errorText(err)))
+			continue
+		}
+		metrics.Inc("trials.started")
+		h.sendButton(ctx, chatID, fmt.Sprintf("tracking <b>%s</b> for %s; it's untracked then unless you keep it", escapeHTML(addr), holdString(period)),
+			"📌 Keep tracking", keepCallback+addr)
+	}
+}
+
+// subscribeWait is how long after a /track reply a new subscription may
+// take to open before the reply calls it pending.
+const subscribeWait = 3 * time.Second
+
+// sendTrackReply sends headline (with a %s for the address) followed by how
+// alerts name the wallet and the chat's wallet count. When the subscription
+// hasn't opened within subscribeWait, the reply is edited in the background
+// to say it is pending, so bulk /track doesn't wait on each wallet.
+func (h *Handler) sendTrackReply(ctx context.Context, chatID int64, addr, headline string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, headline, escapeHTML(addr))
+	fmt.Fprintf(&b, "\nalerts call it <code>%s</code>", escapeHTML(shortAddr(addr)))
+	if list, err := h.st.ListUserWallets(ctx, chatID); err == nil {
+		fmt.Fprintf(&b, " · %d wallet(s) tracked", len(list))
+		if !h.isAdmin(chatID) && h.MaxWalletsPerUser > 0 {
+			fmt.Fprintf(&b, " of %d", h.MaxWalletsPerUser)
+		}
+	}
+
+	if h.tm.Paused() {
+		b.WriteString("\n⏸ subscriptions are paused; it starts on <code>/resumeall</code>")
+	}
+	text := b.String()
+	msgID := h.send(ctx, chatID, text, models.ParseModeHTML)
+	if msgID == 0 || h.tm.Paused() || h.tm.IsOpen(addr) {
+		return
+	}
+
+	util.Go("track-reply", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), subscribeWait+10*time.Second)
+		defer cancel()
+		deadline := time.Now().Add(subscribeWait)
+		for !h.tm.IsOpen(addr) && time.Now().Before(deadline) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if h.tm.Paused() || h.tm.IsOpen(addr) {
+			return
+		}
+		h.editAlert(ctx, sentAlert{chatID: chatID, msgID: msgID}, text, false,
+			"\n⏳ subscription pending: not connected yet, still retrying (see <code>/health</code>)")
+	})
+}
+
+// handleKeepButton ends a trial from its Keep button, keeping the wallet.
+func (h *Handler) handleKeepButton(ctx context.Context, q *models.CallbackQuery) {
+	chatID := q.From.ID
+	if q.Message.Message != nil {
+		chatID = q.Message.Message.Chat.ID
+	}
+	if !h.isAllowed(chatID) {
+		return
+	}
+	addr := strings.TrimPrefix(q.Data, keepCallback)
+	answer := "kept; tracking for good"
+	if kept, err := h.endTrial(ctx, chatID, addr); err != nil {
+		answer = "keep failed: " + util.RedactURLs(err.Error())
+	} else if !kept {
+		answer = "no trial running for this wallet"
+	} else {
+		metrics.Inc("trials.kept")
+	}
+	if _, err := h.bot.AnswerCallbackQuery(ctx, &tg.AnswerCallbackQueryParams{CallbackQueryID: q.ID, Text: answer}); err != nil {
+		log.Printf("[telegram] answer callback: %v", err)
+	}
+}
+
+// loadTrials returns the stored trials. Callers hold trialsMu.
+func (h *Handler) loadTrials(ctx context.Context) ([]trial, error) {
+	raw, ok, err := h.st.GetSetting(ctx, trialsSetting)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var list []trial
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		return nil, fmt.Errorf("decode trials: %w", err)
+	}
+	return list, nil
+}
+
+// saveTrials replaces the stored trials. Callers hold trialsMu.
+func (h *Handler) saveTrials(ctx context.Context, list []trial) error {
+	if len(list) == 0 {
+		return h.st.DeleteSetting(ctx, trialsSetting)
+	}
+	raw, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return h.st.SetSetting(ctx, trialsSetting, string(raw))
+}
+
+// startTrial tracks t.Addr for t.User and records when it expires,
+// replacing any earlier trial of the same wallet for that user. The wallet
+// is tagged trialTag while the trial lasts, so tag:trial selects trials.
+func (h *Handler) startTrial(ctx context.Context, t trial) error {
+	if err := h.trackFor(ctx, t.User, t.Addr); err != nil && !errors.Is(err, store.ErrWalletAlreadyTracked) {
+		return err
+	}
+	h.trialsMu.Lock()
+	defer h.trialsMu.Unlock()
+	list, err := h.loadTrials(ctx)
+	if err != nil {
+		return err
+	}
+	list = dropTrial(list, t.User, t.Addr)
+	if err := h.saveTrials(ctx, append(list, t)); err != nil {
+		return err
+	}
+	return h.setWalletTag(ctx, t.User, t.Addr, trialTag, true)
+}
+
+// endTrial forgets user's trial of addr, if any, leaving the wallet tracked
+// (or not) as it is and dropping its trialTag. It reports whether there was
+// one.
+func (h *Handler) endTrial(ctx context.Context, user int64, addr string) (bool, error) {
+	h.trialsMu.Lock()
+	defer h.trialsMu.Unlock()
+	list, err := h.loadTrials(ctx)
+	if err != nil {
+		return false, err
+	}
+	kept := dropTrial(list, user, addr)
+	if len(kept) == len(list) {
+		return false, nil
+	}
+	if err := h.saveTrials(ctx, kept); err != nil {
+		return true, err
+	}
+	return true, h.setWalletTag(ctx, user, addr, trialTag, false)
+}
+
+func dropTrial(list []trial, user int64, addr string) []trial {
+	out := list[:0:0]
+	for _, t := range list {
+		if t.User != user || t.Addr != addr {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// RunTrialSweeper ends expired trials every interval until ctx is done.
+func (h *Handler) RunTrialSweeper(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		h.sweepTrials(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// sweepTrials sends due reminders and ends expired trials. An expired trial
+// stays stored until its wallet was kept or untracked, so a failed untrack
+// is retried on the next sweep.
+func (h *Handler) sweepTrials(ctx context.Context) {
+	h.trialsMu.Lock()
+	list, err := h.loadTrials(ctx)
+	if err != nil {
+		h.trialsMu.Unlock()
+		log.Printf("[handler] trial sweep: %v", err)
+		return
+	}
+	now := time.Now()
+	var expired, remind []trial
+	for i, t := range list {
+		switch {
+		case !now.Before(t.Until):
+			expired = append(expired, t)
+		case t.reminderDue(now):
+			list[i].Reminded = true
+			remind = append(remind, t)
+		}
+	}
+	if len(remind) > 0 {
+		if err := h.saveTrials(ctx, list); err != nil {
+			h.trialsMu.Unlock()
+			log.Printf("[handler] trial sweep: %v", err)
+			return
+		}
+	}
+	h.trialsMu.Unlock()
+
+	for _, t := range remind {
+		h.sendButton(ctx, t.User, fmt.Sprintf("⏳ Trial of <code>%s</code> ends in %s and it will be untracked.",
+			escapeHTML(shortAddr(t.Addr)), holdString(t.Until.Sub(now))), "📌 Keep tracking", keepCallback+t.Addr)
+	}
+	for _, t := range expired {
+		if t.IfQuiet && h.activeSince(ctx, t.Addr, t.Started) {
+			if _, err := h.endTrial(ctx, t.User, t.Addr); err != nil {
+				log.Printf("[handler] ending trial of %s for %d: %v", t.Addr, t.User, err)
+				continue
+			}
+			metrics.Inc("trials.kept")
+			h.sendHTML(ctx, t.User, fmt.Sprintf("✅ <code>%s</code> was active during its trial, so it stays tracked. <code>/untrack %s</code> to stop.",
+				escapeHTML(shortAddr(t.Addr)), escapeHTML(t.Addr)))
+			continue
+		}
+		// untrackFor forgets the trial once the wallet is gone.
+		err := h.untrackFor(ctx, t.User, t.Addr)
+		if errors.Is(err, store.ErrWalletNotFound) {
+			_, err = h.endTrial(ctx, t.User, t.Addr)
+		}
+		if err != nil {
+			log.Printf("[handler] ending trial of %s for %d: %v", t.Addr, t.User, err)
+			continue
+		}
+		metrics.Inc("trials.expired")
+		h.sendHTML(ctx, t.User, fmt.Sprintf("⌛ Trial of <code>%s</code> ended after %s; no longer tracking it.",
+			escapeHTML(shortAddr(t.Addr)), holdString(t.Until.Sub(t.Started))))
+	}
+}
+
+// activeSince reports whether addr has history at or after since.
+func (h *Handler) activeSince(ctx context.Context, addr string, since time.Time) bool {
+	hist, err := h.st.RecentHistory(ctx, addr, 1)
+	if err != nil {
+		log.Printf("[handler] history for %s: %v", addr, err)
+		return true // keep rather than drop on a read error
+	}
+	return len(hist) > 0 && !hist[0].Time.Before(since)
+}
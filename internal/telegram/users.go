@@ -0,0 +1,350 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/portfolio"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+	"github.com/0xsamyy/solwatch-v2/internal/tracker"
+)
+
+// Every chat that talks to the bot is a user with its own watchlist and
+// settings, keyed by chat ID. The admin chat is always allowed and has no
+// wallet quota; ALLOWED_USERS adds further chats (multi-user mode).
+
+var errQuota = errors.New("wallet quota reached")
+
+// userSettings lists the per-user toggles exposed via /set, with help text.
+var userSettings = map[string]string{
+	"airdrops": "alert on unsolicited token receipts",
+	"bots":     "alert on wallets classified as likely bots",
+	"markdown": "format alerts (and your templates) as MarkdownV2 instead of HTML",
+	"compact":  "show amounts of a million or more as 1.25M, 3.4B",
+}
+
+// userChoice is a per-user setting with a fixed set of values.
+type userChoice struct {
+	help   string
+	values []string
+	def    string
+}
+
+// userChoices lists the multi-valued settings exposed via /set.
+var userChoices = map[string]userChoice{
+	"numbers":  {"number style: en 1,234.5 · de 1.234,5 · fr 1 234,5 · ch 1'234.5 · plain 1234.5", []string{"en", "de", "fr", "ch", "plain"}, "en"},
+	"digits":   {"significant digits for amounts below 1", []string{"2", "3", "4", "5", "6"}, "3"},
+	"layout":   {"alert layout: full block or one compact line (per wallet: /layout)", []string{layoutFull, layoutCompact}, layoutFull},
+	"severity": {"least severe alert to receive (high-priority wallets always alert)", analyzer.SeverityNames(), analyzer.SeverityInfo.String()},
+}
+
+// userSettingDefault returns the value a user gets before changing key.
+func (h *Handler) userSettingDefault(key string) bool {
+	switch key {
+	case "airdrops":
+		return !h.SuppressAirdrops
+	case "markdown", "compact":
+		return false
+	default:
+		return true
+	}
+}
+
+// AllowUsers lets the given chats use the bot in addition to the admin.
+func (h *Handler) AllowUsers(ids ...int64) {
+	if h.allowed == nil {
+		h.allowed = make(map[int64]bool, len(ids))
+	}
+	for _, id := range ids {
+		h.allowed[id] = true
+	}
+}
+
+func (h *Handler) isAdmin(chatID int64) bool { return chatID == h.adminID }
+
+func (h *Handler) isAllowed(chatID int64) bool {
+	return h.isAdmin(chatID) || h.allowed[chatID]
+}
+
+// ClaimUnownedWallets assigns wallets tracked before multi-user support (or
+// otherwise without an owner) to the admin so their alerts keep flowing.
+func (h *Handler) ClaimUnownedWallets(ctx context.Context) error {
+	addrs, err := h.st.ListWallets(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range addrs {
+		users, err := h.st.ListWalletUsers(ctx, a)
+		if err != nil {
+			return err
+		}
+		if len(users) > 0 {
+			continue
+		}
+		if err := h.st.AddUserWallet(ctx, h.adminID, a); err != nil {
+			return err
+		}
+		log.Printf("[handler] assigned unowned wallet %s to admin", a)
+	}
+	return nil
+}
+
+// Resubscribe restores the shared subscriptions for every stored watchlist
+// at startup, taking one reference per watching user. The report counts the
+// wallets resubscribed and keeps the error of each one that failed.
+func (h *Handler) Resubscribe(ctx context.Context) (ResubscribeReport, error) {
+	rep := ResubscribeReport{Failed: make(map[string]error)}
+	addrs, err := h.st.ListWallets(ctx)
+	if err != nil {
+		return rep, err
+	}
+	for _, a := range addrs {
+		users, err := h.st.ListWalletUsers(ctx, a)
+		if err != nil {
+			log.Printf("[handler] owners of %s: %v", a, err)
+			rep.Failed[a] = err
+			continue
+		}
+		for _, u := range users {
+			if err := h.tm.Acquire(ctx, a, u); err != nil && !errors.Is(err, tracker.ErrAlreadyAcquired) {
+				log.Printf("[handler] track %s: %v", a, err)
+				rep.Failed[a] = err
+			}
+		}
+		h.syncLogFilter(ctx, a)
+		if _, failed := rep.Failed[a]; !failed {
+			rep.Wallets++
+		}
+	}
+	return rep, nil
+}
+
+// trackFor adds addr to user's watchlist and makes sure it is subscribed.
+// It returns store.ErrWalletAlreadyTracked when the wallet was already
+// there.
+func (h *Handler) trackFor(ctx context.Context, user int64, addr string) error {
+	if !h.isAdmin(user) && h.MaxWalletsPerUser > 0 {
+		list, err := h.st.ListUserWallets(ctx, user)
+		if err != nil {
+			return err
+		}
+		if len(list) >= h.MaxWalletsPerUser && !contains(list, addr) {
+			return fmt.Errorf("%w (%d)", errQuota, h.MaxWalletsPerUser)
+		}
+	}
+	if err := h.st.AddWallet(ctx, addr); err != nil {
+		return err
+	}
+	stored := h.st.AddUserWallet(ctx, user, addr)
+	if stored != nil && !errors.Is(stored, store.ErrWalletAlreadyTracked) {
+		h.rollbackTrack(ctx, user, addr, false)
+		return stored
+	}
+	// Acquire even for a known wallet so a missing subscription is restored.
+	if err := h.tm.Acquire(ctx, addr, user); err != nil && !errors.Is(err, tracker.ErrAlreadyAcquired) {
+		if stored == nil {
+			h.rollbackTrack(ctx, user, addr, true)
+		}
+		return err
+	}
+	h.syncLogFilter(ctx, addr)
+	return stored
+}
+
+// rollbackTrack undoes a trackFor that failed half-way so the store never
+// lists a wallet the tracker doesn't watch: it drops addr from user's list
+// (when it was added) and from the global list when nobody else has it.
+func (h *Handler) rollbackTrack(ctx context.Context, user int64, addr string, added bool) {
+	if added {
+		if err := h.st.RemoveUserWallet(ctx, user, addr); err != nil {
+			log.Printf("[handler] rollback of %s for %d: %v", addr, user, err)
+		}
+	}
+	users, err := h.st.ListWalletUsers(ctx, addr)
+	if err != nil || len(users) > 0 {
+		return
+	}
+	if err := h.st.RemoveWallet(ctx, addr); err != nil {
+		log.Printf("[handler] rollback of %s: %v", addr, err)
+	}
+}
+
+// TrackAs adds addr to owner's watchlist on behalf of an API client.
+func (h *Handler) TrackAs(ctx context.Context, owner int64, addr string) error {
+	return h.trackFor(ctx, owner, addr)
+}
+
+// UntrackAs removes addr from owner's watchlist on behalf of an API client.
+func (h *Handler) UntrackAs(ctx context.Context, owner int64, addr string) error {
+	return h.untrackFor(ctx, owner, addr)
+}
+
+// WalletsOf returns owner's watchlist.
+func (h *Handler) WalletsOf(ctx context.Context, owner int64) ([]string, error) {
+	return h.st.ListUserWallets(ctx, owner)
+}
+
+// untrackFor removes addr from user's watchlist. The subscription and the
+// wallet's shared state only go away once no user watches it any more.
+// It returns store.ErrWalletNotFound when the user didn't watch addr.
+func (h *Handler) untrackFor(ctx context.Context, user int64, addr string) error {
+	err := h.st.RemoveUserWallet(ctx, user, addr)
+	switch {
+	case errors.Is(err, store.ErrWalletNotFound) && !contains64(h.tm.Owners(addr), user):
+		return err
+	case err != nil && !errors.Is(err, store.ErrWalletNotFound):
+		return err
+	}
+	if _, err := h.endTrial(ctx, user, addr); err != nil {
+		return err
+	}
+	if err := h.st.DeleteSetting(ctx, store.UserSettingKey(user, priorityKey(addr))); err != nil {
+		return err
+	}
+	if err := h.st.DeleteSetting(ctx, store.UserSettingKey(user, layoutKey(addr))); err != nil {
+		return err
+	}
+	if err := h.st.DeleteSetting(ctx, store.UserSettingKey(user, logFilterKey(addr))); err != nil {
+		return err
+	}
+	if err := h.st.DeleteSetting(ctx, store.UserSettingKey(user, tagsKey(addr))); err != nil {
+		return err
+	}
+	if err := h.st.DeleteSetting(ctx, store.UserSettingKey(user, noteKey(addr))); err != nil {
+		return err
+	}
+	if err := h.st.DeleteSetting(ctx, store.UserSettingKey(user, muteKey(addr))); err != nil {
+		return err
+	}
+	if !h.tm.Release(ctx, addr, user) {
+		h.syncLogFilter(ctx, addr)
+		return nil
+	}
+	h.tm.SetLogFilter(addr, nil)
+	if err := h.st.RemoveWallet(ctx, addr); err != nil {
+		return err
+	}
+	if err := portfolio.Forget(ctx, h.st, addr); err != nil {
+		return err
+	}
+	h.forgetWallet(addr)
+	return nil
+}
+
+// recipients returns the chats whose routing rules accept res. Every owner
+// of the wallet is considered; with none (ad-hoc Track) the admin is used so
+// alerts are never silently dropped.
+func (h *Handler) recipients(ctx context.Context, res *analyzer.Result, isBot bool) []int64 {
+	owners := h.tm.Owners(res.Wallet)
+	if len(owners) == 0 {
+		owners = []int64{h.adminID}
+	}
+	var out []int64
+	for _, u := range owners {
+		if res.Airdrop && !h.userFlag(ctx, u, "airdrops", h.userSettingDefault("airdrops")) {
+			continue
+		}
+		if isBot && !h.userFlag(ctx, u, "bots", h.userSettingDefault("bots")) {
+			continue
+		}
+		if !h.severityWanted(ctx, u, res) || h.tagMuted(ctx, u, res.Wallet) || !h.mutedUntil(ctx, u, res.Wallet).IsZero() {
+			continue
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+// severityWanted reports whether res is severe enough for user's "severity"
+// setting. High-priority wallets pass regardless.
+func (h *Handler) severityWanted(ctx context.Context, user int64, res *analyzer.Result) bool {
+	least, err := analyzer.ParseSeverity(h.userValue(ctx, user, "severity"))
+	if err != nil || res.Severity >= least {
+		return true
+	}
+	return h.walletPriority(ctx, user, res.Wallet) == priorityHigh
+}
+
+// userFlag reads a per-user on/off setting, falling back to def.
+func (h *Handler) userFlag(ctx context.Context, user int64, key string, def bool) bool {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(user, key))
+	if err != nil || !ok {
+		return def
+	}
+	return v == "on"
+}
+
+// userValue reads a per-user choice setting, falling back to its default.
+func (h *Handler) userValue(ctx context.Context, user int64, key string) string {
+	v, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(user, key))
+	if err != nil || !ok || !contains(userChoices[key].values, v) {
+		return userChoices[key].def
+	}
+	return v
+}
+
+// numberFormat builds the chat's amount format from its settings.
+func (h *Handler) numberFormat(ctx context.Context, chatID int64) analyzer.NumberFormat {
+	nf := analyzer.NumberLocales[h.userValue(ctx, chatID, "numbers")]
+	nf.SigDigits, _ = strconv.Atoi(h.userValue(ctx, chatID, "digits"))
+	nf.Compact = h.userFlag(ctx, chatID, "compact", h.userSettingDefault("compact"))
+	nf.CompactAbove = h.CompactAbove
+	return nf
+}
+
+func (h *Handler) handleSettings(ctx context.Context, chatID int64) {
+	var b strings.Builder
+	b.WriteString("⚙️ <b>Your settings:</b>\n")
+	for _, k := range sortedKeys(userSettings) {
+		state := "off"
+		if h.userFlag(ctx, chatID, k, h.userSettingDefault(k)) {
+			state = "on"
+		}
+		b.WriteString(fmt.Sprintf("- <code>%s</code>: <b>%s</b> — %s\n", k, state, userSettings[k]))
+	}
+	for _, k := range sortedKeys(userChoices) {
+		c := userChoices[k]
+		b.WriteString(fmt.Sprintf("- <code>%s</code>: <b>%s</b> — %s (%s)\n",
+			k, h.userValue(ctx, chatID, k), escapeHTML(c.help), strings.Join(c.values, "|")))
+	}
+	b.WriteString("\nChange with <code>/set &lt;name&gt; &lt;value&gt;</code>")
+	h.sendHTML(ctx, chatID, b.String())
+}
+
+func (h *Handler) handleSet(ctx context.Context, chatID int64, args []string) {
+	if len(args) != 2 {
+		h.sendHTML(ctx, chatID, "usage: <code>/set &lt;name&gt; &lt;value&gt;</code>")
+		return
+	}
+	if c, ok := userChoices[args[0]]; ok {
+		if !contains(c.values, args[1]) {
+			h.sendHTML(ctx, chatID, fmt.Sprintf("<code>%s</code> must be one of %s", escapeHTML(args[0]), strings.Join(c.values, ", ")))
+			return
+		}
+	} else if _, ok := userSettings[args[0]]; !ok {
+		h.sendHTML(ctx, chatID, "unknown setting. see <code>/settings</code>")
+		return
+	} else if args[1] != "on" && args[1] != "off" {
+		h.sendHTML(ctx, chatID, "usage: <code>/set &lt;name&gt; on|off</code>")
+		return
+	}
+	if err := h.st.SetSetting(ctx, store.UserSettingKey(chatID, args[0]), args[1]); err != nil {
+		h.sendHTML(ctx, chatID, "set failed: "+errorText(err))
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("<code>%s</code> is now <b>%s</b>", escapeHTML(args[0]), escapeHTML(args[1])))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/health"
+)
+
+// maxDroppedListed caps the addresses listed in one dropped alert.
+const maxDroppedListed = 20
+
+// NotifyDropped reports a health.Watchdog alert to the admin chat.
+func (h *Handler) NotifyDropped(ctx context.Context, a health.Alert) {
+	if a.Resolved {
+		h.sendHTML(ctx, h.adminID, "✅ <b>All subscriptions recovered</b>")
+		return
+	}
+
+	var b strings.Builder
+	if a.Repeat == 0 {
+		fmt.Fprintf(&b, "🚨 <b>%d subscription(s) dropped</b>", len(a.Dropped))
+	} else {
+		fmt.Fprintf(&b, "🚨 <b>Still dropped: %d subscription(s)</b> (reminder #%d)", len(a.Dropped), a.Repeat)
+	}
+	fmt.Fprintf(&b, "\nOutage: <code>%s</code>\n", a.Outage.Round(time.Second))
+	for i, d := range a.Dropped {
+		if i == maxDroppedListed {
+			fmt.Fprintf(&b, "\n… and %d more", len(a.Dropped)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n- <code>%s</code> since %s", escapeHTML(d.Address), d.Since.UTC().Format("15:04:05"))
+	}
+	b.WriteString("\n\nSee <code>/health</code>.")
+	h.sendHTML(ctx, h.adminID, b.String())
+}
@@ -0,0 +1,148 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/analyzer"
+	"github.com/0xsamyy/solwatch-v2/internal/email"
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+	"github.com/0xsamyy/solwatch-v2/internal/store"
+)
+
+// A chat's watched tokens combine its wallet watchlist with a token
+// watchlist: when any of its tracked wallets trades a watched mint, the
+// alert gets a loud banner. Each entry is an email.Rule on the mint, so
+// both sinks share one matcher.
+
+const (
+	watchTokensSetting = "watchtokens" // JSON: mint -> "buy"|"sell"|""
+	maxWatchedTokens   = 50
+)
+
+func (h *Handler) watchedTokens(ctx context.Context, chatID int64) map[string]string {
+	raw, ok, err := h.st.GetSetting(ctx, store.UserSettingKey(chatID, watchTokensSetting))
+	if err != nil || !ok {
+		return nil
+	}
+	var m map[string]string
+	_ = json.Unmarshal([]byte(raw), &m)
+	return m
+}
+
+func (h *Handler) saveWatchedTokens(ctx context.Context, chatID int64, m map[string]string) error {
+	key := store.UserSettingKey(chatID, watchTokensSetting)
+	if len(m) == 0 {
+		return h.st.DeleteSetting(ctx, key)
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return h.st.SetSetting(ctx, key, string(raw))
+}
+
+// tokenBanner returns the HTML banner for res when it trades one of the
+// chat's watched tokens, or "".
+func (h *Handler) tokenBanner(ctx context.Context, chatID int64, res *analyzer.Result) string {
+	watched := h.watchedTokens(ctx, chatID)
+	if len(watched) == 0 {
+		return ""
+	}
+	ev := events.FromResult(res)
+	for _, mint := range sortedKeys(watched) {
+		r := email.Rule{Tokens: map[string]bool{mint: true}, Side: watched[mint]}
+		if !r.Match(ev) {
+			continue
+		}
+		leg, _ := r.TokenLeg(ev)
+		verb := "sold"
+		if r.Side == "buy" || r.Side == "" && (email.Rule{Tokens: r.Tokens, Side: "buy"}).Match(ev) {
+			verb = "bought"
+		}
+		return fmt.Sprintf("🎯🎯 <b>WATCHED TOKEN: %s %s %s</b> 🎯🎯\n\n",
+			escapeHTML(shortAddr(res.Wallet)), verb, escapeHTML(leg.Symbol))
+	}
+	return ""
+}
+
+// handleWatchToken adds a mint to the chat's watched tokens.
+//
+//	/watchtoken <mint> [buy|sell]
+func (h *Handler) handleWatchToken(ctx context.Context, chatID int64, args []string) {
+	if len(args) < 1 || len(args) > 2 || !isBase58Len(args[0], 32) {
+		h.sendHTML(ctx, chatID, "usage: <code>/watchtoken &lt;mint&gt; [buy|sell]</code>")
+		return
+	}
+	mint, side := args[0], ""
+	if len(args) == 2 {
+		side = strings.ToLower(args[1])
+		if side != "buy" && side != "sell" {
+			h.sendHTML(ctx, chatID, "side must be <code>buy</code> or <code>sell</code> (omit for both)")
+			return
+		}
+	}
+	m := h.watchedTokens(ctx, chatID)
+	if m == nil {
+		m = make(map[string]string)
+	}
+	if _, ok := m[mint]; !ok && len(m) >= maxWatchedTokens {
+		h.sendHTML(ctx, chatID, fmt.Sprintf("you can watch at most %d tokens", maxWatchedTokens))
+		return
+	}
+	m[mint] = side
+	if err := h.saveWatchedTokens(ctx, chatID, m); err != nil {
+		h.sendHTML(ctx, chatID, "watch failed: "+errorText(err))
+		return
+	}
+	what := "trades"
+	if side != "" {
+		what = side + "s"
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("🎯 You'll get a loud alert when a tracked wallet %s <code>%s</code>", what, escapeHTML(mint)))
+}
+
+// handleUnwatchToken removes a mint from the chat's watched tokens.
+//
+//	/unwatchtoken <mint>
+func (h *Handler) handleUnwatchToken(ctx context.Context, chatID int64, args []string) {
+	if len(args) != 1 {
+		h.sendHTML(ctx, chatID, "usage: <code>/unwatchtoken &lt;mint&gt;</code>")
+		return
+	}
+	m := h.watchedTokens(ctx, chatID)
+	if _, ok := m[args[0]]; !ok {
+		h.sendHTML(ctx, chatID, "that token isn't watched. see <code>/watchtokens</code>")
+		return
+	}
+	delete(m, args[0])
+	if err := h.saveWatchedTokens(ctx, chatID, m); err != nil {
+		h.sendHTML(ctx, chatID, "unwatch failed: "+errorText(err))
+		return
+	}
+	h.sendHTML(ctx, chatID, fmt.Sprintf("🗑 No longer watching <code>%s</code>", escapeHTML(args[0])))
+}
+
+func (h *Handler) listWatchedTokens(ctx context.Context, chatID int64) {
+	m := h.watchedTokens(ctx, chatID)
+	if len(m) == 0 {
+		h.sendHTML(ctx, chatID, "No watched tokens. Add one with <code>/watchtoken &lt;mint&gt; [buy|sell]</code>")
+		return
+	}
+	var b strings.Builder
+	b.WriteString("🎯 <b>Watched tokens:</b>\n")
+	for _, mint := range sortedKeys(m) {
+		side := m[mint]
+		if side == "" {
+			side = "buy/sell"
+		}
+		symbol := mint
+		if meta, ok := h.analyzer.CachedMetadata(mint); ok && !meta.Failed {
+			symbol = meta.Symbol
+		}
+		b.WriteString(fmt.Sprintf("- <b>%s</b> <code>%s</code> (%s)\n", escapeHTML(symbol), escapeHTML(mint), side))
+	}
+	h.sendHTML(ctx, chatID, b.String())
+}
@@ -0,0 +1,111 @@
+package tracker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/solanarpc"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// Backfill recovers the signatures a subscriber missed while its
+// connection was down: once a reconnect is acknowledged it asks the RPC for
+// the wallet's signatures since the last one notified and delivers them
+// like live ones. A nil RPC disables it. Block-mode feeds are not
+// backfilled.
+type Backfill struct {
+	// RPC answers getSignaturesForAddress, and getTransaction for wallets
+	// with a log filter.
+	RPC *solanarpc.Client
+	// Limit caps the signatures recovered per gap (0 = DefaultBackfillLimit);
+	// beyond it the oldest are lost.
+	Limit int
+}
+
+const (
+	DefaultBackfillLimit = 100
+	// backfillSlack widens the gap backwards so clock skew between us and
+	// the validators doesn't drop a signature from the moment of the cut.
+	backfillSlack = time.Minute
+)
+
+// confirmedCommitment maps the subscription commitment to one
+// getSignaturesForAddress and blockSubscribe accept (neither has
+// "processed").
+func confirmedCommitment(c string) string {
+	if c == "finalized" {
+		return c
+	}
+	return "confirmed"
+}
+
+// recoverGap delivers the signatures that landed after *until, the last
+// one notified before the connection dropped, at about since. Only
+// signatures with a block time after since (less backfillSlack) are
+// delivered, so an until the RPC doesn't know can't replay the wallet's
+// history. It runs on the subscriber's context rather than the
+// connection's so a second drop doesn't cut the recovery short.
+func (s *Subscriber) recoverGap(ctx context.Context, until *string, since time.Time) {
+	rpc := *s.backfill.RPC
+	rpc.Commitment = confirmedCommitment(s.commitment)
+	limit := s.backfill.Limit
+	if limit <= 0 {
+		limit = DefaultBackfillLimit
+	}
+	sigs, err := rpc.GetSignaturesForAddress(ctx, s.addr, solanarpc.SignaturesOptions{Until: *until, Limit: limit})
+	if err != nil {
+		util.Errors.Printf("[sub %s] backfill failed: %v", s.prettyAddr(), err)
+		metrics.Inc("subscriber.backfill_failed")
+		return
+	}
+	if len(sigs) == limit {
+		log.Printf("[sub %s] backfill hit its limit of %d; older missed signatures are lost", s.prettyAddr(), limit)
+	}
+
+	if len(sigs) > 0 {
+		// Unless a live notification moved it on meanwhile, the next gap
+		// starts after the newest signature recovered here.
+		s.lastSig.CompareAndSwap(until, &sigs[0].Signature)
+	}
+
+	cutoff := since.Add(-backfillSlack)
+	n := 0
+	for i := len(sigs) - 1; i >= 0; i-- { // oldest first, as they happened
+		sig := sigs[i]
+		if sig.Failed() || (sig.BlockTime != nil && time.Unix(*sig.BlockTime, 0).Before(cutoff)) {
+			continue
+		}
+		if s.isDuplicate(sig.Signature) || !s.backfillPasses(ctx, &rpc, sig.Signature) {
+			continue
+		}
+		s.counters.signatures.Add(1)
+		select {
+		case s.out <- Signature{Signature: sig.Signature, Wallet: s.addr, Received: time.Now()}:
+			n++
+		case <-s.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+	if n > 0 {
+		log.Printf("[sub %s] backfilled %d signature(s) missed while disconnected", s.prettyAddr(), n)
+		metrics.Add("subscriber.backfilled", int64(n))
+	}
+}
+
+// backfillPasses applies the wallet's log filter to a backfilled
+// signature, fetching its logs only when a filter is set. A failed fetch
+// lets the signature through, as analysis will look at it anyway.
+func (s *Subscriber) backfillPasses(ctx context.Context, rpc *solanarpc.Client, signature string) bool {
+	if s.filter.Load() == nil {
+		return true
+	}
+	tx, err := rpc.GetTransaction(ctx, signature)
+	if err != nil || tx == nil || tx.Meta == nil {
+		return true
+	}
+	return s.passes(tx.Meta.LogMessages)
+}
@@ -0,0 +1,163 @@
+package tracker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// In block mode (Manager.BlockMode) the Manager keeps one blockSubscribe
+// connection, the feed, instead of one logsSubscribe per wallet, and
+// matches every transaction's accounts against the tracked set locally.
+// That costs the bandwidth of the whole chain but needs a single
+// connection, so provider per-connection subscription limits no longer
+// cap the watchlist. Each wallet still has a Subscriber in Manager.subs,
+// a view that shares the feed's connection state and keeps its own
+// counters, so health and stats work the same in both modes.
+
+// blockNotification is a `blockSubscribe` message with
+// transactionDetails=accounts.
+type blockNotification struct {
+	rpcReply
+	Params struct {
+		Result struct {
+			Value struct {
+				Slot  uint64 `json:"slot"`
+				Block *struct {
+					Transactions []struct {
+						Transaction struct {
+							Signatures  []string `json:"signatures"`
+							AccountKeys []struct {
+								Pubkey string `json:"pubkey"`
+							} `json:"accountKeys"`
+						} `json:"transaction"`
+						Meta *struct {
+							Err any `json:"err"`
+						} `json:"meta"`
+					} `json:"transactions"`
+				} `json:"block"`
+			} `json:"value"`
+		} `json:"result"`
+	} `json:"params"`
+}
+
+// blockFilter is the feed's tracked set: wallet -> its view Subscriber.
+type blockFilter struct {
+	mu      sync.RWMutex
+	views   map[string]*Subscriber
+	changed chan struct{} // see changedSignal
+}
+
+func (f *blockFilter) add(s *Subscriber) {
+	f.mu.Lock()
+	f.views[s.addr] = s
+	f.notifyLocked()
+	f.mu.Unlock()
+}
+
+func (f *blockFilter) remove(addr string) (empty bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.views, addr)
+	f.notifyLocked()
+	return len(f.views) == 0
+}
+
+func (f *blockFilter) notifyLocked() {
+	select {
+	case f.changed <- struct{}{}:
+	default: // nobody listening, or a change is already pending
+	}
+}
+
+// newFeed returns the block-mode connection Subscriber.
+func newFeed(wss, commitment string, out chan<- Signature) *Subscriber {
+	s := NewSubscriber(wss, commitment, "blocks", out)
+	s.blocks = &blockFilter{views: make(map[string]*Subscriber)}
+	return s
+}
+
+// newView returns the per-wallet Subscriber for addr on feed.
+func newView(feed *Subscriber, addr string) *Subscriber {
+	s := NewSubscriber("", "", addr, feed.out)
+	s.feed = feed
+	feed.blocks.add(s)
+	return s
+}
+
+// blockSubscribeRequest asks for every block's transactions with their
+// account keys only; logs and balances aren't needed for matching. Blocks
+// are streamed from confirmed at the earliest.
+func (s *Subscriber) blockSubscribeRequest() map[string]any {
+	return map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "blockSubscribe",
+		"params": []any{
+			"all",
+			map[string]any{
+				"commitment":                     confirmedCommitment(s.commitment),
+				"encoding":                       "json",
+				"transactionDetails":             "accounts",
+				"showRewards":                    false,
+				"maxSupportedTransactionVersion": 0,
+			},
+		},
+	}
+}
+
+// handleBlock passes on every successful transaction in n that touches a
+// tracked wallet, once per wallet, through that wallet's view.
+func (s *Subscriber) handleBlock(ctx context.Context, n *blockNotification) {
+	block := n.Params.Result.Value.Block
+	if block == nil {
+		return
+	}
+	now := time.Now()
+	s.blocks.mu.RLock()
+	type match struct {
+		view *Subscriber
+		sig  Signature
+	}
+	var matches []match
+	for _, tx := range block.Transactions {
+		if (tx.Meta != nil && tx.Meta.Err != nil) || len(tx.Transaction.Signatures) == 0 {
+			continue
+		}
+		sig := tx.Transaction.Signatures[0]
+		var keys []string // built on the first filtered match
+		for _, k := range tx.Transaction.AccountKeys {
+			view, ok := s.blocks.views[k.Pubkey]
+			if !ok || view.isDuplicate(sig) {
+				continue
+			}
+			if view.filter.Load() != nil {
+				if keys == nil {
+					for _, k := range tx.Transaction.AccountKeys {
+						keys = append(keys, k.Pubkey)
+					}
+				}
+				if !view.passes(keys) {
+					continue
+				}
+			}
+			view.lastEvent.Store(now.UnixNano())
+			view.counters.notifications.Add(1)
+			view.counters.signatures.Add(1)
+			matches = append(matches, match{view, Signature{Signature: sig, Wallet: k.Pubkey, Received: now}})
+		}
+	}
+	s.blocks.mu.RUnlock()
+
+	for _, m := range matches {
+		log.Printf("[sub %s] new signature detected in slot %d: %s...", m.view.prettyAddr(), n.Params.Result.Value.Slot, m.sig.Signature[:16])
+		select {
+		case s.out <- m.sig:
+		case <-s.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package tracker
+
+import "time"
+
+// KeepAlive tunes how a Subscriber notices a connection has died. Zero
+// fields take the Default* values.
+type KeepAlive struct {
+	// PingInterval is the gap between pings on a healthy connection. After
+	// a missed pong the next ping goes out after half the gap (not below
+	// minPingInterval), so a dying link is confirmed sooner.
+	PingInterval time.Duration
+	// ReadTimeout is how long a connection may stay silent (no message and
+	// no pong) before it is dropped. It must exceed PingInterval.
+	ReadTimeout time.Duration
+	// MaxMissedPongs drops the connection once this many pings in a row went
+	// unanswered, before ReadTimeout would.
+	MaxMissedPongs int
+	// IdleRecycle reconnects a connection that delivered no notification
+	// for this long even though it still answers pings, a failure mode of
+	// some RPC providers. Zero disables it: a quiet wallet looks the same.
+	IdleRecycle time.Duration
+}
+
+const (
+	DefaultPingInterval   = 20 * time.Second
+	DefaultReadTimeout    = 60 * time.Second
+	DefaultMaxMissedPongs = 2
+
+	minPingInterval = 5 * time.Second
+	// subscribeAckTimeout is how long the logsSubscribe reply may take
+	// before the connection is considered dead.
+	subscribeAckTimeout = 15 * time.Second
+)
+
+func (k KeepAlive) withDefaults() KeepAlive {
+	if k.PingInterval <= 0 {
+		k.PingInterval = DefaultPingInterval
+	}
+	if k.ReadTimeout <= 0 {
+		k.ReadTimeout = DefaultReadTimeout
+	}
+	if k.MaxMissedPongs <= 0 {
+		k.MaxMissedPongs = DefaultMaxMissedPongs
+	}
+	return k
+}
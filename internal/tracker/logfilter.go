@@ -0,0 +1,65 @@
+package tracker
+
+import (
+	"strings"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// A wallet's log filter lets through only notifications whose logs mention
+// one of its terms (typically program IDs), before the signature costs an
+// enhanced-API fetch. In block mode there are no logs; the terms are matched
+// against the transaction's account keys instead, so only account and
+// program IDs work there.
+
+// passes reports whether lines (log lines or account keys) mention one of
+// the subscriber's filter terms; without a filter everything passes.
+// Rejections are counted.
+func (s *Subscriber) passes(lines []string) bool {
+	terms := s.filter.Load()
+	if terms == nil || len(*terms) == 0 {
+		return true
+	}
+	for _, line := range lines {
+		for _, t := range *terms {
+			if strings.Contains(line, t) {
+				return true
+			}
+		}
+	}
+	s.counters.filtered.Add(1)
+	metrics.Inc("subscriber.filtered")
+	return false
+}
+
+// SetLogFilter limits addr's notifications to those whose logs mention one
+// of terms; no terms removes the filter. It applies to the running
+// subscription at once and survives Pause and re-tracking.
+func (m *Manager) SetLogFilter(addr string, terms []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(terms) == 0 {
+		delete(m.filters, addr)
+	} else {
+		m.filters[addr] = append([]string(nil), terms...)
+	}
+	if sub, ok := m.subs[addr]; ok {
+		sub.setFilter(m.filters[addr])
+	}
+}
+
+// LogFilter returns addr's filter terms, if any.
+func (m *Manager) LogFilter(addr string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string(nil), m.filters[addr]...)
+}
+
+func (s *Subscriber) setFilter(terms []string) {
+	if len(terms) == 0 {
+		s.filter.Store(nil)
+		return
+	}
+	s.filter.Store(&terms)
+}
@@ -2,27 +2,85 @@ package tracker
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/util"
 )
 
+// ErrAlreadyAcquired is returned by Acquire when the owner already watches
+// the wallet.
+var ErrAlreadyAcquired = errors.New("wallet already acquired by owner")
+
 // Manager owns the set of active Subscribers (one per wallet).
 // It is concurrency-safe via an internal RWMutex.
+//
+// In multi-user mode several owners can watch the same wallet; they share one
+// subscription, reference-counted via Acquire/Release.
 type Manager struct {
+	// DialRate caps new WebSocket connections per second across all
+	// subscribers (reconnects included). Zero means no cap. Set it before
+	// the first Track or Acquire.
+	DialRate float64
+	// WSSPool, when set, replaces the single WebSocket endpoint with one per
+	// Helius API key; each new subscription goes to the endpoint with the
+	// fewest. Set it before the first Track or Acquire.
+	WSSPool []string
+	// KeepAlive tunes dead-connection detection for every subscriber.
+	KeepAlive KeepAlive
+	// Reconnect tunes retries and the reconnect-storm limiter. Set it
+	// before the first Track or Acquire.
+	Reconnect Reconnect
+	// BlockMode watches every wallet through one blockSubscribe connection
+	// filtered locally instead of a logsSubscribe per wallet (see block.go).
+	// Set it before the first Track or Acquire.
+	BlockMode bool
+	// Backfill recovers signatures missed while a subscriber was
+	// reconnecting (see backfill.go). Set it before the first Track or
+	// Acquire.
+	Backfill Backfill
+	// Source, when set, streams every wallet through one connection of its
+	// own (e.g. Yellowstone gRPC, see source.go) instead of WebSockets.
+	// It takes precedence over BlockMode. Set it before the first Track or
+	// Acquire.
+	Source Source
+	// Context bounds every subscriber and feed the Manager starts; the ctx
+	// given to Track, Acquire and Resume only covers that call. Defaults to
+	// context.Background(). Set it before the first Track or Acquire.
+	Context context.Context
+
 	wss        string
 	commitment string
+	out        chan<- Signature
+
+	mu      sync.RWMutex
+	subs    map[string]*Subscriber        // addr -> sub
+	owners  map[string]map[int64]struct{} // addr -> owners holding a reference
+	paused  map[string]struct{}           // non-nil while paused: addrs to start on Resume
+	filters map[string][]string           // addr -> log filter terms (see SetLogFilter)
+
+	rampOnce sync.Once
+	ramp     *dialRamp
+	storm    *stormGuard
+	feed     *Subscriber // the blockSubscribe connection in BlockMode, or the Source's
 
-	mu   sync.RWMutex
-	subs map[string]*Subscriber // addr -> sub
+	shardOf   map[string]int // addr -> index into WSSPool
+	shardLoad []int          // subscriptions per WSSPool entry
 }
 
 // NewManager constructs a Manager that will spawn subscribers using the
-// provided WebSocket endpoint and commitment level.
-func NewManager(wss, commitment string) *Manager {
+// provided WebSocket endpoint and commitment level. Every subscriber sends
+// new signatures to out; the consumer must keep draining it.
+func NewManager(wss, commitment string, out chan<- Signature) *Manager {
 	return &Manager{
 		wss:        wss,
 		commitment: commitment,
+		out:        out,
 		subs:       make(map[string]*Subscriber),
+		owners:     make(map[string]map[int64]struct{}),
+		filters:    make(map[string][]string),
 	}
 }
 
@@ -32,28 +90,210 @@ func (m *Manager) Track(ctx context.Context, addr string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.subs[addr]; exists {
-		return nil
-	}
-
-	sub := NewSubscriber(m.wss, m.commitment, addr)
-	m.subs[addr] = sub
-	go sub.Run(ctx) // long-running; will auto-reconnect until Stop or ctx cancel
+	m.startLocked(ctx, addr)
 	return nil
 }
 
-// Untrack stops and removes the subscriber for addr, if present.
+// Untrack stops and removes the subscriber for addr, if present, regardless
+// of how many owners still reference it.
 func (m *Manager) Untrack(_ context.Context, addr string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if sub, ok := m.subs[addr]; ok {
-		sub.Stop() // graceful: closes WS and halts reconnect attempts
-		delete(m.subs, addr)
+	m.stopLocked(addr)
+	delete(m.owners, addr)
+	return nil
+}
+
+// Acquire records owner as a watcher of addr and starts the subscriber if
+// this is the first reference. Acquiring twice returns ErrAlreadyAcquired
+// and changes nothing.
+func (m *Manager) Acquire(ctx context.Context, addr string, owner int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.owners[addr] == nil {
+		m.owners[addr] = make(map[int64]struct{})
+	}
+	if _, ok := m.owners[addr][owner]; ok {
+		return ErrAlreadyAcquired
 	}
+	m.owners[addr][owner] = struct{}{}
+	m.startLocked(ctx, addr)
 	return nil
 }
 
+// Release drops owner's reference to addr and stops the subscriber once no
+// owner is left. It reports whether the subscription was stopped.
+func (m *Manager) Release(_ context.Context, addr string, owner int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.owners[addr], owner)
+	if len(m.owners[addr]) > 0 {
+		return false
+	}
+	delete(m.owners, addr)
+	m.stopLocked(addr)
+	return true
+}
+
+// Owners returns the owners currently referencing addr, in ascending order.
+func (m *Manager) Owners(addr string) []int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]int64, 0, len(m.owners[addr]))
+	for o := range m.owners[addr] {
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func (m *Manager) startLocked(_ context.Context, addr string) {
+	if m.paused != nil {
+		m.paused[addr] = struct{}{}
+		return
+	}
+	if _, exists := m.subs[addr]; exists {
+		return
+	}
+	m.rampOnce.Do(func() {
+		m.ramp = newDialRamp(m.DialRate)
+		m.storm = newStormGuard(m.Reconnect.withDefaults())
+	})
+	ctx := m.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var sub *Subscriber
+	if m.Source != nil || m.BlockMode {
+		if m.feed == nil {
+			if m.Source != nil {
+				m.feed = m.configure(newSourceFeed(m.Source, m.commitment, m.out))
+			} else {
+				m.feed = m.configure(newFeed(m.wss, m.commitment, m.out))
+			}
+			go util.Supervise(ctx, "blockfeed", m.feed.Run)
+		}
+		sub = newView(m.feed, addr)
+	} else {
+		sub = m.configure(NewSubscriber(m.shardLocked(addr), m.commitment, addr, m.out))
+	}
+	sub.setFilter(m.filters[addr])
+	m.subs[addr] = sub
+	go util.Supervise(ctx, "subscriber", sub.Run) // long-running; will auto-reconnect until Stop or m.Context ends
+}
+
+// configure hands s the Manager's shared dial and reconnect settings.
+func (m *Manager) configure(s *Subscriber) *Subscriber {
+	s.ramp = m.ramp
+	s.keepAlive = m.KeepAlive
+	s.reconnect = m.Reconnect
+	s.storm = m.storm
+	s.backfill = m.Backfill
+	return s
+}
+
+func (m *Manager) stopLocked(addr string) {
+	delete(m.paused, addr)
+	if sub, ok := m.subs[addr]; ok {
+		m.dropLocked(addr, sub)
+	}
+}
+
+// dropLocked stops sub and forgets it; the block feed goes with the last
+// wallet it serves.
+func (m *Manager) dropLocked(addr string, sub *Subscriber) {
+	sub.Stop() // graceful: closes WS and halts reconnect attempts
+	delete(m.subs, addr)
+	m.unshardLocked(addr)
+	if sub.feed != nil && sub.feed.blocks.remove(addr) {
+		sub.feed.Stop()
+		if m.feed == sub.feed {
+			m.feed = nil
+		}
+	}
+}
+
+// shardLocked picks the WebSocket endpoint for a new subscription to addr:
+// the least-loaded WSSPool entry, or the single endpoint without a pool.
+func (m *Manager) shardLocked(addr string) string {
+	if len(m.WSSPool) == 0 {
+		return m.wss
+	}
+	if m.shardOf == nil {
+		m.shardOf = make(map[string]int)
+		m.shardLoad = make([]int, len(m.WSSPool))
+	}
+	best := 0
+	for i, n := range m.shardLoad {
+		if n < m.shardLoad[best] {
+			best = i
+		}
+	}
+	m.shardOf[addr] = best
+	m.shardLoad[best]++
+	return m.WSSPool[best]
+}
+
+func (m *Manager) unshardLocked(addr string) {
+	if i, ok := m.shardOf[addr]; ok {
+		m.shardLoad[i]--
+		delete(m.shardOf, addr)
+	}
+}
+
+// ShardLoad returns how many subscriptions each WSSPool endpoint carries,
+// in pool order; nil without a pool.
+func (m *Manager) ShardLoad() []int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]int(nil), m.shardLoad...)
+}
+
+// Pause stops every subscriber but remembers the wallets and their owners,
+// so Resume restarts exactly what was running. While paused, Track and
+// Acquire only record the wallet. It reports how many subscribers it
+// stopped, and is a no-op when already paused.
+func (m *Manager) Pause() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.paused != nil {
+		return 0
+	}
+	m.paused = make(map[string]struct{}, len(m.subs))
+	n := len(m.subs)
+	for addr, sub := range m.subs {
+		m.dropLocked(addr, sub)
+		m.paused[addr] = struct{}{}
+	}
+	return n
+}
+
+// Resume starts a subscriber for every wallet remembered while paused and
+// reports how many. It is a no-op when not paused.
+func (m *Manager) Resume(ctx context.Context) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending := m.paused
+	m.paused = nil
+	for addr := range pending {
+		m.startLocked(ctx, addr)
+	}
+	return len(pending)
+}
+
+// Paused reports whether subscriptions are paused.
+func (m *Manager) Paused() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.paused != nil
+}
+
 // List returns a sorted snapshot of currently tracked addresses.
 func (m *Manager) List() []string {
 	m.mu.RLock()
@@ -93,6 +333,34 @@ func (m *Manager) Stats() (tracked int, open int, dropped []string) {
 	return
 }
 
+// Dialing reports how many subscribers are waiting for a DialRate slot.
+func (m *Manager) Dialing() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ramp.queued()
+}
+
+// IsOpen reports whether addr has a subscription that is currently open.
+func (m *Manager) IsOpen(addr string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.subs[addr]
+	return ok && s.IsOpen()
+}
+
+// LastEvents returns the last notification time of every subscriber; the
+// zero time means nothing has arrived since it started.
+func (m *Manager) LastEvents() map[string]time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make(map[string]time.Time, len(m.subs))
+	for addr, s := range m.subs {
+		out[addr] = s.LastEvent()
+	}
+	return out
+}
+
 // StopAll is a helper to gracefully stop every subscriber.
 // (Not required for your commands, but useful for clean shutdowns.)
 func (m *Manager) StopAll() {
@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dialRamp spaces out WebSocket dials so hundreds of subscribers started at
+// once (startup, /resumeall, a provider outage) don't trip the provider's
+// connection-rate limit. A nil *dialRamp lets every dial through.
+type dialRamp struct {
+	every time.Duration
+
+	mu      sync.Mutex
+	next    time.Time
+	waiting atomic.Int64
+}
+
+// newDialRamp allows perSecond dials a second; zero or less means no limit.
+func newDialRamp(perSecond float64) *dialRamp {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &dialRamp{every: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the caller's dial slot comes up. It returns false when
+// ctx is done or stop is closed first.
+func (r *dialRamp) wait(ctx context.Context, stop <-chan struct{}) bool {
+	if r == nil {
+		return true
+	}
+	r.mu.Lock()
+	now := time.Now()
+	slot := r.next
+	if slot.Before(now) {
+		slot = now
+	}
+	r.next = slot.Add(r.every)
+	r.mu.Unlock()
+
+	d := time.Until(slot)
+	if d <= 0 {
+		return true
+	}
+	r.waiting.Add(1)
+	defer r.waiting.Add(-1)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// queued is how many dials are waiting for their slot.
+func (r *dialRamp) queued() int {
+	if r == nil {
+		return 0
+	}
+	return int(r.waiting.Load())
+}
@@ -0,0 +1,204 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+	"github.com/0xsamyy/solwatch-v2/internal/util"
+)
+
+// A Source streams the transactions of a set of wallets over one
+// connection, in place of the per-wallet logsSubscribe connections (see
+// Manager.Source). As in block mode the Manager keeps a single feed
+// Subscriber that runs the Source, and a view Subscriber per wallet for
+// health and stats; the feed reconnects with the usual backoff and storm
+// guard, and hands the stream the new wallet set whenever it changes.
+type Source interface {
+	// Name identifies the source in logs, e.g. "geyser".
+	Name() string
+	// Subscribe opens a stream of the successful transactions mentioning
+	// one of wallets (never empty) at commitment. The stream ends when ctx
+	// is cancelled.
+	Subscribe(ctx context.Context, commitment string, wallets []string) (SourceStream, error)
+}
+
+// SourceStream is an open Source subscription.
+type SourceStream interface {
+	// Recv blocks for the next transaction; an error ends the stream.
+	Recv() (SourceTx, error)
+	// Update replaces the subscribed wallets (never empty) on the open
+	// stream.
+	Update(wallets []string) error
+}
+
+// SourceTx is one transaction delivered by a Source.
+type SourceTx struct {
+	Signature string
+	Slot      uint64
+	Accounts  []string // account keys, including those loaded from lookup tables
+	Logs      []string // log messages, when the source provides them
+}
+
+// newSourceFeed returns the connection Subscriber that runs src.
+func newSourceFeed(src Source, commitment string, out chan<- Signature) *Subscriber {
+	s := newFeed("", commitment, out)
+	s.addr = src.Name()
+	s.source = src
+	return s
+}
+
+// wallets returns the tracked set, sorted.
+func (f *blockFilter) wallets() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make([]string, 0, len(f.views))
+	for addr := range f.views {
+		out = append(out, addr)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// changedSignal returns a channel that receives after the tracked set
+// changed; a Source feed waits on it to resubscribe.
+func (f *blockFilter) changedSignal() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.changed == nil {
+		f.changed = make(chan struct{}, 1)
+	}
+	return f.changed
+}
+
+// runSource is Run for a Source feed.
+func (s *Subscriber) runSource(ctx context.Context) {
+	rc := s.reconnect.withDefaults()
+	bo := util.NewBackoff(rc.Initial, rc.Max, rc.Factor, rc.Jitter)
+	changed := s.blocks.changedSignal()
+	go s.cleanCache(ctx)
+
+	for {
+		if !s.ShouldBeOpen() {
+			return
+		}
+		wallets := s.blocks.wallets()
+		if len(wallets) == 0 {
+			// The feed starts before its first view is added; an empty
+			// filter would stream every transaction on chain.
+			select {
+			case <-changed:
+				continue
+			case <-s.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+		if !s.storm.wait(ctx, s.stopCh) || !s.ramp.wait(ctx, s.stopCh) {
+			return
+		}
+
+		connCtx, connCancel := context.WithCancel(ctx)
+		stream, err := s.source.Subscribe(connCtx, s.commitment, wallets)
+		if err != nil {
+			connCancel()
+			wait := bo.Next()
+			util.Errors.Printf("[sub %s] subscribe error: %v; retrying in %s", s.prettyAddr(), err, wait)
+			s.counters.failed(fmt.Errorf("subscribe: %w", err))
+			s.storm.failed()
+			util.ReportError("subscriber", fmt.Errorf("%s subscribe: %w", s.addr, err))
+			time.Sleep(wait)
+			continue
+		}
+		log.Printf("[sub %s] streaming %d wallet(s)", s.prettyAddr(), len(wallets))
+		s.open.Store(true)
+		s.counters.connected()
+		bo.Reset()
+
+		go func() {
+			for {
+				select {
+				case <-changed:
+				case <-s.stopCh:
+					connCancel()
+					return
+				case <-connCtx.Done():
+					return
+				}
+				if wallets := s.blocks.wallets(); len(wallets) > 0 {
+					if err := stream.Update(wallets); err != nil {
+						util.Errors.Printf("[sub %s] resubscribe error: %v; reconnecting", s.prettyAddr(), err)
+						connCancel()
+						return
+					}
+				}
+			}
+		}()
+
+		for {
+			tx, err := stream.Recv()
+			if err != nil {
+				if s.ShouldBeOpen() && ctx.Err() == nil {
+					util.Errors.Printf("[sub %s] stream error: %v", s.prettyAddr(), err)
+					s.counters.failed(fmt.Errorf("stream: %w", err))
+					s.storm.failed()
+					metrics.Inc("subscriber.stream_failed")
+					util.ReportError("subscriber", fmt.Errorf("%s stream: %w", s.addr, err))
+				}
+				break
+			}
+			s.lastEvent.Store(time.Now().UnixNano())
+			s.counters.notifications.Add(1)
+			s.handleSourceTx(ctx, tx)
+		}
+		s.open.Store(false)
+		connCancel()
+	}
+}
+
+// handleSourceTx passes tx on through the view of every tracked wallet it
+// mentions, once per wallet.
+func (s *Subscriber) handleSourceTx(ctx context.Context, tx SourceTx) {
+	if tx.Signature == "" {
+		return
+	}
+	now := time.Now()
+	var matches []*Subscriber
+	s.blocks.mu.RLock()
+	for _, k := range tx.Accounts {
+		view, ok := s.blocks.views[k]
+		if !ok || slices.Contains(matches, view) || view.isDuplicate(tx.Signature) {
+			continue
+		}
+		if view.filter.Load() != nil {
+			lines := tx.Logs
+			if len(lines) == 0 {
+				lines = tx.Accounts
+			}
+			if !view.passes(lines) {
+				continue
+			}
+		}
+		view.lastEvent.Store(now.UnixNano())
+		view.counters.notifications.Add(1)
+		view.counters.signatures.Add(1)
+		matches = append(matches, view)
+	}
+	s.blocks.mu.RUnlock()
+
+	for _, view := range matches {
+		log.Printf("[sub %s] new signature detected in slot %d: %s...", view.prettyAddr(), tx.Slot, tx.Signature[:min(16, len(tx.Signature))])
+		select {
+		case s.out <- Signature{Signature: tx.Signature, Wallet: view.addr, Received: now}:
+		case <-s.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
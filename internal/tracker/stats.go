@@ -0,0 +1,79 @@
+package tracker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SubscriberStats are one subscription's counters since it was started.
+type SubscriberStats struct {
+	Open          bool      `json:"open"`
+	Notifications int64     `json:"notifications"` // logs notifications, failed transactions included
+	Signatures    int64     `json:"signatures"`    // new signatures passed on for analysis
+	Filtered      int64     `json:"filtered"`      // signatures dropped by the wallet's log filter
+	Reconnects    int64     `json:"reconnects"`
+	LastConnect   time.Time `json:"last_connect,omitzero"`
+	LastEvent     time.Time `json:"last_event,omitzero"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorAt   time.Time `json:"last_error_at,omitzero"`
+}
+
+// subscriberCounters is the mutable side of SubscriberStats.
+type subscriberCounters struct {
+	notifications atomic.Int64
+	signatures    atomic.Int64
+	filtered      atomic.Int64
+	connects      atomic.Int64
+	lastConnect   atomic.Int64 // unix nanos
+
+	errMu   sync.Mutex
+	lastErr string
+	errAt   time.Time
+}
+
+func (c *subscriberCounters) connected() {
+	c.connects.Add(1)
+	c.lastConnect.Store(time.Now().UnixNano())
+}
+
+func (c *subscriberCounters) failed(err error) {
+	c.errMu.Lock()
+	c.lastErr, c.errAt = err.Error(), time.Now()
+	c.errMu.Unlock()
+}
+
+// Stats returns the subscription's counters.
+func (s *Subscriber) Stats() SubscriberStats {
+	st := SubscriberStats{
+		Open:          s.IsOpen(),
+		Notifications: s.counters.notifications.Load(),
+		Signatures:    s.counters.signatures.Load(),
+		Filtered:      s.counters.filtered.Load(),
+		LastEvent:     s.LastEvent(),
+	}
+	conn := s
+	if s.feed != nil {
+		conn = s.feed // a view shares the feed's connection
+	}
+	st.Reconnects = max(conn.counters.connects.Load()-1, 0)
+	if n := conn.counters.lastConnect.Load(); n != 0 {
+		st.LastConnect = time.Unix(0, n)
+	}
+	conn.counters.errMu.Lock()
+	st.LastError, st.LastErrorAt = conn.counters.lastErr, conn.counters.errAt
+	conn.counters.errMu.Unlock()
+	return st
+}
+
+// SubscriberStats returns the counters of every running subscription.
+func (m *Manager) SubscriberStats() map[string]SubscriberStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make(map[string]SubscriberStats, len(m.subs))
+	for addr, s := range m.subs {
+		out[addr] = s.Stats()
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package tracker
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// Reconnect tunes how subscribers retry. Zero fields take the Default*
+// values.
+type Reconnect struct {
+	// Initial, Max, Factor and Jitter shape each subscriber's dial backoff
+	// (see util.NewBackoff).
+	Initial time.Duration
+	Max     time.Duration
+	Factor  float64
+	Jitter  float64
+	// StormThreshold connection failures across all subscribers within
+	// StormWindow count as a provider blip: every reconnect is then held
+	// for StormWindow and released spread over another StormWindow, instead
+	// of hundreds of subscribers retrying in lockstep. A negative
+	// threshold disables the guard.
+	StormThreshold int
+	StormWindow    time.Duration
+}
+
+const (
+	DefaultReconnectInitial = time.Second
+	DefaultReconnectMax     = 30 * time.Second
+	DefaultReconnectFactor  = 2.0
+	DefaultReconnectJitter  = 0.2
+	DefaultStormThreshold   = 20
+	DefaultStormWindow      = 10 * time.Second
+)
+
+func (r Reconnect) withDefaults() Reconnect {
+	if r.Initial <= 0 {
+		r.Initial = DefaultReconnectInitial
+	}
+	if r.Max <= 0 {
+		r.Max = DefaultReconnectMax
+	}
+	if r.Factor <= 0 {
+		r.Factor = DefaultReconnectFactor
+	}
+	if r.Jitter <= 0 {
+		r.Jitter = DefaultReconnectJitter
+	}
+	if r.StormThreshold == 0 {
+		r.StormThreshold = DefaultStormThreshold
+	}
+	if r.StormWindow <= 0 {
+		r.StormWindow = DefaultStormWindow
+	}
+	return r
+}
+
+// stormGuard is the Manager-wide reconnect-storm limiter described on
+// Reconnect. A nil *stormGuard never holds anyone.
+type stormGuard struct {
+	threshold int
+	window    time.Duration
+
+	mu       sync.Mutex
+	failures []time.Time
+	until    time.Time // reconnects are held until then
+}
+
+func newStormGuard(r Reconnect) *stormGuard {
+	if r.StormThreshold < 0 {
+		return nil
+	}
+	return &stormGuard{threshold: r.StormThreshold, window: r.StormWindow}
+}
+
+// failed records a dropped connection or failed dial.
+func (g *stormGuard) failed() {
+	if g == nil {
+		return
+	}
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	keep := g.failures[:0]
+	for _, t := range g.failures {
+		if now.Sub(t) < g.window {
+			keep = append(keep, t)
+		}
+	}
+	g.failures = append(keep, now)
+	if len(g.failures) >= g.threshold && now.After(g.until) {
+		g.until = now.Add(g.window)
+		g.failures = g.failures[:0]
+		metrics.Inc("subscriber.storms")
+		log.Printf("[tracker] reconnect storm: %d failures within %s; holding reconnects for %s", g.threshold, g.window, g.window)
+	}
+}
+
+// wait holds a reconnect while a storm is on, then releases it at a random
+// point of the following window. It returns false when ctx is done or stop
+// is closed first.
+func (g *stormGuard) wait(ctx context.Context, stop <-chan struct{}) bool {
+	if g == nil {
+		return true
+	}
+	g.mu.Lock()
+	until := g.until
+	g.mu.Unlock()
+	d := time.Until(until)
+	if d <= 0 {
+		return true
+	}
+	d += time.Duration(rand.Int63n(int64(g.window)))
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-t.C:
+		return true
+	}
+}
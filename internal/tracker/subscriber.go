@@ -3,6 +3,8 @@ package tracker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -10,22 +12,38 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
 	"github.com/0xsamyy/solwatch-v2/internal/util"
 	"github.com/gorilla/websocket"
 )
 
-// V2 Change: The callback now includes the address of the wallet that was triggered.
-// This is essential for the handler to know which wallet to associate the signature with.
-var SignatureNotify func(signature string, trackedAddr string)
+// Signature is a new transaction signature seen for a tracked wallet.
+// Subscribers deliver them on the channel given to NewManager.
+type Signature struct {
+	Signature string
+	Wallet    string // the tracked address whose logs mentioned it
+	Received  time.Time
+}
+
+// rpcReply is what every message on a subscription connection has in
+// common: either the reply to our subscribe request or a notification.
+type rpcReply struct {
+	ID    int `json:"id"` // 1 on the reply to our subscribe request
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Method string `json:"method"`
+}
 
 // logsNotification defines the structure of a `logsSubscribe` message from the RPC.
 type logsNotification struct {
-	Method string `json:"method"`
+	rpcReply
 	Params struct {
 		Result struct {
 			Value struct {
-				Signature string `json:"signature"`
-				Err       any    `json:"err"`
+				Signature string   `json:"signature"`
+				Err       any      `json:"err"`
+				Logs      []string `json:"logs"`
 			} `json:"value"`
 		} `json:"result"`
 	} `json:"params"`
@@ -36,20 +54,37 @@ type Subscriber struct {
 	wss        string
 	addr       string
 	commitment string
+	out        chan<- Signature
 
 	open       atomic.Bool
 	shouldOpen atomic.Bool
+	lastEvent  atomic.Int64           // unix nanos of the last notification; 0 = none yet
+	lastSig    atomic.Pointer[string] // newest signature notified; see recoverGap
 
 	dedupeCache map[string]time.Time
 	dedupeMutex sync.Mutex
 
 	stopOnce sync.Once
 	stopCh   chan struct{}
+
+	ramp      *dialRamp   // paces dials; set by the Manager
+	keepAlive KeepAlive   // set by the Manager
+	reconnect Reconnect   // set by the Manager
+	storm     *stormGuard // set by the Manager
+	backfill  Backfill    // set by the Manager
+	counters  subscriberCounters
+
+	filter atomic.Pointer[[]string] // log filter terms; see SetLogFilter
+	blocks *blockFilter             // set on the block-mode or Source feed
+	feed   *Subscriber              // set on a wallet view of a feed
+	source Source                   // set on a Source feed
 }
 
-// NewSubscriber creates a new Subscriber. Call Run() to start it.
-func NewSubscriber(wss, commitment, addr string) *Subscriber {
+// NewSubscriber creates a new Subscriber that delivers signatures to out.
+// Call Run() to start it.
+func NewSubscriber(wss, commitment, addr string, out chan<- Signature) *Subscriber {
 	s := &Subscriber{
+		out:         out,
 		wss:         strings.TrimSpace(wss),
 		addr:        strings.TrimSpace(addr),
 		commitment:  strings.TrimSpace(commitment),
@@ -60,9 +95,24 @@ func NewSubscriber(wss, commitment, addr string) *Subscriber {
 	return s
 }
 
-func (s *Subscriber) IsOpen() bool       { return s.open.Load() }
+func (s *Subscriber) IsOpen() bool {
+	if s.feed != nil {
+		return s.feed.open.Load()
+	}
+	return s.open.Load()
+}
+
 func (s *Subscriber) ShouldBeOpen() bool { return s.shouldOpen.Load() }
 
+// LastEvent returns when the last logs notification arrived (including
+// failed transactions), or the zero time if none has yet.
+func (s *Subscriber) LastEvent() time.Time {
+	if n := s.lastEvent.Load(); n != 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
 func (s *Subscriber) Stop() {
 	s.stopOnce.Do(func() {
 		s.shouldOpen.Store(false)
@@ -105,7 +155,16 @@ func (s *Subscriber) cleanCache(ctx context.Context) {
 }
 
 func (s *Subscriber) Run(ctx context.Context) {
-	bo := util.NewBackoff(1*time.Second, 30*time.Second, 2.0, 0.2)
+	if s.feed != nil {
+		s.cleanCache(ctx) // a view has no connection of its own
+		return
+	}
+	if s.source != nil {
+		s.runSource(ctx)
+		return
+	}
+	rc := s.reconnect.withDefaults()
+	bo := util.NewBackoff(rc.Initial, rc.Max, rc.Factor, rc.Jitter)
 	go s.cleanCache(ctx)
 
 	for {
@@ -113,16 +172,25 @@ func (s *Subscriber) Run(ctx context.Context) {
 			return
 		}
 
+		if !s.storm.wait(ctx, s.stopCh) || !s.ramp.wait(ctx, s.stopCh) {
+			return
+		}
 		conn, _, err := websocket.DefaultDialer.DialContext(ctx, s.wss, http.Header{})
 		if err != nil {
 			wait := bo.Next()
-			log.Printf("[sub %s] dial error: %v; retrying in %s", s.prettyAddr(), err, wait)
+			util.Errors.Printf("[sub %s] dial error: %v; retrying in %s", s.prettyAddr(), err, wait)
+			s.counters.failed(fmt.Errorf("dial: %w", err))
+			s.storm.failed()
+			util.ReportError("subscriber", fmt.Errorf("dial for %s: %w", s.addr, err))
 			time.Sleep(wait)
 			continue
 		}
 
 		s.open.Store(true)
+		s.counters.connected()
 		bo.Reset()
+		ka := s.keepAlive.withDefaults()
+		connected := time.Now()
 
 		connCtx, connCancel := context.WithCancel(ctx)
 		go func() {
@@ -134,9 +202,23 @@ func (s *Subscriber) Run(ctx context.Context) {
 			_ = conn.Close()
 		}()
 
-		_ = conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		// dropped is set when a health check below closes the connection,
+		// so the read error that follows isn't reported as a failure.
+		var dropped, acked atomic.Bool
+		var missed atomic.Int32 // pings sent since the last pong
+		drop := func(reason, detail string) {
+			if dropped.CompareAndSwap(false, true) {
+				log.Printf("[sub %s] %s; reconnecting", s.prettyAddr(), detail)
+				s.counters.failed(errors.New(detail))
+				metrics.Inc("subscriber." + reason)
+				connCancel()
+			}
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(ka.ReadTimeout))
 		conn.SetPongHandler(func(string) error {
-			return conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+			missed.Store(0)
+			return conn.SetReadDeadline(time.Now().Add(ka.ReadTimeout))
 		})
 
 		subMsg := map[string]any{
@@ -148,58 +230,163 @@ func (s *Subscriber) Run(ctx context.Context) {
 				map[string]any{"commitment": s.commitment},
 			},
 		}
+		if s.blocks != nil {
+			subMsg = s.blockSubscribeRequest()
+		}
 		if err := conn.WriteJSON(subMsg); err != nil {
-			log.Printf("[sub %s] subscribe error: %v", s.prettyAddr(), err)
+			util.Errors.Printf("[sub %s] subscribe error: %v", s.prettyAddr(), err)
+			s.counters.failed(fmt.Errorf("subscribe: %w", err))
+			util.ReportError("subscriber", fmt.Errorf("subscribe for %s: %w", s.addr, err))
+			s.open.Store(false)
 			connCancel()
 			continue
 		}
 
+		// Pinger: after a missed pong the gap halves so a dead link is
+		// confirmed quickly; MaxMissedPongs unanswered pings drop it.
+		go func() {
+			interval := ka.PingInterval
+			t := time.NewTimer(interval)
+			defer t.Stop()
+			for {
+				select {
+				case <-connCtx.Done():
+					return
+				case <-t.C:
+				}
+				switch n := int(missed.Load()); {
+				case n >= ka.MaxMissedPongs:
+					drop("missed_pongs", fmt.Sprintf("%d pings unanswered", n))
+					return
+				case n > 0:
+					interval = max(interval/2, minPingInterval)
+				default:
+					interval = ka.PingInterval
+				}
+				missed.Add(1)
+				if err := conn.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(5*time.Second)); err != nil {
+					return
+				}
+				t.Reset(interval)
+			}
+		}()
+
+		// Liveness: the subscription must be acknowledged, and a connection
+		// that answers pings but delivers nothing for IdleRecycle is
+		// recycled, as some providers silently stop notifying.
 		go func() {
-			ticker := time.NewTicker(20 * time.Second)
-			defer ticker.Stop()
+			select {
+			case <-connCtx.Done():
+				return
+			case <-time.After(subscribeAckTimeout):
+			}
+			if !acked.Load() {
+				drop("no_ack", "logsSubscribe not acknowledged")
+				return
+			}
+			if ka.IdleRecycle <= 0 {
+				return
+			}
+			t := time.NewTicker(min(ka.IdleRecycle/4, time.Minute))
+			defer t.Stop()
 			for {
 				select {
 				case <-connCtx.Done():
 					return
-				case <-ticker.C:
-					if err := conn.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(5*time.Second)); err != nil {
-						return
-					}
+				case <-t.C:
+				}
+				last := s.LastEvent()
+				if last.Before(connected) {
+					last = connected
+				}
+				if idle := time.Since(last); idle >= ka.IdleRecycle {
+					drop("recycled", fmt.Sprintf("no notification for %s", idle.Round(time.Second)))
+					return
 				}
 			}
 		}()
 
+		method := subMsg["method"]
+		rejected := false
 		for {
 			_, msg, err := conn.ReadMessage()
 			if err != nil {
-				log.Printf("[sub %s] read error: %v", s.prettyAddr(), err)
+				if dropped.Load() {
+					break
+				}
+				util.Errors.Printf("[sub %s] read error: %v", s.prettyAddr(), err)
+				s.counters.failed(fmt.Errorf("read: %w", err))
+				if s.ShouldBeOpen() && ctx.Err() == nil {
+					s.storm.failed()
+					util.ReportError("subscriber", fmt.Errorf("read for %s: %w", s.addr, err))
+				}
 				break
 			}
+			_ = conn.SetReadDeadline(time.Now().Add(ka.ReadTimeout))
 
 			var notif logsNotification
-			if err := json.Unmarshal(msg, &notif); err != nil {
+			var block blockNotification
+			reply := &notif.rpcReply
+			if s.blocks != nil {
+				reply = &block.rpcReply
+				err = json.Unmarshal(msg, &block)
+			} else {
+				err = json.Unmarshal(msg, &notif)
+			}
+			if err != nil {
+				continue
+			}
+			if reply.Method == "" && reply.ID == 1 {
+				if reply.Error != nil {
+					util.Errors.Printf("[sub %s] %s rejected: %s", s.prettyAddr(), method, reply.Error.Message)
+					s.counters.failed(fmt.Errorf("%s rejected: %s", method, reply.Error.Message))
+					util.ReportError("subscriber", fmt.Errorf("%s for %s: %s", method, s.addr, reply.Error.Message))
+					rejected = true
+					break
+				}
+				acked.Store(true)
+				if last := s.lastSig.Load(); last != nil && s.backfill.RPC != nil && s.blocks == nil {
+					go s.recoverGap(ctx, last, time.Unix(0, s.lastEvent.Load()))
+				}
+				continue
+			}
+			if reply.Method == "blockNotification" {
+				s.lastEvent.Store(time.Now().UnixNano())
+				s.counters.notifications.Add(1)
+				s.handleBlock(ctx, &block)
 				continue
 			}
 
+			if notif.Method == "logsNotification" {
+				s.lastEvent.Store(time.Now().UnixNano())
+				s.counters.notifications.Add(1)
+			}
 			if notif.Method != "logsNotification" || notif.Params.Result.Value.Signature == "" || notif.Params.Result.Value.Err != nil {
 				continue
 			}
 
 			signature := notif.Params.Result.Value.Signature
-			if s.isDuplicate(signature) {
+			s.lastSig.Store(&signature)
+			if s.isDuplicate(signature) || !s.passes(notif.Params.Result.Value.Logs) {
 				continue
 			}
 
 			log.Printf("[sub %s] new signature detected: %s...", s.prettyAddr(), signature[:16])
 
-			if SignatureNotify != nil {
-				// V2 Change: Pass both the signature AND the address of this subscriber.
-				SignatureNotify(signature, s.addr)
+			s.counters.signatures.Add(1)
+			select {
+			case s.out <- Signature{Signature: signature, Wallet: s.addr, Received: time.Now()}:
+			case <-s.stopCh:
+			case <-ctx.Done():
 			}
 		}
 
 		s.open.Store(false)
 		connCancel()
+		if rejected {
+			// e.g. a provider without blockSubscribe; don't spin on it.
+			time.Sleep(bo.Next())
+		}
 	}
 }
 
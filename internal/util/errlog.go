@@ -0,0 +1,127 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// errorIDs matches signatures and addresses (base58) and hashes and raw
+// bytes (hex), which make otherwise identical errors look different.
+var errorIDs = regexp.MustCompile(`[1-9A-HJ-NP-Za-km-z]{32,88}|\b(0x)?[0-9a-fA-F]{16,}\b`)
+
+// errorNumbers matches durations ("1.5s", "2m3s") and plain numbers: slots,
+// sizes, counts and retry delays.
+var errorNumbers = regexp.MustCompile(`\b\d+(\.\d+)?((ns|µs|us|ms|s|m|h)(\d+(\.\d+)?(ns|µs|us|ms|s|m|h))*)?\b`)
+
+// errorURLs matches URLs; their paths and query strings can hold API keys.
+var errorURLs = regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9+.-]*://[^/\s"'?#]+)[^\s"']*`)
+
+// RedactURLs cuts every URL in msg down to its scheme and host, dropping
+// paths and query strings that may carry credentials (?api-key=…).
+func RedactURLs(msg string) string {
+	return errorURLs.ReplaceAllString(msg, "$1")
+}
+
+// ErrorShape returns msg with URLs redacted, signatures, addresses and hex
+// replaced by "…" and numbers and durations by "#", so repeats of one error
+// compare equal.
+func ErrorShape(msg string) string {
+	msg = errorIDs.ReplaceAllString(RedactURLs(msg), "…")
+	return errorNumbers.ReplaceAllString(msg, "#")
+}
+
+// ErrorLog collapses repeated error log lines. The first line of each shape
+// (see ErrorShape) is logged as is; repeats within Window are only counted
+// and then logged once as "<shape> ×37 in last 5m". A zero Window logs
+// every line.
+type ErrorLog struct {
+	Window time.Duration
+
+	mu     sync.Mutex
+	groups map[string]*errorGroup
+}
+
+type errorGroup struct {
+	since   time.Time
+	repeats int
+}
+
+// Errors is the process-wide ErrorLog; main sets its Window.
+var Errors = &ErrorLog{Window: 5 * time.Minute}
+
+// Printf logs like log.Printf unless the same error was logged within the
+// window, in which case it is counted for the next summary.
+func (l *ErrorLog) Printf(format string, args ...any) {
+	msg := RedactURLs(fmt.Sprintf(format, args...))
+	if l.Window <= 0 {
+		log.Print(msg)
+		return
+	}
+	shape := ErrorShape(msg)
+	now := time.Now()
+
+	l.mu.Lock()
+	if l.groups == nil {
+		l.groups = make(map[string]*errorGroup)
+	}
+	g := l.groups[shape]
+	if g != nil && now.Sub(g.since) < l.Window {
+		g.repeats++
+		l.mu.Unlock()
+		return
+	}
+	l.groups[shape] = &errorGroup{since: now}
+	l.mu.Unlock()
+
+	if g != nil && g.repeats > 0 {
+		l.summarize(shape, g.repeats)
+	}
+	log.Print(msg)
+}
+
+// Flush logs the summary of every group whose window has passed and forgets
+// it, so the next occurrence is logged in full again.
+func (l *ErrorLog) Flush() {
+	now := time.Now()
+	due := make(map[string]int)
+	l.mu.Lock()
+	for shape, g := range l.groups {
+		if now.Sub(g.since) >= l.Window {
+			if g.repeats > 0 {
+				due[shape] = g.repeats
+			}
+			delete(l.groups, shape)
+		}
+	}
+	l.mu.Unlock()
+	for shape, n := range due {
+		l.summarize(shape, n)
+	}
+}
+
+// Run flushes summaries as their windows pass until ctx is done.
+func (l *ErrorLog) Run(ctx context.Context) {
+	if l.Window <= 0 {
+		return
+	}
+	t := time.NewTicker(l.Window / 5)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			l.Flush()
+			return
+		case <-t.C:
+			l.Flush()
+		}
+	}
+}
+
+func (l *ErrorLog) summarize(shape string, repeats int) {
+	// The first occurrence was logged in full, so it is not counted again.
+	log.Printf("%s ×%d more in last %s", shape, repeats, l.Window)
+}
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/0xsamyy/solwatch-v2/internal/metrics"
+)
+
+// PanicHandler, when set, is told about every recovered panic (main points
+// it at the admin chat). It is called on its own goroutine.
+var PanicHandler func(component string, value any, stack []byte)
+
+// Recover reports a panic instead of crashing the process. It must be
+// deferred directly:
+//
+//	defer util.Recover("analysis")
+func Recover(component string) {
+	if r := recover(); r != nil {
+		reportPanic(component, r)
+	}
+}
+
+// Go runs fn on a new goroutine with panic recovery.
+func Go(component string, fn func()) {
+	go func() {
+		defer Recover(component)
+		fn()
+	}()
+}
+
+// Supervise runs fn until it returns normally or ctx is done. When fn
+// panics, the panic is reported and fn restarted after a backoff.
+func Supervise(ctx context.Context, component string, fn func(ctx context.Context)) {
+	bo := NewBackoff(1*time.Second, time.Minute, 2.0, 0.2)
+	for {
+		if !runGuarded(ctx, component, fn) {
+			return
+		}
+		wait := bo.Next()
+		log.Printf("[panic] restarting %s in %s", component, wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runGuarded reports whether fn panicked.
+func runGuarded(ctx context.Context, component string, fn func(ctx context.Context)) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportPanic(component, r)
+			panicked = true
+		}
+	}()
+	fn(ctx)
+	return false
+}
+
+func reportPanic(component string, value any) {
+	stack := debug.Stack()
+	metrics.Inc("panics." + component)
+	log.Printf("[panic] %s: %v\n%s", component, value, stack)
+	if h := PanicHandler; h != nil {
+		go func() {
+			defer func() { _ = recover() }() // never let reporting take us down
+			h(component, value, stack)
+		}()
+	}
+}
@@ -0,0 +1,28 @@
+package util
+
+import "github.com/0xsamyy/solwatch-v2/internal/metrics"
+
+// redactedError is an error whose text has its URLs redacted (RedactURLs)
+// before it leaves the process.
+type redactedError struct{ err error }
+
+func (e redactedError) Error() string { return RedactURLs(e.err.Error()) }
+func (e redactedError) Unwrap() error { return e.err }
+
+// ErrorHandler, when set, is told about operational errors an operator
+// should see (main points it at the debug chat). It is called on its own
+// goroutine.
+var ErrorHandler func(component string, err error)
+
+// ReportError counts err under errors.<component> and passes it to
+// ErrorHandler with URLs redacted, so endpoint credentials never reach the
+// debug chat. Callers still log it themselves.
+func ReportError(component string, err error) {
+	metrics.Inc("errors." + component)
+	if h := ErrorHandler; h != nil {
+		go func() {
+			defer func() { _ = recover() }() // never let reporting take us down
+			h(component, redactedError{err})
+		}()
+	}
+}
@@ -0,0 +1,47 @@
+// Package sdk is the stable surface for solwatch extensions. A plugin is a
+// Go plugin (.so) built with `go build -buildmode=plugin` against the same
+// solwatch version and Go toolchain as the bot, exporting
+//
+//	var Plugin sdk.Plugin = myPlugin{}
+//
+// The value must implement Plugin and any of Interpreter, Filter or Sink.
+package sdk
+
+import (
+	"context"
+
+	"github.com/0xsamyy/solwatch-v2/internal/events"
+)
+
+// APIVersion is bumped on any breaking change to this package.
+const APIVersion = 1
+
+// Event is one analyzed transaction. See the JSON tags for field meanings.
+type Event = events.Event
+
+// Amount is one token leg of an Event.
+type Amount = events.Amount
+
+// Plugin identifies an extension.
+type Plugin interface {
+	Name() string
+}
+
+// Interpreter adds protocol knowledge: it returns extra plain-text lines to
+// show under the alert, or nil when it has nothing to say. Event.Programs
+// lists the program IDs the transaction invoked.
+type Interpreter interface {
+	Interpret(ctx context.Context, ev Event) []string
+}
+
+// Filter can veto an alert. Returning false drops it before it is stored,
+// published or sent.
+type Filter interface {
+	Keep(ctx context.Context, ev Event) bool
+}
+
+// Sink receives every kept event, e.g. to forward it to another system.
+// Calls happen on a dedicated goroutine per sink.
+type Sink interface {
+	Send(ctx context.Context, ev Event) error
+}